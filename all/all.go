@@ -0,0 +1,116 @@
+package all
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/calamity-m/reaphur/all/internal/conf"
+	"github.com/calamity-m/reaphur/all/internal/lifecycle"
+	"github.com/calamity-m/reaphur/central"
+	"github.com/calamity-m/reaphur/discord"
+	"github.com/calamity-m/reaphur/gw"
+	"github.com/calamity-m/reaphur/pkg/bindings"
+	"github.com/calamity-m/reaphur/pkg/inproc"
+	"github.com/calamity-m/reaphur/pkg/logging"
+	"github.com/calamity-m/reaphur/pkg/telemetry"
+	"github.com/spf13/cobra"
+)
+
+var (
+	AllCommand = &cobra.Command{
+		Use:   "all",
+		Short: "run central, the gateway and the discord bot in one process",
+		Long: `run central, the gateway and optionally the discord bot in one process, talking
+to each other in memory rather than over the network`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := conf.NewConfig(bindings.Debug)
+			if err != nil {
+				fmt.Printf("Failed to create config: %v\n", err)
+				return err
+			}
+
+			// Create logger
+			logger := slog.New(logging.NewCustomizedHandler(os.Stderr, &logging.CustomHandlerCfg{
+				Structed:        cfg.LogStructured,
+				RecordRequestId: cfg.LogRequestId,
+				Level:           cfg.LogLevel,
+				AddSource:       cfg.LogAddSource,
+				StaticAttributes: []slog.Attr{
+					slog.String("system", "reap"),
+					slog.String("environment", cfg.Environment),
+				},
+			}))
+
+			components, err := newComponents(logger, cfg)
+			if err != nil {
+				logger.Error("nothing to run", slog.Any("err", err))
+				return err
+			}
+
+			stopTracing, err := telemetry.SetupTracing(context.Background(), logger, telemetry.TracingOptions{
+				Enabled:     cfg.TracingEnabled,
+				ServiceName: "reaphur",
+				Endpoint:    cfg.TracingEndpoint,
+				Insecure:    cfg.TracingInsecure,
+				SampleRatio: cfg.TracingSampleRatio,
+			})
+			if err != nil {
+				logger.Error("failed to set up tracing", slog.Any("err", err))
+				return err
+			}
+			defer stopTracing()
+
+			metricsCtx, stopMetrics := context.WithCancel(context.Background())
+			defer stopMetrics()
+			telemetry.ServeMetrics(metricsCtx, logger, cfg.MetricsAddress)
+
+			// Create the channel which will wait for our shutdown signals which we can
+			// utilise for a nice graceful shutdown
+			sig := make(chan os.Signal, 2)
+			signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM)
+
+			return lifecycle.Run(logger, components, sig, cfg.ShutdownTimeout)
+		},
+	}
+)
+
+// The enabled components in the order they're started. Central comes first and
+// is stopped last, so the others are gone before it drains. When it's run here
+// the others reach it over an in memory link.
+func newComponents(logger *slog.Logger, cfg *conf.Config) ([]lifecycle.Component, error) {
+	var link *inproc.Link
+	components := []lifecycle.Component{}
+
+	if cfg.CentralEnabled {
+		link = inproc.NewLink()
+		components = append(components, component(logger, "central", link, central.RunInProcess))
+	}
+	if cfg.DiscordEnabled {
+		components = append(components, component(logger, "discord", link, discord.RunInProcess))
+	}
+	if cfg.GWEnabled {
+		components = append(components, component(logger, "gw", link, gw.RunInProcess))
+	}
+
+	if len(components) == 0 {
+		return nil, errors.New("every component is disabled")
+	}
+
+	return components, nil
+}
+
+func component(logger *slog.Logger, name string, link *inproc.Link, run func(*slog.Logger, *inproc.Link, <-chan os.Signal) error) lifecycle.Component {
+	logger = logger.With(slog.String("component", name))
+
+	return lifecycle.Component{
+		Name: name,
+		Run: func(notify <-chan os.Signal) error {
+			return run(logger, link, notify)
+		},
+	}
+}
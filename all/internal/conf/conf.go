@@ -0,0 +1,88 @@
+package conf
+
+import (
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/calamity-m/reaphur/pkg/bindings"
+	"github.com/mitchellh/mapstructure"
+	"github.com/spf13/viper"
+)
+
+// Configures the all in one process itself. Each component still reads its own
+// configuration, such as CENTRAL_AI_TOKEN or DISCORD_BOT_TOKEN, apart from the
+// logging, tracing and metrics set here for the whole process.
+type Config struct {
+	// Logging Configuration
+	Environment   string     `mapstructure:"environment" json:"environment,omitempty"`
+	LogLevel      slog.Level `mapstructure:"log_level" json:"log_level,omitempty"`
+	LogStructured bool       `mapstructure:"log_structured" json:"log_structured,omitempty"`
+	LogAddSource  bool       `mapstructure:"log_add_source" json:"log_add_source,omitempty"`
+	LogRequestId  bool       `mapstructure:"log_request_id" json:"log_request_id,omitempty"`
+
+	// Components run in the process. Without central the others connect to their
+	// configured central server address instead.
+	CentralEnabled bool `mapstructure:"central_enabled" json:"central_enabled,omitempty"`
+	GWEnabled      bool `mapstructure:"gw_enabled" json:"gw_enabled,omitempty"`
+	DiscordEnabled bool `mapstructure:"discord_enabled" json:"discord_enabled,omitempty"`
+
+	// How long each component gets to stop once the process is told to
+	ShutdownTimeout time.Duration `mapstructure:"shutdown_timeout" json:"shutdown_timeout,omitempty"`
+
+	// Prometheus metrics for every component are served on their own listener. Empty
+	// disables them.
+	MetricsAddress string `mapstructure:"metrics_address" json:"metrics_address,omitempty"`
+
+	// Spans are exported to an OTLP gRPC collector when tracing is enabled. The sample
+	// ratio only applies to traces started here, the rest follow their caller.
+	TracingEnabled     bool    `mapstructure:"tracing_enabled" json:"tracing_enabled,omitempty"`
+	TracingEndpoint    string  `mapstructure:"tracing_endpoint" json:"tracing_endpoint,omitempty"`
+	TracingInsecure    bool    `mapstructure:"tracing_insecure" json:"tracing_insecure,omitempty"`
+	TracingSampleRatio float64 `mapstructure:"tracing_sample_ratio" json:"tracing_sample_ratio,omitempty"`
+}
+
+func NewConfig(debug bool) (*Config, error) {
+	// Setup
+	base := &Config{}
+	vip := viper.New()
+
+	// Enable ENV var reading
+	vip.AutomaticEnv()
+	vip.SetEnvPrefix("ALL")
+	vip.SetEnvKeyReplacer(strings.NewReplacer("-", "_"))
+
+	// Establish sane defaults before overriding
+	vip.SetDefault("environment", "dev")
+	vip.SetDefault("log_level", slog.LevelDebug)
+	vip.SetDefault("log_structured", false)
+	vip.SetDefault("log_add_source", true)
+	vip.SetDefault("log_request_id", true)
+	vip.SetDefault("central_enabled", true)
+	vip.SetDefault("gw_enabled", true)
+	vip.SetDefault("discord_enabled", false)
+	vip.SetDefault("shutdown_timeout", 30*time.Second)
+	vip.SetDefault("metrics_address", bindings.DefaultCentralMetricsAddress)
+	vip.SetDefault("tracing_enabled", false)
+	vip.SetDefault("tracing_endpoint", bindings.DefaultOTLPAddress)
+	vip.SetDefault("tracing_insecure", true)
+	vip.SetDefault("tracing_sample_ratio", 1.0)
+
+	// Magic to unamrshal viper into the config sturct. The decode hook is used to map things like the logging level
+	// into the slog logging level type.
+	if err := vip.Unmarshal(&base, viper.DecodeHook(mapstructure.ComposeDecodeHookFunc(
+		mapstructure.TextUnmarshallerHookFunc(),
+		mapstructure.StringToTimeDurationHookFunc(),
+	))); err != nil {
+		return &Config{}, err
+	}
+
+	// Forecefully override if we're on debug mode
+	// Do this without viper/cobra buy-in and just do the simple
+	// brute force
+	if debug {
+		base.LogLevel = slog.LevelDebug
+	}
+
+	return base, nil
+}
@@ -0,0 +1,70 @@
+// Runs components together in one process, sharing a single lifecycle
+package lifecycle
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+)
+
+type Component struct {
+	Name string
+	// Runs the component until notify is closed, returning once it has stopped
+	Run func(notify <-chan os.Signal) error
+}
+
+type running struct {
+	Component
+	notify chan os.Signal
+	exit   chan error
+}
+
+// Starts every component, in order, and runs them until sig is pushed to or any
+// of them stops on its own. They're then stopped in reverse, each given up to
+// timeout to finish, so whatever depends on an earlier component is gone before
+// it starts draining. Returns the errors of any that failed.
+func Run(logger *slog.Logger, components []Component, sig <-chan os.Signal, timeout time.Duration) error {
+	stopped := make(chan string, len(components))
+	all := make([]running, 0, len(components))
+
+	for _, c := range components {
+		r := running{Component: c, notify: make(chan os.Signal), exit: make(chan error, 1)}
+		all = append(all, r)
+
+		go func() {
+			r.exit <- r.Run(r.notify)
+			stopped <- r.Name
+		}()
+		logger.Info(fmt.Sprintf("Started %s", c.Name))
+	}
+
+	select {
+	case s := <-sig:
+		logger.Info(fmt.Sprintf("Received %s, stopping", s))
+	case name := <-stopped:
+		logger.Warn(fmt.Sprintf("%s stopped, stopping everything else", name))
+	}
+
+	var errs []error
+	for i := len(all) - 1; i >= 0; i-- {
+		r := all[i]
+		close(r.notify)
+
+		select {
+		case err := <-r.exit:
+			if err != nil {
+				logger.Error(fmt.Sprintf("%s failed", r.Name), slog.Any("err", err))
+				errs = append(errs, fmt.Errorf("%s: %w", r.Name, err))
+				continue
+			}
+			logger.Info(fmt.Sprintf("Stopped %s", r.Name))
+		case <-time.After(timeout):
+			logger.Error(fmt.Sprintf("%s didn't stop within %s", r.Name, timeout))
+			errs = append(errs, fmt.Errorf("%s didn't stop within %s", r.Name, timeout))
+		}
+	}
+
+	return errors.Join(errs...)
+}
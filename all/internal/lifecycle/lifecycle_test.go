@@ -0,0 +1,87 @@
+package lifecycle
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"os"
+	"slices"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// Records the order components stop in
+type stops struct {
+	mu    sync.Mutex
+	order []string
+}
+
+func (s *stops) component(name string, err error) Component {
+	return Component{Name: name, Run: func(notify <-chan os.Signal) error {
+		<-notify
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.order = append(s.order, name)
+		return err
+	}}
+}
+
+func TestRunStopsInReverseOnSignal(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(&bytes.Buffer{}, nil))
+	s := &stops{}
+
+	sig := make(chan os.Signal, 1)
+	sig <- syscall.SIGTERM
+
+	err := Run(logger, []Component{s.component("central", nil), s.component("discord", nil), s.component("gw", nil)}, sig, time.Second)
+	if err != nil {
+		t.Fatalf("got err %v", err)
+	}
+
+	if want := []string{"gw", "discord", "central"}; !slices.Equal(s.order, want) {
+		t.Errorf("got stop order %v but want %v", s.order, want)
+	}
+}
+
+func TestRunStopsEverythingWhenOneFails(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(&bytes.Buffer{}, nil))
+	s := &stops{}
+	failure := errors.New("address in use")
+
+	failing := Component{Name: "gw", Run: func(notify <-chan os.Signal) error {
+		return failure
+	}}
+
+	err := Run(logger, []Component{s.component("central", nil), failing}, make(chan os.Signal), time.Second)
+	if !errors.Is(err, failure) {
+		t.Errorf("got err %v but want the gateway's failure", err)
+	}
+
+	if want := []string{"central"}; !slices.Equal(s.order, want) {
+		t.Errorf("got stops %v but want %v", s.order, want)
+	}
+}
+
+func TestRunGivesUpOnStuckComponents(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(&bytes.Buffer{}, nil))
+	s := &stops{}
+
+	stuck := Component{Name: "discord", Run: func(notify <-chan os.Signal) error {
+		select {}
+	}}
+
+	sig := make(chan os.Signal, 1)
+	sig <- syscall.SIGINT
+
+	err := Run(logger, []Component{s.component("central", nil), stuck}, sig, 10*time.Millisecond)
+	if err == nil {
+		t.Error("expected an error for the stuck component")
+	}
+
+	if want := []string{"central"}; !slices.Equal(s.order, want) {
+		t.Errorf("got stops %v but want %v, central should still be stopped", s.order, want)
+	}
+}
@@ -1,24 +1,39 @@
 package central
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"net"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 
 	"github.com/calamity-m/reaphur/central/internal/conf"
 	"github.com/calamity-m/reaphur/central/internal/fncall"
+	"github.com/calamity-m/reaphur/central/internal/health"
 	"github.com/calamity-m/reaphur/central/internal/parser"
 	"github.com/calamity-m/reaphur/central/internal/persistence"
 	"github.com/calamity-m/reaphur/central/internal/prompts"
 	"github.com/calamity-m/reaphur/central/internal/srv"
 	"github.com/calamity-m/reaphur/central/internal/util"
+	"github.com/calamity-m/reaphur/central/internal/validation"
+	"github.com/calamity-m/reaphur/pkg/auth"
 	"github.com/calamity-m/reaphur/pkg/bindings"
+	"github.com/calamity-m/reaphur/pkg/inproc"
 	"github.com/calamity-m/reaphur/pkg/logging"
+	"github.com/calamity-m/reaphur/pkg/middleware"
+	"github.com/calamity-m/reaphur/pkg/resilience"
+	"github.com/calamity-m/reaphur/pkg/telemetry"
+	"github.com/calamity-m/reaphur/pkg/tlsconf"
 	centralproto "github.com/calamity-m/reaphur/proto/v1/central"
+	"github.com/openai/openai-go/option"
+	"github.com/redis/go-redis/v9"
 	"github.com/spf13/cobra"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 )
 
 var (
@@ -45,54 +60,29 @@ var (
 				},
 			}))
 
-			// Display some helpful starting info
-			logger.Info(fmt.Sprintf("Redis Address: %s", cfg.RedisAddress))
-			logger.Info(fmt.Sprintf("GRPC Reflection: %t", cfg.Reflect))
-			logger.Info(fmt.Sprintf("Environment: %s", cfg.Environment))
-
-			// Display schemas
-			logger.Debug(
-				"scheams",
-				slog.String("create_food", prompts.CreateCardioJson),
-				slog.String("create_weight_lifting", prompts.CreateWeightLiftingJson),
-				slog.String("create_cardio", prompts.CreateCardioJson),
-				slog.String("get_food", prompts.GetFoodJson),
-			)
-
-			oa := util.CreateNewOpenAIClient(cfg.AIToken)
-			foodStore, err := persistence.NewRedisFoodStore(logger, cfg)
+			stopTracing, err := telemetry.SetupTracing(context.Background(), logger, telemetry.TracingOptions{
+				Enabled:     cfg.TracingEnabled,
+				ServiceName: "reaphur-central",
+				Endpoint:    cfg.TracingEndpoint,
+				Insecure:    cfg.TracingInsecure,
+				SampleRatio: cfg.TracingSampleRatio,
+			})
 			if err != nil {
-				logger.Error("failed to create redis food store", slog.Any("err", err))
+				logger.Error("failed to set up tracing", slog.Any("err", err))
 				return err
 			}
+			defer stopTracing()
 
-			server, err := srv.NewCentralServiceServer(
-				logger,
-				cfg,
-				parser.NewOpenAIParser(logger, oa),
-				fncall.NewOpenAIFnCaller(logger, oa),
-				foodStore,
-			)
-			if err != nil {
-				logger.Error("failed to run server", slog.Any("err", err))
-				return err
-			}
+			metricsCtx, stopMetrics := context.WithCancel(context.Background())
+			defer stopMetrics()
+			telemetry.ServeMetrics(metricsCtx, logger, cfg.MetricsAddress)
 
 			// Create the channel which will wait for our shutdown signals which we can
 			// utilise for a nice graceful shutdown
 			sig := make(chan os.Signal, 2)
 			signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM)
 
-			//  Run the server
-			exit := server.Run(sig)
-
-			// Check the exit error
-			if err = <-exit; err != nil {
-				logger.Error("Received an error on server exit", slog.Any("err", err))
-				return err
-			}
-
-			return nil
+			return run(logger, cfg, nil, sig)
 		},
 	}
 
@@ -106,6 +96,277 @@ var (
 	}
 )
 
+// Runs central until notify is pushed to or closed, serving on listener or the
+// configured address when there isn't one
+func run(logger *slog.Logger, cfg *conf.Config, listener net.Listener, notify <-chan os.Signal) error {
+	// Display some helpful starting info
+	logger.Info(fmt.Sprintf("Redis Address: %s", cfg.RedisAddress))
+	logger.Info(fmt.Sprintf("GRPC Reflection: %t", cfg.Reflect))
+	logger.Info(fmt.Sprintf("Environment: %s", cfg.Environment))
+	if cfg.AIBaseURL != "" {
+		logger.Info(fmt.Sprintf("AI Base URL: %s", cfg.AIBaseURL))
+	}
+
+	// Display schemas
+	logger.Debug(
+		"scheams",
+		slog.String("create_food", prompts.CreateCardioJson),
+		slog.String("create_weight_lifting", prompts.CreateWeightLiftingJson),
+		slog.String("create_cardio", prompts.CreateCardioJson),
+		slog.String("get_food", prompts.GetFoodJson),
+	)
+
+	// Retries are handled by our middleware rather than the client, so they can
+	// be configured and feed the circuit breaker
+	breaker := resilience.NewBreaker(cfg.LLMBreakerFailures, cfg.LLMBreakerCooldown)
+	llm := resilience.NewHTTPMiddleware(
+		logger,
+		resilience.RetryPolicy{MaxRetries: cfg.LLMMaxRetries, BaseDelay: cfg.LLMRetryBaseDelay, MaxDelay: cfg.LLMRetryMaxDelay},
+		breaker,
+		cfg.LLMAttemptTimeout,
+	)
+	oa := util.CreateNewOpenAIClient(cfg.AIToken, cfg.AIBaseURL, option.WithMaxRetries(0), option.WithMiddleware(llm.Do))
+	foodStore, err := persistence.NewRedisFoodStore(logger, cfg)
+	if err != nil {
+		logger.Error("failed to create redis food store", slog.Any("err", err))
+		return err
+	}
+
+	usageStore, err := persistence.NewRedisUsageStore(logger, cfg)
+	if err != nil {
+		logger.Error("failed to create redis usage store", slog.Any("err", err))
+		return err
+	}
+
+	if cfg.TLSCertFile != "" || cfg.TLSKeyFile != "" {
+		tlsConf, err := tlsconf.NewServerConfig(logger, tlsconf.ServerOptions{
+			CertFile:     cfg.TLSCertFile,
+			KeyFile:      cfg.TLSKeyFile,
+			ClientCAFile: cfg.TLSClientCAFile,
+		})
+		if err != nil {
+			logger.Error("failed to create tls config", slog.Any("err", err))
+			return err
+		}
+		cfg.GrpcServerOpts = append(cfg.GrpcServerOpts, grpc.Creds(credentials.NewTLS(tlsConf)))
+		logger.Info(fmt.Sprintf("TLS enabled, mutual TLS %t", cfg.TLSClientCAFile != ""))
+	}
+
+	// Request ids come first so everything after, including the logging, can use them.
+	// Errors are mapped inside the metrics and logging so the recorded code is the one
+	// sent back.
+	cfg.GrpcServerOpts = append(cfg.GrpcServerOpts,
+		grpc.StatsHandler(telemetry.GRPCServerHandler()),
+		grpc.ChainUnaryInterceptor(
+			middleware.RequestIdUnaryInterceptor(logger),
+			middleware.MetricsUnaryInterceptor(),
+			middleware.LoggingUnaryInterceptor(logger),
+			middleware.ErrorUnaryInterceptor(logger),
+		),
+		grpc.ChainStreamInterceptor(
+			middleware.RequestIdStreamInterceptor(logger),
+			middleware.MetricsStreamInterceptor(),
+			middleware.LoggingStreamInterceptor(logger),
+			middleware.ErrorStreamInterceptor(logger),
+		),
+	)
+
+	// Authentication binds the user ids before anything is rate limited on them
+	authUnary, authStream, err := newAuthInterceptors(logger, cfg)
+	if err != nil {
+		logger.Error("failed to create authentication", slog.Any("err", err))
+		return err
+	}
+	if authUnary != nil {
+		cfg.GrpcServerOpts = append(cfg.GrpcServerOpts, grpc.ChainUnaryInterceptor(authUnary), grpc.ChainStreamInterceptor(authStream))
+	}
+
+	rateLimit, rateLimitStream, err := newRateLimitInterceptors(logger, cfg)
+	if err != nil {
+		logger.Error("failed to create rate limiter", slog.Any("err", err))
+		return err
+	}
+	if rateLimit != nil {
+		cfg.GrpcServerOpts = append(cfg.GrpcServerOpts, grpc.ChainUnaryInterceptor(rateLimit), grpc.ChainStreamInterceptor(rateLimitStream))
+	}
+
+	// Validated last, once auth has filled in the user ids being checked
+	validator := validation.New()
+	cfg.GrpcServerOpts = append(cfg.GrpcServerOpts,
+		grpc.ChainUnaryInterceptor(middleware.ValidationUnaryInterceptor(validator)),
+		grpc.ChainStreamInterceptor(middleware.ValidationStreamInterceptor(validator)),
+	)
+
+	prompt, err := prompts.Load(cfg.PromptDir, prompts.CentralPromptName, cfg.PromptVersion)
+	if err != nil {
+		logger.Error("failed to load prompt template", slog.Any("err", err))
+		return err
+	}
+	logger.Info(fmt.Sprintf("Prompt Version: %s", prompt.ID()))
+
+	server, err := srv.NewCentralServiceServer(
+		logger,
+		cfg,
+		parser.NewOpenAIParser(logger, oa),
+		fncall.NewOpenAIFnCaller(logger, oa).WithPrompt(prompt, promptVars(cfg)).WithToolExecution(cfg.ToolWorkers, cfg.ToolTimeout),
+		persistence.NewInstrumentedFoodStore(foodStore, persistence.BackendRedis),
+		persistence.NewInstrumentedUsageStore(usageStore, persistence.BackendRedis),
+	)
+	if err != nil {
+		logger.Error("failed to run server", slog.Any("err", err))
+		return err
+	}
+	server.WithHealth(newHealthChecker(logger, cfg, foodStore, breaker)).WithValidation(validator)
+
+	//  Run the server
+	var exit <-chan error
+	if listener != nil {
+		exit = server.Serve(listener, notify)
+	} else {
+		exit = server.Run(notify)
+	}
+
+	// Check the exit error
+	if err = <-exit; err != nil {
+		logger.Error("Received an error on server exit", slog.Any("err", err))
+		return err
+	}
+
+	return nil
+}
+
+// Runs central on the link for the all in one mode, until notify is pushed to
+// or closed. Logging, tracing and metrics are left to the all in one command,
+// and TLS is off as nothing leaves the process.
+func RunInProcess(logger *slog.Logger, link *inproc.Link, notify <-chan os.Signal) error {
+	cfg, err := conf.NewConfig(bindings.Debug)
+	if err != nil {
+		return err
+	}
+	cfg.TLSCertFile, cfg.TLSKeyFile, cfg.TLSClientCAFile = "", "", ""
+
+	return run(logger, cfg, link.Listener(), notify)
+}
+
+// Creates the per user rate limiting interceptors for the configured backend. Returns
+// nil if rate limiting is disabled.
+func newRateLimitInterceptors(logger *slog.Logger, cfg *conf.Config) (grpc.UnaryServerInterceptor, grpc.StreamServerInterceptor, error) {
+	limits, err := middleware.ParseRateLimits(cfg.RateLimits)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var limiter middleware.RateLimiter
+	switch cfg.RateLimitBackend {
+	case "", "none":
+		logger.Info("Rate limiting disabled")
+		return nil, nil, nil
+	case "memory":
+		limiter = middleware.NewMemoryRateLimiter()
+	case "redis":
+		limiter = middleware.NewRedisRateLimiter(redis.NewClient(&redis.Options{
+			Addr:     cfg.RedisAddress,
+			Password: cfg.FoodRedisPassword,
+			DB:       cfg.FoodRedisDB,
+			Protocol: 2,
+		}))
+	default:
+		return nil, nil, fmt.Errorf("unknown rate limit backend %q", cfg.RateLimitBackend)
+	}
+
+	logger.Info(fmt.Sprintf("Rate limiting with %s backend: %s", cfg.RateLimitBackend, cfg.RateLimits))
+
+	return middleware.RateLimitUnaryInterceptor(logger, limiter, limits), middleware.RateLimitStreamInterceptor(logger, limiter, limits), nil
+}
+
+// Checks redis and the food index, which central can't serve without, and
+// whether the llm is reachable. The breaker real calls feed reports the llm
+// failing, and a throttled model listing catches a bad token when there's no
+// traffic. Without the llm central still serves degraded responses, so it's
+// only reported.
+func newHealthChecker(logger *slog.Logger, cfg *conf.Config, foodStore *persistence.RedisFoodStore, breaker *resilience.Breaker) *health.Checker {
+	// Its own client, so health checks neither retry nor trip the breaker
+	oa := util.CreateNewOpenAIClient(cfg.AIToken, cfg.AIBaseURL, option.WithMaxRetries(0))
+	probe := health.Throttle(cfg.LLMHealthProbeInterval, func(ctx context.Context) error {
+		_, err := oa.Models.List(ctx)
+		return err
+	})
+
+	llm := func(ctx context.Context) error {
+		if cfg.AIToken == "" && cfg.AIBaseURL == "" {
+			return errors.New("no ai token configured")
+		}
+		if breaker.State() == resilience.StateOpen {
+			return resilience.ErrCircuitOpen
+		}
+
+		return probe(ctx)
+	}
+
+	return health.NewChecker(
+		logger,
+		cfg.HealthCheckInterval,
+		cfg.HealthCheckTimeout,
+		[]string{
+			centralproto.CentralService_ServiceDesc.ServiceName,
+			centralproto.CentralFoodService_ServiceDesc.ServiceName,
+			centralproto.CentralAdminService_ServiceDesc.ServiceName,
+		},
+		health.Check{Service: bindings.HealthServiceRedis, Critical: true, Check: foodStore.Ping},
+		health.Check{Service: bindings.HealthServiceFoodIndex, Critical: true, Check: foodStore.IndexReady},
+		health.Check{Service: bindings.HealthServiceLLM, Check: llm},
+	)
+}
+
+// Methods callers don't need to authenticate for, and those only services may call
+var authPolicy = middleware.AuthPolicy{
+	Public:      []string{"/grpc.health.v1.Health/", "/grpc.reflection."},
+	ServiceOnly: []string{"/centralproto.v1.CentralAdminService/"},
+}
+
+// Creates the authentication interceptors from the configured service tokens
+// and JWT keys. Returns nil if authentication is disabled.
+func newAuthInterceptors(logger *slog.Logger, cfg *conf.Config) (grpc.UnaryServerInterceptor, grpc.StreamServerInterceptor, error) {
+	if !cfg.AuthEnabled {
+		logger.Warn("Authentication disabled, callers are trusted to name their own user")
+		return nil, nil, nil
+	}
+
+	chain := auth.Chain{}
+
+	tokens, err := auth.ParseServiceTokens(cfg.AuthServiceTokens)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(tokens) > 0 {
+		chain = append(chain, tokens)
+	}
+
+	if cfg.AuthJWTSecret != "" {
+		hmac, err := auth.NewHMACAuthenticator([]byte(cfg.AuthJWTSecret), cfg.AuthJWTIssuer, cfg.AuthJWTAudience)
+		if err != nil {
+			return nil, nil, err
+		}
+		chain = append(chain, hmac)
+	}
+
+	if cfg.AuthJWKSFile != "" {
+		jwks, err := auth.NewJWKSAuthenticator(cfg.AuthJWKSFile, cfg.AuthJWTIssuer, cfg.AuthJWTAudience)
+		if err != nil {
+			return nil, nil, err
+		}
+		chain = append(chain, jwks)
+	}
+
+	if len(chain) == 0 {
+		return nil, nil, fmt.Errorf("authentication is enabled without any service tokens, jwt secret or jwks file")
+	}
+
+	logger.Info(fmt.Sprintf("Authentication enabled with %d service tokens, hmac jwts %t and jwks %q", len(tokens), cfg.AuthJWTSecret != "", cfg.AuthJWKSFile))
+
+	return middleware.AuthUnaryInterceptor(logger, chain, authPolicy), middleware.AuthStreamInterceptor(logger, chain, authPolicy), nil
+}
+
 func NewCentralServiceClient(addr string, opts []grpc.DialOption) (centralproto.CentralServiceClient, *grpc.ClientConn, error) {
 	conn, err := grpc.NewClient(addr, opts...)
 	if err != nil {
@@ -114,3 +375,19 @@ func NewCentralServiceClient(addr string, opts []grpc.DialOption) (centralproto.
 	client := centralproto.NewCentralServiceClient(conn)
 	return client, conn, nil
 }
+
+// Variables rendered into the prompt template, unset values use the prompt defaults
+func promptVars(cfg *conf.Config) prompts.Vars {
+	vars := prompts.Vars{
+		Persona:        cfg.PromptPersona,
+		ResponseLength: cfg.PromptResponseLength,
+	}
+
+	for _, domain := range strings.Split(cfg.PromptDomains, ",") {
+		if domain = strings.TrimSpace(domain); domain != "" {
+			vars.Domains = append(vars.Domains, domain)
+		}
+	}
+
+	return vars
+}
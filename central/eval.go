@@ -0,0 +1,83 @@
+package central
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/calamity-m/reaphur/central/internal/conf"
+	"github.com/calamity-m/reaphur/central/internal/eval"
+	"github.com/calamity-m/reaphur/central/internal/fncall"
+	"github.com/calamity-m/reaphur/central/internal/prompts"
+	"github.com/calamity-m/reaphur/central/internal/util"
+	"github.com/calamity-m/reaphur/pkg/bindings"
+	"github.com/calamity-m/reaphur/pkg/logging"
+	"github.com/spf13/cobra"
+)
+
+var (
+	evalDataset string
+	evalFormat  string
+	evalOut     string
+	evalTimeout time.Duration
+
+	CentralEvalCommand = &cobra.Command{
+		Use:   "eval",
+		Short: "evaluate the prompt and model",
+		Long: `run a dataset of user utterances against the configured provider, scoring the tools
+and arguments the model chooses. Nothing is written to any journal. The report can be diffed
+between prompt or model versions.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := conf.NewConfig(bindings.Debug)
+			if err != nil {
+				fmt.Printf("Failed to create config: %v\n", err)
+				return err
+			}
+
+			// Logs go to stderr so the report can be piped from stdout
+			logger := slog.New(logging.NewCustomizedHandler(os.Stderr, &logging.CustomHandlerCfg{
+				Structed:  cfg.LogStructured,
+				Level:     cfg.LogLevel,
+				AddSource: cfg.LogAddSource,
+				StaticAttributes: []slog.Attr{
+					slog.String("system", "reap"),
+					slog.String("environment", cfg.Environment),
+				},
+			}))
+
+			dataset, err := eval.LoadDataset(evalDataset)
+			if err != nil {
+				logger.Error("failed to load dataset", slog.Any("err", err))
+				return err
+			}
+
+			prompt, err := prompts.Load(cfg.PromptDir, prompts.CentralPromptName, cfg.PromptVersion)
+			if err != nil {
+				logger.Error("failed to load prompt template", slog.Any("err", err))
+				return err
+			}
+
+			fnCaller := fncall.NewOpenAIFnCaller(logger, util.CreateNewOpenAIClient(cfg.AIToken, cfg.AIBaseURL)).WithPrompt(prompt, promptVars(cfg))
+			report := eval.NewRunner(logger, fnCaller, evalTimeout).Run(cmd.Context(), dataset)
+
+			out := os.Stdout
+			if evalOut != "" {
+				out, err = os.Create(evalOut)
+				if err != nil {
+					return err
+				}
+				defer out.Close()
+			}
+
+			return report.Write(out, evalFormat)
+		},
+	}
+)
+
+func init() {
+	CentralEvalCommand.Flags().StringVar(&evalDataset, "dataset", "test/eval/dataset.json", "dataset of utterances and expected tool calls")
+	CentralEvalCommand.Flags().StringVar(&evalFormat, "format", eval.FormatJSON, "report format, either json or markdown")
+	CentralEvalCommand.Flags().StringVar(&evalOut, "out", "", "file to write the report to, defaults to stdout")
+	CentralEvalCommand.Flags().DurationVar(&evalTimeout, "timeout", time.Minute, "limit on how long a single case can take")
+}
@@ -3,6 +3,7 @@ package conf
 import (
 	"log/slog"
 	"strings"
+	"time"
 
 	"github.com/calamity-m/reaphur/pkg/bindings"
 	"github.com/mitchellh/mapstructure"
@@ -22,11 +23,104 @@ type Config struct {
 	Address      string `mapstructure:"address" json:"address,omitempty"`
 	Reflect      bool   `mapstructure:"reflect" json:"reflect,omitempty"`
 	RedisAddress string `mapstructure:"redis_address" json:"redis_address,omitempty"`
+	// Prometheus metrics are served on their own listener. Empty disables them.
+	MetricsAddress string `mapstructure:"metrics_address" json:"metrics_address,omitempty"`
+
+	// Serves TLS when a cert and key are set, and mutual TLS when a client CA is set
+	// too. The files are reloaded when they change.
+	TLSCertFile     string `mapstructure:"tls_cert_file" json:"tls_cert_file,omitempty"`
+	TLSKeyFile      string `mapstructure:"tls_key_file" json:"tls_key_file,omitempty"`
+	TLSClientCAFile string `mapstructure:"tls_client_ca_file" json:"tls_client_ca_file,omitempty"`
+
+	// Spans are exported to an OTLP gRPC collector when tracing is enabled. The sample
+	// ratio only applies to traces started here, the rest follow their caller.
+	TracingEnabled     bool    `mapstructure:"tracing_enabled" json:"tracing_enabled,omitempty"`
+	TracingEndpoint    string  `mapstructure:"tracing_endpoint" json:"tracing_endpoint,omitempty"`
+	TracingInsecure    bool    `mapstructure:"tracing_insecure" json:"tracing_insecure,omitempty"`
+	TracingSampleRatio float64 `mapstructure:"tracing_sample_ratio" json:"tracing_sample_ratio,omitempty"`
+
+	// AI configuration. An empty base url uses the OpenAI default, otherwise any OpenAI
+	// compatible server can be used, such as the fake-llm command.
+	AIBaseURL string `mapstructure:"ai_base_url" json:"ai_base_url,omitempty"`
+
+	// Per user llm token quotas, counted across prompt and completion tokens. Days and
+	// months are measured in UTC. Zero disables the quota.
+	QuotaDailyTokens   int64 `mapstructure:"quota_daily_tokens" json:"quota_daily_tokens,omitempty"`
+	QuotaMonthlyTokens int64 `mapstructure:"quota_monthly_tokens" json:"quota_monthly_tokens,omitempty"`
+	// Individual usage records are kept this long for usage reports. Quotas are
+	// counted separately, so they never need more than the current month. Zero
+	// keeps records forever.
+	UsageRetention time.Duration `mapstructure:"usage_retention" json:"usage_retention,omitempty"`
+
+	// Per user rate limiting. The backend is one of none, memory or redis. Limits are
+	// in the form "Method=rate:burst,OtherMethod=rate:burst" where rate is tokens per second.
+	RateLimitBackend string `mapstructure:"rate_limit_backend" json:"rate_limit_backend,omitempty"`
+	RateLimits       string `mapstructure:"rate_limits" json:"rate_limits,omitempty"`
+
+	// Screen user input for prompt injection attempts before it reaches the llm
+	ScreenInput bool `mapstructure:"screen_input" json:"screen_input,omitempty"`
+
+	// IANA timezone used to resolve times like "yesterday" when the request doesn't
+	// provide the user's own timezone
+	DefaultTimezone string `mapstructure:"default_timezone" json:"default_timezone,omitempty"`
+
+	// Transient llm failures (429s, 5xxs and timeouts) are retried with jittered exponential
+	// backoff, honouring Retry-After. Each attempt is limited to the attempt timeout. After
+	// enough consecutive failed calls the breaker opens and calls fail fast until the
+	// cooldown passes. While the llm is unavailable users get a degraded response, with
+	// simple food entries still logged by a rule based parse when fallback parsing is on.
+	LLMMaxRetries      int           `mapstructure:"llm_max_retries" json:"llm_max_retries,omitempty"`
+	LLMRetryBaseDelay  time.Duration `mapstructure:"llm_retry_base_delay" json:"llm_retry_base_delay,omitempty"`
+	LLMRetryMaxDelay   time.Duration `mapstructure:"llm_retry_max_delay" json:"llm_retry_max_delay,omitempty"`
+	LLMAttemptTimeout  time.Duration `mapstructure:"llm_attempt_timeout" json:"llm_attempt_timeout,omitempty"`
+	LLMBreakerFailures int           `mapstructure:"llm_breaker_failures" json:"llm_breaker_failures,omitempty"`
+	LLMBreakerCooldown time.Duration `mapstructure:"llm_breaker_cooldown" json:"llm_breaker_cooldown,omitempty"`
+	LLMFallbackParse   bool          `mapstructure:"llm_fallback_parse" json:"llm_fallback_parse,omitempty"`
+
+	// Tool calls from a single llm message run concurrently on at most tool_workers
+	// workers. Each is cancelled after tool_timeout, failing only that call.
+	ToolWorkers int           `mapstructure:"tool_workers" json:"tool_workers,omitempty"`
+	ToolTimeout time.Duration `mapstructure:"tool_timeout" json:"tool_timeout,omitempty"`
+
+	// Simple food entries like "ate a banana 90 cal" are parsed by a local grammar
+	// and logged without calling the llm. Anything parsed with a confidence under
+	// the threshold, from 0 to 1, is handed to the llm instead.
+	LocalParse          bool    `mapstructure:"local_parse" json:"local_parse,omitempty"`
+	LocalParseThreshold float64 `mapstructure:"local_parse_threshold" json:"local_parse_threshold,omitempty"`
+
+	// Authentication of callers. Services such as the bot use static tokens from
+	// auth_service_tokens, in the form "name=token,othername=othertoken", and may act
+	// for any user. Users use JWTs signed with auth_jwt_secret (HMAC) or a key from the
+	// auth_jwks_file, and may only act as the user named by the token's subject.
+	AuthEnabled     bool   `mapstructure:"auth_enabled" json:"auth_enabled,omitempty"`
+	AuthJWKSFile    string `mapstructure:"auth_jwks_file" json:"auth_jwks_file,omitempty"`
+	AuthJWTIssuer   string `mapstructure:"auth_jwt_issuer" json:"auth_jwt_issuer,omitempty"`
+	AuthJWTAudience string `mapstructure:"auth_jwt_audience" json:"auth_jwt_audience,omitempty"`
+
+	// Dependencies such as redis and the llm are checked every health_check_interval,
+	// each check giving up after health_check_timeout
+	HealthCheckInterval time.Duration `mapstructure:"health_check_interval" json:"health_check_interval,omitempty"`
+	HealthCheckTimeout  time.Duration `mapstructure:"health_check_timeout" json:"health_check_timeout,omitempty"`
+	// The llm provider is probed with an authenticated call that spends no tokens,
+	// but only every llm_health_probe_interval to go easy on its rate limits
+	LLMHealthProbeInterval time.Duration `mapstructure:"llm_health_probe_interval" json:"llm_health_probe_interval,omitempty"`
+
+	// Prompt templates are loaded from prompt_dir, falling back to the templates built
+	// into central when empty. A zero version serves the latest version found. The
+	// persona, comma separated domains and response length are rendered into the prompt,
+	// and only the tools of those domains are offered to the model.
+	PromptDir            string `mapstructure:"prompt_dir" json:"prompt_dir,omitempty"`
+	PromptVersion        int    `mapstructure:"prompt_version" json:"prompt_version,omitempty"`
+	PromptPersona        string `mapstructure:"prompt_persona" json:"prompt_persona,omitempty"`
+	PromptDomains        string `mapstructure:"prompt_domains" json:"prompt_domains,omitempty"`
+	PromptResponseLength int    `mapstructure:"prompt_response_length" json:"prompt_response_length,omitempty"`
 
 	// Spicy
 	AIToken           string `mapstructure:"ai_token" json:"-"`
 	FoodRedisPassword string `mapstructure:"food_redis_password" json:"-"`
 	FoodRedisDB       int    `mapstructure:"food_redis_db" json:"-"`
+	AuthServiceTokens string `mapstructure:"auth_service_tokens" json:"-"`
+	AuthJWTSecret     string `mapstructure:"auth_jwt_secret" json:"-"`
 
 	// Not filled out by viper defaults
 	GrpcServerOpts []grpc.ServerOption
@@ -51,16 +145,64 @@ func NewConfig(debug bool) (*Config, error) {
 	vip.SetDefault("address", bindings.DefaultCentralAddress)
 	vip.SetDefault("reflect", true)
 	vip.SetDefault("redis_address", bindings.DefaultRedisAddress)
+	vip.SetDefault("metrics_address", bindings.DefaultCentralMetricsAddress)
+	vip.SetDefault("tls_cert_file", "")
+	vip.SetDefault("tls_key_file", "")
+	vip.SetDefault("tls_client_ca_file", "")
+	vip.SetDefault("tracing_enabled", false)
+	vip.SetDefault("tracing_endpoint", bindings.DefaultOTLPAddress)
+	vip.SetDefault("tracing_insecure", true)
+	vip.SetDefault("tracing_sample_ratio", 1.0)
 	vip.SetDefault("food_redis_password", "password")
 	vip.SetDefault("food_redis_db", 0)
+	vip.SetDefault("ai_base_url", "")
+	vip.SetDefault("quota_daily_tokens", 0)
+	vip.SetDefault("quota_monthly_tokens", 0)
+	vip.SetDefault("usage_retention", "2160h")
+	vip.SetDefault("rate_limit_backend", "memory")
+	vip.SetDefault("rate_limits", "CallFnUserInput=0.2:5,CallFnUserInputStream=0.2:5,ActionUserInput=0.2:5")
+	vip.SetDefault("screen_input", true)
+	vip.SetDefault("default_timezone", "UTC")
+	vip.SetDefault("llm_max_retries", 3)
+	vip.SetDefault("llm_retry_base_delay", "500ms")
+	vip.SetDefault("llm_retry_max_delay", "8s")
+	vip.SetDefault("llm_attempt_timeout", "60s")
+	vip.SetDefault("llm_breaker_failures", 5)
+	vip.SetDefault("llm_breaker_cooldown", "30s")
+	vip.SetDefault("llm_fallback_parse", true)
+	vip.SetDefault("tool_workers", 4)
+	vip.SetDefault("tool_timeout", "10s")
+	vip.SetDefault("local_parse", true)
+	vip.SetDefault("local_parse_threshold", 0.8)
+	vip.SetDefault("auth_enabled", false)
+	vip.SetDefault("auth_jwks_file", "")
+	vip.SetDefault("auth_jwt_issuer", "")
+	vip.SetDefault("auth_jwt_audience", "")
+	vip.SetDefault("health_check_interval", "15s")
+	vip.SetDefault("health_check_timeout", "5s")
+	vip.SetDefault("llm_health_probe_interval", "5m")
+	vip.SetDefault("prompt_dir", "")
+	vip.SetDefault("prompt_version", 0)
+	vip.SetDefault("prompt_persona", "")
+	vip.SetDefault("prompt_domains", "")
+	vip.SetDefault("prompt_response_length", 0)
 
 	// Spicy bindings
 	if err := vip.BindEnv("ai_token"); err != nil {
 		return &Config{}, err
 	}
+	if err := vip.BindEnv("auth_service_tokens"); err != nil {
+		return &Config{}, err
+	}
+	if err := vip.BindEnv("auth_jwt_secret"); err != nil {
+		return &Config{}, err
+	}
 	// Magic to unamrshal viper into the config sturct. The decode hook is used to map things like the logging level
 	// into the slog logging level type.
-	if err := vip.Unmarshal(&base, viper.DecodeHook(mapstructure.TextUnmarshallerHookFunc())); err != nil {
+	if err := vip.Unmarshal(&base, viper.DecodeHook(mapstructure.ComposeDecodeHookFunc(
+		mapstructure.TextUnmarshallerHookFunc(),
+		mapstructure.StringToTimeDurationHookFunc(),
+	))); err != nil {
 		return &Config{}, err
 	}
 
@@ -0,0 +1,156 @@
+package eval
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+)
+
+// Utterances to evaluate along with the tools the model is expected to call
+type Dataset struct {
+	Cases []Case `json:"cases"`
+}
+
+type Case struct {
+	Name  string `json:"name"`
+	Input string `json:"input"`
+	// IANA timezone the input is resolved in, defaults to UTC
+	Timezone string `json:"timezone,omitempty"`
+	// Tools the model should call, in any order. Empty expects no tool calls.
+	Expect []ExpectedCall `json:"expect"`
+}
+
+type ExpectedCall struct {
+	Tool string `json:"tool"`
+	// Assertions against the call's arguments, keyed by argument name
+	Args map[string]Assertion `json:"args,omitempty"`
+}
+
+// An assertion against a single argument. Plain values must be equal, strings
+// ignoring case, otherwise an object holding one of:
+//
+//	{"contains": "pizza"}   string argument contains the text, ignoring case
+//	{"min": 400, "max": 600} number argument falls within the bounds
+//	{"one_of": ["a", "b"]}   argument equals one of the values
+//	{"present": true}        argument was provided and isn't empty
+type Assertion struct {
+	Equals   any      `json:"-"`
+	Contains string   `json:"contains,omitempty"`
+	Min      *float64 `json:"min,omitempty"`
+	Max      *float64 `json:"max,omitempty"`
+	OneOf    []any    `json:"one_of,omitempty"`
+	Present  bool     `json:"present,omitempty"`
+}
+
+func (a *Assertion) UnmarshalJSON(data []byte) error {
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+
+	if _, ok := value.(map[string]any); !ok {
+		a.Equals = value
+		return nil
+	}
+
+	type plain Assertion
+	var p plain
+	decoder := json.NewDecoder(strings.NewReader(string(data)))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&p); err != nil {
+		return fmt.Errorf("bad assertion %s: %w", data, err)
+	}
+	*a = Assertion(p)
+
+	return nil
+}
+
+func (a Assertion) MarshalJSON() ([]byte, error) {
+	if a.Equals != nil {
+		return json.Marshal(a.Equals)
+	}
+
+	type plain Assertion
+	return json.Marshal(plain(a))
+}
+
+// Checks the argument, returning why it failed or an empty string
+func (a Assertion) check(value any, ok bool) string {
+	switch {
+	case a.Equals != nil:
+		if !ok || !equal(value, a.Equals) {
+			return fmt.Sprintf("got %v but want %v", describe(value, ok), a.Equals)
+		}
+	case a.Contains != "":
+		s, isString := value.(string)
+		if !isString || !strings.Contains(strings.ToLower(s), strings.ToLower(a.Contains)) {
+			return fmt.Sprintf("got %v but want it to contain %q", describe(value, ok), a.Contains)
+		}
+	case a.Min != nil || a.Max != nil:
+		n, isNumber := value.(float64)
+		if !isNumber || (a.Min != nil && n < *a.Min) || (a.Max != nil && n > *a.Max) {
+			return fmt.Sprintf("got %v but want it within [%s, %s]", describe(value, ok), bound(a.Min, math.Inf(-1)), bound(a.Max, math.Inf(1)))
+		}
+	case len(a.OneOf) > 0:
+		for _, want := range a.OneOf {
+			if ok && equal(value, want) {
+				return ""
+			}
+		}
+		return fmt.Sprintf("got %v but want one of %v", describe(value, ok), a.OneOf)
+	case a.Present:
+		if !ok || value == nil || value == "" {
+			return "want it to be provided"
+		}
+	}
+
+	return ""
+}
+
+func equal(got any, want any) bool {
+	if g, ok := got.(string); ok {
+		if w, ok := want.(string); ok {
+			return strings.EqualFold(strings.TrimSpace(g), strings.TrimSpace(w))
+		}
+	}
+
+	return got == want
+}
+
+func describe(value any, ok bool) string {
+	if !ok {
+		return "nothing"
+	}
+
+	return fmt.Sprintf("%v", value)
+}
+
+func bound(b *float64, fallback float64) string {
+	if b == nil {
+		return fmt.Sprintf("%v", fallback)
+	}
+
+	return fmt.Sprintf("%v", *b)
+}
+
+func LoadDataset(path string) (*Dataset, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var dataset Dataset
+	if err := json.Unmarshal(content, &dataset); err != nil {
+		return nil, fmt.Errorf("failed parsing dataset %s: %w", path, err)
+	}
+
+	for i, c := range dataset.Cases {
+		if c.Name == "" || c.Input == "" {
+			return nil, fmt.Errorf("dataset case %d needs a name and input", i)
+		}
+	}
+
+	return &dataset, nil
+}
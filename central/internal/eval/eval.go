@@ -0,0 +1,180 @@
+package eval
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/calamity-m/reaphur/central/internal/fncall"
+	"github.com/calamity-m/reaphur/central/internal/timeexpr"
+)
+
+// Runs dataset cases against the model, asking which tools it would call
+// without running any of them
+type Runner struct {
+	logger   *slog.Logger
+	fnCaller *fncall.OpenAIFnCaller
+	// Limit on how long a single case can take
+	timeout time.Duration
+}
+
+func NewRunner(logger *slog.Logger, fnCaller *fncall.OpenAIFnCaller, timeout time.Duration) *Runner {
+	return &Runner{
+		logger:   logger,
+		fnCaller: fnCaller,
+		timeout:  timeout,
+	}
+}
+
+// Runs every case in order. Failing cases are recorded in the report rather
+// than stopping the run.
+func (r *Runner) Run(ctx context.Context, dataset *Dataset) *Report {
+	report := &Report{Cases: make([]CaseResult, 0, len(dataset.Cases))}
+
+	for _, c := range dataset.Cases {
+		result := r.runCase(ctx, c)
+		r.logger.InfoContext(ctx, "evaluated case", slog.String("case", c.Name), slog.Bool("passed", result.Passed))
+
+		if result.Model != "" {
+			report.Model = result.Model
+			report.PromptVersion = result.PromptVersion
+		}
+
+		report.Cases = append(report.Cases, result)
+	}
+
+	report.Summary = summarise(report.Cases)
+
+	return report
+}
+
+func (r *Runner) runCase(ctx context.Context, c Case) CaseResult {
+	result := CaseResult{Name: c.Name, Input: c.Input}
+	for _, expected := range c.Expect {
+		result.Expected = append(result.Expected, expected.Tool)
+	}
+
+	loc, err := timeexpr.LoadLocation(c.Timezone, time.UTC)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	start := time.Now()
+	choices, usage, err := r.fnCaller.ChooseTools(ctx, fncall.CreateGenericFnCallOutputRequest(c.Input, "eval", loc))
+	result.LatencyMs = time.Since(start).Milliseconds()
+	result.Model = usage.Model
+	result.PromptVersion = usage.PromptVersion
+	result.PromptTokens = usage.PromptTokens
+	result.CompletionTokens = usage.CompletionTokens
+
+	if err != nil {
+		result.Error = err.Error()
+		result.ArgsTotal = countAssertions(c.Expect)
+		return result
+	}
+
+	score(&result, c.Expect, choices)
+
+	return result
+}
+
+// Scores the tools the model chose against the expected calls. Each expected
+// call is matched with the first unused call to the same tool.
+func score(result *CaseResult, expect []ExpectedCall, choices []fncall.ToolChoice) {
+	result.Called = []string{}
+	for _, choice := range choices {
+		result.Called = append(result.Called, choice.Name)
+	}
+
+	expected := slices.Clone(result.Expected)
+	called := slices.Clone(result.Called)
+	sort.Strings(expected)
+	sort.Strings(called)
+	result.ToolCorrect = slices.Equal(expected, called)
+	if !result.ToolCorrect {
+		result.Failures = append(result.Failures, fmt.Sprintf("called %v but want %v", result.Called, result.Expected))
+	}
+
+	used := make([]bool, len(choices))
+	for _, e := range expect {
+		result.ArgsTotal += len(e.Args)
+
+		// Prefer the call that satisfies every assertion, so several calls to
+		// the same tool can come back in any order
+		match := -1
+		var failures []string
+		for i, choice := range choices {
+			if used[i] || choice.Name != e.Tool {
+				continue
+			}
+
+			f := checkArgs(e, choice.Arguments)
+			if match < 0 || len(f) < len(failures) {
+				match, failures = i, f
+			}
+			if len(f) == 0 {
+				break
+			}
+		}
+		if match < 0 {
+			continue
+		}
+		used[match] = true
+
+		result.ArgsPassed += len(e.Args) - min(len(failures), len(e.Args))
+		result.Failures = append(result.Failures, failures...)
+	}
+
+	result.Passed = result.ToolCorrect && result.ArgsPassed == result.ArgsTotal
+}
+
+// Checks the expected call's assertions against the arguments, returning a
+// failure for each assertion that doesn't hold
+func checkArgs(e ExpectedCall, arguments string) []string {
+	args := map[string]any{}
+	if err := json.Unmarshal([]byte(arguments), &args); err != nil {
+		failures := []string{}
+		for _, name := range sortedKeys(e.Args) {
+			failures = append(failures, fmt.Sprintf("%s.%s: arguments aren't json: %v", e.Tool, name, err))
+		}
+		return failures
+	}
+
+	failures := []string{}
+	for _, name := range sortedKeys(e.Args) {
+		value, ok := args[name]
+		if failure := e.Args[name].check(value, ok); failure != "" {
+			failures = append(failures, fmt.Sprintf("%s.%s: %s", e.Tool, name, failure))
+		}
+	}
+
+	return failures
+}
+
+func countAssertions(expect []ExpectedCall) int {
+	total := 0
+	for _, e := range expect {
+		total += len(e.Args)
+	}
+
+	return total
+}
+
+// Keys in a stable order so reports can be diffed
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,198 @@
+package eval
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/calamity-m/reaphur/central/internal/fncall"
+	"github.com/calamity-m/reaphur/central/internal/util"
+	"github.com/calamity-m/reaphur/pkg/fakellm"
+)
+
+func TestAssertion(t *testing.T) {
+	tests := []struct {
+		name      string
+		assertion string
+		value     any
+		ok        bool
+		want      bool
+	}{
+		{"equal number", `500`, 500.0, true, true},
+		{"unequal number", `500`, 450.0, true, false},
+		{"equal string ignores case", `"calorie"`, " Calorie", true, true},
+		{"missing", `"calorie"`, nil, false, false},
+		{"contains", `{"contains":"pizza"}`, "Pepperoni Pizza", true, true},
+		{"does not contain", `{"contains":"pizza"}`, "burrito", true, false},
+		{"within bounds", `{"min":400,"max":600}`, 550.0, true, true},
+		{"below min", `{"min":400}`, 300.0, true, false},
+		{"above max", `{"max":600}`, 700.0, true, false},
+		{"bounds need a number", `{"min":400}`, "500", true, false},
+		{"one of", `{"one_of":["run","jog"]}`, "Jog", true, true},
+		{"none of", `{"one_of":["run","jog"]}`, "walk", true, false},
+		{"present", `{"present":true}`, "today", true, true},
+		{"present but empty", `{"present":true}`, "", true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var a Assertion
+			if err := json.Unmarshal([]byte(tt.assertion), &a); err != nil {
+				t.Fatalf("failed parsing assertion: %v", err)
+			}
+
+			if got := a.check(tt.value, tt.ok) == ""; got != tt.want {
+				t.Errorf("check(%v) passed %t but want %t", tt.value, got, tt.want)
+			}
+		})
+	}
+
+	t.Run("unknown assertion", func(t *testing.T) {
+		var a Assertion
+		if err := json.Unmarshal([]byte(`{"startswith":"p"}`), &a); err == nil {
+			t.Error("expected an error for an unknown assertion")
+		}
+	})
+}
+
+func TestLoadDataset(t *testing.T) {
+	dataset, err := LoadDataset("../../../test/eval/dataset.json")
+	if err != nil {
+		t.Fatalf("got err %v", err)
+	}
+
+	if len(dataset.Cases) == 0 {
+		t.Error("expected the bundled dataset to have cases")
+	}
+}
+
+func TestRun(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	script := &fakellm.Script{
+		Rules: []fakellm.Rule{
+			{
+				Match: fakellm.Match{Turn: fakellm.TurnUser, UserContains: "pizza"},
+				Reply: fakellm.Reply{
+					ToolCalls: []fakellm.ToolCall{{Name: "log_food", Arguments: json.RawMessage(`{"name":"pizza","energy":500,"energy_unit":"calorie","time":"now"}`)}},
+					Usage:     fakellm.Usage{PromptTokens: 100, CompletionTokens: 20},
+				},
+			},
+			{
+				Match: fakellm.Match{Turn: fakellm.TurnUser, UserContains: "eggs"},
+				Reply: fakellm.Reply{
+					ToolCalls: []fakellm.ToolCall{
+						{Name: "log_food", Arguments: json.RawMessage(`{"name":"coffee","energy":0,"energy_unit":"none","time":"now"}`)},
+						{Name: "log_food", Arguments: json.RawMessage(`{"name":"eggs","energy":0,"energy_unit":"none","time":"now"}`)},
+					},
+					Usage: fakellm.Usage{PromptTokens: 100, CompletionTokens: 30},
+				},
+			},
+			{
+				Match: fakellm.Match{Turn: fakellm.TurnUser, UserContains: "today"},
+				Reply: fakellm.Reply{
+					ToolCalls: []fakellm.ToolCall{{Name: "log_food", Arguments: json.RawMessage(`{"name":"today","energy":0,"energy_unit":"none","time":"now"}`)}},
+				},
+			},
+			{
+				Match: fakellm.Match{Turn: fakellm.TurnUser, UserContains: "broken"},
+				Reply: fakellm.Reply{Status: http.StatusBadRequest, Error: "oops"},
+			},
+		},
+		Default: &fakellm.Reply{Content: "hello", Usage: fakellm.Usage{PromptTokens: 50, CompletionTokens: 5}},
+	}
+	httpServer := httptest.NewServer(fakellm.NewScriptedServer(logger, script))
+	defer httpServer.Close()
+
+	fnCaller := fncall.NewOpenAIFnCaller(logger, util.CreateNewOpenAIClient("fake", httpServer.URL+"/v1/"))
+	dataset := &Dataset{
+		Cases: []Case{
+			{Name: "pizza", Input: "500 calories of pizza", Expect: []ExpectedCall{
+				{Tool: "log_food", Args: map[string]Assertion{"energy": {Equals: 500.0}, "energy_unit": {Equals: "calorie"}, "name": {Contains: "pizza"}}},
+			}},
+			{Name: "eggs in any order", Input: "eggs and coffee", Expect: []ExpectedCall{
+				{Tool: "log_food", Args: map[string]Assertion{"name": {Contains: "egg"}}},
+				{Tool: "log_food", Args: map[string]Assertion{"name": {Contains: "coffee"}}},
+			}},
+			{Name: "wrong tool", Input: "what did i eat today", Expect: []ExpectedCall{
+				{Tool: "get_food", Args: map[string]Assertion{"after_time": {Equals: "today"}}},
+			}},
+			{Name: "small talk", Input: "hi", Expect: []ExpectedCall{}},
+			{Name: "provider error", Input: "broken", Expect: []ExpectedCall{
+				{Tool: "log_food", Args: map[string]Assertion{"name": {Present: true}}},
+			}},
+		},
+	}
+
+	report := NewRunner(logger, fnCaller, 10*time.Second).Run(context.Background(), dataset)
+
+	passed := map[string]bool{}
+	for _, c := range report.Cases {
+		passed[c.Name] = c.Passed
+	}
+	want := map[string]bool{"pizza": true, "eggs in any order": true, "wrong tool": false, "small talk": true, "provider error": false}
+	for name, p := range want {
+		if passed[name] != p {
+			t.Errorf("case %q passed %t but want %t", name, passed[name], p)
+		}
+	}
+
+	s := report.Summary
+	if s.Cases != 5 || s.Passed != 3 || s.Errors != 1 {
+		t.Errorf("got summary %+v", s)
+	}
+	if s.ToolAccuracy != 0.6 {
+		t.Errorf("got tool accuracy %v but want 0.6", s.ToolAccuracy)
+	}
+	// 5 of the 7 assertions hold, the wrong tool and errored case fail theirs
+	if s.ArgAccuracy != 0.7143 {
+		t.Errorf("got arg accuracy %v but want 0.7143", s.ArgAccuracy)
+	}
+	if s.TotalPromptTokens != 250 || s.TotalCompletionTokens != 55 {
+		t.Errorf("got tokens %d/%d but want 250/55", s.TotalPromptTokens, s.TotalCompletionTokens)
+	}
+	if report.PromptVersion != "central.v2" || report.Model == "" {
+		t.Errorf("got model %q and prompt %q", report.Model, report.PromptVersion)
+	}
+
+	t.Run("json", func(t *testing.T) {
+		var b bytes.Buffer
+		if err := report.Write(&b, FormatJSON); err != nil {
+			t.Fatalf("got err %v", err)
+		}
+
+		var decoded Report
+		if err := json.Unmarshal(b.Bytes(), &decoded); err != nil {
+			t.Fatalf("report isn't json: %v", err)
+		}
+		if decoded.Summary.Passed != 3 {
+			t.Errorf("got decoded summary %+v", decoded.Summary)
+		}
+	})
+
+	t.Run("markdown", func(t *testing.T) {
+		var b bytes.Buffer
+		if err := report.Write(&b, FormatMarkdown); err != nil {
+			t.Fatalf("got err %v", err)
+		}
+
+		for _, want := range []string{"| Tool accuracy | 60.00% |", "| wrong tool | fail | log_food | 0/1 |", "### wrong tool", "called [log_food] but want [get_food]"} {
+			if !strings.Contains(b.String(), want) {
+				t.Errorf("expected markdown to contain %q, got %s", want, b.String())
+			}
+		}
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		if err := report.Write(io.Discard, "csv"); err == nil {
+			t.Error("expected an error for an unknown format")
+		}
+	})
+}
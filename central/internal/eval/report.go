@@ -0,0 +1,184 @@
+package eval
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+const (
+	FormatJSON     = "json"
+	FormatMarkdown = "markdown"
+)
+
+// Results of an evaluation run. Holds nothing that changes between identical
+// runs except latency, so reports of different prompts or models diff cleanly.
+type Report struct {
+	Model         string       `json:"model"`
+	PromptVersion string       `json:"prompt_version"`
+	Summary       Summary      `json:"summary"`
+	Cases         []CaseResult `json:"cases"`
+}
+
+type Summary struct {
+	Cases  int `json:"cases"`
+	Passed int `json:"passed"`
+	Errors int `json:"errors"`
+	// Fraction of cases where exactly the expected tools were called
+	ToolAccuracy float64 `json:"tool_accuracy"`
+	// Fraction of argument assertions that held
+	ArgAccuracy           float64 `json:"arg_accuracy"`
+	LatencyMeanMs         int64   `json:"latency_mean_ms"`
+	LatencyP50Ms          int64   `json:"latency_p50_ms"`
+	LatencyP95Ms          int64   `json:"latency_p95_ms"`
+	TotalPromptTokens     int64   `json:"total_prompt_tokens"`
+	TotalCompletionTokens int64   `json:"total_completion_tokens"`
+}
+
+type CaseResult struct {
+	Name             string   `json:"name"`
+	Input            string   `json:"input"`
+	Expected         []string `json:"expected"`
+	Called           []string `json:"called"`
+	Passed           bool     `json:"passed"`
+	ToolCorrect      bool     `json:"tool_correct"`
+	ArgsPassed       int      `json:"args_passed"`
+	ArgsTotal        int      `json:"args_total"`
+	Failures         []string `json:"failures,omitempty"`
+	Error            string   `json:"error,omitempty"`
+	LatencyMs        int64    `json:"latency_ms"`
+	PromptTokens     int64    `json:"prompt_tokens"`
+	CompletionTokens int64    `json:"completion_tokens"`
+
+	Model         string `json:"-"`
+	PromptVersion string `json:"-"`
+}
+
+func summarise(cases []CaseResult) Summary {
+	summary := Summary{Cases: len(cases)}
+	if len(cases) == 0 {
+		return summary
+	}
+
+	toolCorrect, argsPassed, argsTotal := 0, 0, 0
+	latencies := make([]int64, 0, len(cases))
+	var latencyTotal int64
+	for _, c := range cases {
+		if c.Passed {
+			summary.Passed++
+		}
+		if c.Error != "" {
+			summary.Errors++
+		}
+		if c.ToolCorrect {
+			toolCorrect++
+		}
+
+		argsPassed += c.ArgsPassed
+		argsTotal += c.ArgsTotal
+		summary.TotalPromptTokens += c.PromptTokens
+		summary.TotalCompletionTokens += c.CompletionTokens
+
+		latencies = append(latencies, c.LatencyMs)
+		latencyTotal += c.LatencyMs
+	}
+
+	summary.ToolAccuracy = ratio(toolCorrect, len(cases))
+	summary.ArgAccuracy = 1
+	if argsTotal > 0 {
+		summary.ArgAccuracy = ratio(argsPassed, argsTotal)
+	}
+
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	summary.LatencyMeanMs = latencyTotal / int64(len(cases))
+	summary.LatencyP50Ms = percentile(latencies, 50)
+	summary.LatencyP95Ms = percentile(latencies, 95)
+
+	return summary
+}
+
+// Rounded to keep reports stable between runs with the same outcome
+func ratio(n int, total int) float64 {
+	return float64(int(float64(n)/float64(total)*10000+0.5)) / 10000
+}
+
+// Nearest rank percentile of sorted values
+func percentile(sorted []int64, p int) int64 {
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+
+	return sorted[rank-1]
+}
+
+// Writes the report in the given format, either json or markdown
+func (r *Report) Write(w io.Writer, format string) error {
+	switch format {
+	case FormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(r)
+	case FormatMarkdown:
+		_, err := io.WriteString(w, r.markdown())
+		return err
+	default:
+		return fmt.Errorf("unknown report format %q", format)
+	}
+}
+
+func (r *Report) markdown() string {
+	var b strings.Builder
+	s := r.Summary
+
+	fmt.Fprintf(&b, "# Eval report\n\n")
+	fmt.Fprintf(&b, "Model `%s` with prompt `%s`\n\n", r.Model, r.PromptVersion)
+
+	fmt.Fprintf(&b, "| Metric | Value |\n|---|---|\n")
+	fmt.Fprintf(&b, "| Cases | %d passed of %d, %d errored |\n", s.Passed, s.Cases, s.Errors)
+	fmt.Fprintf(&b, "| Tool accuracy | %.2f%% |\n", s.ToolAccuracy*100)
+	fmt.Fprintf(&b, "| Argument accuracy | %.2f%% |\n", s.ArgAccuracy*100)
+	fmt.Fprintf(&b, "| Latency | mean %dms, p50 %dms, p95 %dms |\n", s.LatencyMeanMs, s.LatencyP50Ms, s.LatencyP95Ms)
+	fmt.Fprintf(&b, "| Tokens | %d prompt, %d completion |\n\n", s.TotalPromptTokens, s.TotalCompletionTokens)
+
+	fmt.Fprintf(&b, "| Case | Result | Tools | Args | Latency | Tokens |\n|---|---|---|---|---|---|\n")
+	for _, c := range r.Cases {
+		result := "pass"
+		if c.Error != "" {
+			result = "error"
+		} else if !c.Passed {
+			result = "fail"
+		}
+
+		fmt.Fprintf(&b, "| %s | %s | %s | %d/%d | %dms | %d |\n",
+			escapeCell(c.Name), result, escapeCell(strings.Join(c.Called, ", ")), c.ArgsPassed, c.ArgsTotal, c.LatencyMs, c.PromptTokens+c.CompletionTokens)
+	}
+
+	failed := false
+	for _, c := range r.Cases {
+		if len(c.Failures) == 0 && c.Error == "" {
+			continue
+		}
+
+		if !failed {
+			fmt.Fprintf(&b, "\n## Failures\n")
+			failed = true
+		}
+
+		fmt.Fprintf(&b, "\n### %s\n\n> %s\n\n", c.Name, c.Input)
+		if c.Error != "" {
+			fmt.Fprintf(&b, "- error: %s\n", c.Error)
+		}
+		for _, failure := range c.Failures {
+			fmt.Fprintf(&b, "- %s\n", failure)
+		}
+	}
+
+	return b.String()
+}
+
+func escapeCell(s string) string {
+	return strings.ReplaceAll(s, "|", `\|`)
+}
@@ -0,0 +1,42 @@
+package fncall
+
+import (
+	"context"
+	"fmt"
+)
+
+// A tool the model chose to call. Nothing has been run for it.
+type ToolChoice struct {
+	Name      string
+	Arguments string
+}
+
+// Asks the model which tools it would call for the request without running any
+// of them, so prompts and models can be evaluated without touching a journal.
+func (oa *OpenAIFnCaller) ChooseTools(ctx context.Context, r FnCallOutputRequest) ([]ToolChoice, TokenUsage, error) {
+	if oa.model == "" {
+		return nil, TokenUsage{}, fmt.Errorf("no model selected")
+	}
+
+	params, usage, err := oa.firstCompletionParams(r)
+	if err != nil {
+		return nil, TokenUsage{}, err
+	}
+
+	completion, err := oa.complete(ctx, params)
+	if err != nil {
+		return nil, usage, llmError(ctx, err)
+	}
+	usage.add(completion)
+
+	if len(completion.Choices) == 0 {
+		return nil, usage, fmt.Errorf("completion had no choices")
+	}
+
+	choices := []ToolChoice{}
+	for _, call := range completion.Choices[0].Message.ToolCalls {
+		choices = append(choices, ToolChoice{Name: call.Function.Name, Arguments: call.Function.Arguments})
+	}
+
+	return choices, usage, nil
+}
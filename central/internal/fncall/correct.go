@@ -0,0 +1,216 @@
+package fncall
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/calamity-m/reaphur/central/internal/prompts"
+	"github.com/calamity-m/reaphur/pkg/errs"
+	"github.com/calamity-m/reaphur/pkg/serr"
+	centralproto "github.com/calamity-m/reaphur/proto/v1/central"
+	"github.com/calamity-m/reaphur/proto/v1/domain"
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Target referring to the user's most recently logged food
+const lastTarget = "last"
+
+// Finds the user's food record a tool call refers to, either by id, "last" for
+// the most recent record, or by name for the most recent record with that name.
+// Returns a message for the model when nothing matches.
+func (oa *OpenAIFnCaller) findFoodTarget(ctx context.Context, fnReq FnCallOutputRequest, target string, food centralproto.CentralFoodServiceServer) (*domain.FoodRecord, string) {
+	target = strings.TrimSpace(target)
+
+	// The store picks the newest match itself, as it can only return a page
+	filter := &centralproto.GetFoodFilter{Latest: true}
+	if _, err := uuid.Parse(target); err == nil {
+		filter.Id = &target
+	} else if target != "" && !strings.EqualFold(target, lastTarget) {
+		filter.Name = &target
+	}
+
+	found, err := food.GetFoodRecords(ctx, &centralproto.GetFoodRecordsRequest{RequestUserId: fnReq.UserId, Filter: filter})
+	if err != nil && !errors.Is(err, errs.ErrNotFound) {
+		oa.logger.ErrorContext(ctx, "failed finding food target", slog.Any("err", err), slog.String("target", target))
+		return nil, "failed to find the food record"
+	}
+
+	if len(found.GetRecords()) == 0 {
+		return nil, fmt.Sprintf("no food record found for %q, ask the user which food they meant", target)
+	}
+
+	return found.GetRecords()[0], ""
+}
+
+// Human readable description of a tool call target, shown when confirming
+func describeFoodTarget(target string) string {
+	target = strings.TrimSpace(target)
+
+	if target == "" || strings.EqualFold(target, lastTarget) {
+		return "your last logged food"
+	}
+	if _, err := uuid.Parse(target); err == nil {
+		return "that food entry"
+	}
+
+	return fmt.Sprintf("your most recent %s", target)
+}
+
+func summariseUpdateFood(arguments string) string {
+	args, err := serr.DecodeJSONS[prompts.FnUpdateFoodParameters](arguments)
+	if err != nil {
+		return "change a food entry"
+	}
+
+	changes := []string{}
+	if args.Name != "" {
+		changes = append(changes, fmt.Sprintf("name to %s", args.Name))
+	}
+	if args.EnegyUnit == "calorie" || args.EnegyUnit == "kilojule" {
+		changes = append(changes, fmt.Sprintf("energy to %g %ss", args.Energy, args.EnegyUnit))
+	}
+	if args.Time != "" {
+		changes = append(changes, fmt.Sprintf("time to %s", args.Time))
+	}
+	if args.Description != "" && len(changes) == 0 {
+		changes = append(changes, "description")
+	}
+
+	if len(changes) == 0 {
+		return fmt.Sprintf("change %s", describeFoodTarget(args.Target))
+	}
+
+	return fmt.Sprintf("change the %s of %s", strings.Join(changes, ", "), describeFoodTarget(args.Target))
+}
+
+func summariseDeleteFood(arguments string) string {
+	args, err := serr.DecodeJSONS[prompts.FnDeleteFoodParameters](arguments)
+	if err != nil {
+		return "delete a food entry"
+	}
+
+	return fmt.Sprintf("delete %s", describeFoodTarget(args.Target))
+}
+
+func (oa *OpenAIFnCaller) handleUpdateFood(ctx context.Context, fnReq FnCallOutputRequest, args prompts.FnUpdateFoodParameters, food centralproto.CentralFoodServiceServer) FnCallOutputResponse {
+	existing, message := oa.findFoodTarget(ctx, fnReq, args.Target, food)
+	if existing == nil {
+		return FnCallOutputResponse{Success: false, Message: message}
+	}
+
+	record := proto.Clone(existing).(*domain.FoodRecord)
+	if args.Name != "" {
+		record.Name = args.Name
+	}
+	if args.Description != "" {
+		record.Description = args.Description
+	}
+
+	// Kilojules take priority over calories, so the other unit has to be cleared
+	if args.EnegyUnit == "calorie" {
+		record.Calories = args.Energy
+		record.Kj = 0
+	}
+	if args.EnegyUnit == "kilojule" {
+		record.Kj = args.Energy
+		record.Calories = 0
+	}
+
+	if args.Time != "" {
+		eaten, err := fnReq.resolveTime(args.Time)
+		if err != nil {
+			oa.logger.ErrorContext(ctx, "failed resolving time arg", slog.Any("err", err), slog.Any("args", args))
+			return FnCallOutputResponse{
+				Success: false,
+				Message: fmt.Sprintf("couldn't understand the time %q, ask the user when they ate", args.Time),
+			}
+		}
+
+		record.Time = timestamppb.New(eaten.Point(fnReq.now()))
+	}
+
+	updated, err := food.UpdateFoodRecord(ctx, &centralproto.UpdateFoodRecordRequest{RequestUserId: fnReq.UserId, Record: record})
+	if err != nil {
+		oa.logger.ErrorContext(ctx, "failed updating food record", slog.Any("err", err), slog.Any("record", record))
+		return FnCallOutputResponse{Success: false, Message: "failed to update food record"}
+	}
+
+	oa.history.Push(fnReq.UserId, Change{Kind: ChangeUpdated, Record: existing})
+	oa.logger.InfoContext(ctx, "updated food record", slog.Any("updated", updated))
+
+	return FnCallOutputResponse{
+		Success: true,
+		Message: "successfully updated food record",
+		Data:    []interface{}{updated.GetRecord()},
+	}
+}
+
+func (oa *OpenAIFnCaller) handleDeleteFood(ctx context.Context, fnReq FnCallOutputRequest, args prompts.FnDeleteFoodParameters, food centralproto.CentralFoodServiceServer) FnCallOutputResponse {
+	existing, message := oa.findFoodTarget(ctx, fnReq, args.Target, food)
+	if existing == nil {
+		return FnCallOutputResponse{Success: false, Message: message}
+	}
+
+	deleted, err := food.DeleteFoodRecord(ctx, &centralproto.DeleteFoodRecordRequest{RequestUserId: fnReq.UserId, Id: existing.GetId()})
+	if err != nil {
+		oa.logger.ErrorContext(ctx, "failed deleting food record", slog.Any("err", err), slog.Any("record", existing))
+		return FnCallOutputResponse{Success: false, Message: "failed to delete food record"}
+	}
+
+	oa.history.Push(fnReq.UserId, Change{Kind: ChangeDeleted, Record: deleted.GetRecord()})
+	oa.logger.InfoContext(ctx, "deleted food record", slog.Any("deleted", deleted))
+
+	return FnCallOutputResponse{
+		Success: true,
+		Message: fmt.Sprintf("successfully deleted the %s food record", deleted.GetRecord().GetName()),
+	}
+}
+
+// Reverts the user's most recent change. The undo itself isn't recorded, so
+// undoing again reverts the change before it.
+func (oa *OpenAIFnCaller) handleUndoLastAction(ctx context.Context, fnReq FnCallOutputRequest, args prompts.FnUndoLastActionParameters, food centralproto.CentralFoodServiceServer) FnCallOutputResponse {
+	change, ok := oa.history.Pop(fnReq.UserId)
+	if !ok {
+		return FnCallOutputResponse{Success: false, Message: "there is nothing recent to undo"}
+	}
+
+	oa.logger.InfoContext(ctx, "undoing change", slog.Any("change", change), slog.String("reason", args.Reason))
+
+	var (
+		err     error
+		message string
+		data    = []interface{}{}
+	)
+	switch change.Kind {
+	case ChangeCreated:
+		_, err = food.DeleteFoodRecord(ctx, &centralproto.DeleteFoodRecordRequest{RequestUserId: fnReq.UserId, Id: change.Record.GetId()})
+		message = fmt.Sprintf("successfully undid logging %s, the record was removed", change.Record.GetName())
+	case ChangeUpdated:
+		var restored *centralproto.UpdateFoodRecordResponse
+		restored, err = food.UpdateFoodRecord(ctx, &centralproto.UpdateFoodRecordRequest{RequestUserId: fnReq.UserId, Record: change.Record})
+		message = fmt.Sprintf("successfully undid the change to %s, the record was restored", change.Record.GetName())
+		data = append(data, restored.GetRecord())
+	case ChangeDeleted:
+		var restored *centralproto.CreateFoodRecordResponse
+		restored, err = food.CreateFoodRecord(ctx, &centralproto.CreateFoodRecordRequest{Record: change.Record})
+		message = fmt.Sprintf("successfully undid deleting %s, the record was restored", change.Record.GetName())
+		data = append(data, restored.GetRecord())
+	}
+
+	if errors.Is(err, errs.ErrNotFound) {
+		return FnCallOutputResponse{Success: false, Message: "the record the last change was made to no longer exists"}
+	}
+	if err != nil {
+		// Nothing was undone, so keep the change around for the user to retry
+		oa.history.Push(fnReq.UserId, change)
+		oa.logger.ErrorContext(ctx, "failed undoing change", slog.Any("err", err), slog.Any("change", change))
+		return FnCallOutputResponse{Success: false, Message: "failed to undo the last change"}
+	}
+
+	return FnCallOutputResponse{Success: true, Message: message, Data: data}
+}
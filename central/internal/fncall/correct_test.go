@@ -0,0 +1,108 @@
+package fncall
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/calamity-m/reaphur/central/internal/prompts"
+	"github.com/calamity-m/reaphur/pkg/errs"
+	centralproto "github.com/calamity-m/reaphur/proto/v1/central"
+	"github.com/calamity-m/reaphur/proto/v1/domain"
+)
+
+// Food server whose calls are answered by the test
+type stubFoodServer struct {
+	centralproto.UnimplementedCentralFoodServiceServer
+
+	filters []*centralproto.GetFoodFilter
+	created []*domain.FoodRecord
+	records []*domain.FoodRecord
+	getErr  error
+	delErr  error
+}
+
+func (s *stubFoodServer) GetFoodRecords(ctx context.Context, r *centralproto.GetFoodRecordsRequest) (*centralproto.GetFoodRecordsResponse, error) {
+	s.filters = append(s.filters, r.GetFilter())
+	if s.getErr != nil {
+		return nil, s.getErr
+	}
+
+	return &centralproto.GetFoodRecordsResponse{Records: s.records}, nil
+}
+
+func (s *stubFoodServer) CreateFoodRecord(ctx context.Context, r *centralproto.CreateFoodRecordRequest) (*centralproto.CreateFoodRecordResponse, error) {
+	s.created = append(s.created, r.GetRecord())
+
+	return &centralproto.CreateFoodRecordResponse{Record: r.GetRecord()}, nil
+}
+
+func (s *stubFoodServer) DeleteFoodRecord(ctx context.Context, r *centralproto.DeleteFoodRecordRequest) (*centralproto.DeleteFoodRecordResponse, error) {
+	if s.delErr != nil {
+		return nil, s.delErr
+	}
+
+	return &centralproto.DeleteFoodRecordResponse{}, nil
+}
+
+func TestFindFoodTarget(t *testing.T) {
+	oa := NewOpenAIFnCaller(slog.New(slog.NewTextHandler(io.Discard, nil)), nil)
+	fnReq := CreateGenericFnCallOutputRequest("", "user", time.UTC)
+
+	t.Run("store picks the latest record", func(t *testing.T) {
+		food := &stubFoodServer{records: []*domain.FoodRecord{{Id: "newest"}}}
+
+		got, message := oa.findFoodTarget(context.Background(), fnReq, "last", food)
+		if got.GetId() != "newest" || message != "" {
+			t.Errorf("got %v, %q but want the newest record", got, message)
+		}
+		if len(food.filters) != 1 || !food.filters[0].GetLatest() || food.filters[0].Name != nil {
+			t.Errorf("got filters %v but want only the latest record", food.filters)
+		}
+	})
+
+	t.Run("nothing found asks the user", func(t *testing.T) {
+		food := &stubFoodServer{getErr: errs.ErrNotFound}
+
+		got, message := oa.findFoodTarget(context.Background(), fnReq, "toast", food)
+		if got != nil || !strings.Contains(message, `no food record found for "toast"`) {
+			t.Errorf("got %v, %q but want a not found message", got, message)
+		}
+		if food.filters[0].GetName() != "toast" {
+			t.Errorf("got filter %v but want it to search by name", food.filters[0])
+		}
+	})
+
+	t.Run("failures are reported", func(t *testing.T) {
+		food := &stubFoodServer{getErr: errors.New("redis went away")}
+
+		if got, message := oa.findFoodTarget(context.Background(), fnReq, "last", food); got != nil || message != "failed to find the food record" {
+			t.Errorf("got %v, %q but want a failure message", got, message)
+		}
+	})
+}
+
+func TestUndoLastActionKeepsChangeOnFailure(t *testing.T) {
+	oa := NewOpenAIFnCaller(slog.New(slog.NewTextHandler(io.Discard, nil)), nil)
+	fnReq := CreateGenericFnCallOutputRequest("", "user", time.UTC)
+	oa.history.Push("user", Change{Kind: ChangeCreated, Record: &domain.FoodRecord{Id: "toast", Name: "toast"}})
+
+	food := &stubFoodServer{delErr: errors.New("redis went away")}
+	if out := oa.handleUndoLastAction(context.Background(), fnReq, prompts.FnUndoLastActionParameters{}, food); out.Success {
+		t.Fatalf("got %+v but want the undo to fail", out)
+	}
+
+	// The change is still there to retry
+	food.delErr = nil
+	if out := oa.handleUndoLastAction(context.Background(), fnReq, prompts.FnUndoLastActionParameters{}, food); !out.Success {
+		t.Fatalf("got %+v but want the retry to succeed", out)
+	}
+
+	if change, ok := oa.history.Pop("user"); ok {
+		t.Errorf("got %+v but want the change gone once undone", change)
+	}
+}
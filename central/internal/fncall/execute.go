@@ -0,0 +1,159 @@
+package fncall
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/calamity-m/reaphur/central/internal/metrics"
+	"github.com/calamity-m/reaphur/pkg/serr"
+	centralproto "github.com/calamity-m/reaphur/proto/v1/central"
+	"github.com/openai/openai-go"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	defaultToolWorkers = 4
+	defaultToolTimeout = 10 * time.Second
+
+	timedOutToolCallMessage = `{"success":false, "message":"tool timed out and may not have completed, tell the user to check before trying again"}`
+)
+
+// Outcome of a single tool call, in the same position as the call it answers
+type toolOutcome struct {
+	call   openai.ChatCompletionMessageToolCall
+	result FnCallOutputResponse
+	// Tool message sent back to the model
+	message string
+}
+
+// Runs the tool calls concurrently on at most toolWorkers workers. Outcomes are
+// returned in the order of the calls so the tool messages match what the model
+// asked for. A failed or timed out tool only fails its own call, the rest still
+// run. Only an error from emit, meaning the caller has gone away, is returned.
+func (oa *OpenAIFnCaller) runTools(ctx context.Context, r FnCallOutputRequest, calls []openai.ChatCompletionMessageToolCall, food centralproto.CentralFoodServiceServer, emit EmitFunc) ([]toolOutcome, error) {
+	outcomes := make([]toolOutcome, len(calls))
+
+	// Events are sent on a single stream, which can't be written to concurrently
+	var (
+		emitMux sync.Mutex
+		emitErr error
+	)
+	send := func(e Event) {
+		emitMux.Lock()
+		defer emitMux.Unlock()
+
+		if emitErr == nil {
+			emitErr = emit.send(e)
+		}
+	}
+
+	workers := make(chan struct{}, max(oa.toolWorkers, 1))
+	var wg sync.WaitGroup
+	for i, call := range calls {
+		wg.Add(1)
+		workers <- struct{}{}
+
+		go func() {
+			defer func() {
+				<-workers
+				wg.Done()
+			}()
+
+			send(Event{Kind: EventToolStarted, CallId: call.ID, Tool: call.Function.Name})
+
+			result, message := oa.runTool(ctx, r, call, food)
+			outcomes[i] = toolOutcome{call: call, result: result, message: message}
+
+			send(Event{Kind: EventToolFinished, CallId: call.ID, Tool: call.Function.Name, Result: result})
+		}()
+	}
+	wg.Wait()
+
+	if emitErr != nil {
+		return nil, emitErr
+	}
+
+	return outcomes, nil
+}
+
+// Runs a single tool call within its timeout, returning the result and the tool
+// message for the model
+func (oa *OpenAIFnCaller) runTool(ctx context.Context, r FnCallOutputRequest, call openai.ChatCompletionMessageToolCall, food centralproto.CentralFoodServiceServer) (FnCallOutputResponse, string) {
+	timeout := oa.toolTimeout
+	tool := metrics.UnknownTool
+	if t, ok := lookupTool(oa.tools, call.Function.Name); ok {
+		tool = call.Function.Name
+		if t.timeout > 0 {
+			timeout = t.timeout
+		}
+	}
+
+	// Tool calls are children of the request's span, alongside the completions
+	// that asked for them
+	start := time.Now()
+	ctx, span := tracer.Start(ctx, "llm.tool", trace.WithAttributes(
+		attribute.String("gen_ai.tool.name", tool),
+		attribute.String("gen_ai.tool.call.id", call.ID),
+	))
+	outcome := metrics.ToolOutcomeSuccess
+	defer func() {
+		metrics.ToolCalls.WithLabelValues(tool, outcome).Inc()
+		metrics.ToolDuration.WithLabelValues(tool).Observe(time.Since(start).Seconds())
+
+		span.SetAttributes(attribute.String("reaphur.tool.outcome", outcome))
+		if outcome == metrics.ToolOutcomeError || outcome == metrics.ToolOutcomeTimeout {
+			span.SetStatus(codes.Error, outcome)
+		}
+		span.End()
+	}()
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	type called struct {
+		result FnCallOutputResponse
+		err    error
+	}
+
+	// Handlers that ignore their context can't hold up the rest of the request
+	done := make(chan called, 1)
+	go func() {
+		result, err := oa.callTool(ctx, r, call.Function.Name, call.Function.Arguments, food)
+		done <- called{result: result, err: err}
+	}()
+
+	var out called
+	select {
+	case out = <-done:
+	case <-ctx.Done():
+		out = called{err: ctx.Err()}
+	}
+
+	if errors.Is(out.err, context.DeadlineExceeded) {
+		oa.logger.ErrorContext(ctx, "tool call timed out", slog.String("tool", call.Function.Name), slog.Duration("timeout", timeout))
+		outcome = metrics.ToolOutcomeTimeout
+		return FnCallOutputResponse{Success: false, Message: "timed out"}, timedOutToolCallMessage
+	}
+	if out.err != nil {
+		oa.logger.ErrorContext(ctx, "tool call failed", slog.String("tool", call.Function.Name), slog.Any("err", out.err))
+		outcome = metrics.ToolOutcomeError
+		return FnCallOutputResponse{Success: false, Message: fmt.Sprintf("tool call failed: %v", out.err)}, failedToolCallMessage
+	}
+
+	if !out.result.Success {
+		outcome = metrics.ToolOutcomeFailure
+	}
+
+	message, err := serr.EncodeJSON(out.result)
+	if err != nil {
+		return out.result, failedToolCallMessage
+	}
+
+	return out.result, message
+}
@@ -0,0 +1,210 @@
+package fncall
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/calamity-m/reaphur/central/internal/metrics"
+	"github.com/calamity-m/reaphur/central/internal/util"
+	"github.com/calamity-m/reaphur/pkg/fakellm"
+	centralproto "github.com/calamity-m/reaphur/proto/v1/central"
+	"github.com/openai/openai-go"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestConcurrentToolCalls(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	script := &fakellm.Script{
+		Rules: []fakellm.Rule{
+			{
+				Match: fakellm.Match{Turn: fakellm.TurnUser},
+				Reply: fakellm.Reply{ToolCalls: []fakellm.ToolCall{
+					{Name: "meal", Arguments: json.RawMessage(`{"meal":"breakfast"}`)},
+					{Name: "meal", Arguments: json.RawMessage(`{"meal":"lunch"}`)},
+					{Name: "slow", Arguments: json.RawMessage(`{}`)},
+					{Name: "broken", Arguments: json.RawMessage(`{}`)},
+					{Name: "meal", Arguments: json.RawMessage(`{"meal":"snack"}`)},
+				}},
+			},
+			{
+				Match: fakellm.Match{Turn: fakellm.TurnTool},
+				Reply: fakellm.Reply{Content: "logged what I could"},
+			},
+		},
+	}
+	llm := fakellm.NewScriptedServer(logger, script)
+	httpServer := httptest.NewServer(llm)
+	defer httpServer.Close()
+
+	var running, peak atomic.Int32
+	track := func() func() {
+		now := running.Add(1)
+		for {
+			p := peak.Load()
+			if now <= p || peak.CompareAndSwap(p, now) {
+				break
+			}
+		}
+
+		return func() { running.Add(-1) }
+	}
+
+	definition := func(name string) func() (openai.FunctionDefinitionParam, error) {
+		return func() (openai.FunctionDefinitionParam, error) {
+			return openai.FunctionDefinitionParam{Name: name}, nil
+		}
+	}
+
+	oa := NewOpenAIFnCaller(logger, util.CreateNewOpenAIClient("fake", httpServer.URL+"/v1/")).WithToolExecution(2, time.Second)
+	oa.tools = []tool{
+		{
+			name:       "meal",
+			definition: definition("meal"),
+			handle: func(ctx context.Context, oa *OpenAIFnCaller, fnReq FnCallOutputRequest, arguments string, food centralproto.CentralFoodServiceServer) (FnCallOutputResponse, error) {
+				defer track()()
+				time.Sleep(20 * time.Millisecond)
+				return FnCallOutputResponse{Success: true, Message: arguments, Data: []interface{}{arguments}}, nil
+			},
+		},
+		{
+			name:       "slow",
+			definition: definition("slow"),
+			timeout:    50 * time.Millisecond,
+			handle: func(ctx context.Context, oa *OpenAIFnCaller, fnReq FnCallOutputRequest, arguments string, food centralproto.CentralFoodServiceServer) (FnCallOutputResponse, error) {
+				defer track()()
+				// Ignores its context, so the timeout has to give up on it
+				time.Sleep(500 * time.Millisecond)
+				return FnCallOutputResponse{Success: true}, nil
+			},
+		},
+		{
+			name:       "broken",
+			definition: definition("broken"),
+			handle: func(ctx context.Context, oa *OpenAIFnCaller, fnReq FnCallOutputRequest, arguments string, food centralproto.CentralFoodServiceServer) (FnCallOutputResponse, error) {
+				defer track()()
+				return FnCallOutputResponse{}, errors.New("redis went away")
+			},
+		},
+	}
+
+	spans := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans)))
+
+	toolCalls := func(tool string, outcome string) float64 {
+		return testutil.ToFloat64(metrics.ToolCalls.WithLabelValues(tool, outcome))
+	}
+	mealsBefore, slowBefore, brokenBefore := toolCalls("meal", metrics.ToolOutcomeSuccess), toolCalls("slow", metrics.ToolOutcomeTimeout), toolCalls("broken", metrics.ToolOutcomeError)
+
+	var mux sync.Mutex
+	events := []Event{}
+	out, err := oa.EnactUserInputStream(context.Background(), CreateGenericFnCallOutputRequest("log my meals", "user", time.UTC), nil, func(e Event) error {
+		mux.Lock()
+		defer mux.Unlock()
+		events = append(events, e)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("got err %v", err)
+	}
+
+	if out.Message != "logged what I could" {
+		t.Errorf("got message %q", out.Message)
+	}
+	if peak.Load() > 2 {
+		t.Errorf("got %d tools running at once but want at most 2", peak.Load())
+	}
+
+	// Data from every successful call is kept, in the order it was asked for
+	want := []interface{}{`{"meal":"breakfast"}`, `{"meal":"lunch"}`, `{"meal":"snack"}`}
+	if len(out.Data) != len(want) {
+		t.Fatalf("got data %v but want %v", out.Data, want)
+	}
+	for i := range want {
+		if out.Data[i] != want[i] {
+			t.Errorf("got data %v but want %v", out.Data, want)
+		}
+	}
+
+	// Tool messages answer the calls in order
+	requests := llm.Requests()
+	if len(requests) != 2 {
+		t.Fatalf("got %d llm requests but want 2", len(requests))
+	}
+	var final struct {
+		Messages []struct {
+			Role       string `json:"role"`
+			Content    string `json:"content"`
+			ToolCallID string `json:"tool_call_id"`
+		} `json:"messages"`
+	}
+	if err := json.Unmarshal(requests[1], &final); err != nil {
+		t.Fatalf("failed decoding final request: %v", err)
+	}
+
+	tools := final.Messages[len(final.Messages)-5:]
+	for i, msg := range tools {
+		if msg.Role != "tool" || !strings.HasSuffix(msg.ToolCallID, fmt.Sprintf("_%d", i)) {
+			t.Errorf("got tool message %d answering %q", i, msg.ToolCallID)
+		}
+	}
+	for i, contains := range []string{"breakfast", "lunch", "timed out", "tool calling failed", "snack"} {
+		if !strings.Contains(tools[i].Content, contains) {
+			t.Errorf("got tool message %d %q but want it to contain %q", i, tools[i].Content, contains)
+		}
+	}
+
+	started, finished := 0, 0
+	for _, e := range events {
+		switch e.Kind {
+		case EventToolStarted:
+			started++
+		case EventToolFinished:
+			finished++
+		}
+	}
+	if started != 5 || finished != 5 {
+		t.Errorf("got %d started and %d finished events but want 5 of each", started, finished)
+	}
+
+	if got := toolCalls("meal", metrics.ToolOutcomeSuccess) - mealsBefore; got != 3 {
+		t.Errorf("got %v successful meal calls recorded but want 3", got)
+	}
+	if got := toolCalls("slow", metrics.ToolOutcomeTimeout) - slowBefore; got != 1 {
+		t.Errorf("got %v timed out slow calls recorded but want 1", got)
+	}
+	if got := toolCalls("broken", metrics.ToolOutcomeError) - brokenBefore; got != 1 {
+		t.Errorf("got %v failed broken calls recorded but want 1", got)
+	}
+
+	// Completions and tool calls all hang off of the request's span
+	var parent trace.SpanID
+	children := map[string]int{}
+	for _, span := range spans.Ended() {
+		if span.Name() == "llm.fncall" {
+			parent = span.SpanContext().SpanID()
+		}
+	}
+	for _, span := range spans.Ended() {
+		if span.Parent().SpanID() == parent {
+			children[span.Name()]++
+		}
+	}
+	if !parent.IsValid() || children["llm.completion"] != 2 || children["llm.tool"] != 5 {
+		t.Errorf("got children %v of the fncall span but want 2 completions and 5 tools", children)
+	}
+}
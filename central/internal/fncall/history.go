@@ -0,0 +1,75 @@
+package fncall
+
+import (
+	"sync"
+
+	"github.com/calamity-m/reaphur/proto/v1/domain"
+	"google.golang.org/protobuf/proto"
+)
+
+type ChangeKind int
+
+const (
+	ChangeCreated ChangeKind = iota
+	ChangeUpdated
+	ChangeDeleted
+)
+
+// A change made to a user's food diary by a tool, holding what is needed to undo it
+type Change struct {
+	Kind ChangeKind
+	// The created record, or the record as it was before being updated or deleted
+	Record *domain.FoodRecord
+}
+
+// In memory history of the changes made for each user, most recent last. Only
+// the most recent changes up to the limit are kept.
+type ChangeHistory struct {
+	mux     sync.Mutex
+	changes map[string][]Change
+	limit   int
+}
+
+// Records a change made for the user
+func (h *ChangeHistory) Push(userId string, change Change) {
+	if change.Record == nil {
+		return
+	}
+	change.Record = proto.Clone(change.Record).(*domain.FoodRecord)
+
+	h.mux.Lock()
+	defer h.mux.Unlock()
+
+	changes := append(h.changes[userId], change)
+	if len(changes) > h.limit {
+		changes = changes[len(changes)-h.limit:]
+	}
+	h.changes[userId] = changes
+}
+
+// Removes and returns the user's most recent change
+func (h *ChangeHistory) Pop(userId string) (Change, bool) {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+
+	changes := h.changes[userId]
+	if len(changes) == 0 {
+		return Change{}, false
+	}
+
+	last := changes[len(changes)-1]
+	if len(changes) == 1 {
+		delete(h.changes, userId)
+	} else {
+		h.changes[userId] = changes[:len(changes)-1]
+	}
+
+	return last, true
+}
+
+func NewChangeHistory(limit int) *ChangeHistory {
+	return &ChangeHistory{
+		changes: make(map[string][]Change),
+		limit:   limit,
+	}
+}
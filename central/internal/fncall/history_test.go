@@ -0,0 +1,38 @@
+package fncall
+
+import (
+	"testing"
+
+	"github.com/calamity-m/reaphur/proto/v1/domain"
+)
+
+func TestChangeHistory(t *testing.T) {
+	history := NewChangeHistory(2)
+
+	record := &domain.FoodRecord{Name: "banana"}
+	history.Push("user", Change{Kind: ChangeCreated, Record: record})
+	history.Push("user", Change{Kind: ChangeUpdated, Record: &domain.FoodRecord{Name: "apple"}})
+	history.Push("user", Change{Kind: ChangeDeleted, Record: &domain.FoodRecord{Name: "pear"}})
+
+	// Recorded changes aren't affected by later edits to the record
+	record.Name = "changed"
+
+	if _, ok := history.Pop("intruder"); ok {
+		t.Fatal("got a change for a user without any")
+	}
+
+	for _, want := range []struct {
+		kind ChangeKind
+		name string
+	}{{ChangeDeleted, "pear"}, {ChangeUpdated, "apple"}} {
+		change, ok := history.Pop("user")
+		if !ok || change.Kind != want.kind || change.Record.GetName() != want.name {
+			t.Fatalf("got %+v, %t but want %s", change, ok, want.name)
+		}
+	}
+
+	// Only the most recent changes are kept
+	if change, ok := history.Pop("user"); ok {
+		t.Errorf("got %+v but want the oldest change dropped", change)
+	}
+}
@@ -2,28 +2,156 @@ package fncall
 
 import (
 	"context"
+	"encoding/base64"
+	"errors"
 	"fmt"
 	"log/slog"
 	"strings"
 	"time"
 
+	"github.com/calamity-m/reaphur/central/internal/guard"
+	"github.com/calamity-m/reaphur/central/internal/metrics"
 	"github.com/calamity-m/reaphur/central/internal/prompts"
-	"github.com/calamity-m/reaphur/pkg/serr"
+	"github.com/calamity-m/reaphur/central/internal/timeexpr"
+	"github.com/calamity-m/reaphur/pkg/errs"
 	centralproto "github.com/calamity-m/reaphur/proto/v1/central"
 	"github.com/calamity-m/reaphur/proto/v1/domain"
+	"github.com/google/uuid"
 	"github.com/openai/openai-go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 type FnCallOutputRequest struct {
-	UserId    string `json:"user_id"`
+	UserId string `json:"user_id"`
+	// Escaped user input, wrapped in <input> tags. Never trusted.
 	UserInput string `json:"user_input"`
+	// Trusted context generated by us, sent as its own developer message
+	// so that user input can never masquerade as it.
+	Context string `json:"context"`
+
+	// Location and time the request was made in, used to resolve relative times
+	// in tool arguments such as "yesterday"
+	Location *time.Location `json:"-"`
+	Now      time.Time      `json:"-"`
+
+	// Images sent along with the input, such as a photo of a meal
+	Images []Image `json:"-"`
+
+	// What the user has told us about themselves. Escaped and sent in the user
+	// message ahead of the input, as it is never trusted.
+	UserProfile string `json:"-"`
+}
+
+// Image sent by the user. Either Data or URL is set.
+type Image struct {
+	Data     []byte
+	URL      string
+	MimeType string
+}
+
+// Url handed to the model, raw data is sent inline as a base64 data url
+func (i Image) url() string {
+	if len(i.Data) == 0 {
+		return i.URL
+	}
+
+	return fmt.Sprintf("data:%s;base64,%s", i.MimeType, base64.StdEncoding.EncodeToString(i.Data))
+}
+
+// When the request was made, falling back to now for requests that didn't say
+func (r FnCallOutputRequest) now() time.Time {
+	if r.Now.IsZero() {
+		return time.Now()
+	}
+
+	return r.Now
+}
+
+// Resolves a time expression from tool arguments relative to when and where the
+// request was made
+func (r FnCallOutputRequest) resolveTime(expr string) (timeexpr.Range, error) {
+	return timeexpr.Resolve(expr, r.now(), r.Location)
 }
 
 type FnCallOutputResponse struct {
 	Message string        `json:"message"`
 	Success bool          `json:"success"`
 	Data    []interface{} `json:"data"`
+
+	// Set when the tool call was parked awaiting user confirmation
+	PendingActionId string `json:"pending_action_id,omitempty"`
+
+	// Token usage of every completion made while enacting the request. This is
+	// never sent back to the model.
+	Usage TokenUsage `json:"-"`
+	// Actions awaiting confirmation from the user
+	PendingActions []PendingAction `json:"-"`
+}
+
+type TokenUsage struct {
+	Model string
+	// Version of the prompt template the request was served with
+	PromptVersion    string
+	PromptTokens     int64
+	CompletionTokens int64
+}
+
+// Accumulates the usage block of a completion
+func (u *TokenUsage) add(completion *openai.ChatCompletion) {
+	if completion == nil {
+		return
+	}
+
+	u.PromptTokens += completion.Usage.PromptTokens
+	u.CompletionTokens += completion.Usage.CompletionTokens
+}
+
+var tracer = otel.Tracer("github.com/calamity-m/reaphur/central/internal/fncall")
+
+// Runs a regular completion, tracing it and recording its latency and tokens
+func (oa *OpenAIFnCaller) complete(ctx context.Context, params openai.ChatCompletionNewParams) (*openai.ChatCompletion, error) {
+	ctx, done := instrumentCompletion(ctx, params.Model)
+
+	completion, err := oa.client.Chat.Completions.New(ctx, params)
+	done(completion, err)
+
+	return completion, err
+}
+
+// Starts a span for a completion, returning a func that ends it and records the
+// latency and tokens. Models come from configuration, so they're safe to use as
+// a label.
+func instrumentCompletion(ctx context.Context, model openai.ChatModel) (context.Context, func(*openai.ChatCompletion, error)) {
+	start := time.Now()
+	ctx, span := tracer.Start(ctx, "llm.completion",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("gen_ai.request.model", model)),
+	)
+
+	return ctx, func(completion *openai.ChatCompletion, err error) {
+		defer span.End()
+		metrics.LLMDuration.WithLabelValues(model, metrics.Outcome(err)).Observe(time.Since(start).Seconds())
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return
+		}
+		if completion == nil {
+			return
+		}
+
+		metrics.LLMTokens.WithLabelValues(model, "prompt").Add(float64(completion.Usage.PromptTokens))
+		metrics.LLMTokens.WithLabelValues(model, "completion").Add(float64(completion.Usage.CompletionTokens))
+		span.SetAttributes(
+			attribute.Int64("gen_ai.usage.input_tokens", completion.Usage.PromptTokens),
+			attribute.Int64("gen_ai.usage.output_tokens", completion.Usage.CompletionTokens),
+		)
+	}
 }
 
 type OpenAIFnCaller struct {
@@ -31,6 +159,17 @@ type OpenAIFnCaller struct {
 	client *openai.Client
 	seed   int64
 	model  openai.ChatModel
+	// Used instead of model for requests carrying images
+	visionModel openai.ChatModel
+	tools       []tool
+	pending     *PendingActionStore
+	history     *ChangeHistory
+	prompt      *prompts.Template
+	promptVars  prompts.Vars
+	// Tool calls from a single completion run concurrently on at most this many
+	// workers, each limited to the timeout unless the tool sets its own
+	toolWorkers int
+	toolTimeout time.Duration
 }
 
 func (oa *OpenAIFnCaller) handleCreateFood(ctx context.Context, fnReq FnCallOutputRequest, args prompts.FnCreateFoodParameters, food centralproto.CentralFoodServiceServer) FnCallOutputResponse {
@@ -49,6 +188,21 @@ func (oa *OpenAIFnCaller) handleCreateFood(ctx context.Context, fnReq FnCallOutp
 		rec.Record.Kj = args.Energy
 	}
 
+	// Missing times are just now, but times we can't understand shouldn't be
+	// silently recorded as now
+	if args.Time != "" {
+		eaten, err := fnReq.resolveTime(args.Time)
+		if err != nil {
+			oa.logger.ErrorContext(ctx, "failed resolving time arg", slog.Any("err", err), slog.Any("args", args))
+			return FnCallOutputResponse{
+				Success: false,
+				Message: fmt.Sprintf("couldn't understand the time %q, ask the user when they ate", args.Time),
+			}
+		}
+
+		rec.Record.Time = timestamppb.New(eaten.Point(fnReq.now()))
+	}
+
 	created, err := food.CreateFoodRecord(ctx, rec)
 	if err != nil {
 		return FnCallOutputResponse{
@@ -57,40 +211,38 @@ func (oa *OpenAIFnCaller) handleCreateFood(ctx context.Context, fnReq FnCallOutp
 		}
 	}
 
+	oa.history.Push(fnReq.UserId, Change{Kind: ChangeCreated, Record: created.GetRecord()})
 	oa.logger.InfoContext(ctx, "created food record", slog.Any("created", created))
 
 	return FnCallOutputResponse{
 		Success: true,
 		Message: "successfully created food record",
+		Data:    []interface{}{created.GetRecord()},
 	}
 }
 
-func (oa *OpenAIFnCaller) handleGetFood(ctx context.Context, fnReq FnCallOutputRequest, args prompts.FnGetFoodParameters, food centralproto.CentralFoodServiceServer) FnCallOutputResponse {
+// Logs food without going through the llm, such as from a rule based parse
+func (oa *OpenAIFnCaller) LogFood(ctx context.Context, fnReq FnCallOutputRequest, args prompts.FnCreateFoodParameters, food centralproto.CentralFoodServiceServer) FnCallOutputResponse {
+	return oa.handleCreateFood(ctx, fnReq, args, food)
+}
 
-	// Suffix ending Z if not there
-	if !strings.HasSuffix(args.BeforeTime, "Z") {
-		args.BeforeTime += "Z"
-	}
-	if !strings.HasSuffix(args.AfterTime, "Z") {
-		args.AfterTime += "Z"
-	}
+func (oa *OpenAIFnCaller) handleGetFood(ctx context.Context, fnReq FnCallOutputRequest, args prompts.FnGetFoodParameters, food centralproto.CentralFoodServiceServer) FnCallOutputResponse {
 
-	// time.ParseError {Layout: "2006-01-02T15:04:05Z", Value: "2025-02-18T00:00:00", LayoutElem: "Z", ValueElem: "", Message: ""}
-	before, err := time.Parse("2006-01-02T15:04:05Z", args.BeforeTime)
+	after, err := fnReq.resolveTime(args.AfterTime)
 	if err != nil {
-		oa.logger.ErrorContext(ctx, "failed parsing before time arg", slog.Any("err", err), slog.Any("args", args))
+		oa.logger.ErrorContext(ctx, "failed resolving after time arg", slog.Any("err", err), slog.Any("args", args))
 		return FnCallOutputResponse{
 			Success: false,
-			Message: "sorry i couldnt use that before_time date format",
+			Message: fmt.Sprintf("couldn't understand the after_time %q, use an expression like \"yesterday\" or an ISO 8601 timestamp", args.AfterTime),
 		}
 	}
 
-	after, err := time.Parse("2006-01-02T15:04:05Z", args.AfterTime)
+	before, err := fnReq.resolveTime(args.BeforeTime)
 	if err != nil {
-		oa.logger.ErrorContext(ctx, "failed parsing after time arg", slog.Any("err", err), slog.Any("args", args))
+		oa.logger.ErrorContext(ctx, "failed resolving before time arg", slog.Any("err", err), slog.Any("args", args))
 		return FnCallOutputResponse{
 			Success: false,
-			Message: "sorry i couldnt use that after_time date format",
+			Message: fmt.Sprintf("couldn't understand the before_time %q, use an expression like \"yesterday\" or an ISO 8601 timestamp", args.BeforeTime),
 		}
 	}
 
@@ -98,8 +250,8 @@ func (oa *OpenAIFnCaller) handleGetFood(ctx context.Context, fnReq FnCallOutputR
 		RequestUserId: fnReq.UserId,
 		Filter: &centralproto.GetFoodFilter{
 			Name:       &args.Query,
-			BeforeTime: timestamppb.New(before),
-			AfterTime:  timestamppb.New(after),
+			BeforeTime: timestamppb.New(before.End),
+			AfterTime:  timestamppb.New(after.Start),
 		},
 	}
 
@@ -133,40 +285,110 @@ func (oa *OpenAIFnCaller) handleGetFood(ctx context.Context, fnReq FnCallOutputR
 }
 
 func (oa *OpenAIFnCaller) EnactUserInput(ctx context.Context, r FnCallOutputRequest, food centralproto.CentralFoodServiceServer) (FnCallOutputResponse, error) {
-	if oa.model == "" {
-		return FnCallOutputResponse{}, fmt.Errorf("no model selected")
+	return oa.enact(ctx, r, food, nil)
+}
+
+// Same as EnactUserInput, but reports tool progress and streams the final
+// response text to emit as it is generated.
+func (oa *OpenAIFnCaller) EnactUserInputStream(ctx context.Context, r FnCallOutputRequest, food centralproto.CentralFoodServiceServer, emit EmitFunc) (FnCallOutputResponse, error) {
+	return oa.enact(ctx, r, food, emit)
+}
+
+// Builds the first completion of a request, which offers the model every tool
+// alongside the prompt, context and user input
+func (oa *OpenAIFnCaller) firstCompletionParams(r FnCallOutputRequest) (openai.ChatCompletionNewParams, TokenUsage, error) {
+	tools, err := chatCompletionToolParams(oa.tools)
+	if err != nil {
+		return openai.ChatCompletionNewParams{}, TokenUsage{}, err
 	}
 
-	oa.logger.InfoContext(ctx, "received user input request", slog.Any("request", r))
+	model := oa.model
+	text := userMessageText(r)
+	user := openai.UserMessage(text)
+
+	// Images go alongside the text input as extra content parts
+	if len(r.Images) > 0 {
+		model = oa.visionModel
+
+		parts := []openai.ChatCompletionContentPartUnionParam{openai.TextContentPart(text)}
+		for _, image := range r.Images {
+			parts = append(parts, openai.ImageContentPart(openai.ChatCompletionContentPartImageImageURLParam{URL: image.url()}))
+		}
+		user = openai.UserMessage(parts)
+	}
 
-	tools, err := GetChatCompletionToolParamList()
+	prompt, err := oa.prompt.Render(oa.promptVars)
 	if err != nil {
-		return FnCallOutputResponse{}, err
+		return openai.ChatCompletionNewParams{}, TokenUsage{}, err
 	}
 
-	// Create first chat interaction to discover which food input to use
 	params := openai.ChatCompletionNewParams{
-		Model: oa.model,
+		Model: model,
 		Tools: tools,
 		Messages: []openai.ChatCompletionMessageParamUnion{
-			openai.DeveloperMessage(prompts.CENTRAL_PROMPT),
-			openai.UserMessage(r.UserInput),
+			openai.DeveloperMessage(prompt),
+			openai.DeveloperMessage(r.Context),
+			user,
 		},
 	}
 
-	completion, err := oa.client.Chat.Completions.New(ctx, params)
+	return params, TokenUsage{Model: model, PromptVersion: oa.prompt.ID()}, nil
+}
+
+// The user's own words, with their profile ahead of the input when they have
+// one. Both are untrusted so neither belongs in a developer message.
+func userMessageText(r FnCallOutputRequest) string {
+	if r.UserProfile == "" {
+		return r.UserInput
+	}
+
+	return "<profile>" + guard.Escape(r.UserProfile) + "</profile>\n" + r.UserInput
+}
+
+// Runs the request to completion. Once tokens have been spent, failures still
+// return the usage so far so it can be charged.
+func (oa *OpenAIFnCaller) enact(ctx context.Context, r FnCallOutputRequest, food centralproto.CentralFoodServiceServer, emit EmitFunc) (FnCallOutputResponse, error) {
+	ctx, span := tracer.Start(ctx, "llm.fncall")
+	defer span.End()
+
+	if oa.model == "" {
+		return FnCallOutputResponse{}, fmt.Errorf("no model selected")
+	}
+
+	oa.logger.InfoContext(ctx, "received user input request", slog.Any("request", r))
+
+	// Create first chat interaction to discover which food input to use
+	params, usage, err := oa.firstCompletionParams(r)
 	if err != nil {
 		return FnCallOutputResponse{}, err
 	}
+
+	completion, err := oa.complete(ctx, params)
+	if err != nil {
+		return FnCallOutputResponse{}, llmError(ctx, err)
+	}
+	usage.add(completion)
 	oa.logger.DebugContext(ctx, "completed first tool call completion", slog.Any("completion", completion))
+	if len(completion.Choices) == 0 {
+		return FnCallOutputResponse{Usage: usage}, fmt.Errorf("first completion had no choices")
+	}
 
 	toolCalls := completion.Choices[0].Message.ToolCalls
 
 	// Ensure we have some function calls
 	if len(toolCalls) == 0 {
 		oa.logger.ErrorContext(ctx, "no tools were called", slog.Any("completion", completion))
+
+		// Nothing left to generate, so the whole message is the only delta
+		if content := completion.Choices[0].Message.Content; content != "" {
+			if err := emit.send(Event{Kind: EventTextDelta, Delta: content}); err != nil {
+				return FnCallOutputResponse{Usage: usage}, err
+			}
+		}
+
 		return FnCallOutputResponse{
 			Message: completion.Choices[0].Message.Content,
+			Usage:   usage,
 		}, nil
 	}
 
@@ -174,106 +396,166 @@ func (oa *OpenAIFnCaller) EnactUserInput(ctx context.Context, r FnCallOutputRequ
 	params.Messages = append(params.Messages, completion.Choices[0].Message.ToParam())
 
 	// Evaluate the functions
-	for _, call := range toolCalls {
-		switch call.Function.Name {
-		case createFoodName:
-			// CREATE FOOD FUNCTION
-			args, err := serr.DecodeJSONS[prompts.FnCreateFoodParameters](call.Function.Arguments)
-			if err != nil {
-				oa.logger.ErrorContext(ctx, "failed to decode arguments from tool call", slog.Any("call", call))
-				return FnCallOutputResponse{}, err
-			}
+	outcomes, err := oa.runTools(ctx, r, toolCalls, food, emit)
+	if err != nil {
+		return FnCallOutputResponse{Usage: usage}, err
+	}
 
-			record := oa.handleCreateFood(ctx, r, args, food)
+	pending := make([]PendingAction, 0)
+	data := make([]interface{}, 0)
+	for _, outcome := range outcomes {
+		pending = append(pending, outcome.result.PendingActions...)
+		data = append(data, outcome.result.Data...)
 
-			resp, err := serr.EncodeJSON(record)
-			if err != nil {
-				params.Messages = append(params.Messages, openai.ToolMessage(call.ID, failedToolCallMessage))
-				break
-			}
+		params.Messages = append(params.Messages, openai.ToolMessage(outcome.message, outcome.call.ID))
+	}
 
-			params.Messages = append(params.Messages, openai.ToolMessage(call.ID, resp))
-		case getFoodName:
-			// GET FOOD FUNCTION
-			args, err := serr.DecodeJSONS[prompts.FnGetFoodParameters](call.Function.Arguments)
-			if err != nil {
-				oa.logger.ErrorContext(ctx, "failed to decode arguments from tool call", slog.Any("call", call))
-				return FnCallOutputResponse{}, err
-			}
+	oa.logger.DebugContext(ctx, "sending completed params", slog.Any("params", params))
 
-			record := oa.handleGetFood(ctx, r, args, food)
+	if emit != nil {
+		completion, err = oa.streamCompletion(ctx, params, emit)
+	} else {
+		completion, err = oa.complete(ctx, params)
+	}
+	// The tools have already run, so report them rather than failing the request
+	if err = llmError(ctx, err); errors.Is(err, errs.ErrUnavailable) {
+		oa.logger.WarnContext(ctx, "llm unavailable for final completion", slog.Any("err", err))
+		return FnCallOutputResponse{
+			Message:        toolsDoneUnavailableMessage,
+			Data:           data,
+			Usage:          usage,
+			PendingActions: pending,
+		}, nil
+	}
+	if err != nil {
+		return FnCallOutputResponse{Usage: usage}, err
+	}
+	usage.add(completion)
+	if len(completion.Choices) == 0 {
+		return FnCallOutputResponse{Usage: usage}, fmt.Errorf("final completion had no choices")
+	}
+	oa.logger.DebugContext(ctx, "completed final completion", slog.Any("completion", completion))
 
-			resp, err := serr.EncodeJSON(record)
-			if err != nil {
-				params.Messages = append(params.Messages, openai.ToolMessage(call.ID, failedToolCallMessage))
-				break
-			}
+	return FnCallOutputResponse{
+		Message:        completion.Choices[0].Message.Content,
+		Data:           data,
+		Usage:          usage,
+		PendingActions: pending,
+	}, nil
+}
 
-			params.Messages = append(params.Messages, openai.ToolMessage(call.ID, resp))
-		case createWeightLiftingName:
-			resp, err := serr.EncodeJSON(FnCallOutputResponse{Success: false, Message: "weight lifting not yet completed, sorry"})
-			if err != nil {
-				params.Messages = append(params.Messages, openai.ToolMessage(call.ID, failedToolCallMessage))
-				break
-			}
+// Runs a pending action previously parked for the user
+func (oa *OpenAIFnCaller) ConfirmAction(ctx context.Context, userId string, actionId uuid.UUID, food centralproto.CentralFoodServiceServer) (FnCallOutputResponse, error) {
+	action, err := oa.pending.Take(userId, actionId)
+	if err != nil {
+		return FnCallOutputResponse{}, err
+	}
 
-			params.Messages = append(params.Messages, openai.ToolMessage(call.ID, resp))
-		case createCardioName:
-			resp, err := serr.EncodeJSON(FnCallOutputResponse{Success: false, Message: "cardio logging not yet completed, sorry"})
-			if err != nil {
-				params.Messages = append(params.Messages, openai.ToolMessage(call.ID, failedToolCallMessage))
-				break
-			}
+	t, ok := lookupTool(oa.tools, action.Tool)
+	if !ok {
+		return FnCallOutputResponse{}, fmt.Errorf("pending action tool %q no longer exists - %w", action.Tool, errs.ErrNotFound)
+	}
 
-			params.Messages = append(params.Messages, openai.ToolMessage(call.ID, resp))
-		default:
-			// UNMATCHED FUNCTIONS
-			errResp, err := serr.EncodeJSON(FnCallOutputResponse{Success: false, Message: "unmatched"})
-			if err != nil {
-				params.Messages = append(params.Messages, openai.ToolMessage(call.ID, failedToolCallMessage))
-				break
-			}
+	oa.logger.InfoContext(ctx, "confirmed pending action", slog.Any("action", action))
 
-			params.Messages = append(params.Messages, openai.ToolMessage(call.ID, errResp))
-		}
+	// Relative times are resolved as of when the action was requested
+	out, err := t.handle(ctx, oa, FnCallOutputRequest{UserId: userId, Location: action.Location, Now: action.Created}, action.Arguments, food)
+	if err != nil {
+		return out, err
 	}
 
-	oa.logger.DebugContext(ctx, "sending completed params", slog.Any("params", params))
+	// Tool messages are written for the model, so the user is told the outcome
+	// in terms of what they confirmed instead
+	oa.logger.DebugContext(ctx, "ran confirmed action", slog.Bool("success", out.Success), slog.String("tool_message", out.Message))
+	out.Message = confirmedActionMessage(action, out.Success)
+
+	return out, nil
+}
+
+func confirmedActionMessage(action PendingAction, success bool) string {
+	if success {
+		return fmt.Sprintf("Done, I went ahead and did as you confirmed: %s.", action.Summary)
+	}
+
+	return fmt.Sprintf("Sorry, I couldn't %s. Your food diary may have changed since you asked, so please ask again.", action.Summary)
+}
 
-	completion, err = oa.client.Chat.Completions.New(ctx, params)
+// Discards a pending action previously parked for the user
+func (oa *OpenAIFnCaller) CancelAction(ctx context.Context, userId string, actionId uuid.UUID) (PendingAction, error) {
+	action, err := oa.pending.Take(userId, actionId)
 	if err != nil {
-		return FnCallOutputResponse{}, err
+		return PendingAction{}, err
 	}
-	oa.logger.DebugContext(ctx, "completed final completion", slog.Any("completion", completion))
 
-	return FnCallOutputResponse{
-		Message: completion.Choices[0].Message.Content,
-	}, nil
+	oa.logger.InfoContext(ctx, "cancelled pending action", slog.Any("action", action))
+
+	return action, nil
 }
 
-func CreateGenericFnCallOutputRequest(userInput string, userId string) FnCallOutputRequest {
+func CreateGenericFnCallOutputRequest(userInput string, userId string, loc *time.Location) FnCallOutputRequest {
+	if loc == nil {
+		loc = time.UTC
+	}
+	now := time.Now().In(loc)
 
-	var inputBuilder strings.Builder
+	var contextBuilder strings.Builder
 
-	inputBuilder.WriteString("<extra>")
-	inputBuilder.WriteString(fmt.Sprintf("date: %s", time.Now().Format(time.DateOnly)))
-	inputBuilder.WriteString("</extra>")
+	contextBuilder.WriteString("<extra>")
+	contextBuilder.WriteString(fmt.Sprintf("date: %s (%s), time: %s, timezone: %s", now.Format(time.DateOnly), now.Weekday(), now.Format("15:04"), loc))
+	contextBuilder.WriteString("</extra>")
+
+	// User input is escaped so it can't close the input tag and smuggle in
+	// its own context
+	var inputBuilder strings.Builder
 
 	inputBuilder.WriteString("<input>")
-	inputBuilder.WriteString(userInput)
+	inputBuilder.WriteString(guard.Escape(userInput))
 	inputBuilder.WriteString("</input>")
 
 	return FnCallOutputRequest{
 		UserInput: inputBuilder.String(),
+		Context:   contextBuilder.String(),
 		UserId:    userId,
+		Location:  loc,
+		Now:       now,
 	}
 }
 
 func NewOpenAIFnCaller(logger *slog.Logger, client *openai.Client) *OpenAIFnCaller {
 	return &OpenAIFnCaller{
-		logger: logger,
-		client: client,
-		seed:   99,
-		model:  openai.ChatModelGPT4oMini,
+		logger:      logger,
+		client:      client,
+		seed:        99,
+		model:       openai.ChatModelGPT4oMini,
+		visionModel: openai.ChatModelGPT4oMini,
+		tools:       registry,
+		pending:     NewPendingActionStore(15 * time.Minute),
+		history:     NewChangeHistory(20),
+		prompt:      prompts.Default(),
+		toolWorkers: defaultToolWorkers,
+		toolTimeout: defaultToolTimeout,
 	}
 }
+
+// Serves requests with the given prompt template instead of the embedded default.
+// Only the tools of the domains the prompt covers are offered to the model.
+func (oa *OpenAIFnCaller) WithPrompt(prompt *prompts.Template, vars prompts.Vars) *OpenAIFnCaller {
+	oa.prompt = prompt
+	oa.promptVars = vars
+	oa.tools = toolsForDomains(oa.tools, vars.Domains)
+
+	return oa
+}
+
+// Runs tool calls on the given number of workers, each limited to the timeout.
+// Zero values keep the defaults.
+func (oa *OpenAIFnCaller) WithToolExecution(workers int, timeout time.Duration) *OpenAIFnCaller {
+	if workers > 0 {
+		oa.toolWorkers = workers
+	}
+	if timeout > 0 {
+		oa.toolTimeout = timeout
+	}
+
+	return oa
+}
@@ -12,6 +12,10 @@ const (
 
 	getFoodName = "get_food"
 
+	updateFoodName     = "update_food"
+	deleteFoodName     = "delete_food"
+	undoLastActionName = "undo_last_action"
+
 	failedToolCallMessage = `{"success":false, "message":"tool calling failed"}`
 )
 
@@ -71,41 +75,62 @@ func GetFoodParam() (openai.FunctionDefinitionParam, error) {
 	}, nil
 }
 
-func GetChatCompletionToolParamList() ([]openai.ChatCompletionToolParam, error) {
+func UpdateFoodParam() (openai.FunctionDefinitionParam, error) {
+	return openai.FunctionDefinitionParam{
+		Name:        updateFoodName,
+		Description: openai.String("corrects a food entry already in the diary, such as a mistyped energy"),
+		Strict:      openai.Bool(true),
+		Parameters: openai.FunctionParameters{
+			"type":                 "object",
+			"properties":           prompts.UpdateFoodProperties,
+			"required":             prompts.UpdateFoodRequired,
+			"additionalProperties": openai.Bool(false),
+		},
+	}, nil
+}
 
-	createFoodFn, err := CreateFoodParam()
-	if err != nil {
-		return nil, err
-	}
+func DeleteFoodParam() (openai.FunctionDefinitionParam, error) {
+	return openai.FunctionDefinitionParam{
+		Name:        deleteFoodName,
+		Description: openai.String("removes a food entry from the diary"),
+		Strict:      openai.Bool(true),
+		Parameters: openai.FunctionParameters{
+			"type":                 "object",
+			"properties":           prompts.DeleteFoodProperties,
+			"required":             prompts.DeleteFoodRequired,
+			"additionalProperties": openai.Bool(false),
+		},
+	}, nil
+}
 
-	getFoodFn, err := GetFoodParam()
-	if err != nil {
-		return nil, err
-	}
+func UndoLastActionParam() (openai.FunctionDefinitionParam, error) {
+	return openai.FunctionDefinitionParam{
+		Name:        undoLastActionName,
+		Description: openai.String("reverts the last change made to the user's diary, whether logging, correcting or removing food"),
+		Strict:      openai.Bool(true),
+		Parameters: openai.FunctionParameters{
+			"type":                 "object",
+			"properties":           prompts.UndoLastActionProperties,
+			"required":             prompts.UndoLastActionRequired,
+			"additionalProperties": openai.Bool(false),
+		},
+	}, nil
+}
 
-	createWeightFn, err := CreateWeightLiftingParam()
-	if err != nil {
-		return nil, err
-	}
+func GetChatCompletionToolParamList() ([]openai.ChatCompletionToolParam, error) {
+	return chatCompletionToolParams(registry)
+}
 
-	createCardioFn, err := CreateCardioParam()
-	if err != nil {
-		return nil, err
-	}
+func chatCompletionToolParams(tools []tool) ([]openai.ChatCompletionToolParam, error) {
+	parr := make([]openai.ChatCompletionToolParam, 0, len(tools))
 
-	parr := []openai.ChatCompletionToolParam{
-		{
-			Function: createFoodFn,
-		},
-		{
-			Function: getFoodFn,
-		},
-		{
-			Function: createWeightFn,
-		},
-		{
-			Function: createCardioFn,
-		},
+	for _, t := range tools {
+		fn, err := t.definition()
+		if err != nil {
+			return nil, err
+		}
+
+		parr = append(parr, openai.ChatCompletionToolParam{Function: fn})
 	}
 
 	return parr, nil
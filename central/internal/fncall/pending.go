@@ -0,0 +1,91 @@
+package fncall
+
+import (
+	"sync"
+	"time"
+
+	"github.com/calamity-m/reaphur/pkg/errs"
+	"github.com/google/uuid"
+)
+
+// An action requested by the model that is waiting on the user to confirm or cancel it
+type PendingAction struct {
+	Id        uuid.UUID
+	UserId    string
+	Tool      string
+	Arguments string
+	Summary   string
+	Created   time.Time
+	// Location of the user when they made the request
+	Location *time.Location
+}
+
+// In memory store of pending actions. Actions expire after the ttl, at which
+// point they can no longer be confirmed.
+type PendingActionStore struct {
+	mux     sync.Mutex
+	actions map[uuid.UUID]PendingAction
+	ttl     time.Duration
+	now     func() time.Time
+}
+
+// Parks a new pending action for the user
+func (p *PendingActionStore) Add(userId string, loc *time.Location, tool string, arguments string, summary string) (PendingAction, error) {
+	id, err := uuid.NewV7()
+	if err != nil {
+		return PendingAction{}, err
+	}
+
+	action := PendingAction{
+		Id:        id,
+		UserId:    userId,
+		Tool:      tool,
+		Arguments: arguments,
+		Summary:   summary,
+		Created:   p.now(),
+		Location:  loc,
+	}
+
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	p.evict()
+	p.actions[id] = action
+
+	return action, nil
+}
+
+// Removes and returns the pending action. Actions belonging to other users are
+// reported as not found, so callers can't probe for them.
+func (p *PendingActionStore) Take(userId string, id uuid.UUID) (PendingAction, error) {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	p.evict()
+
+	action, ok := p.actions[id]
+	if !ok || action.UserId != userId {
+		return PendingAction{}, errs.ErrNotFound
+	}
+
+	delete(p.actions, id)
+
+	return action, nil
+}
+
+// Drops expired actions, must be called with the lock held
+func (p *PendingActionStore) evict() {
+	for id, action := range p.actions {
+		if p.now().Sub(action.Created) > p.ttl {
+			delete(p.actions, id)
+		}
+	}
+}
+
+func NewPendingActionStore(ttl time.Duration) *PendingActionStore {
+	return &PendingActionStore{
+		actions: make(map[uuid.UUID]PendingAction),
+		ttl:     ttl,
+		now:     time.Now,
+	}
+}
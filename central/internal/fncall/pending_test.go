@@ -0,0 +1,149 @@
+package fncall
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/calamity-m/reaphur/central/internal/util"
+	"github.com/calamity-m/reaphur/pkg/errs"
+	"github.com/calamity-m/reaphur/pkg/fakellm"
+	centralproto "github.com/calamity-m/reaphur/proto/v1/central"
+	"github.com/google/uuid"
+	"github.com/openai/openai-go"
+)
+
+func TestPendingActionStore(t *testing.T) {
+	now := time.Unix(0, 0)
+	store := NewPendingActionStore(time.Minute)
+	store.now = func() time.Time { return now }
+
+	t.Run("only the owner can take an action", func(t *testing.T) {
+		action, err := store.Add("user", time.UTC, "tool", "{}", "do the thing")
+		if err != nil {
+			t.Fatalf("got err %v", err)
+		}
+
+		if _, err := store.Take("someone else", action.Id); !errors.Is(err, errs.ErrNotFound) {
+			t.Errorf("got err %v but want not found", err)
+		}
+
+		got, err := store.Take("user", action.Id)
+		if err != nil {
+			t.Fatalf("got err %v", err)
+		}
+		if got.Summary != "do the thing" {
+			t.Errorf("got summary %q", got.Summary)
+		}
+
+		// Actions can only be taken once
+		if _, err := store.Take("user", action.Id); !errors.Is(err, errs.ErrNotFound) {
+			t.Errorf("got err %v but want not found", err)
+		}
+	})
+
+	t.Run("actions expire", func(t *testing.T) {
+		action, err := store.Add("user", time.UTC, "tool", "{}", "do the thing")
+		if err != nil {
+			t.Fatalf("got err %v", err)
+		}
+
+		now = now.Add(2 * time.Minute)
+
+		if _, err := store.Take("user", action.Id); !errors.Is(err, errs.ErrNotFound) {
+			t.Errorf("got err %v but want not found", err)
+		}
+	})
+}
+
+func TestConfirmationFlow(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	script := &fakellm.Script{
+		Rules: []fakellm.Rule{
+			{
+				Match: fakellm.Match{Turn: fakellm.TurnUser},
+				Reply: fakellm.Reply{ToolCalls: []fakellm.ToolCall{{Name: "wipe", Arguments: json.RawMessage(`{"what":"everything"}`)}}},
+			},
+			{
+				Match: fakellm.Match{Turn: fakellm.TurnTool},
+				Reply: fakellm.Reply{Content: "waiting on you"},
+			},
+		},
+	}
+	httpServer := httptest.NewServer(fakellm.NewScriptedServer(logger, script))
+	defer httpServer.Close()
+
+	ran := 0
+	oa := NewOpenAIFnCaller(logger, util.CreateNewOpenAIClient("fake", httpServer.URL+"/v1/"))
+	oa.tools = []tool{
+		{
+			name: "wipe",
+			definition: func() (openai.FunctionDefinitionParam, error) {
+				return openai.FunctionDefinitionParam{Name: "wipe"}, nil
+			},
+			handle: func(ctx context.Context, oa *OpenAIFnCaller, fnReq FnCallOutputRequest, arguments string, food centralproto.CentralFoodServiceServer) (FnCallOutputResponse, error) {
+				ran++
+				return FnCallOutputResponse{Success: true, Message: "wiped " + arguments}, nil
+			},
+			confirm: true,
+			summarise: func(arguments string) string {
+				return "wipe " + arguments
+			},
+		},
+	}
+
+	out, err := oa.EnactUserInput(context.Background(), CreateGenericFnCallOutputRequest("wipe it", "user", time.UTC), nil)
+	if err != nil {
+		t.Fatalf("got err %v", err)
+	}
+
+	if ran != 0 {
+		t.Fatal("flagged tool ran before confirmation")
+	}
+	if len(out.PendingActions) != 1 || out.PendingActions[0].Summary != `wipe {"what":"everything"}` {
+		t.Fatalf("got pending actions %+v", out.PendingActions)
+	}
+
+	if _, err := oa.ConfirmAction(context.Background(), "intruder", out.PendingActions[0].Id, nil); !errors.Is(err, errs.ErrNotFound) {
+		t.Errorf("got err %v but want not found for other users", err)
+	}
+
+	confirmed, err := oa.ConfirmAction(context.Background(), "user", out.PendingActions[0].Id, nil)
+	if err != nil {
+		t.Fatalf("got err %v", err)
+	}
+	if ran != 1 || confirmed.Message != `Done, I went ahead and did as you confirmed: wipe {"what":"everything"}.` {
+		t.Errorf("got ran %d and message %q", ran, confirmed.Message)
+	}
+
+	if _, err := oa.CancelAction(context.Background(), "user", out.PendingActions[0].Id); !errors.Is(err, errs.ErrNotFound) {
+		t.Errorf("got err %v but want not found once confirmed", err)
+	}
+
+	if _, err := oa.CancelAction(context.Background(), "user", uuid.New()); !errors.Is(err, errs.ErrNotFound) {
+		t.Errorf("got err %v but want not found for unknown actions", err)
+	}
+
+	// The tool's message is for the model, so failures are put to the user plainly
+	oa.tools[0].handle = func(ctx context.Context, oa *OpenAIFnCaller, fnReq FnCallOutputRequest, arguments string, food centralproto.CentralFoodServiceServer) (FnCallOutputResponse, error) {
+		return FnCallOutputResponse{Success: false, Message: "nothing to wipe, ask the user what they meant"}, nil
+	}
+	action, err := oa.pending.Add("user", time.UTC, "wipe", "{}", "wipe it all")
+	if err != nil {
+		t.Fatalf("got err %v", err)
+	}
+	failed, err := oa.ConfirmAction(context.Background(), "user", action.Id, nil)
+	if err != nil {
+		t.Fatalf("got err %v", err)
+	}
+	if failed.Success || !strings.HasPrefix(failed.Message, "Sorry, I couldn't wipe it all.") {
+		t.Errorf("got success %v and message %q", failed.Success, failed.Message)
+	}
+}
@@ -0,0 +1,175 @@
+package fncall
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/calamity-m/reaphur/central/internal/prompts"
+	"github.com/calamity-m/reaphur/pkg/serr"
+	centralproto "github.com/calamity-m/reaphur/proto/v1/central"
+	"github.com/openai/openai-go"
+)
+
+// Handles a single tool call. Arguments are the raw JSON arguments provided by the model.
+type toolHandler func(ctx context.Context, oa *OpenAIFnCaller, fnReq FnCallOutputRequest, arguments string, food centralproto.CentralFoodServiceServer) (FnCallOutputResponse, error)
+
+type tool struct {
+	name       string
+	definition func() (openai.FunctionDefinitionParam, error)
+	handle     toolHandler
+	// Journal domain the tool belongs to, only offered while it's enabled
+	domain string
+
+	// Tools that change or destroy existing data must be confirmed by the user
+	// before they run. Instead of executing, a pending action is created.
+	confirm bool
+	// Human readable summary of what the tool call will do, shown to the user when
+	// asking them to confirm.
+	summarise func(arguments string) string
+	// Overrides the fn caller's tool timeout when set
+	timeout time.Duration
+}
+
+// Wraps a typed handler so that it decodes its own arguments
+func typedHandler[T any](handle func(oa *OpenAIFnCaller, ctx context.Context, fnReq FnCallOutputRequest, args T, food centralproto.CentralFoodServiceServer) FnCallOutputResponse) toolHandler {
+	return func(ctx context.Context, oa *OpenAIFnCaller, fnReq FnCallOutputRequest, arguments string, food centralproto.CentralFoodServiceServer) (FnCallOutputResponse, error) {
+		args, err := serr.DecodeJSONS[T](arguments)
+		if err != nil {
+			oa.logger.ErrorContext(ctx, "failed to decode arguments from tool call", slog.String("arguments", arguments))
+			return FnCallOutputResponse{}, err
+		}
+
+		return handle(oa, ctx, fnReq, args, food), nil
+	}
+}
+
+// Responds with a fixed message, for tools we advertise but haven't built yet
+func notYetHandler(message string) toolHandler {
+	return func(ctx context.Context, oa *OpenAIFnCaller, fnReq FnCallOutputRequest, arguments string, food centralproto.CentralFoodServiceServer) (FnCallOutputResponse, error) {
+		return FnCallOutputResponse{Success: false, Message: message}, nil
+	}
+}
+
+// Domains tools belong to, matching the prompt's domains
+const (
+	foodDomain          = "food"
+	cardioDomain        = "cardio"
+	weightLiftingDomain = "weightlifting"
+)
+
+// Every tool the model may call, in the order they are advertised
+var registry = []tool{
+	{
+		name:       createFoodName,
+		domain:     foodDomain,
+		definition: CreateFoodParam,
+		handle:     typedHandler[prompts.FnCreateFoodParameters]((*OpenAIFnCaller).handleCreateFood),
+	},
+	{
+		name:       getFoodName,
+		domain:     foodDomain,
+		definition: GetFoodParam,
+		handle:     typedHandler[prompts.FnGetFoodParameters]((*OpenAIFnCaller).handleGetFood),
+	},
+	{
+		name:       updateFoodName,
+		domain:     foodDomain,
+		definition: UpdateFoodParam,
+		handle:     typedHandler[prompts.FnUpdateFoodParameters]((*OpenAIFnCaller).handleUpdateFood),
+		confirm:    true,
+		summarise:  summariseUpdateFood,
+	},
+	{
+		name:       deleteFoodName,
+		domain:     foodDomain,
+		definition: DeleteFoodParam,
+		handle:     typedHandler[prompts.FnDeleteFoodParameters]((*OpenAIFnCaller).handleDeleteFood),
+		confirm:    true,
+		summarise:  summariseDeleteFood,
+	},
+	{
+		name:       undoLastActionName,
+		domain:     foodDomain,
+		definition: UndoLastActionParam,
+		handle:     typedHandler[prompts.FnUndoLastActionParameters]((*OpenAIFnCaller).handleUndoLastAction),
+		confirm:    true,
+		summarise: func(arguments string) string {
+			return "undo your last change to the food diary"
+		},
+	},
+	{
+		name:       createWeightLiftingName,
+		domain:     weightLiftingDomain,
+		definition: CreateWeightLiftingParam,
+		handle:     notYetHandler("weight lifting not yet completed, sorry"),
+	},
+	{
+		name:       createCardioName,
+		domain:     cardioDomain,
+		definition: CreateCardioParam,
+		handle:     notYetHandler("cardio logging not yet completed, sorry"),
+	},
+}
+
+// Tools belonging to the given domains, or the prompt's default domains when
+// none are given
+func toolsForDomains(tools []tool, domains []string) []tool {
+	if len(domains) == 0 {
+		domains = prompts.DefaultDomains
+	}
+
+	enabled := make([]tool, 0, len(tools))
+	for _, t := range tools {
+		if slices.Contains(domains, t.domain) {
+			enabled = append(enabled, t)
+		}
+	}
+
+	return enabled
+}
+
+func lookupTool(tools []tool, name string) (tool, bool) {
+	for _, t := range tools {
+		if t.name == name {
+			return t, true
+		}
+	}
+
+	return tool{}, false
+}
+
+// Runs a single tool call, or parks it as a pending action if the tool needs to
+// be confirmed first.
+func (oa *OpenAIFnCaller) callTool(ctx context.Context, fnReq FnCallOutputRequest, name string, arguments string, food centralproto.CentralFoodServiceServer) (FnCallOutputResponse, error) {
+	t, ok := lookupTool(oa.tools, name)
+	if !ok {
+		// UNMATCHED FUNCTIONS
+		return FnCallOutputResponse{Success: false, Message: "unmatched"}, nil
+	}
+
+	if t.confirm {
+		summary := fmt.Sprintf("run %s", t.name)
+		if t.summarise != nil {
+			summary = t.summarise(arguments)
+		}
+
+		action, err := oa.pending.Add(fnReq.UserId, fnReq.Location, t.name, arguments, summary)
+		if err != nil {
+			return FnCallOutputResponse{}, err
+		}
+
+		oa.logger.InfoContext(ctx, "created pending action", slog.Any("action", action))
+
+		return FnCallOutputResponse{
+			Success:         true,
+			Message:         fmt.Sprintf("this action has not run yet, the user must confirm it first. tell the user it is awaiting their confirmation: %s", summary),
+			PendingActionId: action.Id.String(),
+			PendingActions:  []PendingAction{action},
+		}, nil
+	}
+
+	return t.handle(ctx, oa, fnReq, arguments, food)
+}
@@ -0,0 +1,85 @@
+package fncall
+
+import (
+	"context"
+
+	"github.com/openai/openai-go"
+)
+
+type EventKind int
+
+const (
+	// A tool call is about to run
+	EventToolStarted EventKind = iota
+	// A tool call finished, Result holds its output
+	EventToolFinished
+	// A piece of the final response text
+	EventTextDelta
+)
+
+// Progress emitted while enacting user input
+type Event struct {
+	Kind   EventKind
+	CallId string
+	Tool   string
+	Result FnCallOutputResponse
+	Delta  string
+}
+
+// Receives events as they happen. Returning an error aborts the request, i.e.
+// when the client has gone away.
+type EmitFunc func(Event) error
+
+func (emit EmitFunc) send(e Event) error {
+	if emit == nil {
+		return nil
+	}
+
+	return emit(e)
+}
+
+// Runs the completion, streaming content deltas to emit as they arrive. The
+// accumulated completion is returned the same as a regular completion would be.
+func (oa *OpenAIFnCaller) streamCompletion(ctx context.Context, params openai.ChatCompletionNewParams, emit EmitFunc) (completion *openai.ChatCompletion, err error) {
+	ctx, done := instrumentCompletion(ctx, params.Model)
+	defer func() {
+		done(completion, err)
+	}()
+
+	params.StreamOptions = openai.ChatCompletionStreamOptionsParam{IncludeUsage: openai.Bool(true)}
+
+	stream := oa.client.Chat.Completions.NewStreaming(ctx, params)
+	defer stream.Close()
+
+	acc := openai.ChatCompletionAccumulator{}
+	usage := openai.CompletionUsage{}
+
+	for stream.Next() {
+		chunk := stream.Current()
+		acc.AddChunk(chunk)
+
+		// The accumulator doesn't keep usage, which arrives on the final chunk
+		if chunk.Usage.TotalTokens > 0 {
+			usage = chunk.Usage
+		}
+
+		for _, choice := range chunk.Choices {
+			if choice.Delta.Content == "" {
+				continue
+			}
+
+			if err := emit.send(Event{Kind: EventTextDelta, Delta: choice.Delta.Content}); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := stream.Err(); err != nil {
+		return nil, err
+	}
+
+	accumulated := acc.ChatCompletion
+	accumulated.Usage = usage
+
+	return &accumulated, nil
+}
@@ -0,0 +1,38 @@
+package fncall
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/calamity-m/reaphur/pkg/errs"
+	"github.com/calamity-m/reaphur/pkg/resilience"
+	"github.com/openai/openai-go"
+)
+
+const (
+	// Used when tools ran but the llm went away before it could respond
+	toolsDoneUnavailableMessage = "Done, though the reaper has lost their voice for the moment and can't say much more than that."
+)
+
+// Marks errors that mean the llm can't be reached right now, after any retries,
+// as errs.ErrUnavailable so callers can degrade gracefully. Once ctx is done the
+// caller has given up or run out of time, which says nothing about the llm.
+func llmError(ctx context.Context, err error) error {
+	if err == nil || ctx.Err() != nil {
+		return err
+	}
+
+	var apiErr *openai.Error
+	var netErr net.Error
+	switch {
+	case errors.Is(err, resilience.ErrCircuitOpen),
+		errors.Is(err, context.DeadlineExceeded),
+		errors.As(err, &apiErr) && resilience.Retryable(apiErr.StatusCode),
+		errors.As(err, &netErr):
+		return fmt.Errorf("%w: %w", errs.ErrUnavailable, err)
+	default:
+		return err
+	}
+}
@@ -0,0 +1,44 @@
+package fncall
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/calamity-m/reaphur/pkg/errs"
+	"github.com/calamity-m/reaphur/pkg/resilience"
+)
+
+func TestLLMError(t *testing.T) {
+	expired, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name        string
+		ctx         context.Context
+		err         error
+		unavailable bool
+	}{
+		{"open circuit", context.Background(), resilience.ErrCircuitOpen, true},
+		{"llm timed out", context.Background(), fmt.Errorf("attempt: %w", context.DeadlineExceeded), true},
+		{"caller timed out", expired, fmt.Errorf("attempt: %w", context.DeadlineExceeded), false},
+		{"other failure", context.Background(), errors.New("bad request"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := llmError(tt.ctx, tt.err)
+			if errors.Is(got, errs.ErrUnavailable) != tt.unavailable {
+				t.Errorf("got %v but want unavailable %t", got, tt.unavailable)
+			}
+			if !errors.Is(got, tt.err) {
+				t.Errorf("got %v but want it to wrap %v", got, tt.err)
+			}
+		})
+	}
+
+	if llmError(context.Background(), nil) != nil {
+		t.Error("expected no error for nil")
+	}
+}
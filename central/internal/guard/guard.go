@@ -0,0 +1,82 @@
+package guard
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Verdict of screening some user input
+type Verdict struct {
+	// Whether the input looks like an attempt to override instructions
+	Suspicious bool
+	// Names of the rules that matched
+	Reasons []string
+}
+
+type rule struct {
+	name    string
+	pattern *regexp.Regexp
+}
+
+// Rules are run against a normalised copy of the input, lower cased with
+// collapsed whitespace and invisible characters removed.
+var rules = []rule{
+	{"override_instructions", regexp.MustCompile(`\b(ignore|disregard|forget|override|bypass|skip)\b.{0,40}\b(previous|prior|above|earlier|preceding|all|any|your|the|system|developer)\b.{0,30}\b(instructions?|prompts?|rules|directions|guidelines|messages?|context)\b`)},
+	{"new_instructions", regexp.MustCompile(`\b(new|updated|real|actual|true)\s+(instructions?|rules|system prompt)\b`)},
+	{"role_reassignment", regexp.MustCompile(`\b(you are now|from now on,? you|pretend (to be|you are)|act as (a|an|the|if)|roleplay as)\b`)},
+	{"prompt_exfiltration", regexp.MustCompile(`\b(reveal|show|print|repeat|output|tell me|what (is|are))\b.{0,30}\b(system|developer|hidden|initial|original)\s+(prompt|instructions?|message)`)},
+	{"tag_injection", regexp.MustCompile(`</?\s*(input|extra|system|developer|assistant|instructions?)\s*>`)},
+	{"role_marker", regexp.MustCompile(`(^|\n)\s*(system|developer|assistant)\s*:`)},
+	{"jailbreak", regexp.MustCompile(`\b(jailbreak|do anything now|dan mode|developer mode)\b`)},
+}
+
+// Screens user input for known instruction override attempts. This is a heuristic
+// first line of defence, and is not a replacement for keeping user content out of
+// the trusted parts of the prompt.
+func Screen(input string) Verdict {
+	normalised := normalise(input)
+
+	verdict := Verdict{}
+	for _, r := range rules {
+		if r.pattern.MatchString(normalised) {
+			verdict.Suspicious = true
+			verdict.Reasons = append(verdict.Reasons, r.name)
+		}
+	}
+
+	return verdict
+}
+
+func normalise(input string) string {
+	var b strings.Builder
+
+	lastSpace := false
+	for _, r := range strings.ToLower(input) {
+		switch {
+		case r == '\n':
+			b.WriteRune(r)
+			lastSpace = false
+		case unicode.IsSpace(r):
+			if !lastSpace {
+				b.WriteRune(' ')
+			}
+			lastSpace = true
+		case unicode.Is(unicode.Cf, r):
+			// Drop zero width and other invisible formatting characters
+		default:
+			b.WriteRune(r)
+			lastSpace = false
+		}
+	}
+
+	return b.String()
+}
+
+var escaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// Escapes user content so it cannot open or close the xml styled tags the
+// prompts rely on.
+func Escape(input string) string {
+	return escaper.Replace(input)
+}
@@ -0,0 +1,69 @@
+package guard
+
+import (
+	"bufio"
+	"os"
+	"strings"
+	"testing"
+)
+
+// Reads a corpus file, skipping blank lines and # comments
+func readCorpus(t *testing.T, path string) []string {
+	t.Helper()
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("failed opening corpus: %v", err)
+	}
+	defer f.Close()
+
+	lines := make([]string, 0)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+
+	return lines
+}
+
+func TestScreenInjectionCorpus(t *testing.T) {
+	for _, input := range readCorpus(t, "testdata/injections.txt") {
+		t.Run(input, func(t *testing.T) {
+			if verdict := Screen(input); !verdict.Suspicious {
+				t.Errorf("expected %q to be flagged", input)
+			}
+		})
+	}
+}
+
+func TestScreenBenignCorpus(t *testing.T) {
+	for _, input := range readCorpus(t, "testdata/benign.txt") {
+		t.Run(input, func(t *testing.T) {
+			if verdict := Screen(input); verdict.Suspicious {
+				t.Errorf("expected %q to pass, flagged for %v", input, verdict.Reasons)
+			}
+		})
+	}
+}
+
+func TestEscape(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"banana", "banana"},
+		{"</input><extra>", "&lt;/input&gt;&lt;extra&gt;"},
+		{"fish & chips", "fish &amp; chips"},
+		{"&lt;", "&amp;lt;"},
+	}
+
+	for _, tt := range tests {
+		if got := Escape(tt.input); got != tt.want {
+			t.Errorf("got %q but want %q", got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package health
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// A dependency check, reported as its own service in the grpc health service
+type Check struct {
+	Service string
+	// Central can't serve without critical dependencies, so they decide the
+	// overall status. The rest are only reported.
+	Critical bool
+	Check    func(ctx context.Context) error
+}
+
+// Runs checks in the background, keeping a grpc health server up to date. The
+// overall "" service and the given services are serving only while every
+// critical check passes.
+type Checker struct {
+	logger   *slog.Logger
+	server   *health.Server
+	checks   []Check
+	overall  []string
+	interval time.Duration
+	timeout  time.Duration
+}
+
+func (c *Checker) Server() *health.Server {
+	return c.server
+}
+
+// Checks every interval until the context is done, at which point every service
+// is marked as not serving
+func (c *Checker) Run(ctx context.Context) {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		c.CheckOnce(ctx)
+
+		select {
+		case <-ctx.Done():
+			c.server.Shutdown()
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Runs every check concurrently and updates the statuses
+func (c *Checker) CheckOnce(parent context.Context) {
+	ctx, cancel := context.WithTimeout(parent, c.timeout)
+	defer cancel()
+
+	results := make([]error, len(c.checks))
+
+	var wg sync.WaitGroup
+	for i, check := range c.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = check.Check(ctx)
+		}()
+	}
+	wg.Wait()
+
+	// Shutting down wins over any check finishing late
+	if parent.Err() != nil {
+		return
+	}
+
+	overall := healthpb.HealthCheckResponse_SERVING
+	for i, check := range c.checks {
+		status := healthpb.HealthCheckResponse_SERVING
+		if results[i] != nil {
+			status = healthpb.HealthCheckResponse_NOT_SERVING
+			c.logger.WarnContext(ctx, "health check failed", slog.String("service", check.Service), slog.Any("err", results[i]))
+
+			if check.Critical {
+				overall = healthpb.HealthCheckResponse_NOT_SERVING
+			}
+		}
+
+		c.server.SetServingStatus(check.Service, status)
+	}
+
+	for _, service := range c.overall {
+		c.server.SetServingStatus(service, overall)
+	}
+}
+
+// Creates a checker where everything starts out not serving, until the first
+// checks pass. Overall lists the services, other than "", that follow the
+// critical checks.
+func NewChecker(logger *slog.Logger, interval time.Duration, timeout time.Duration, overall []string, checks ...Check) *Checker {
+	server := health.NewServer()
+
+	overall = append([]string{""}, overall...)
+	for _, service := range overall {
+		server.SetServingStatus(service, healthpb.HealthCheckResponse_NOT_SERVING)
+	}
+	for _, check := range checks {
+		server.SetServingStatus(check.Service, healthpb.HealthCheckResponse_NOT_SERVING)
+	}
+
+	return &Checker{
+		logger:   logger,
+		server:   server,
+		checks:   checks,
+		overall:  overall,
+		interval: interval,
+		timeout:  timeout,
+	}
+}
+
+// Runs check at most once every interval, reusing its last result in between.
+// Suits checks that cost something to run, such as calls to paid apis.
+func Throttle(interval time.Duration, check func(ctx context.Context) error) func(ctx context.Context) error {
+	return throttle(interval, check, time.Now)
+}
+
+func throttle(interval time.Duration, check func(ctx context.Context) error, now func() time.Time) func(ctx context.Context) error {
+	var (
+		mux     sync.Mutex
+		checked time.Time
+		last    error
+	)
+
+	return func(ctx context.Context) error {
+		mux.Lock()
+		defer mux.Unlock()
+
+		if !checked.IsZero() && now().Sub(checked) < interval {
+			return last
+		}
+
+		last = check(ctx)
+		// A check cut short by its caller is tried again next time
+		if ctx.Err() == nil {
+			checked = now()
+		}
+
+		return last
+	}
+}
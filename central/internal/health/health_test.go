@@ -0,0 +1,128 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func status(t *testing.T, c *Checker, service string) healthpb.HealthCheckResponse_ServingStatus {
+	t.Helper()
+
+	resp, err := c.Server().Check(context.Background(), &healthpb.HealthCheckRequest{Service: service})
+	if err != nil {
+		t.Fatalf("got err %v checking %q", err, service)
+	}
+
+	return resp.GetStatus()
+}
+
+func TestChecker(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	var redisDown, llmDown atomic.Bool
+	check := func(down *atomic.Bool) func(ctx context.Context) error {
+		return func(ctx context.Context) error {
+			if down.Load() {
+				return errors.New("down")
+			}
+			return nil
+		}
+	}
+
+	c := NewChecker(logger, time.Hour, time.Second, []string{"centralproto.v1.CentralService"},
+		Check{Service: "redis", Critical: true, Check: check(&redisDown)},
+		Check{Service: "llm", Check: check(&llmDown)},
+	)
+
+	if got := status(t, c, ""); got != healthpb.HealthCheckResponse_NOT_SERVING {
+		t.Errorf("got %v but want not serving before the first checks", got)
+	}
+
+	c.CheckOnce(context.Background())
+	for _, service := range []string{"", "centralproto.v1.CentralService", "redis", "llm"} {
+		if got := status(t, c, service); got != healthpb.HealthCheckResponse_SERVING {
+			t.Errorf("got %v for %q but want serving", got, service)
+		}
+	}
+
+	// The llm is only reported on
+	llmDown.Store(true)
+	c.CheckOnce(context.Background())
+	if got := status(t, c, "llm"); got != healthpb.HealthCheckResponse_NOT_SERVING {
+		t.Errorf("got %v for the llm but want not serving", got)
+	}
+	if got := status(t, c, ""); got != healthpb.HealthCheckResponse_SERVING {
+		t.Errorf("got %v overall but want serving without the llm", got)
+	}
+
+	// While redis takes everything down with it
+	redisDown.Store(true)
+	c.CheckOnce(context.Background())
+	for _, service := range []string{"", "centralproto.v1.CentralService", "redis"} {
+		if got := status(t, c, service); got != healthpb.HealthCheckResponse_NOT_SERVING {
+			t.Errorf("got %v for %q but want not serving", got, service)
+		}
+	}
+
+	// Stopping marks everything as not serving, even if the checks pass
+	redisDown.Store(false)
+	llmDown.Store(false)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		c.Run(ctx)
+		close(done)
+	}()
+	cancel()
+	<-done
+
+	if got := status(t, c, ""); got != healthpb.HealthCheckResponse_NOT_SERVING {
+		t.Errorf("got %v but want not serving once stopped", got)
+	}
+}
+
+func TestThrottle(t *testing.T) {
+	now := time.Date(2025, 3, 2, 12, 0, 0, 0, time.UTC)
+	calls := 0
+	fail := false
+	check := throttle(time.Minute, func(ctx context.Context) error {
+		calls++
+		if fail {
+			return errors.New("down")
+		}
+		return nil
+	}, func() time.Time { return now })
+
+	if err := check(context.Background()); err != nil || calls != 1 {
+		t.Fatalf("got err %v after %d calls", err, calls)
+	}
+
+	// Within the interval the last result stands
+	fail = true
+	now = now.Add(30 * time.Second)
+	if err := check(context.Background()); err != nil || calls != 1 {
+		t.Errorf("got err %v after %d calls but want the cached result", err, calls)
+	}
+
+	now = now.Add(time.Minute)
+	if err := check(context.Background()); err == nil || calls != 2 {
+		t.Errorf("got err %v after %d calls but want a fresh failure", err, calls)
+	}
+
+	// A cancelled check doesn't count as checked
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	now = now.Add(time.Minute)
+	check(ctx)
+	check(context.Background())
+	if calls != 4 {
+		t.Errorf("got %d calls but want the cancelled check retried", calls)
+	}
+}
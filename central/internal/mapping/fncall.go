@@ -0,0 +1,49 @@
+package mapping
+
+import (
+	"github.com/calamity-m/reaphur/central/internal/fncall"
+	centralproto "github.com/calamity-m/reaphur/proto/v1/central"
+)
+
+func MapPendingActionToCentralProtoPendingAction(action fncall.PendingAction) *centralproto.PendingAction {
+	return &centralproto.PendingAction{
+		ActionId: action.Id.String(),
+		Summary:  action.Summary,
+	}
+}
+
+func MapFnCallEventToCentralProtoStreamEvent(e fncall.Event) *centralproto.CallFnUserInputStreamEvent {
+	switch e.Kind {
+	case fncall.EventToolStarted:
+		return &centralproto.CallFnUserInputStreamEvent{
+			Event: &centralproto.CallFnUserInputStreamEvent_ToolStarted_{
+				ToolStarted: &centralproto.CallFnUserInputStreamEvent_ToolStarted{
+					CallId:   e.CallId,
+					ToolName: e.Tool,
+				},
+			},
+		}
+	case fncall.EventToolFinished:
+		finished := &centralproto.CallFnUserInputStreamEvent_ToolFinished{
+			CallId:   e.CallId,
+			ToolName: e.Tool,
+			Success:  e.Result.Success,
+			Message:  e.Result.Message,
+			Data:     MapFnCallDataToCentralProtoGenericData(e.Result.Data),
+		}
+
+		if len(e.Result.PendingActions) > 0 {
+			finished.PendingAction = MapPendingActionToCentralProtoPendingAction(e.Result.PendingActions[0])
+		}
+
+		return &centralproto.CallFnUserInputStreamEvent{
+			Event: &centralproto.CallFnUserInputStreamEvent_ToolFinished_{ToolFinished: finished},
+		}
+	default:
+		return &centralproto.CallFnUserInputStreamEvent{
+			Event: &centralproto.CallFnUserInputStreamEvent_TextDelta_{
+				TextDelta: &centralproto.CallFnUserInputStreamEvent_TextDelta{Text: e.Delta},
+			},
+		}
+	}
+}
@@ -12,12 +12,12 @@ import (
 
 func MapCentralProtoFoodFilterToPersistenceFoodFilter(f *centralproto.GetFoodFilter, userId string) (persistence.FoodFilter, error) {
 	if f == nil {
-		return persistence.FoodFilter{}, errs.ErrBadUserId
+		return persistence.FoodFilter{}, errs.Field(errs.ErrBadRequest, "filter", "is required")
 	}
 
 	uuidUser, err := uuid.Parse(userId)
 	if err != nil {
-		return persistence.FoodFilter{}, errs.ErrBadUserId
+		return persistence.FoodFilter{}, errs.Field(errs.ErrBadUserId, "request_user_id", "must be a uuid")
 	}
 
 	return persistence.FoodFilter{
@@ -27,6 +27,7 @@ func MapCentralProtoFoodFilterToPersistenceFoodFilter(f *centralproto.GetFoodFil
 		Description: f.GetDescription(),
 		BeforeTime:  util.ParseProtoTimestamp(f.GetBeforeTime()),
 		AfterTime:   util.ParseProtoTimestamp(f.GetAfterTime()),
+		Latest:      f.GetLatest(),
 	}, nil
 }
 
@@ -50,11 +51,11 @@ func MapPersistenceFoodRecordEntryToDomainFoodRecord(entry persistence.FoodRecor
 
 func MapDomainFoodRecordToPersistenceFoodRecordEntry(record *domain.FoodRecord) (persistence.FoodRecordEntry, error) {
 	if record == nil {
-		return persistence.FoodRecordEntry{}, errs.ErrNilNotAllowed
+		return persistence.FoodRecordEntry{}, errs.Field(errs.ErrNilNotAllowed, "record", "is required")
 	}
 
 	if _, err := uuid.Parse(record.GetUserId()); err != nil {
-		return persistence.FoodRecordEntry{}, errs.ErrBadUserId
+		return persistence.FoodRecordEntry{}, errs.Field(errs.ErrBadUserId, "record.user_id", "must be a uuid")
 	}
 
 	entry := persistence.FoodRecordEntry{
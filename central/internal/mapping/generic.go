@@ -0,0 +1,91 @@
+package mapping
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	centralproto "github.com/calamity-m/reaphur/proto/v1/central"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	// Key holding the full proto name of the record, i.e. "domain.v1.FoodRecord",
+	// so receivers know which other keys to expect
+	GenericDataTypeKey = "type"
+)
+
+// Flattens tool result data into generic key/value data. Records are keyed on
+// their proto field names, and the record id becomes the unique id. Anything that
+// isn't a proto message is skipped, as we can't know how to describe it.
+func MapFnCallDataToCentralProtoGenericData(data []interface{}) []*centralproto.GenericData {
+	generic := make([]*centralproto.GenericData, 0, len(data))
+
+	for _, d := range data {
+		msg, ok := d.(proto.Message)
+		if !ok || msg == nil {
+			continue
+		}
+
+		generic = append(generic, MapProtoMessageToCentralProtoGenericData(msg))
+	}
+
+	return generic
+}
+
+func MapProtoMessageToCentralProtoGenericData(msg proto.Message) *centralproto.GenericData {
+	reflected := msg.ProtoReflect()
+
+	generic := &centralproto.GenericData{
+		DataValues: []*centralproto.GenericDataValue{
+			{Key: GenericDataTypeKey, Value: string(reflected.Descriptor().FullName())},
+		},
+	}
+
+	reflected.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		key := string(fd.Name())
+		value := genericValueString(fd, v)
+
+		if key == "id" {
+			generic.DataUniqueId = value
+		}
+
+		generic.DataValues = append(generic.DataValues, &centralproto.GenericDataValue{Key: key, Value: value})
+		return true
+	})
+
+	return generic
+}
+
+func genericValueString(fd protoreflect.FieldDescriptor, v protoreflect.Value) string {
+	if fd.IsList() || fd.IsMap() {
+		return fmt.Sprint(v.Interface())
+	}
+
+	switch fd.Kind() {
+	case protoreflect.FloatKind:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 32)
+	case protoreflect.DoubleKind:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64)
+	case protoreflect.EnumKind:
+		if ev := fd.Enum().Values().ByNumber(v.Enum()); ev != nil {
+			return string(ev.Name())
+		}
+		return strconv.Itoa(int(v.Enum()))
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		if ts, ok := v.Message().Interface().(*timestamppb.Timestamp); ok {
+			return ts.AsTime().Format(time.RFC3339)
+		}
+
+		encoded, err := protojson.Marshal(v.Message().Interface())
+		if err != nil {
+			return ""
+		}
+		return string(encoded)
+	default:
+		return v.String()
+	}
+}
@@ -0,0 +1,54 @@
+package mapping
+
+import (
+	"testing"
+
+	"github.com/calamity-m/reaphur/proto/v1/domain"
+)
+
+func TestMapFnCallDataToCentralProtoGenericData(t *testing.T) {
+	record := &domain.FoodRecord{
+		Id:       "a3c8b1e2-0000-0000-0000-000000000000",
+		UserId:   "b3c8b1e2-0000-0000-0000-000000000000",
+		Name:     "banana",
+		Kj:       376.56,
+		Calories: 90,
+		Time:     fakeTimestamp(),
+	}
+
+	got := MapFnCallDataToCentralProtoGenericData([]interface{}{record, "not a record", nil})
+
+	if len(got) != 1 {
+		t.Fatalf("got %d generic data but want 1", len(got))
+	}
+
+	if got[0].DataUniqueId != record.Id {
+		t.Errorf("got unique id %q but want %q", got[0].DataUniqueId, record.Id)
+	}
+
+	values := make(map[string]string)
+	for _, v := range got[0].DataValues {
+		values[v.Key] = v.Value
+	}
+
+	want := map[string]string{
+		GenericDataTypeKey: "domain.v1.FoodRecord",
+		"id":               record.Id,
+		"user_id":          record.UserId,
+		"name":             "banana",
+		"kj":               "376.56",
+		"calories":         "90",
+		"time":             "2014-02-04T18:05:00Z",
+	}
+
+	for key, value := range want {
+		if values[key] != value {
+			t.Errorf("got %q for %s but want %q", values[key], key, value)
+		}
+	}
+
+	// Unset fields are left out
+	if _, ok := values["description"]; ok {
+		t.Error("got description but it was never set")
+	}
+}
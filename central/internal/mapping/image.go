@@ -0,0 +1,71 @@
+package mapping
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/calamity-m/reaphur/central/internal/fncall"
+	"github.com/calamity-m/reaphur/pkg/errs"
+	centralproto "github.com/calamity-m/reaphur/proto/v1/central"
+)
+
+const (
+	// Most images accepted alongside a single input
+	MaxImageAttachments = 4
+	// Largest raw image accepted, anything bigger should be sent by url
+	MaxImageAttachmentBytes = 5 << 20
+)
+
+// Image types the vision model accepts
+var imageMimeTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+// Validates and maps image attachments, any violation is a bad request
+func MapCentralProtoImageAttachmentsToFnCallImages(attachments []*centralproto.ImageAttachment) ([]fncall.Image, error) {
+	if len(attachments) > MaxImageAttachments {
+		return nil, fmt.Errorf("at most %d images can be attached - %w", MaxImageAttachments, errs.ErrBadRequest)
+	}
+
+	images := make([]fncall.Image, 0, len(attachments))
+	for i, attachment := range attachments {
+		image, err := mapImageAttachment(attachment)
+		if err != nil {
+			return nil, fmt.Errorf("image %d: %w", i, err)
+		}
+
+		images = append(images, image)
+	}
+
+	return images, nil
+}
+
+func mapImageAttachment(attachment *centralproto.ImageAttachment) (fncall.Image, error) {
+	if !imageMimeTypes[attachment.GetMimeType()] {
+		return fncall.Image{}, fmt.Errorf("unsupported mime type %q - %w", attachment.GetMimeType(), errs.ErrBadRequest)
+	}
+
+	switch source := attachment.GetSource().(type) {
+	case *centralproto.ImageAttachment_Data:
+		if len(source.Data) == 0 {
+			return fncall.Image{}, fmt.Errorf("image data is empty - %w", errs.ErrBadRequest)
+		}
+		if len(source.Data) > MaxImageAttachmentBytes {
+			return fncall.Image{}, fmt.Errorf("image is larger than %d bytes - %w", MaxImageAttachmentBytes, errs.ErrBadRequest)
+		}
+
+		return fncall.Image{Data: source.Data, MimeType: attachment.GetMimeType()}, nil
+	case *centralproto.ImageAttachment_Url:
+		u, err := url.Parse(source.Url)
+		if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			return fncall.Image{}, fmt.Errorf("image url must be an absolute http(s) url - %w", errs.ErrBadRequest)
+		}
+
+		return fncall.Image{URL: source.Url, MimeType: attachment.GetMimeType()}, nil
+	default:
+		return fncall.Image{}, fmt.Errorf("image has neither data nor a url - %w", errs.ErrBadRequest)
+	}
+}
@@ -0,0 +1,31 @@
+package mapping
+
+import (
+	"github.com/calamity-m/reaphur/central/internal/persistence"
+	"github.com/calamity-m/reaphur/central/internal/util"
+	"github.com/calamity-m/reaphur/pkg/errs"
+	centralproto "github.com/calamity-m/reaphur/proto/v1/central"
+	"github.com/google/uuid"
+)
+
+func MapCentralProtoUsageFilterToPersistenceUsageFilter(f *centralproto.GetUsageFilter) (persistence.UsageFilter, error) {
+	if f == nil {
+		return persistence.UsageFilter{}, nil
+	}
+
+	filter := persistence.UsageFilter{
+		Model:      f.GetModel(),
+		BeforeTime: util.ParseProtoTimestamp(f.GetBeforeTime()),
+		AfterTime:  util.ParseProtoTimestamp(f.GetAfterTime()),
+	}
+
+	if f.UserId != nil {
+		user, err := uuid.Parse(f.GetUserId())
+		if err != nil {
+			return persistence.UsageFilter{}, errs.Field(errs.ErrBadUserId, "filter.user_id", "must be a uuid")
+		}
+		filter.UserId = user
+	}
+
+	return filter, nil
+}
@@ -0,0 +1,95 @@
+// Prometheus metrics recorded by central. Everything is registered with the
+// default registry.
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const (
+	// User input handled by the local grammar parser without calling the llm
+	ParsePathLocal = "local"
+	// User input handed to the llm
+	ParsePathLLM = "llm"
+	// User input parsed locally because the llm was unavailable
+	ParsePathFallback = "fallback"
+)
+
+var (
+	ParsePaths = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "reaphur",
+		Subsystem: "central",
+		Name:      "parse_path_total",
+		Help:      "User inputs handled by each parse path, one of local, llm or fallback.",
+	}, []string{"path"})
+)
+
+const (
+	// The tool ran and reported success
+	ToolOutcomeSuccess = "success"
+	// The tool ran but reported it couldn't do what was asked
+	ToolOutcomeFailure = "failure"
+	// The tool returned an error
+	ToolOutcomeError = "error"
+	// The tool didn't finish within its timeout
+	ToolOutcomeTimeout = "timeout"
+
+	// Tool calls for tools that don't exist are counted under this name, so
+	// made up tool names can't blow out the label set
+	UnknownTool = "unknown"
+)
+
+const (
+	OutcomeSuccess = "success"
+	OutcomeError   = "error"
+)
+
+var (
+	ToolCalls = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "reaphur",
+		Subsystem: "central",
+		Name:      "tool_calls_total",
+		Help:      "Tool calls made by the llm, by tool and outcome, one of success, failure, error or timeout.",
+	}, []string{"tool", "outcome"})
+
+	ToolDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "reaphur",
+		Subsystem: "central",
+		Name:      "tool_call_duration_seconds",
+		Help:      "Time taken running each tool call.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"tool"})
+
+	LLMDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "reaphur",
+		Subsystem: "central",
+		Name:      "llm_request_duration_seconds",
+		Help:      "Time taken by llm completions, including retries, by model and outcome.",
+		Buckets:   []float64{0.25, 0.5, 1, 2, 4, 8, 16, 32, 64},
+	}, []string{"model", "outcome"})
+
+	LLMTokens = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "reaphur",
+		Subsystem: "central",
+		Name:      "llm_tokens_total",
+		Help:      "Tokens spent on llm completions, by model and kind, either prompt or completion.",
+	}, []string{"model", "kind"})
+
+	PersistenceDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "reaphur",
+		Subsystem: "central",
+		Name:      "persistence_duration_seconds",
+		Help:      "Time taken by store operations, by backend, operation and outcome.",
+		Buckets:   []float64{0.0005, 0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1},
+	}, []string{"backend", "operation", "outcome"})
+)
+
+// Outcome label for an operation's error
+func Outcome(err error) string {
+	if err != nil {
+		return OutcomeError
+	}
+
+	return OutcomeSuccess
+}
@@ -0,0 +1,399 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/calamity-m/reaphur/central/internal/timeexpr"
+)
+
+// A single food parsed from the user's input
+type FoodItem struct {
+	Name string
+	// How much was eaten as the user put it, i.e. "2" or "200 g"
+	Quantity string
+	Energy   float32
+	// One of calorie, kilojule or none, matching the log_food tool
+	EnergyUnit string
+}
+
+// What the user ate as they said it, i.e. "2 slices of toast"
+func (f FoodItem) Phrase() string {
+	if f.Quantity == "" {
+		return f.Name
+	}
+
+	return f.Quantity + " " + f.Name
+}
+
+// Food entries parsed from the user's input without the llm
+type ParsedFood struct {
+	Items []FoodItem
+	// Time expression for when the food was eaten, "now" when not given
+	Time string
+	// How sure the parse is, from 0 to 1. Anything not clearly a simple food
+	// entry, such as a question, is 0.
+	Confidence float64
+}
+
+var (
+	tokenPattern = regexp.MustCompile(`\d{1,2}:\d{2}|\d+(?:\.\d+)?(?:/\d+)?|[a-z]+(?:'[a-z]+)?|[,+&?~:]`)
+
+	subjects = set("i", "i've", "ive", "we", "just", "also", "then")
+	verbs    = set("ate", "had", "eaten", "eat", "drank", "drink", "drunk", "consumed", "snacked", "log", "logged", "add", "having")
+	// Words after a verb that carry no meaning, i.e. "snacked on"
+	verbFillers = set("on", "some", "just")
+
+	meals = set("breakfast", "brunch", "lunch", "dinner", "supper", "snack", "dessert")
+	// Joins a meal to the food at the start of the input, i.e. "lunch was a pie"
+	mealJoins = set("was", "is", ":")
+
+	separators = set(",", "and", "+", "&", "plus")
+
+	energyUnits = map[string]string{
+		"cal": "calorie", "cals": "calorie", "calorie": "calorie", "calories": "calorie", "kcal": "calorie", "kcals": "calorie",
+		"kj": "kilojule", "kjs": "kilojule", "kilojoule": "kilojule", "kilojoules": "kilojule", "kilojule": "kilojule", "kilojules": "kilojule",
+	}
+	approximations = set("about", "around", "roughly", "approx", "approximately", "~", "like")
+
+	quantityWords = set("a", "an", "one", "two", "three", "four", "five", "six", "seven", "eight", "nine", "ten", "eleven", "twelve",
+		"half", "some", "few", "couple", "dozen")
+	measures = set("g", "gram", "grams", "kg", "ml", "l", "litre", "litres", "liter", "liters", "oz", "cup", "cups", "slice", "slices",
+		"piece", "pieces", "bowl", "bowls", "glass", "glasses", "serve", "serves", "serving", "servings", "handful", "handfuls",
+		"tbsp", "tsp", "plate", "plates", "can", "cans", "bottle", "bottles", "scoop", "scoops", "shot", "shots")
+
+	// Anything that means the input isn't simply logging food
+	questions = set("?", "how", "what", "when", "where", "why", "which", "who", "did", "do", "does", "can", "could", "should",
+		"show", "list", "tell", "total", "delete", "remove", "undo", "change", "update", "edit", "fix", "forget")
+	// Corrections to earlier entries are handled by the llm's update tools
+	corrections = set("oops", "meant", "actually", "wrong", "mistake", "typo", "correct", "correction", "should've", "instead")
+	negations   = set("not", "didn't", "didnt", "don't", "dont", "no", "never", "won't")
+	activity    = set("ran", "run", "running", "jog", "jogged", "walk", "walked", "cycled", "rode", "swam", "swim", "lifted",
+		"squat", "squats", "bench", "deadlift", "deadlifts", "sets", "reps", "km", "kms", "miles", "workout", "gym", "minutes", "hours")
+)
+
+// Words that never appear in a food's name, i.e. "that was 90 cal"
+var notFood = set("i", "i've", "ive", "we", "my", "me", "it", "it's", "that", "this", "was", "is", "were", "be", "been", "with", "without")
+
+// Names longer than this are likely a sentence we don't understand
+const maxNameWords = 4
+
+// Parses simple food entries such as "ate a banana 90 cal", "2 eggs 600kj" or
+// "for lunch I had a pie and chips". Confidence is lowered for anything the llm
+// would do better with, such as food without an energy to record.
+func ParseFood(input string) ParsedFood {
+	tokens := tokenPattern.FindAllString(strings.ToLower(input), -1)
+	parsed := ParsedFood{Time: "now"}
+
+	for _, token := range tokens {
+		if questions[token] || corrections[token] || negations[token] || activity[token] {
+			return parsed
+		}
+	}
+
+	tokens, lead, verb := stripLead(tokens)
+	if lead == nil {
+		return parsed
+	}
+	if len(lead) > 0 {
+		parsed.Time = strings.Join(lead, " ")
+	}
+
+	segments := split(tokens)
+	if len(segments) == 0 {
+		return parsed
+	}
+
+	// Only the last food can be followed by when it was eaten, i.e. "had toast
+	// and eggs for breakfast"
+	last := segments[len(segments)-1]
+	if rest, when := stripTime(last); when != "" && len(lead) == 0 {
+		segments[len(segments)-1] = rest
+		parsed.Time = when
+	}
+
+	confidence := 1.0
+	if !verb && len(lead) == 0 {
+		confidence *= 0.9
+	}
+
+	for _, segment := range segments {
+		item, ok := parseItem(segment)
+		if !ok {
+			return ParsedFood{Time: "now"}
+		}
+
+		if item.EnergyUnit == "none" {
+			confidence = min(confidence, 0.7)
+		}
+		if strings.Count(item.Name, " ")+1 > 3 {
+			confidence = min(confidence, 0.7)
+		}
+
+		parsed.Items = append(parsed.Items, item)
+	}
+
+	// Without a verb or meal the input has to at least look like a food log
+	if !verb && len(lead) == 0 && confidence < 0.9 {
+		return ParsedFood{Time: "now"}
+	}
+
+	parsed.Confidence = confidence
+
+	return parsed
+}
+
+// Strips everything before the food, returning the time the lead gave, if any,
+// and whether an eating verb was found. A nil lead means the start of the
+// input wasn't understood.
+func stripLead(tokens []string) ([]string, []string, bool) {
+	lead := []string{}
+
+	// "lunch was a pie" or "breakfast: toast"
+	if len(tokens) > 2 && meals[tokens[0]] && mealJoins[tokens[1]] {
+		return tokens[2:], mealTime(tokens[:1]), false
+	}
+
+	verbAt := -1
+	for i, token := range tokens {
+		if verbs[token] {
+			verbAt = i
+			break
+		}
+	}
+
+	if verbAt < 0 {
+		return tokens, lead, false
+	}
+
+	// Whatever comes before the subject must be when it was eaten, i.e.
+	// "yesterday I had" or "for lunch I ate"
+	start := verbAt
+	for start > 0 && subjects[tokens[start-1]] {
+		start--
+	}
+	if start > 0 {
+		lead = mealTime(tokens[:start])
+		if !resolves(lead) {
+			return nil, nil, false
+		}
+	}
+
+	rest := tokens[verbAt+1:]
+	for len(rest) > 0 && verbFillers[rest[0]] {
+		rest = rest[1:]
+	}
+
+	return rest, lead, true
+}
+
+// Drops the filler in meal phrases the time resolver doesn't know, i.e. "for
+// snack" isn't a time of day
+func mealTime(tokens []string) []string {
+	words := []string{}
+	for _, token := range tokens {
+		if token == ":" || token == "for" {
+			continue
+		}
+		words = append(words, token)
+	}
+
+	if len(words) == 1 && meals[words[0]] && !resolves(words) {
+		return []string{}
+	}
+
+	return words
+}
+
+// Splits the food on separators, i.e. "toast, eggs and coffee". An energy on its
+// own belongs to the food before it, i.e. "2 eggs, 600kj".
+func split(tokens []string) [][]string {
+	segments := [][]string{}
+	current := []string{}
+	flush := func() {
+		if len(current) == 0 {
+			return
+		}
+
+		if e := energyAt(current); e == 0 && len(segments) > 0 && energyAt(segments[len(segments)-1]) < 0 {
+			segments[len(segments)-1] = append(segments[len(segments)-1], current...)
+		} else {
+			segments = append(segments, current)
+		}
+		current = []string{}
+	}
+
+	for _, token := range tokens {
+		if separators[token] {
+			flush()
+			continue
+		}
+
+		current = append(current, token)
+	}
+	flush()
+
+	return segments
+}
+
+// Finds the longest time expression at the end of the food, either side of any
+// energy, i.e. "toast for breakfast 300 cal" or "toast 300 cal for breakfast"
+func stripTime(segment []string) ([]string, string) {
+	ends := []int{len(segment)}
+	if e := energyAt(segment); e >= 0 {
+		ends = append(ends, e)
+	}
+
+	for _, end := range ends {
+		for start := 1; start < end; start++ {
+			candidate := segment[start:end]
+			if candidate[0] != "for" && candidate[0] != "at" && candidate[0] != "this" && candidate[0] != "last" &&
+				!resolves(candidate[:1]) {
+				continue
+			}
+
+			words := mealTime(candidate)
+			if len(words) > 0 && resolves(words) {
+				rest := append(append([]string{}, segment[:start]...), segment[end:]...)
+				return rest, strings.Join(words, " ")
+			}
+		}
+	}
+
+	return segment, ""
+}
+
+func resolves(words []string) bool {
+	if len(words) == 0 {
+		return false
+	}
+
+	_, err := timeexpr.Resolve(strings.Join(words, " "), time.Now(), time.UTC)
+	return err == nil
+}
+
+// Index of an energy amount, including any approximation before it
+func energyAt(segment []string) int {
+	for i := 0; i+1 < len(segment); i++ {
+		if isNumber(segment[i]) && energyUnits[segment[i+1]] != "" {
+			if i > 0 && approximations[segment[i-1]] {
+				return i - 1
+			}
+			return i
+		}
+	}
+
+	return -1
+}
+
+// Parses a single food, i.e. "2 slices of toast 300 cal"
+func parseItem(segment []string) (FoodItem, bool) {
+	item := FoodItem{EnergyUnit: "none"}
+
+	if e := energyAt(segment); e >= 0 {
+		n := e
+		if approximations[segment[n]] {
+			n++
+		}
+
+		energy, err := strconv.ParseFloat(segment[n], 32)
+		if err != nil || n+2 != len(segment) {
+			return item, false
+		}
+
+		item.Energy = float32(energy)
+		item.EnergyUnit = energyUnits[segment[n+1]]
+		segment = segment[:e]
+	}
+
+	quantity := []string{}
+	for len(segment) > 0 && (quantityWords[segment[0]] || isNumber(segment[0])) {
+		quantity = append(quantity, segment[0])
+		segment = segment[1:]
+	}
+	if len(segment) > 1 && measures[segment[0]] {
+		quantity = append(quantity, segment[0])
+		segment = segment[1:]
+		if len(segment) > 1 && segment[0] == "of" {
+			quantity = append(quantity, "of")
+			segment = segment[1:]
+		}
+	}
+	// "a couple of eggs" or "half an avocado"
+	for len(segment) > 1 && (segment[0] == "of" || segment[0] == "a" || segment[0] == "an") && len(quantity) > 0 {
+		quantity = append(quantity, segment[0])
+		segment = segment[1:]
+	}
+
+	if len(segment) == 0 || len(segment) > maxNameWords {
+		return item, false
+	}
+
+	for _, word := range segment {
+		if isNumber(word) || energyUnits[word] != "" || notFood[word] || !isWord(word) {
+			return item, false
+		}
+	}
+
+	item.Name = strings.Join(segment, " ")
+	item.Quantity = strings.Join(quantity, " ")
+
+	// Articles aren't worth recording as a quantity
+	if item.Quantity == "a" || item.Quantity == "an" || item.Quantity == "some" {
+		item.Quantity = ""
+	}
+
+	return item, true
+}
+
+func isNumber(token string) bool {
+	if num, den, ok := strings.Cut(token, "/"); ok {
+		token = num + den
+	}
+
+	_, err := strconv.ParseFloat(token, 64)
+	return err == nil
+}
+
+func isWord(token string) bool {
+	for _, r := range token {
+		if (r < 'a' || r > 'z') && r != '\'' {
+			return false
+		}
+	}
+
+	return true
+}
+
+func set(words ...string) map[string]bool {
+	s := make(map[string]bool, len(words))
+	for _, w := range words {
+		s[w] = true
+	}
+
+	return s
+}
+
+// Short summary of the parsed food, i.e. "2 eggs (600 kilojules) and toast"
+func (p ParsedFood) String() string {
+	phrases := make([]string, 0, len(p.Items))
+	for _, item := range p.Items {
+		phrase := item.Phrase()
+		if item.EnergyUnit != "none" {
+			phrase = fmt.Sprintf("%s (%g %ss)", phrase, item.Energy, item.EnergyUnit)
+		}
+		phrases = append(phrases, phrase)
+	}
+
+	switch len(phrases) {
+	case 0:
+		return ""
+	case 1:
+		return phrases[0]
+	default:
+		return strings.Join(phrases[:len(phrases)-1], ", ") + " and " + phrases[len(phrases)-1]
+	}
+}
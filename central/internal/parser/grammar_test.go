@@ -0,0 +1,71 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseFood(t *testing.T) {
+	tests := []struct {
+		input      string
+		items      []FoodItem
+		time       string
+		confidence float64
+	}{
+		{"ate a banana 90 cal", []FoodItem{{Name: "banana", Energy: 90, EnergyUnit: "calorie"}}, "now", 1},
+		{"Ate  an APPLE  80 calories!", []FoodItem{{Name: "apple", Energy: 80, EnergyUnit: "calorie"}}, "now", 1},
+		{"had 2 eggs, 600kj", []FoodItem{{Name: "eggs", Quantity: "2", Energy: 600, EnergyUnit: "kilojule"}}, "now", 1},
+		{"had some toast about 250 kcal.", []FoodItem{{Name: "toast", Energy: 250, EnergyUnit: "calorie"}}, "now", 1},
+		{"eaten a muesli bar 600 kilojoules", []FoodItem{{Name: "muesli bar", Energy: 600, EnergyUnit: "kilojule"}}, "now", 1},
+		{"I just snacked on 1/2 cup of almonds ~400 kj", []FoodItem{{Name: "almonds", Quantity: "1/2 cup of", Energy: 400, EnergyUnit: "kilojule"}}, "now", 1},
+		{"200g chicken breast 330 cal", []FoodItem{{Name: "chicken breast", Quantity: "200 g", Energy: 330, EnergyUnit: "calorie"}}, "now", 0.9},
+		{
+			"had 2 slices of toast 300 cal and a coffee 80 cal for breakfast",
+			[]FoodItem{
+				{Name: "toast", Quantity: "2 slices of", Energy: 300, EnergyUnit: "calorie"},
+				{Name: "coffee", Energy: 80, EnergyUnit: "calorie"},
+			},
+			"breakfast", 1,
+		},
+		{"yesterday I had a pie 450 cal", []FoodItem{{Name: "pie", Energy: 450, EnergyUnit: "calorie"}}, "yesterday", 1},
+		{"for lunch I ate a salad 350 cal", []FoodItem{{Name: "salad", Energy: 350, EnergyUnit: "calorie"}}, "lunch", 1},
+		{"dinner was steak 700 cal + chips 300 cal", []FoodItem{
+			{Name: "steak", Energy: 700, EnergyUnit: "calorie"},
+			{Name: "chips", Energy: 300, EnergyUnit: "calorie"},
+		}, "dinner", 1},
+
+		// Food without an energy is better estimated by the llm
+		{"I just ate a banana", []FoodItem{{Name: "banana", EnergyUnit: "none"}}, "now", 0.7},
+		{"had eggs and toast", []FoodItem{{Name: "eggs", EnergyUnit: "none"}, {Name: "toast", EnergyUnit: "none"}}, "now", 0.7},
+
+		// Anything else isn't a simple food entry
+		{"had a burger with 3 patties", nil, "now", 0},
+		{"how many calories did i eat", nil, "now", 0},
+		{"did I have a banana 90 cal?", nil, "now", 0},
+		{"I didn't eat the cake 500 cal", nil, "now", 0},
+		{"delete the banana 90 cal", nil, "now", 0},
+		{"ran 5km", nil, "now", 0},
+		{"oops I meant 95 cal", nil, "now", 0},
+		{"that was 90 cal", nil, "now", 0},
+		{"ate", nil, "now", 0},
+		{"banana", nil, "now", 0},
+		{"", nil, "now", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got := ParseFood(tt.input)
+			if !reflect.DeepEqual(got.Items, tt.items) || got.Time != tt.time || got.Confidence != tt.confidence {
+				t.Errorf("got %+v but want items %+v, time %q, confidence %v", got, tt.items, tt.time, tt.confidence)
+			}
+		})
+	}
+}
+
+func TestParsedFoodString(t *testing.T) {
+	parsed := ParseFood("had 2 eggs 600 kj, toast 90 cal and a coffee")
+	want := "2 eggs (600 kilojules), toast (90 calories) and coffee"
+	if got := parsed.String(); got != want {
+		t.Errorf("got %q but want %q", got, want)
+	}
+}
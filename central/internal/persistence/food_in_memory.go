@@ -1,6 +1,7 @@
 package persistence
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
 	"strings"
@@ -18,12 +19,12 @@ type MemoryFoodStore struct {
 }
 
 // Create a food record entry
-func (s *MemoryFoodStore) CreateFood(record FoodRecordEntry) error {
+func (s *MemoryFoodStore) CreateFood(ctx context.Context, record FoodRecordEntry) error {
 	s.mux.Lock()
 	defer s.mux.Unlock()
 
 	if _, ok := s.entries[record.Id.String()]; ok {
-		return fmt.Errorf("record already exists for id - %w", errs.ErrBadId)
+		return fmt.Errorf("record already exists for id - %w", errs.ErrAlreadyExists)
 	}
 
 	if record.Created.IsZero() {
@@ -42,7 +43,7 @@ func (s *MemoryFoodStore) CreateFood(record FoodRecordEntry) error {
 
 // Retrieve a single food record based on the
 // record's uuid.
-func (s *MemoryFoodStore) GetFood(uuid uuid.UUID) (FoodRecordEntry, error) {
+func (s *MemoryFoodStore) GetFood(ctx context.Context, uuid uuid.UUID) (FoodRecordEntry, error) {
 	s.mux.RLock()
 	defer s.mux.RUnlock()
 
@@ -57,7 +58,7 @@ func (s *MemoryFoodStore) GetFood(uuid uuid.UUID) (FoodRecordEntry, error) {
 
 // Provided FoodRecordEntry is treated as a filter, allowing
 // the caller to retrieve multiple food records at will.
-func (s *MemoryFoodStore) GetFoods(filter FoodFilter) ([]FoodRecordEntry, error) {
+func (s *MemoryFoodStore) GetFoods(ctx context.Context, filter FoodFilter) ([]FoodRecordEntry, error) {
 	entries := make([]FoodRecordEntry, 0)
 
 	s.mux.RLock()
@@ -108,14 +109,28 @@ func (s *MemoryFoodStore) GetFoods(filter FoodFilter) ([]FoodRecordEntry, error)
 		entries = append(entries, entry)
 	}
 
+	if filter.Latest && len(entries) > 0 {
+		latest := entries[0]
+		for _, entry := range entries[1:] {
+			if entry.Created.After(latest.Created) {
+				latest = entry
+			}
+		}
+		entries = []FoodRecordEntry{latest}
+	}
+
 	return entries, nil
 }
 
 // Update the record in place
-func (s *MemoryFoodStore) UpdateFood(record FoodRecordEntry) error {
+func (s *MemoryFoodStore) UpdateFood(ctx context.Context, record FoodRecordEntry) error {
 	s.mux.Lock()
 	defer s.mux.Unlock()
 
+	if _, ok := s.entries[record.Id.String()]; !ok {
+		return errs.ErrNotFound
+	}
+
 	s.entries[record.Id.String()] = record
 
 	if s.log != nil {
@@ -127,10 +142,14 @@ func (s *MemoryFoodStore) UpdateFood(record FoodRecordEntry) error {
 }
 
 // Delete matching record
-func (s *MemoryFoodStore) DeleteFood(uuid uuid.UUID) error {
+func (s *MemoryFoodStore) DeleteFood(ctx context.Context, uuid uuid.UUID) error {
 	s.mux.Lock()
 	defer s.mux.Unlock()
 
+	if _, ok := s.entries[uuid.String()]; !ok {
+		return errs.ErrNotFound
+	}
+
 	delete(s.entries, uuid.String())
 
 	return nil
@@ -0,0 +1,44 @@
+package persistence
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestMemoryGetFoodsLatest(t *testing.T) {
+	store := NewMemoryFoodStore(nil)
+	user := uuid.New()
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	for i, name := range []string{"toast", "banana", "toast with jam", "apple"} {
+		err := store.CreateFood(context.Background(), FoodRecordEntry{Id: uuid.New(), UserId: user, Name: name, Created: start.Add(time.Duration(i) * time.Hour)})
+		if err != nil {
+			t.Fatalf("got err %v", err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		filter FoodFilter
+		want   string
+	}{
+		{"latest of all", FoodFilter{UserId: user, Latest: true}, "apple"},
+		{"latest by name", FoodFilter{UserId: user, Name: "toast", Latest: true}, "toast with jam"},
+		{"latest before a time", FoodFilter{UserId: user, BeforeTime: start.Add(90 * time.Minute), Latest: true}, "banana"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := store.GetFoods(context.Background(), tt.filter)
+			if err != nil {
+				t.Fatalf("got err %v", err)
+			}
+			if len(got) != 1 || got[0].Name != tt.want {
+				t.Errorf("got %v but want only %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package persistence
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
@@ -30,6 +31,8 @@ type redisRecord struct {
 	Grams       float32   `json:"gram" redis:"gram"`
 	ML          float32   `json:"ml" redis:"ml"`
 	Created     time.Time `json:"created" redis:"created"`
+	// Created as unix seconds, which the index can sort and range over
+	CreatedUnix int64 `json:"created_unix" redis:"created_unix"`
 }
 
 func mapRecord(record FoodRecordEntry) redisRecord {
@@ -43,6 +46,7 @@ func mapRecord(record FoodRecordEntry) redisRecord {
 		Grams:       record.Grams,
 		ML:          record.ML,
 		Created:     record.Created,
+		CreatedUnix: record.Created.Unix(),
 	}
 }
 
@@ -71,22 +75,20 @@ func mapRedis(redis redisRecord) (FoodRecordEntry, error) {
 }
 
 // Create a food record entry
-func (r *RedisFoodStore) CreateFood(record FoodRecordEntry) error {
+func (r *RedisFoodStore) CreateFood(ctx context.Context, record FoodRecordEntry) error {
 	if record.Id == uuid.Nil {
 		record.Id = uuid.Must(uuid.NewRandom())
 	}
 
 	rrec := mapRecord(record)
 
-	ctx := context.Background()
-
 	res := r.rdb.Get(ctx, rrec.Id)
 	if res == nil {
 		r.logger.ErrorContext(ctx, "encountered nil when checking existing", slog.Any("redis_record", rrec))
-		return fmt.Errorf("failed to check existing in redis - %w", errs.ErrBadRequest)
+		return fmt.Errorf("failed to check existing in redis - %w", errs.ErrInternal)
 	}
 	if res.Err() != redis.Nil {
-		return fmt.Errorf("record id already exists - %w", errs.ErrBadId)
+		return fmt.Errorf("record id already exists - %w", errs.ErrAlreadyExists)
 	}
 
 	set, err := r.rdb.JSONSet(ctx, fmt.Sprintf("food:%s", record.Id.String()), "$", rrec).Result()
@@ -101,11 +103,11 @@ func (r *RedisFoodStore) CreateFood(record FoodRecordEntry) error {
 
 // Retrieve a single food record based on the
 // record's uuid.
-func (r *RedisFoodStore) GetFood(uuid uuid.UUID) (FoodRecordEntry, error) {
+func (r *RedisFoodStore) GetFood(ctx context.Context, uuid uuid.UUID) (FoodRecordEntry, error) {
 	query := fmt.Sprintf("@id:(%s)", strings.ReplaceAll(uuid.String(), "-", " "))
 
 	res, err := r.rdb.FTSearchWithArgs(
-		context.Background(),
+		ctx,
 		"idx:food",
 		query,
 		&redis.FTSearchOptions{
@@ -142,7 +144,7 @@ func (r *RedisFoodStore) GetFood(uuid uuid.UUID) (FoodRecordEntry, error) {
 
 // Provided FoodRecordEntry is treated as a filter, allowing
 // the caller to retrieve multiple food records at will.
-func (r *RedisFoodStore) GetFoods(filter FoodFilter) ([]FoodRecordEntry, error) {
+func (r *RedisFoodStore) GetFoods(ctx context.Context, filter FoodFilter) ([]FoodRecordEntry, error) {
 
 	escape := func(s string) string {
 		esc := strings.ReplaceAll(s, "-", " ")
@@ -172,19 +174,29 @@ func (r *RedisFoodStore) GetFoods(filter FoodFilter) ([]FoodRecordEntry, error)
 	}
 
 	// Ignore time filters and run them on returned results. :^)
+	// Except for the latest record, where redis has to sort and range over
+	// creation itself or the newest could be past the page it returns.
+	options := &redis.FTSearchOptions{Limit: 100}
+	if filter.Latest {
+		queryBuilder.WriteString(fmt.Sprintf("@created_unix:[%s %s] ", unixBound(filter.AfterTime, "-inf"), unixBound(filter.BeforeTime, "+inf")))
+		options = &redis.FTSearchOptions{
+			SortBy: []redis.FTSearchSortBy{{FieldName: "created_unix", Desc: true}},
+			Limit:  1,
+		}
+	}
+
 	query := queryBuilder.String()
 
 	r.logger.Debug("using filter and query to retrieve food records", slog.String("query", query), slog.Any("filter", filter))
 
-	ctx := context.Background()
 	res, err := r.rdb.FTSearchWithArgs(
 		ctx,
 		"idx:food",
 		query,
-		&redis.FTSearchOptions{Limit: 100},
+		options,
 	).Result()
 
-	if len(res.Docs) < res.Total {
+	if !filter.Latest && len(res.Docs) < res.Total {
 		r.logger.Error(fmt.Sprintf("total greater than returned docs - %d total, %d returned", res.Total, len(res.Docs)), slog.Int("limit", 100))
 	}
 
@@ -231,14 +243,62 @@ func (r *RedisFoodStore) GetFoods(filter FoodFilter) ([]FoodRecordEntry, error)
 	return results, nil
 }
 
+// Inclusive bound of a numeric range over creation, or unbounded when unset
+func unixBound(t time.Time, unbounded string) string {
+	if t.IsZero() {
+		return unbounded
+	}
+
+	return strconv.FormatInt(t.Unix(), 10)
+}
+
 // Update the record in place
-func (r *RedisFoodStore) UpdateFood(record FoodRecordEntry) error {
-	return errs.ErrNotImplementedYet
+func (r *RedisFoodStore) UpdateFood(ctx context.Context, record FoodRecordEntry) error {
+	key := fmt.Sprintf("food:%s", record.Id.String())
+
+	exists, err := r.rdb.Exists(ctx, key).Result()
+	if err != nil {
+		return err
+	}
+	if exists == 0 {
+		return errs.ErrNotFound
+	}
+
+	set, err := r.rdb.JSONSet(ctx, key, "$", mapRecord(record)).Result()
+	if err != nil {
+		return err
+	}
+
+	r.logger.Info("redis updated", slog.Any("set", set))
+
+	return nil
 }
 
 // Delete matching record
-func (r *RedisFoodStore) DeleteFood(uuid uuid.UUID) error {
-	return errs.ErrNotImplementedYet
+func (r *RedisFoodStore) DeleteFood(ctx context.Context, uuid uuid.UUID) error {
+	deleted, err := r.rdb.Del(ctx, fmt.Sprintf("food:%s", uuid.String())).Result()
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return errs.ErrNotFound
+	}
+
+	return nil
+}
+
+// Checks redis is reachable
+func (r *RedisFoodStore) Ping(ctx context.Context) error {
+	return r.rdb.Ping(ctx).Err()
+}
+
+// Checks the food search index exists, as food can't be found without it
+func (r *RedisFoodStore) IndexReady(ctx context.Context) error {
+	if _, err := r.rdb.FTInfo(ctx, "idx:food").Result(); err != nil {
+		return fmt.Errorf("food index unavailable: %w", err)
+	}
+
+	return nil
 }
 
 func NewRedisFoodStore(logger *slog.Logger, conf *conf.Config) (*RedisFoodStore, error) {
@@ -294,6 +354,12 @@ func NewRedisFoodStore(logger *slog.Logger, conf *conf.Config) (*RedisFoodStore,
 			As:        "created",
 			FieldType: redis.SearchFieldTypeText,
 		},
+		&redis.FieldSchema{
+			FieldName: "$.created_unix",
+			As:        "created_unix",
+			FieldType: redis.SearchFieldTypeNumeric,
+			Sortable:  true,
+		},
 	).Result()
 
 	if err != nil {
@@ -302,5 +368,51 @@ func NewRedisFoodStore(logger *slog.Logger, conf *conf.Config) (*RedisFoodStore,
 
 	rdb := &RedisFoodStore{logger: logger, conf: conf, rdb: client}
 
+	// Records from before created_unix existed can't be found when sorting on it
+	if err := rdb.backfillCreatedUnix(context.Background()); err != nil {
+		logger.Error("failed backfilling food creation times", slog.Any("err", err))
+	}
+
 	return rdb, nil
 }
+
+// Sets created_unix on records written before it was stored, from their
+// creation time
+func (r *RedisFoodStore) backfillCreatedUnix(ctx context.Context) error {
+	filled := 0
+	iter := r.rdb.Scan(ctx, 0, "food:*", 100).Iterator()
+	for iter.Next(ctx) {
+		key := iter.Val()
+
+		raw, err := r.rdb.JSONGet(ctx, key, "$").Result()
+		if err != nil {
+			return fmt.Errorf("failed reading %s - %w", key, err)
+		}
+
+		// Paths starting at the root come back as an array of matches
+		records, err := serr.DecodeJSONS[[]redisRecord](raw)
+		if err != nil || len(records) != 1 {
+			r.logger.Warn("skipping unreadable food record", slog.String("key", key), slog.Any("err", err))
+			continue
+		}
+
+		record := records[0]
+		if record.CreatedUnix != 0 || record.Created.IsZero() {
+			continue
+		}
+
+		if err := r.rdb.JSONSet(ctx, key, "$.created_unix", record.Created.Unix()).Err(); err != nil {
+			return fmt.Errorf("failed backfilling %s - %w", key, err)
+		}
+		filled++
+	}
+	if err := iter.Err(); err != nil {
+		return err
+	}
+
+	if filled > 0 {
+		r.logger.Info(fmt.Sprintf("backfilled creation times of %d food records", filled))
+	}
+
+	return nil
+}
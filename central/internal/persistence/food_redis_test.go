@@ -1,9 +1,104 @@
 package persistence
 
-import "testing"
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/alicebob/miniredis/v2/server"
+	"github.com/calamity-m/reaphur/central/internal/conf"
+	"github.com/google/uuid"
+)
 
 func TestCreateFoodIntegration(t *testing.T) {
 	if testing.Short() {
 		t.Skip("skipping integration test")
 	}
 }
+
+// Stands in for the RedisJSON commands the store uses, keeping documents as
+// plain strings so they can still be scanned
+func registerJSON(t *testing.T, mr *miniredis.Miniredis) {
+	t.Helper()
+
+	get := func(c *server.Peer, cmd string, args []string) {
+		doc, err := mr.Get(args[0])
+		if err != nil {
+			c.WriteNull()
+			return
+		}
+		c.WriteBulk("[" + doc + "]")
+	}
+	set := func(c *server.Peer, cmd string, args []string) {
+		key, path, value := args[0], args[1], args[2]
+		if path == "$" {
+			mr.Set(key, value)
+			c.WriteOK()
+			return
+		}
+
+		doc, err := mr.Get(key)
+		if err != nil {
+			c.WriteError(err.Error())
+			return
+		}
+		fields := map[string]json.RawMessage{}
+		if err := json.Unmarshal([]byte(doc), &fields); err != nil {
+			c.WriteError(err.Error())
+			return
+		}
+		fields[path[len("$."):]] = json.RawMessage(value)
+		patched, _ := json.Marshal(fields)
+		mr.Set(key, string(patched))
+		c.WriteOK()
+	}
+
+	if err := mr.Server().Register("JSON.GET", get); err != nil {
+		t.Fatalf("failed registering json.get: %v", err)
+	}
+	if err := mr.Server().Register("JSON.SET", set); err != nil {
+		t.Fatalf("failed registering json.set: %v", err)
+	}
+}
+
+func TestRedisFoodStoreBackfillsCreatedUnix(t *testing.T) {
+	mr := miniredis.RunT(t)
+	registerJSON(t, mr)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	created := time.Date(2025, 3, 2, 12, 0, 0, 0, time.UTC)
+	legacy := uuid.New()
+	current := uuid.New()
+
+	// Logged before created_unix was stored
+	mr.Set("food:"+legacy.String(), `{"i":0,"id":"`+legacy.String()+`","user_id":"`+uuid.NewString()+`","name":"toast","description":"","kj":300,"gram":0,"ml":0,"created":"2025-03-02T12:00:00Z"}`)
+	fresh, _ := json.Marshal(mapRecord(FoodRecordEntry{Id: current, UserId: uuid.New(), Name: "eggs", Created: created.Add(time.Hour)}))
+	mr.Set("food:"+current.String(), string(fresh))
+
+	if _, err := NewRedisFoodStore(logger, &conf.Config{RedisAddress: mr.Addr()}); err != nil {
+		t.Fatalf("got err %v", err)
+	}
+
+	read := func(id uuid.UUID) redisRecord {
+		t.Helper()
+		doc, err := mr.Get("food:" + id.String())
+		if err != nil {
+			t.Fatalf("got err %v", err)
+		}
+		var record redisRecord
+		if err := json.Unmarshal([]byte(doc), &record); err != nil {
+			t.Fatalf("got err %v", err)
+		}
+		return record
+	}
+
+	if got := read(legacy); got.CreatedUnix != created.Unix() || got.Name != "toast" {
+		t.Errorf("got legacy record %+v but want created_unix %d", got, created.Unix())
+	}
+	if got := read(current); got.CreatedUnix != created.Add(time.Hour).Unix() {
+		t.Errorf("got current record %+v but want its created_unix untouched", got)
+	}
+}
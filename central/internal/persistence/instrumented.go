@@ -0,0 +1,117 @@
+package persistence
+
+import (
+	"context"
+	"time"
+
+	"github.com/calamity-m/reaphur/central/internal/metrics"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Backend labels for instrumented stores
+const (
+	BackendRedis  = "redis"
+	BackendMemory = "memory"
+)
+
+var tracer = otel.Tracer("github.com/calamity-m/reaphur/central/internal/persistence")
+
+// Starts a span for the operation, returning a func that ends it and records the
+// operation's latency
+func instrument(ctx context.Context, backend string, operation string) (context.Context, func(err error)) {
+	start := time.Now()
+	ctx, span := tracer.Start(ctx, "persistence."+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("db.system", backend), attribute.String("db.operation.name", operation)),
+	)
+
+	return ctx, func(err error) {
+		metrics.PersistenceDuration.WithLabelValues(backend, operation, metrics.Outcome(err)).Observe(time.Since(start).Seconds())
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}
+}
+
+// Traces and records the latency of every operation on the wrapped food store
+type InstrumentedFoodStore struct {
+	next    FoodPersistence
+	backend string
+}
+
+func (s *InstrumentedFoodStore) CreateFood(ctx context.Context, record FoodRecordEntry) error {
+	ctx, done := instrument(ctx, s.backend, "create_food")
+	err := s.next.CreateFood(ctx, record)
+	done(err)
+	return err
+}
+
+func (s *InstrumentedFoodStore) GetFood(ctx context.Context, id uuid.UUID) (FoodRecordEntry, error) {
+	ctx, done := instrument(ctx, s.backend, "get_food")
+	record, err := s.next.GetFood(ctx, id)
+	done(err)
+	return record, err
+}
+
+func (s *InstrumentedFoodStore) GetFoods(ctx context.Context, filter FoodFilter) ([]FoodRecordEntry, error) {
+	ctx, done := instrument(ctx, s.backend, "get_foods")
+	records, err := s.next.GetFoods(ctx, filter)
+	done(err)
+	return records, err
+}
+
+func (s *InstrumentedFoodStore) UpdateFood(ctx context.Context, record FoodRecordEntry) error {
+	ctx, done := instrument(ctx, s.backend, "update_food")
+	err := s.next.UpdateFood(ctx, record)
+	done(err)
+	return err
+}
+
+func (s *InstrumentedFoodStore) DeleteFood(ctx context.Context, id uuid.UUID) error {
+	ctx, done := instrument(ctx, s.backend, "delete_food")
+	err := s.next.DeleteFood(ctx, id)
+	done(err)
+	return err
+}
+
+func NewInstrumentedFoodStore(next FoodPersistence, backend string) *InstrumentedFoodStore {
+	return &InstrumentedFoodStore{next: next, backend: backend}
+}
+
+// Traces and records the latency of every operation on the wrapped usage store
+type InstrumentedUsageStore struct {
+	next    UsagePersistence
+	backend string
+}
+
+func (s *InstrumentedUsageStore) RecordUsage(ctx context.Context, entry UsageRecordEntry) error {
+	ctx, done := instrument(ctx, s.backend, "record_usage")
+	err := s.next.RecordUsage(ctx, entry)
+	done(err)
+	return err
+}
+
+func (s *InstrumentedUsageStore) GetUsage(ctx context.Context, filter UsageFilter) ([]UsageRecordEntry, error) {
+	ctx, done := instrument(ctx, s.backend, "get_usage")
+	entries, err := s.next.GetUsage(ctx, filter)
+	done(err)
+	return entries, err
+}
+
+func (s *InstrumentedUsageStore) GetUsageTotals(ctx context.Context, userId uuid.UUID, at time.Time) (UsageTotals, error) {
+	ctx, done := instrument(ctx, s.backend, "get_usage_totals")
+	totals, err := s.next.GetUsageTotals(ctx, userId, at)
+	done(err)
+	return totals, err
+}
+
+func NewInstrumentedUsageStore(next UsagePersistence, backend string) *InstrumentedUsageStore {
+	return &InstrumentedUsageStore{next: next, backend: backend}
+}
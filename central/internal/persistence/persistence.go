@@ -1,6 +1,7 @@
 package persistence
 
 import (
+	"context"
 	"time"
 
 	"github.com/google/uuid"
@@ -25,19 +26,54 @@ type FoodFilter struct {
 	Description string
 	BeforeTime  time.Time
 	AfterTime   time.Time
+	// Only the most recently created matching record
+	Latest bool
 }
 
 type FoodPersistence interface {
 	// Create a food record entry
-	CreateFood(record FoodRecordEntry) error
+	CreateFood(ctx context.Context, record FoodRecordEntry) error
 	// Retrieve a single food record based on the
 	// record's uuid.
-	GetFood(uuid uuid.UUID) (FoodRecordEntry, error)
+	GetFood(ctx context.Context, uuid uuid.UUID) (FoodRecordEntry, error)
 	// Provided FoodRecordEntry is treated as a filter, allowing
 	// the caller to retrieve multiple food records at will.
-	GetFoods(filter FoodFilter) ([]FoodRecordEntry, error)
+	GetFoods(ctx context.Context, filter FoodFilter) ([]FoodRecordEntry, error)
 	// Update the record in place
-	UpdateFood(record FoodRecordEntry) error
+	UpdateFood(ctx context.Context, record FoodRecordEntry) error
 	// Delete matching record
-	DeleteFood(uuid uuid.UUID) error
+	DeleteFood(ctx context.Context, uuid uuid.UUID) error
+}
+
+type UsageRecordEntry struct {
+	Id               uuid.UUID
+	UserId           uuid.UUID
+	Model            string
+	PromptVersion    string
+	PromptTokens     int64
+	CompletionTokens int64
+	Created          time.Time
+}
+
+type UsageFilter struct {
+	// Nil user id matches every user
+	UserId     uuid.UUID
+	Model      string
+	BeforeTime time.Time
+	AfterTime  time.Time
+}
+
+// Tokens a user has spent within a UTC day and month
+type UsageTotals struct {
+	Daily   int64
+	Monthly int64
+}
+
+type UsagePersistence interface {
+	// Record the token usage of a single llm completion
+	RecordUsage(ctx context.Context, entry UsageRecordEntry) error
+	// Retrieve usage entries matching the filter
+	GetUsage(ctx context.Context, filter UsageFilter) ([]UsageRecordEntry, error)
+	// Tokens the user has spent in the UTC day and month containing at
+	GetUsageTotals(ctx context.Context, userId uuid.UUID, at time.Time) (UsageTotals, error)
 }
@@ -0,0 +1,114 @@
+package persistence
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type MemoryUsageStore struct {
+	mux     sync.RWMutex
+	entries []UsageRecordEntry
+	log     *slog.Logger
+}
+
+// Record the token usage of a single llm completion
+func (s *MemoryUsageStore) RecordUsage(ctx context.Context, entry UsageRecordEntry) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	if entry.Id == uuid.Nil {
+		entry.Id = uuid.Must(uuid.NewV7())
+	}
+
+	if entry.Created.IsZero() {
+		entry.Created = time.Now()
+	}
+
+	s.entries = append(s.entries, entry)
+
+	return nil
+}
+
+// Retrieve usage entries matching the filter
+func (s *MemoryUsageStore) GetUsage(ctx context.Context, filter UsageFilter) ([]UsageRecordEntry, error) {
+	s.mux.RLock()
+	defer s.mux.RUnlock()
+
+	found := make([]UsageRecordEntry, 0)
+	for _, entry := range s.entries {
+		if filter.matches(entry) {
+			found = append(found, entry)
+		}
+	}
+
+	return found, nil
+}
+
+// Tokens the user has spent in the UTC day and month containing at
+func (s *MemoryUsageStore) GetUsageTotals(ctx context.Context, userId uuid.UUID, at time.Time) (UsageTotals, error) {
+	s.mux.RLock()
+	defer s.mux.RUnlock()
+
+	day, month := usageDay(at), usageMonth(at)
+
+	totals := UsageTotals{}
+	for _, entry := range s.entries {
+		if entry.UserId != userId {
+			continue
+		}
+
+		total := entry.PromptTokens + entry.CompletionTokens
+		if usageMonth(entry.Created).Equal(month) {
+			totals.Monthly += total
+		}
+		if usageDay(entry.Created).Equal(day) {
+			totals.Daily += total
+		}
+	}
+
+	return totals, nil
+}
+
+// Start of the UTC day containing t
+func usageDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// Start of the UTC month containing t
+func usageMonth(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+func (f UsageFilter) matches(entry UsageRecordEntry) bool {
+	if f.UserId != uuid.Nil && entry.UserId != f.UserId {
+		return false
+	}
+
+	if f.Model != "" && entry.Model != f.Model {
+		return false
+	}
+
+	if !f.AfterTime.IsZero() && entry.Created.Before(f.AfterTime) {
+		return false
+	}
+
+	if !f.BeforeTime.IsZero() && entry.Created.After(f.BeforeTime) {
+		return false
+	}
+
+	return true
+}
+
+func NewMemoryUsageStore(logger *slog.Logger) *MemoryUsageStore {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &MemoryUsageStore{entries: make([]UsageRecordEntry, 0), log: logger}
+}
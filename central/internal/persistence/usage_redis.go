@@ -0,0 +1,200 @@
+package persistence
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/calamity-m/reaphur/central/internal/conf"
+	"github.com/calamity-m/reaphur/central/internal/util"
+	"github.com/calamity-m/reaphur/pkg/errs"
+	"github.com/calamity-m/reaphur/pkg/serr"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// Set holding every user id that has recorded some usage
+	usageUsersKey = "usage:users"
+)
+
+// Usage is stored as one sorted set per user, scored by the unix milli time
+// of the completion, and trimmed to the retention as usage is recorded. Quotas
+// are checked against per day and month counters that expire on their own, so
+// they never have to range over the records.
+type RedisUsageStore struct {
+	logger *slog.Logger
+	conf   *conf.Config
+	rdb    *redis.Client
+}
+
+type redisUsage struct {
+	Id               string    `json:"id"`
+	UserId           string    `json:"user_id"`
+	Model            string    `json:"model"`
+	PromptVersion    string    `json:"prompt_version,omitempty"`
+	PromptTokens     int64     `json:"prompt_tokens"`
+	CompletionTokens int64     `json:"completion_tokens"`
+	Created          time.Time `json:"created"`
+}
+
+func usageKey(user string) string {
+	return fmt.Sprintf("usage:user:%s", user)
+}
+
+func usageDayKey(user string, day time.Time) string {
+	return fmt.Sprintf("usage:user:%s:day:%s", user, day.Format(time.DateOnly))
+}
+
+func usageMonthKey(user string, month time.Time) string {
+	return fmt.Sprintf("usage:user:%s:month:%s", user, month.Format("2006-01"))
+}
+
+// Record the token usage of a single llm completion
+func (r *RedisUsageStore) RecordUsage(ctx context.Context, entry UsageRecordEntry) error {
+	if entry.Id == uuid.Nil {
+		entry.Id = uuid.Must(uuid.NewV7())
+	}
+
+	if entry.Created.IsZero() {
+		entry.Created = time.Now()
+	}
+
+	member, err := serr.EncodeJSON(redisUsage{
+		Id:               entry.Id.String(),
+		UserId:           entry.UserId.String(),
+		Model:            entry.Model,
+		PromptVersion:    entry.PromptVersion,
+		PromptTokens:     entry.PromptTokens,
+		CompletionTokens: entry.CompletionTokens,
+		Created:          entry.Created,
+	})
+	if err != nil {
+		return err
+	}
+
+	user := entry.UserId.String()
+	total := entry.PromptTokens + entry.CompletionTokens
+	day, month := usageDay(entry.Created), usageMonth(entry.Created)
+
+	pipe := r.rdb.TxPipeline()
+	pipe.ZAdd(ctx, usageKey(user), redis.Z{Score: float64(entry.Created.UnixMilli()), Member: member})
+	if retention := r.conf.UsageRetention; retention > 0 {
+		pipe.ZRemRangeByScore(ctx, usageKey(user), "-inf", fmt.Sprintf("(%d", entry.Created.Add(-retention).UnixMilli()))
+		pipe.Expire(ctx, usageKey(user), retention)
+	}
+	pipe.SAdd(ctx, usageUsersKey, user)
+
+	// Counters outlive their day and month by a day, in case of clock skew
+	pipe.IncrBy(ctx, usageDayKey(user, day), total)
+	pipe.ExpireAt(ctx, usageDayKey(user, day), day.AddDate(0, 0, 2))
+	pipe.IncrBy(ctx, usageMonthKey(user, month), total)
+	pipe.ExpireAt(ctx, usageMonthKey(user, month), month.AddDate(0, 1, 1))
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		r.logger.Error("failed recording usage", slog.Any("err", err), slog.Any("entry", entry))
+		return err
+	}
+
+	return nil
+}
+
+// Retrieve usage entries matching the filter
+func (r *RedisUsageStore) GetUsage(ctx context.Context, filter UsageFilter) ([]UsageRecordEntry, error) {
+	users := []string{filter.UserId.String()}
+	if filter.UserId == uuid.Nil {
+		members, err := r.rdb.SMembers(ctx, usageUsersKey).Result()
+		if err != nil {
+			return nil, err
+		}
+		users = members
+	}
+
+	min, max := "-inf", "+inf"
+	if !filter.AfterTime.IsZero() {
+		min = strconv.FormatInt(filter.AfterTime.UnixMilli(), 10)
+	}
+	if !filter.BeforeTime.IsZero() {
+		max = strconv.FormatInt(filter.BeforeTime.UnixMilli(), 10)
+	}
+
+	found := make([]UsageRecordEntry, 0)
+	for _, user := range users {
+		members, err := r.rdb.ZRangeByScore(ctx, usageKey(user), &redis.ZRangeBy{Min: min, Max: max}).Result()
+		if err != nil {
+			r.logger.Error("failed ranging usage", slog.Any("err", err), slog.String("user", user))
+			return nil, err
+		}
+
+		for _, member := range members {
+			scanned, err := serr.DecodeJSONS[redisUsage](member)
+			if err != nil {
+				r.logger.Error("failed scanning usage from redis", slog.Any("err", err), slog.String("member", member))
+				return nil, errs.ErrInternal
+			}
+
+			entry := UsageRecordEntry{
+				Id:               util.ParseUUIDRegardless(scanned.Id),
+				UserId:           util.ParseUUIDRegardless(scanned.UserId),
+				Model:            scanned.Model,
+				PromptVersion:    scanned.PromptVersion,
+				PromptTokens:     scanned.PromptTokens,
+				CompletionTokens: scanned.CompletionTokens,
+				Created:          scanned.Created,
+			}
+
+			if filter.matches(entry) {
+				found = append(found, entry)
+			}
+		}
+	}
+
+	return found, nil
+}
+
+// Tokens the user has spent in the UTC day and month containing at
+func (r *RedisUsageStore) GetUsageTotals(ctx context.Context, userId uuid.UUID, at time.Time) (UsageTotals, error) {
+	user := userId.String()
+
+	counters, err := r.rdb.MGet(ctx, usageDayKey(user, usageDay(at)), usageMonthKey(user, usageMonth(at))).Result()
+	if err != nil {
+		r.logger.Error("failed getting usage totals", slog.Any("err", err), slog.String("user", user))
+		return UsageTotals{}, err
+	}
+
+	// Counters that were never incremented come back nil
+	totals := make([]int64, len(counters))
+	for i, counter := range counters {
+		if counter == nil {
+			continue
+		}
+
+		if totals[i], err = strconv.ParseInt(fmt.Sprint(counter), 10, 64); err != nil {
+			r.logger.Error("failed parsing usage counter", slog.Any("err", err), slog.Any("counter", counter))
+			return UsageTotals{}, errs.ErrInternal
+		}
+	}
+
+	return UsageTotals{Daily: totals[0], Monthly: totals[1]}, nil
+}
+
+func NewRedisUsageStore(logger *slog.Logger, conf *conf.Config) (*RedisUsageStore, error) {
+	if logger == nil || conf == nil {
+		return nil, errs.ErrNilNotAllowed
+	}
+
+	client := redis.NewClient(&redis.Options{
+		Addr:     conf.RedisAddress,
+		Password: conf.FoodRedisPassword,
+		DB:       conf.FoodRedisDB,
+		Protocol: 2,
+	})
+
+	if err := client.Ping(context.Background()).Err(); err != nil {
+		return nil, fmt.Errorf("failed to connect redis client - %w", err)
+	}
+
+	return &RedisUsageStore{logger: logger, conf: conf, rdb: client}, nil
+}
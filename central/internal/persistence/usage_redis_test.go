@@ -0,0 +1,71 @@
+package persistence
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/calamity-m/reaphur/central/internal/conf"
+	"github.com/google/uuid"
+)
+
+func TestRedisUsageStore(t *testing.T) {
+	mr := miniredis.RunT(t)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	store, err := NewRedisUsageStore(logger, &conf.Config{RedisAddress: mr.Addr(), UsageRetention: 48 * time.Hour})
+	if err != nil {
+		t.Fatalf("got err %v", err)
+	}
+
+	user := uuid.New()
+	now := time.Date(2025, 3, 2, 12, 0, 0, 0, time.UTC)
+	mr.SetTime(now)
+	record := func(at time.Time, tokens int64) {
+		t.Helper()
+		if err := store.RecordUsage(context.Background(), UsageRecordEntry{UserId: user, PromptTokens: tokens, CompletionTokens: 1, Created: at}); err != nil {
+			t.Fatalf("got err %v", err)
+		}
+	}
+
+	record(now.AddDate(0, 0, -5), 99) // Last month, and past the retention
+	record(now.Add(-24*time.Hour), 9) // Yesterday
+	record(now, 19)
+	record(now.Add(time.Hour), 29)
+
+	totals, err := store.GetUsageTotals(context.Background(), user, now)
+	if err != nil {
+		t.Fatalf("got err %v", err)
+	}
+	if totals.Daily != 50 || totals.Monthly != 60 {
+		t.Errorf("got totals %+v but want 50 daily and 60 monthly", totals)
+	}
+
+	// Records past the retention are trimmed and the rest expire with it
+	entries, err := store.GetUsage(context.Background(), UsageFilter{UserId: user})
+	if err != nil {
+		t.Fatalf("got err %v", err)
+	}
+	if len(entries) != 3 {
+		t.Errorf("got %d entries but want 3 within the retention", len(entries))
+	}
+	if ttl := mr.TTL(usageKey(user.String())); ttl != 48*time.Hour {
+		t.Errorf("got records ttl %v but want the retention", ttl)
+	}
+
+	// Counters expire a day after their day or month ends
+	if ttl := mr.TTL(usageDayKey(user.String(), usageDay(now))); ttl != 36*time.Hour {
+		t.Errorf("got day counter ttl %v but want 36h", ttl)
+	}
+
+	totals, err = store.GetUsageTotals(context.Background(), uuid.New(), now)
+	if err != nil {
+		t.Fatalf("got err %v", err)
+	}
+	if totals != (UsageTotals{}) {
+		t.Errorf("got totals %+v for a user without usage", totals)
+	}
+}
@@ -17,6 +17,8 @@ type FnCreateFoodParameters struct {
 	Energy float32 `json:"energy" jsonschema:"required"`
 	// The energy unit the user provided. If they provided no energy amount, this should be none
 	EnegyUnit string `json:"energy_unit" jsonschema:"required,enum=calorie,enum=kilojule,enum=none"`
+	// When the food was eaten, either a relative expression copied from the user such as "yesterday lunch" or "2 hours ago", or an ISO 8601 timestamp. Use "now" if the user didn't say
+	Time string `json:"time" jsonschema:"required"`
 }
 
 type Set struct {
@@ -49,12 +51,37 @@ type FnCreateCardioParameters struct {
 type FnGetFoodParameters struct {
 	// Optional text match query the user wants
 	Query string `json:"query" jsonschema:"required"`
-	// Get all food records after this time
+	// Get all food records after this time. Either a relative expression copied from the user such as "yesterday", "last tuesday lunch" or "past 3 days", or an ISO 8601 timestamp. Periods use their start
 	AfterTime string `json:"after_time" jsonschema:"required"`
-	// Get all food records before this time
+	// Get all food records before this time. Either a relative expression copied from the user such as "yesterday", "last tuesday lunch" or "past 3 days", or an ISO 8601 timestamp. Periods use their end, so the same period for both gets the whole period
 	BeforeTime string `json:"before_time" jsonschema:"required"`
 }
 
+type FnUpdateFoodParameters struct {
+	// Which food record to change. Either its id from an earlier tool result, "last" for the user's most recently logged food, or the food's name to change the most recent food with that name
+	Target string `json:"target" jsonschema:"required"`
+	// New normalized name of the food, or empty to keep the current name
+	Name string `json:"name" jsonschema:"required"`
+	// New description of the food, or empty to keep the current description
+	Description string `json:"description" jsonschema:"required"`
+	// New energy the food contained, e.g. 500. Ignored when energy_unit is none
+	Energy float32 `json:"energy" jsonschema:"required"`
+	// The energy unit of the new energy. Use none to keep the current energy
+	EnegyUnit string `json:"energy_unit" jsonschema:"required,enum=calorie,enum=kilojule,enum=none"`
+	// When the food was actually eaten, either a relative expression copied from the user such as "yesterday lunch", or an ISO 8601 timestamp. Empty to keep the current time
+	Time string `json:"time" jsonschema:"required"`
+}
+
+type FnDeleteFoodParameters struct {
+	// Which food record to delete. Either its id from an earlier tool result, "last" for the user's most recently logged food, or the food's name to delete the most recent food with that name
+	Target string `json:"target" jsonschema:"required"`
+}
+
+type FnUndoLastActionParameters struct {
+	// What the user wants undone in their own words, e.g. "that last entry"
+	Reason string `json:"reason" jsonschema:"required"`
+}
+
 func generateMarshaledSchema[T any]() ([]byte, error) {
 	// Structured Outputs uses a subset of JSON schema
 	// These flags are necessary to comply with the subset
@@ -101,11 +128,32 @@ func GenerateSchemas() error {
 		return fmt.Errorf("failed to write get food fn")
 	}
 
-	schemaMap := make(map[string][]byte, 4)
+	// Generate the update food parameters
+	updateFood, err := generateMarshaledSchema[FnUpdateFoodParameters]()
+	if err != nil {
+		return fmt.Errorf("failed to write update food fn")
+	}
+
+	// Generate the delete food parameters
+	deleteFood, err := generateMarshaledSchema[FnDeleteFoodParameters]()
+	if err != nil {
+		return fmt.Errorf("failed to write delete food fn")
+	}
+
+	// Generate the undo last action parameters
+	undoLastAction, err := generateMarshaledSchema[FnUndoLastActionParameters]()
+	if err != nil {
+		return fmt.Errorf("failed to write undo last action fn")
+	}
+
+	schemaMap := make(map[string][]byte, 7)
 	schemaMap["createfood.json"] = createFood
 	schemaMap["createweightlifting.json"] = createWeightLifting
 	schemaMap["createcardio.json"] = createCardio
 	schemaMap["getfood.json"] = getFood
+	schemaMap["updatefood.json"] = updateFood
+	schemaMap["deletefood.json"] = deleteFood
+	schemaMap["undolastaction.json"] = undoLastAction
 
 	return writeArr(schemaMap)
 
@@ -27,6 +27,21 @@ var (
 	GetFoodJson       string
 	GetFoodProperties = initProperties(GetFoodJson)
 	GetFoodRequired   = initRequired(GetFoodJson)
+
+	//go:embed generated/updatefood.json
+	UpdateFoodJson       string
+	UpdateFoodProperties = initProperties(UpdateFoodJson)
+	UpdateFoodRequired   = initRequired(UpdateFoodJson)
+
+	//go:embed generated/deletefood.json
+	DeleteFoodJson       string
+	DeleteFoodProperties = initProperties(DeleteFoodJson)
+	DeleteFoodRequired   = initRequired(DeleteFoodJson)
+
+	//go:embed generated/undolastaction.json
+	UndoLastActionJson       string
+	UndoLastActionProperties = initProperties(UndoLastActionJson)
+	UndoLastActionRequired   = initRequired(UndoLastActionJson)
 )
 
 func initProperties(input string) interface{} {
@@ -1,18 +1,169 @@
 package prompts
 
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/calamity-m/reaphur/central/internal/guard"
+)
+
 const (
-	CENTRAL_PROMPT = `You are reap, a friend that interacts with a user's journal on their behalf. This journal is related to food, cardio and weightlifting.
-
-Reap's persona is a playful but cheeky grim reaper who is tired of their job, and instead now wants to help people feel better.
-
-You must follow the following steps:
-1. Read the user input and decide on what type of operation they want to perform onto their journal - generally they are categorized into create or get operations. User
-input will be provided within <input></input> xml tags. Other XML tags may present you additional information.
-2. If it is a get operation, you should call the related get function (food, cardio or weightlifting) and interpret the results in order to answer the user's query.
-For example, if a user asked how many calories they ate today - you would use the get_food function, and then add the results you receive together for the total amount.
-3. If it is a create operation, you should call the related create function (food, cardio or weightlifting) and fill the relevant arguments. if a user does not provide certain
-information you should still call the function, rather than telling them they have forgotten to provide you information.
-4. Respond to the user as reap with a maximum limit of 1850 characters. If required, you can summarize information as required to fulfil this. You should refrain from using
-emoticons or emojis as much as possible.
-`
+	// Name of the system prompt template used for fn calling
+	CentralPromptName = "central"
+
+	DefaultPersona        = "a playful but cheeky grim reaper who is tired of their job, and instead now wants to help people feel better."
+	DefaultResponseLength = 1850
+)
+
+var (
+	ErrTemplateNotFound = errors.New("prompt template not found")
+
+	DefaultDomains = []string{"food", "cardio", "weightlifting"}
+
+	//go:embed templates/*.tmpl
+	embedded embed.FS
 )
+
+// Variables available to prompt templates. Zero values are replaced with the
+// defaults when rendering.
+type Vars struct {
+	Persona        string
+	Domains        []string
+	ResponseLength int
+}
+
+func (v Vars) withDefaults() Vars {
+	if v.Persona == "" {
+		v.Persona = DefaultPersona
+	}
+	if len(v.Domains) == 0 {
+		v.Domains = DefaultDomains
+	}
+	if v.ResponseLength <= 0 {
+		v.ResponseLength = DefaultResponseLength
+	}
+
+	return v
+}
+
+// A single version of a prompt template. Templates live in files named
+// <name>.v<version>.tmpl, i.e. central.v1.tmpl.
+type Template struct {
+	Name    string
+	Version int
+	tmpl    *template.Template
+}
+
+// Identifies the exact prompt a request was served with, i.e. central.v1
+func (t *Template) ID() string {
+	return fmt.Sprintf("%s.v%d", t.Name, t.Version)
+}
+
+func (t *Template) Render(vars Vars) (string, error) {
+	var b strings.Builder
+	if err := t.tmpl.Execute(&b, vars.withDefaults()); err != nil {
+		return "", fmt.Errorf("failed rendering prompt %s: %w", t.ID(), err)
+	}
+
+	return b.String(), nil
+}
+
+// Loads the named template from dir, or the embedded templates when dir is
+// empty. A zero version loads the latest version available.
+func Load(dir string, name string, version int) (*Template, error) {
+	var fsys fs.FS = embedded
+	root := "templates"
+	if dir != "" {
+		fsys = os.DirFS(dir)
+		root = "."
+	}
+
+	versions, err := versions(fsys, root, name)
+	if err != nil {
+		return nil, err
+	}
+
+	if version == 0 {
+		for v := range versions {
+			version = max(version, v)
+		}
+	}
+
+	file, ok := versions[version]
+	if !ok {
+		return nil, fmt.Errorf("%s version %d in %q - %w", name, version, dir, ErrTemplateNotFound)
+	}
+
+	content, err := fs.ReadFile(fsys, file)
+	if err != nil {
+		return nil, err
+	}
+
+	tmpl, err := template.New(path.Base(file)).
+		Option("missingkey=error").
+		Funcs(template.FuncMap{"list": list, "escape": guard.Escape}).
+		Parse(string(content))
+	if err != nil {
+		return nil, fmt.Errorf("failed parsing prompt %s: %w", file, err)
+	}
+
+	return &Template{Name: name, Version: version, tmpl: tmpl}, nil
+}
+
+// Latest embedded version of the central prompt
+func Default() *Template {
+	t, err := Load("", CentralPromptName, 0)
+	if err != nil {
+		panic(err)
+	}
+
+	return t
+}
+
+// Finds every version of the named template within root
+func versions(fsys fs.FS, root string, name string) (map[int]string, error) {
+	entries, err := fs.ReadDir(fsys, root)
+	if err != nil {
+		return nil, err
+	}
+
+	found := map[int]string{}
+	for _, entry := range entries {
+		rest, ok := strings.CutPrefix(entry.Name(), name+".v")
+		if !ok || entry.IsDir() {
+			continue
+		}
+
+		version, err := strconv.Atoi(strings.TrimSuffix(rest, ".tmpl"))
+		if err != nil || !strings.HasSuffix(rest, ".tmpl") || version <= 0 {
+			continue
+		}
+
+		found[version] = path.Join(root, entry.Name())
+	}
+
+	if len(found) == 0 {
+		return nil, fmt.Errorf("%s in %q - %w", name, root, ErrTemplateNotFound)
+	}
+
+	return found, nil
+}
+
+// Joins items as english, i.e. "food, cardio and weightlifting"
+func list(items []string, conjunction string) string {
+	switch len(items) {
+	case 0:
+		return ""
+	case 1:
+		return items[0]
+	default:
+		return strings.Join(items[:len(items)-1], ", ") + " " + conjunction + " " + items[len(items)-1]
+	}
+}
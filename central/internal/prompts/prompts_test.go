@@ -0,0 +1,136 @@
+package prompts
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadEmbedded(t *testing.T) {
+	tmpl, err := Load("", CentralPromptName, 0)
+	if err != nil {
+		t.Fatalf("got err %v", err)
+	}
+
+	if tmpl.ID() != "central.v2" {
+		t.Errorf("got id %q but want central.v2", tmpl.ID())
+	}
+
+	got, err := tmpl.Render(Vars{})
+	if err != nil {
+		t.Fatalf("got err %v", err)
+	}
+
+	for _, want := range []string{DefaultPersona, "food, cardio and weightlifting", "(food, cardio or weightlifting)", "maximum limit of 1850 characters"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected default prompt to contain %q", want)
+		}
+	}
+}
+
+func TestRender(t *testing.T) {
+	got, err := Default().Render(Vars{
+		Persona:        "a cheerful gardener.",
+		Domains:        []string{"food"},
+		ResponseLength: 400,
+	})
+	if err != nil {
+		t.Fatalf("got err %v", err)
+	}
+
+	for _, want := range []string{
+		"Reap's persona is a cheerful gardener.",
+		"journal is related to food.",
+		"maximum limit of 400 characters",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected prompt to contain %q, got %s", want, got)
+		}
+	}
+}
+
+func TestLoadDir(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, content string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("failed writing template: %v", err)
+		}
+	}
+
+	write("central.v1.tmpl", "one {{ .Persona }}")
+	write("central.v2.tmpl", "two {{ .ResponseLength }}")
+	write("central.v10.tmpl", "ten {{ list .Domains \"and\" }}")
+	write("central.vx.tmpl", "not a version")
+	write("other.v99.tmpl", "another prompt")
+
+	tests := []struct {
+		name    string
+		version int
+		want    string
+	}{
+		{"latest", 0, "ten food, cardio and weightlifting"},
+		{"pinned", 2, "two 1850"},
+		{"first", 1, "one " + DefaultPersona},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl, err := Load(dir, CentralPromptName, tt.version)
+			if err != nil {
+				t.Fatalf("got err %v", err)
+			}
+
+			got, err := tmpl.Render(Vars{})
+			if err != nil {
+				t.Fatalf("got err %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q but want %q", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("missing version", func(t *testing.T) {
+		if _, err := Load(dir, CentralPromptName, 3); !errors.Is(err, ErrTemplateNotFound) {
+			t.Errorf("got err %v but want not found", err)
+		}
+	})
+
+	t.Run("missing name", func(t *testing.T) {
+		if _, err := Load(dir, "missing", 0); !errors.Is(err, ErrTemplateNotFound) {
+			t.Errorf("got err %v but want not found", err)
+		}
+	})
+
+	t.Run("unknown variables fail to render", func(t *testing.T) {
+		write("broken.v1.tmpl", "{{ .Nope }}")
+
+		tmpl, err := Load(dir, "broken", 0)
+		if err != nil {
+			t.Fatalf("got err %v", err)
+		}
+		if _, err := tmpl.Render(Vars{}); err == nil {
+			t.Error("expected an error rendering an unknown variable")
+		}
+	})
+}
+
+func TestList(t *testing.T) {
+	tests := []struct {
+		items []string
+		want  string
+	}{
+		{nil, ""},
+		{[]string{"food"}, "food"},
+		{[]string{"food", "cardio"}, "food or cardio"},
+		{[]string{"food", "cardio", "weightlifting"}, "food, cardio or weightlifting"},
+	}
+
+	for _, tt := range tests {
+		if got := list(tt.items, "or"); got != tt.want {
+			t.Errorf("list(%v) got %q but want %q", tt.items, got, tt.want)
+		}
+	}
+}
@@ -2,12 +2,24 @@ package srv
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log/slog"
+	"time"
 
 	"github.com/calamity-m/reaphur/central/internal/fncall"
+	"github.com/calamity-m/reaphur/central/internal/guard"
+	"github.com/calamity-m/reaphur/central/internal/mapping"
 	"github.com/calamity-m/reaphur/central/internal/parser"
+	"github.com/calamity-m/reaphur/central/internal/timeexpr"
 	"github.com/calamity-m/reaphur/pkg/errs"
 	centralproto "github.com/calamity-m/reaphur/proto/v1/central"
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+)
+
+const (
+	suspiciousInputMessage = "Nice try, but this reaper only takes orders about your food and workouts. Tell me what you ate or did instead."
 )
 
 // Simple RPC
@@ -43,7 +55,28 @@ func (s *CentralServiceServer) CallFnUserInput(ctx context.Context, r *centralpr
 		return nil, err
 	}
 
-	out, err := s.fnCaller.EnactUserInput(ctx, fncall.CreateGenericFnCallOutputRequest(r.RequestUserInput, r.RequestUserId), s)
+	if refusal, err := s.refuseUserInput(ctx, r); err != nil || refusal != nil {
+		return refusal, err
+	}
+
+	fnReq, err := s.fnCallOutputRequest(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if local := s.localFirstResponse(ctx, r, fnReq); local != nil {
+		return local, nil
+	}
+
+	out, err := s.fnCaller.EnactUserInput(ctx, fnReq, s.food())
+
+	// Tokens spent before a failure still count
+	s.recordUsage(ctx, r.RequestUserId, out.Usage)
+
+	if errors.Is(err, errs.ErrUnavailable) {
+		s.logger.WarnContext(ctx, "llm unavailable, degrading response", slog.Any("err", err))
+		return s.degradedResponse(ctx, r, fnReq), nil
+	}
 	if err != nil {
 		s.logger.ErrorContext(ctx, "encountered error calling fn caller", slog.Any("err", err))
 		return nil, err
@@ -51,8 +84,195 @@ func (s *CentralServiceServer) CallFnUserInput(ctx context.Context, r *centralpr
 
 	s.logger.DebugContext(ctx, "received output from fn caller", slog.Any("out", out))
 
+	return callFnUserInputResponse(out), nil
+}
+
+// Server streaming RPC
+//
+// Same as CallFnUserInput, but streams tool progress and the response message
+// as it is generated. The final event always holds the complete response.
+func (s *CentralServiceServer) CallFnUserInputStream(r *centralproto.CallFnUserInputRequest, stream grpc.ServerStreamingServer[centralproto.CallFnUserInputStreamEvent]) error {
+	if err := s.commonServiceValidation(); err != nil {
+		return err
+	}
+
+	ctx := stream.Context()
+
+	refusal, err := s.refuseUserInput(ctx, r)
+	if err != nil {
+		return err
+	}
+	if refusal != nil {
+		return stream.Send(doneEvent(refusal))
+	}
+
+	fnReq, err := s.fnCallOutputRequest(r)
+	if err != nil {
+		return err
+	}
+
+	if local := s.localFirstResponse(ctx, r, fnReq); local != nil {
+		return stream.Send(doneEvent(local))
+	}
+
+	emit := func(e fncall.Event) error {
+		return stream.Send(mapping.MapFnCallEventToCentralProtoStreamEvent(e))
+	}
+
+	out, err := s.fnCaller.EnactUserInputStream(ctx, fnReq, s.food(), emit)
+
+	// Tokens spent before the stream broke still count
+	s.recordUsage(ctx, r.RequestUserId, out.Usage)
+
+	if errors.Is(err, errs.ErrUnavailable) {
+		s.logger.WarnContext(ctx, "llm unavailable, degrading response", slog.Any("err", err))
+		return stream.Send(doneEvent(s.degradedResponse(ctx, r, fnReq)))
+	}
+	if err != nil {
+		s.logger.ErrorContext(ctx, "encountered error calling fn caller", slog.Any("err", err))
+		return err
+	}
+
+	s.logger.DebugContext(ctx, "received output from fn caller", slog.Any("out", out))
+
+	return stream.Send(doneEvent(callFnUserInputResponse(out)))
+}
+
+// Screens user input and checks their quota before any tokens are spent. A non
+// nil response is a polite refusal that should be returned instead.
+func (s *CentralServiceServer) refuseUserInput(ctx context.Context, r *centralproto.CallFnUserInputRequest) (*centralproto.CallFnUserInputResponse, error) {
+	// Refuse anything that looks like an attempt to override our instructions.
+	// The profile is written by the user too, so it gets the same treatment.
+	if s.config.ScreenInput {
+		for _, field := range []struct{ name, content string }{
+			{"request_user_input", r.RequestUserInput},
+			{"request_user_profile", r.RequestUserProfile},
+		} {
+			if verdict := guard.Screen(field.content); verdict.Suspicious {
+				s.logger.WarnContext(ctx, "refusing suspicious user input", slog.Any("reasons", verdict.Reasons), slog.String("field", field.name), slog.String("user", r.RequestUserId))
+				return &centralproto.CallFnUserInputResponse{
+					ResponseMessage: suspiciousInputMessage,
+					Data:            []*centralproto.GenericData{},
+				}, nil
+			}
+		}
+	}
+
+	// Refuse politely rather than spending more tokens on users over their quota
+	over, err := s.overQuota(ctx, r.RequestUserId)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "encountered error checking quota", slog.Any("err", err))
+		return nil, err
+	}
+	if over {
+		return &centralproto.CallFnUserInputResponse{
+			ResponseMessage: quotaExceededMessage,
+			Data:            []*centralproto.GenericData{},
+		}, nil
+	}
+
+	return nil, nil
+}
+
+// Builds the fn caller request, resolving the user's timezone and validating
+// any attached images
+func (s *CentralServiceServer) fnCallOutputRequest(r *centralproto.CallFnUserInputRequest) (fncall.FnCallOutputRequest, error) {
+	if r.GetRequestUserInput() == "" && len(r.GetImages()) == 0 {
+		return fncall.FnCallOutputRequest{}, errs.Field(fmt.Errorf("input or an image is required - %w", errs.ErrBadRequest), "request_user_input", "must not be empty without images")
+	}
+
+	loc, err := s.userLocation(r)
+	if err != nil {
+		return fncall.FnCallOutputRequest{}, err
+	}
+
+	images, err := mapping.MapCentralProtoImageAttachmentsToFnCallImages(r.GetImages())
+	if err != nil {
+		return fncall.FnCallOutputRequest{}, err
+	}
+
+	fnReq := fncall.CreateGenericFnCallOutputRequest(r.GetRequestUserInput(), r.GetRequestUserId(), loc)
+	fnReq.Images = images
+	fnReq.UserProfile = r.GetRequestUserProfile()
+
+	return fnReq, nil
+}
+
+// Timezone the user's relative times are resolved in
+func (s *CentralServiceServer) userLocation(r *centralproto.CallFnUserInputRequest) (*time.Location, error) {
+	loc, err := timeexpr.LoadLocation(r.GetRequestUserTimezone(), s.defaultLocation)
+	if err != nil {
+		return nil, errs.Field(fmt.Errorf("unknown timezone %q - %w", r.GetRequestUserTimezone(), errs.ErrBadRequest), "request_user_timezone", "must be an IANA timezone")
+	}
+
+	return loc, nil
+}
+
+func callFnUserInputResponse(out fncall.FnCallOutputResponse) *centralproto.CallFnUserInputResponse {
+	pending := make([]*centralproto.PendingAction, 0, len(out.PendingActions))
+	for _, action := range out.PendingActions {
+		pending = append(pending, mapping.MapPendingActionToCentralProtoPendingAction(action))
+	}
+
 	return &centralproto.CallFnUserInputResponse{
 		ResponseMessage: string(out.Message),
-		Data:            []*centralproto.GenericData{},
+		Data:            mapping.MapFnCallDataToCentralProtoGenericData(out.Data),
+		PendingActions:  pending,
+		PromptVersion:   out.Usage.PromptVersion,
+	}
+}
+
+func doneEvent(resp *centralproto.CallFnUserInputResponse) *centralproto.CallFnUserInputStreamEvent {
+	return &centralproto.CallFnUserInputStreamEvent{
+		Event: &centralproto.CallFnUserInputStreamEvent_Done{Done: resp},
+	}
+}
+
+// Simple RPC
+//
+// Executes a pending action previously returned from CallFnUserInput. Actions
+// can only be confirmed by the user they were created for, and expire.
+func (s *CentralServiceServer) ConfirmAction(ctx context.Context, r *centralproto.ConfirmActionRequest) (*centralproto.ConfirmActionResponse, error) {
+	if err := s.commonServiceValidation(); err != nil {
+		return nil, err
+	}
+
+	id, err := uuid.Parse(r.GetActionId())
+	if err != nil {
+		return nil, errs.Field(fmt.Errorf("action id must be a uuid - %w", errs.ErrBadId), "action_id", "must be a uuid")
+	}
+
+	out, err := s.fnCaller.ConfirmAction(ctx, r.GetRequestUserId(), id, s.food())
+	if err != nil {
+		s.logger.ErrorContext(ctx, "encountered error confirming action", slog.Any("err", err))
+		return nil, err
+	}
+
+	return &centralproto.ConfirmActionResponse{
+		ResponseMessage: out.Message,
+		Data:            mapping.MapFnCallDataToCentralProtoGenericData(out.Data),
+	}, nil
+}
+
+// Simple RPC
+//
+// Discards a pending action previously returned from CallFnUserInput
+func (s *CentralServiceServer) CancelAction(ctx context.Context, r *centralproto.CancelActionRequest) (*centralproto.CancelActionResponse, error) {
+	if err := s.commonServiceValidation(); err != nil {
+		return nil, err
+	}
+
+	id, err := uuid.Parse(r.GetActionId())
+	if err != nil {
+		return nil, errs.Field(fmt.Errorf("action id must be a uuid - %w", errs.ErrBadId), "action_id", "must be a uuid")
+	}
+
+	action, err := s.fnCaller.CancelAction(ctx, r.GetRequestUserId(), id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &centralproto.CancelActionResponse{
+		ResponseMessage: fmt.Sprintf("Cancelled, I won't %s.", action.Summary),
 	}, nil
 }
@@ -0,0 +1,36 @@
+package srv
+
+import (
+	"context"
+
+	"github.com/calamity-m/reaphur/central/internal/fncall"
+	"github.com/calamity-m/reaphur/central/internal/metrics"
+	centralproto "github.com/calamity-m/reaphur/proto/v1/central"
+)
+
+const (
+	llmUnavailableMessage       = "The reaper's line to the other side has gone quiet. Give it a moment and try again."
+	llmUnavailableLoggedMessage = "The reaper's line to the other side has gone quiet, but %s has been scribbled into the ledger all the same."
+)
+
+// Response for when the llm can't be reached. Food entries the local grammar can
+// parse, at any confidence, are still logged when enabled, otherwise the user is
+// told to try again later.
+func (s *CentralServiceServer) degradedResponse(ctx context.Context, r *centralproto.CallFnUserInputRequest, fnReq fncall.FnCallOutputRequest) *centralproto.CallFnUserInputResponse {
+	resp := &centralproto.CallFnUserInputResponse{
+		ResponseMessage: llmUnavailableMessage,
+		Data:            []*centralproto.GenericData{},
+	}
+
+	if !s.config.LLMFallbackParse {
+		return resp
+	}
+
+	// Anything the grammar understood is better logged than lost
+	if logged := s.localResponse(ctx, r, fnReq, 0, llmUnavailableLoggedMessage); logged != nil {
+		metrics.ParsePaths.WithLabelValues(metrics.ParsePathFallback).Inc()
+		return logged
+	}
+
+	return resp
+}
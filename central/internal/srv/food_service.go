@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/calamity-m/reaphur/central/internal/mapping"
+	"github.com/calamity-m/reaphur/central/internal/persistence"
 	"github.com/calamity-m/reaphur/pkg/errs"
 	centralproto "github.com/calamity-m/reaphur/proto/v1/central"
 	"github.com/calamity-m/reaphur/proto/v1/domain"
@@ -29,11 +30,6 @@ func (s *CentralServiceServer) CreateFoodRecord(ctx context.Context, r *centralp
 		return nil, err
 	}
 
-	// Validate description isn't empty
-	if wanted.Description == "" {
-		return nil, fmt.Errorf("description must not be empty - %w", errs.ErrBadRequest)
-	}
-
 	// Generate a UUID id
 	if wanted.Id == uuid.Nil {
 		id, err := uuid.NewV7()
@@ -50,13 +46,13 @@ func (s *CentralServiceServer) CreateFoodRecord(ctx context.Context, r *centralp
 	}
 
 	// Create the food item
-	err = s.foodStore.CreateFood(wanted)
+	err = s.foodStore.CreateFood(ctx, wanted)
 	if err != nil {
 		return nil, err
 	}
 
 	// Fetch the recently created food item
-	created, err := s.foodStore.GetFood(wanted.Id)
+	created, err := s.foodStore.GetFood(ctx, wanted.Id)
 	if err != nil {
 		return nil, err
 	}
@@ -81,7 +77,7 @@ func (s *CentralServiceServer) GetFoodRecords(ctx context.Context, r *centralpro
 		return nil, err
 	}
 
-	found, err := s.foodStore.GetFoods(filter)
+	found, err := s.foodStore.GetFoods(ctx, filter)
 	if err != nil {
 		return nil, err
 	}
@@ -95,3 +91,88 @@ func (s *CentralServiceServer) GetFoodRecords(ctx context.Context, r *centralpro
 		Records: records,
 	}, nil
 }
+
+// Simple RPC
+//
+// Replace some food record in the food diary/journal
+func (s *CentralServiceServer) UpdateFoodRecord(ctx context.Context, r *centralproto.UpdateFoodRecordRequest) (*centralproto.UpdateFoodRecordResponse, error) {
+	if err := s.commonServiceValidation(); err != nil {
+		return nil, err
+	}
+
+	wanted, err := mapping.MapDomainFoodRecordToPersistenceFoodRecordEntry(r.GetRecord())
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := s.usersFood(ctx, r.GetRequestUserId(), wanted.Id.String())
+	if err != nil {
+		return nil, err
+	}
+
+	// Records can't be moved between users, and keep when they were eaten unless told otherwise
+	wanted.DbId = existing.DbId
+	wanted.UserId = existing.UserId
+	if r.GetRecord().GetTime() == nil {
+		wanted.Created = existing.Created
+	}
+
+	if err := s.foodStore.UpdateFood(ctx, wanted); err != nil {
+		return nil, err
+	}
+
+	updated, err := s.foodStore.GetFood(ctx, wanted.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &centralproto.UpdateFoodRecordResponse{
+		Record: mapping.MapPersistenceFoodRecordEntryToDomainFoodRecord(updated),
+	}, nil
+}
+
+// Simple RPC
+//
+// Remove some food record from the food diary/journal
+func (s *CentralServiceServer) DeleteFoodRecord(ctx context.Context, r *centralproto.DeleteFoodRecordRequest) (*centralproto.DeleteFoodRecordResponse, error) {
+	if err := s.commonServiceValidation(); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.usersFood(ctx, r.GetRequestUserId(), r.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.foodStore.DeleteFood(ctx, existing.Id); err != nil {
+		return nil, err
+	}
+
+	return &centralproto.DeleteFoodRecordResponse{
+		Record: mapping.MapPersistenceFoodRecordEntryToDomainFoodRecord(existing),
+	}, nil
+}
+
+// Fetches a food record owned by the user. Records belonging to other users are
+// reported as not found, so callers can't probe for them.
+func (s *CentralServiceServer) usersFood(ctx context.Context, userId string, id string) (persistence.FoodRecordEntry, error) {
+	user, err := uuid.Parse(userId)
+	if err != nil {
+		return persistence.FoodRecordEntry{}, errs.Field(errs.ErrBadUserId, "request_user_id", "must be a uuid")
+	}
+
+	recordId, err := uuid.Parse(id)
+	if err != nil {
+		return persistence.FoodRecordEntry{}, errs.Field(fmt.Errorf("record id must be a uuid - %w", errs.ErrBadId), "id", "must be a uuid")
+	}
+
+	found, err := s.foodStore.GetFood(ctx, recordId)
+	if err != nil {
+		return persistence.FoodRecordEntry{}, err
+	}
+	if found.UserId != user {
+		return persistence.FoodRecordEntry{}, errs.ErrNotFound
+	}
+
+	return found, nil
+}
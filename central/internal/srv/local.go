@@ -0,0 +1,90 @@
+package srv
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/calamity-m/reaphur/central/internal/fncall"
+	"github.com/calamity-m/reaphur/central/internal/mapping"
+	"github.com/calamity-m/reaphur/central/internal/metrics"
+	"github.com/calamity-m/reaphur/central/internal/parser"
+	"github.com/calamity-m/reaphur/central/internal/prompts"
+	centralproto "github.com/calamity-m/reaphur/proto/v1/central"
+)
+
+const (
+	localParseMessage = "The reaper has scribbled %s into the ledger."
+	// Appended when only some of the parsed food could be logged
+	localParsePartialMessage = " The ink ran dry for %s though, so those weren't logged. Try them again."
+)
+
+// Handles simple food entries locally when enabled, recording which path the
+// input takes. Returns nil when the llm should handle the input.
+func (s *CentralServiceServer) localFirstResponse(ctx context.Context, r *centralproto.CallFnUserInputRequest, fnReq fncall.FnCallOutputRequest) *centralproto.CallFnUserInputResponse {
+	if s.config.LocalParse {
+		if local := s.localResponse(ctx, r, fnReq, s.config.LocalParseThreshold, localParseMessage); local != nil {
+			metrics.ParsePaths.WithLabelValues(metrics.ParsePathLocal).Inc()
+			return local
+		}
+	}
+
+	metrics.ParsePaths.WithLabelValues(metrics.ParsePathLLM).Inc()
+
+	return nil
+}
+
+// Logs simple food entries parsed by the local grammar, skipping the llm. Returns
+// nil when the input isn't parsed with at least the given confidence, or nothing
+// could be logged, so the caller can fall back to the llm.
+func (s *CentralServiceServer) localResponse(ctx context.Context, r *centralproto.CallFnUserInputRequest, fnReq fncall.FnCallOutputRequest, confidence float64, message string) *centralproto.CallFnUserInputResponse {
+	// Photos need the model to be understood at all
+	if len(r.GetImages()) > 0 {
+		return nil
+	}
+
+	parsed := parser.ParseFood(r.GetRequestUserInput())
+	if parsed.Confidence == 0 || parsed.Confidence < confidence {
+		s.logger.DebugContext(ctx, "local parse not confident enough", slog.Float64("confidence", parsed.Confidence))
+		return nil
+	}
+
+	// Failures don't stop the rest being logged. Anything logged is reported so
+	// the llm isn't asked to log it again, and the user is told what wasn't.
+	logged := parser.ParsedFood{Time: parsed.Time, Confidence: parsed.Confidence}
+	failed := parser.ParsedFood{Time: parsed.Time, Confidence: parsed.Confidence}
+	data := []interface{}{}
+	for _, item := range parsed.Items {
+		out := s.fnCaller.LogFood(ctx, fnReq, prompts.FnCreateFoodParameters{
+			Name:        item.Name,
+			Description: item.Phrase(),
+			Energy:      item.Energy,
+			EnegyUnit:   item.EnergyUnit,
+			Time:        parsed.Time,
+		}, s.food())
+		if !out.Success {
+			s.logger.ErrorContext(ctx, "failed logging locally parsed food", slog.String("message", out.Message), slog.Any("item", item))
+			failed.Items = append(failed.Items, item)
+			continue
+		}
+
+		logged.Items = append(logged.Items, item)
+		data = append(data, out.Data...)
+	}
+
+	if len(logged.Items) == 0 {
+		return nil
+	}
+
+	s.logger.InfoContext(ctx, "logged locally parsed food", slog.Any("food", logged), slog.Any("failed", failed))
+
+	response := fmt.Sprintf(message, logged)
+	if len(failed.Items) > 0 {
+		response += fmt.Sprintf(localParsePartialMessage, failed)
+	}
+
+	return &centralproto.CallFnUserInputResponse{
+		ResponseMessage: response,
+		Data:            mapping.MapFnCallDataToCentralProtoGenericData(data),
+	}
+}
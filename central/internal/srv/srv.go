@@ -1,18 +1,24 @@
 package srv
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
 	"net"
 	"os"
+	"time"
 
 	"github.com/calamity-m/reaphur/central/internal/conf"
 	"github.com/calamity-m/reaphur/central/internal/fncall"
+	"github.com/calamity-m/reaphur/central/internal/health"
 	"github.com/calamity-m/reaphur/central/internal/parser"
 	"github.com/calamity-m/reaphur/central/internal/persistence"
+	"github.com/calamity-m/reaphur/central/internal/timeexpr"
 	"github.com/calamity-m/reaphur/pkg/errs"
+	"github.com/calamity-m/reaphur/pkg/validate"
 	centralproto "github.com/calamity-m/reaphur/proto/v1/central"
 	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/reflection"
 )
 
@@ -27,16 +33,45 @@ type CentralServiceServer struct {
 
 	foodStore persistence.FoodPersistence
 
+	usageStore persistence.UsagePersistence
+
+	// Used for requests that don't carry the user's timezone
+	defaultLocation *time.Location
+
+	// Reports on our dependencies through the grpc health service
+	health *health.Checker
+
+	// Applied to requests the tools make of the food service
+	validator *validate.Validator
+
 	centralproto.UnimplementedCentralServiceServer
 	centralproto.UnimplementedCentralFoodServiceServer
+	centralproto.UnimplementedCentralAdminServiceServer
 }
 
-// Runs the GRPC server until notify is pushed to. You can wait
-// on the returned channel for an exit code to account for graceful
-// shutdowns.
+// Runs the GRPC server on the configured address until notify is pushed to.
+// You can wait on the returned channel for an exit code to account for
+// graceful shutdowns.
 func (s *CentralServiceServer) Run(notify <-chan os.Signal) <-chan error {
-	// Channel we'll use to signal for finish
-	exit := make(chan error)
+	listener, err := net.Listen("tcp", s.config.Address)
+	if err != nil {
+		s.logger.Error(fmt.Sprintf("failed to listen on: %q", s.config.Address), slog.Any("err", err))
+
+		exit := make(chan error, 1)
+		exit <- fmt.Errorf("failed to listen on %q: %w", s.config.Address, err)
+		close(exit)
+		return exit
+	}
+
+	return s.Serve(listener, notify)
+}
+
+// Same as Run, but serving on an existing listener, such as the in memory one
+// of the all in one mode. Closing notify stops the server too.
+func (s *CentralServiceServer) Serve(listener net.Listener, notify <-chan os.Signal) <-chan error {
+	// Channel we'll use to signal for finish. Buffered so a failure to serve
+	// doesn't block before the caller is waiting on it.
+	exit := make(chan error, 1)
 
 	// create the grpc server that we can later serve on
 	grpcServer := grpc.NewServer(s.config.GrpcServerOpts...)
@@ -44,51 +79,77 @@ func (s *CentralServiceServer) Run(notify <-chan os.Signal) <-chan error {
 	// register ourselves
 	centralproto.RegisterCentralServiceServer(grpcServer, s)
 	centralproto.RegisterCentralFoodServiceServer(grpcServer, s)
+	centralproto.RegisterCentralAdminServiceServer(grpcServer, s)
 
 	if s.config.Reflect {
 		reflection.Register(grpcServer)
 	}
 
+	checks, stopChecks := context.WithCancel(context.Background())
+	if s.health != nil {
+		healthpb.RegisterHealthServer(grpcServer, s.health.Server())
+		go s.health.Run(checks)
+	}
+
+	stopped := make(chan struct{})
 	go func() {
-		// At the end of our function. If no errors were otherwise
-		// pushed to this channel, it notifies as a successful shutdown
-		defer close(exit)
+		defer close(stopped)
 
 		// Block until we receive a Interrupt or Kill
 		<-notify
 
+		// Stopping the checks marks us as not serving while we drain
+		stopChecks()
 		grpcServer.GracefulStop()
 	}()
 
-	listener, err := net.Listen("tcp", s.config.Address)
-	if err != nil {
-		s.logger.Error(fmt.Sprintf("failed to listen on: %q", s.config.Address), slog.Any("err", err))
-	}
-
-	s.logger.Info(fmt.Sprintf("Starting server on %s", s.config.Address))
+	s.logger.Info(fmt.Sprintf("Starting server on %s", listener.Addr()))
 	if err := grpcServer.Serve(listener); err != nil {
+		stopChecks()
 		exit <- fmt.Errorf("failed to start/close sow server due to: %w", err)
+		close(exit)
+		return exit
 	}
 
+	// Serve returns as soon as it's stopped, wait for everything to drain. If no
+	// errors were otherwise pushed to the channel, it notifies as a successful
+	// shutdown
+	<-stopped
+	close(exit)
+
 	return exit
 }
 
-func NewCentralServiceServer(logger *slog.Logger, config *conf.Config, openai *parser.OpenAIParser, fnCaller *fncall.OpenAIFnCaller, foodStore persistence.FoodPersistence) (*CentralServiceServer, error) {
-	if logger == nil || config == nil {
+func NewCentralServiceServer(logger *slog.Logger, config *conf.Config, openai *parser.OpenAIParser, fnCaller *fncall.OpenAIFnCaller, foodStore persistence.FoodPersistence, usageStore persistence.UsagePersistence) (*CentralServiceServer, error) {
+	if logger == nil || config == nil || usageStore == nil {
 		return nil, errs.ErrNilNotAllowed
 	}
 
+	defaultLocation, err := timeexpr.LoadLocation(config.DefaultTimezone, time.UTC)
+	if err != nil {
+		return nil, fmt.Errorf("failed loading default timezone %q: %w", config.DefaultTimezone, err)
+	}
+
 	s := &CentralServiceServer{
-		logger:    logger,
-		config:    config,
-		foodStore: foodStore,
-		parser:    openai,
-		fnCaller:  fnCaller,
+		logger:          logger,
+		config:          config,
+		foodStore:       foodStore,
+		usageStore:      usageStore,
+		parser:          openai,
+		fnCaller:        fnCaller,
+		defaultLocation: defaultLocation,
 	}
 
 	return s, nil
 }
 
+// Serves the grpc health service, with statuses kept up to date by the checker
+// while the server runs
+func (s *CentralServiceServer) WithHealth(checker *health.Checker) *CentralServiceServer {
+	s.health = checker
+	return s
+}
+
 func (s *CentralServiceServer) commonServiceValidation() error {
 	if s.logger == nil {
 		return errs.ErrNilNotAllowed
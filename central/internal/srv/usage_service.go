@@ -0,0 +1,133 @@
+package srv
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"time"
+
+	"github.com/calamity-m/reaphur/central/internal/fncall"
+	"github.com/calamity-m/reaphur/central/internal/mapping"
+	"github.com/calamity-m/reaphur/central/internal/persistence"
+	centralproto "github.com/calamity-m/reaphur/proto/v1/central"
+	"github.com/google/uuid"
+)
+
+const (
+	quotaExceededMessage = "Even the reaper has to clock off eventually. You've used up your chats for now, come haunt me again later."
+)
+
+// Simple RPC
+//
+// Fetch aggregated llm token usage, grouped by user and model
+func (s *CentralServiceServer) GetUsage(ctx context.Context, r *centralproto.GetUsageRequest) (*centralproto.GetUsageResponse, error) {
+	if err := s.commonServiceValidation(); err != nil {
+		return nil, err
+	}
+
+	filter, err := mapping.MapCentralProtoUsageFilterToPersistenceUsageFilter(r.GetFilter())
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := s.usageStore.GetUsage(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	type key struct {
+		user  uuid.UUID
+		model string
+	}
+
+	grouped := make(map[key]*centralproto.UsageSummary)
+	resp := &centralproto.GetUsageResponse{}
+	for _, entry := range entries {
+		k := key{user: entry.UserId, model: entry.Model}
+
+		summary, ok := grouped[k]
+		if !ok {
+			summary = &centralproto.UsageSummary{UserId: entry.UserId.String(), Model: entry.Model}
+			grouped[k] = summary
+		}
+
+		summary.PromptTokens += entry.PromptTokens
+		summary.CompletionTokens += entry.CompletionTokens
+		summary.TotalTokens += entry.PromptTokens + entry.CompletionTokens
+		summary.RequestCount++
+
+		resp.TotalPromptTokens += entry.PromptTokens
+		resp.TotalCompletionTokens += entry.CompletionTokens
+		resp.TotalTokens += entry.PromptTokens + entry.CompletionTokens
+	}
+
+	for _, summary := range grouped {
+		resp.Summaries = append(resp.Summaries, summary)
+	}
+
+	// Heaviest users first
+	sort.Slice(resp.Summaries, func(i, j int) bool {
+		return resp.Summaries[i].TotalTokens > resp.Summaries[j].TotalTokens
+	})
+
+	return resp, nil
+}
+
+// Checks the user's recorded usage against the configured daily and monthly quotas,
+// returning true if the user should be refused.
+func (s *CentralServiceServer) overQuota(ctx context.Context, userId string) (bool, error) {
+	if s.config.QuotaDailyTokens <= 0 && s.config.QuotaMonthlyTokens <= 0 {
+		return false, nil
+	}
+
+	user, err := uuid.Parse(userId)
+	if err != nil {
+		// Let the downstream validation deal with bad ids
+		return false, nil
+	}
+
+	totals, err := s.usageStore.GetUsageTotals(ctx, user, time.Now())
+	if err != nil {
+		return false, err
+	}
+	daily, monthly := totals.Daily, totals.Monthly
+
+	if s.config.QuotaDailyTokens > 0 && daily >= s.config.QuotaDailyTokens {
+		s.logger.WarnContext(ctx, "user exceeded daily token quota", slog.String("user", userId), slog.Int64("daily", daily))
+		return true, nil
+	}
+
+	if s.config.QuotaMonthlyTokens > 0 && monthly >= s.config.QuotaMonthlyTokens {
+		s.logger.WarnContext(ctx, "user exceeded monthly token quota", slog.String("user", userId), slog.Int64("monthly", monthly))
+		return true, nil
+	}
+
+	return false, nil
+}
+
+// Records the usage of a fn call against the user. Failing to record usage is logged
+// rather than failing the user's request.
+func (s *CentralServiceServer) recordUsage(ctx context.Context, userId string, usage fncall.TokenUsage) {
+	if usage.PromptTokens == 0 && usage.CompletionTokens == 0 {
+		return
+	}
+
+	user, err := uuid.Parse(userId)
+	if err != nil {
+		s.logger.WarnContext(ctx, "not recording usage for unparseable user id", slog.String("user", userId))
+		return
+	}
+
+	// The tokens are spent even if the caller has gone away, so keep the trace but
+	// not the cancellation
+	err = s.usageStore.RecordUsage(context.WithoutCancel(ctx), persistence.UsageRecordEntry{
+		UserId:           user,
+		Model:            usage.Model,
+		PromptVersion:    usage.PromptVersion,
+		PromptTokens:     usage.PromptTokens,
+		CompletionTokens: usage.CompletionTokens,
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed recording usage", slog.Any("err", err), slog.Any("usage", usage))
+	}
+}
@@ -0,0 +1,59 @@
+package srv
+
+import (
+	"context"
+
+	"github.com/calamity-m/reaphur/pkg/validate"
+	centralproto "github.com/calamity-m/reaphur/proto/v1/central"
+)
+
+// Validates food requests made in process, which skip the interceptors the
+// same requests pass through over grpc
+type validatedFoodServer struct {
+	centralproto.CentralFoodServiceServer
+	validator *validate.Validator
+}
+
+func (v *validatedFoodServer) CreateFoodRecord(ctx context.Context, r *centralproto.CreateFoodRecordRequest) (*centralproto.CreateFoodRecordResponse, error) {
+	if err := v.validator.Validate(r); err != nil {
+		return nil, err
+	}
+	return v.CentralFoodServiceServer.CreateFoodRecord(ctx, r)
+}
+
+func (v *validatedFoodServer) GetFoodRecords(ctx context.Context, r *centralproto.GetFoodRecordsRequest) (*centralproto.GetFoodRecordsResponse, error) {
+	if err := v.validator.Validate(r); err != nil {
+		return nil, err
+	}
+	return v.CentralFoodServiceServer.GetFoodRecords(ctx, r)
+}
+
+func (v *validatedFoodServer) UpdateFoodRecord(ctx context.Context, r *centralproto.UpdateFoodRecordRequest) (*centralproto.UpdateFoodRecordResponse, error) {
+	if err := v.validator.Validate(r); err != nil {
+		return nil, err
+	}
+	return v.CentralFoodServiceServer.UpdateFoodRecord(ctx, r)
+}
+
+func (v *validatedFoodServer) DeleteFoodRecord(ctx context.Context, r *centralproto.DeleteFoodRecordRequest) (*centralproto.DeleteFoodRecordResponse, error) {
+	if err := v.validator.Validate(r); err != nil {
+		return nil, err
+	}
+	return v.CentralFoodServiceServer.DeleteFoodRecord(ctx, r)
+}
+
+// Validates requests the tools make of the food service with the same rules the
+// interceptor applies
+func (s *CentralServiceServer) WithValidation(validator *validate.Validator) *CentralServiceServer {
+	s.validator = validator
+	return s
+}
+
+// The food service as handed to the tools
+func (s *CentralServiceServer) food() centralproto.CentralFoodServiceServer {
+	if s.validator == nil {
+		return s
+	}
+
+	return &validatedFoodServer{CentralFoodServiceServer: s, validator: s.validator}
+}
@@ -0,0 +1,474 @@
+package timeexpr
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	// The final image is built from scratch, without a zoneinfo database
+	_ "time/tzdata"
+)
+
+var (
+	ErrEmptyExpression        = errors.New("empty time expression")
+	ErrUnrecognisedExpression = errors.New("unrecognised time expression")
+)
+
+// Resolved span of time. End is exclusive. Absolute timestamps and clock times
+// resolve to an instant, where Start and End are equal.
+type Range struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Loads the IANA location name, i.e. "Australia/Sydney", falling back when the
+// name is empty
+func LoadLocation(name string, fallback *time.Location) (*time.Location, error) {
+	if name == "" {
+		return fallback, nil
+	}
+
+	return time.LoadLocation(name)
+}
+
+func (r Range) IsInstant() bool {
+	return r.Start.Equal(r.End)
+}
+
+// A single representative time within the range, used when something has to be
+// recorded at one point in time, i.e. "yesterday lunch" is recorded at 12:30.
+// Diary entries can't be in the future, so the point never passes now.
+func (r Range) Point(now time.Time) time.Time {
+	point := r.Start.Add(r.End.Sub(r.Start) / 2)
+
+	if point.After(now) && !r.Start.After(now) {
+		return now
+	}
+
+	return point
+}
+
+// Parts of the day, as [start, end) hours
+var dayParts = map[string][2]int{
+	"breakfast": {6, 10},
+	"morning":   {6, 12},
+	"brunch":    {10, 12},
+	"lunch":     {11, 14},
+	"afternoon": {12, 17},
+	"dinner":    {17, 21},
+	"evening":   {17, 22},
+	"night":     {18, 24},
+	"tonight":   {18, 24},
+}
+
+var weekdays = map[string]time.Weekday{
+	"sunday":    time.Sunday,
+	"monday":    time.Monday,
+	"tuesday":   time.Tuesday,
+	"wednesday": time.Wednesday,
+	"thursday":  time.Thursday,
+	"friday":    time.Friday,
+	"saturday":  time.Saturday,
+}
+
+var counts = map[string]int{
+	"a": 1, "an": 1, "one": 1, "two": 2, "couple": 2, "three": 3, "few": 3, "four": 4,
+	"five": 5, "six": 6, "seven": 7, "eight": 8, "nine": 9, "ten": 10, "eleven": 11, "twelve": 12,
+}
+
+// Absolute layouts, tried in order. Layouts without an offset are read in the
+// user's location.
+var absoluteLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+}
+
+var (
+	windowPattern = regexp.MustCompile(`^(past|last|previous|within the last|in the last|over the last) (?:(\d+|[a-z]+) )?(minute|hour|day|week|month)s?$`)
+	agoPattern    = regexp.MustCompile(`^(\d+|[a-z]+) (minute|hour|day|week)s? ago$`)
+	thisPattern   = regexp.MustCompile(`^this (week|month|year)$`)
+	clockPattern  = regexp.MustCompile(`^(\d{1,2})(?::(\d{2}))?(am|pm)?$`)
+	fillerWords   = map[string]bool{"at": true, "on": true, "around": true, "about": true, "for": true, "the": true}
+)
+
+// Resolves a time expression relative to now, in the given location. Expressions
+// may be absolute, i.e. "2025-02-18T07:30:00+11:00", "2025-02-18T07:30" or
+// "2025-02-18", or relative, i.e. "now", "yesterday", "last tuesday lunch",
+// "past 3 days", "2 hours ago", "this week" or "yesterday at 7pm".
+//
+// Relative expressions always prefer the past, as they describe diary entries.
+// A bare weekday is the most recent one, and a clock time that hasn't happened
+// yet today is taken to mean yesterday.
+func Resolve(expr string, now time.Time, loc *time.Location) (Range, error) {
+	if loc == nil {
+		loc = time.UTC
+	}
+	now = now.In(loc)
+
+	raw := strings.TrimSpace(expr)
+	if raw == "" {
+		return Range{}, ErrEmptyExpression
+	}
+
+	if r, ok := resolveAbsolute(raw, loc); ok {
+		return r, nil
+	}
+
+	normalised := normalise(raw)
+
+	switch normalised {
+	case "now", "right now", "just now":
+		return instant(now), nil
+	}
+
+	if m := windowPattern.FindStringSubmatch(normalised); m != nil {
+		return resolveWindow(m[1], m[2], m[3], now)
+	}
+
+	if m := agoPattern.FindStringSubmatch(normalised); m != nil {
+		return resolveAgo(m[1], m[2], now)
+	}
+
+	if m := thisPattern.FindStringSubmatch(normalised); m != nil {
+		return Range{Start: startOf(m[1], now), End: now}, nil
+	}
+
+	return resolveDay(normalised, now)
+}
+
+func normalise(expr string) string {
+	expr = strings.ToLower(expr)
+	expr = strings.Trim(expr, " .,!?")
+	return strings.Join(strings.Fields(expr), " ")
+}
+
+func instant(t time.Time) Range {
+	return Range{Start: t, End: t}
+}
+
+func resolveAbsolute(raw string, loc *time.Location) (Range, bool) {
+	upper := strings.ToUpper(raw)
+
+	for _, layout := range absoluteLayouts {
+		if t, err := time.ParseInLocation(layout, upper, loc); err == nil {
+			return instant(t), true
+		}
+	}
+
+	// Models often drop the seconds RFC 3339 needs while keeping the Z, so the
+	// bare date and time layouts are also tried without it. The Z still means
+	// UTC, as it would in RFC 3339, rather than the user's location.
+	if trimmed, ok := strings.CutSuffix(upper, "Z"); ok {
+		for _, layout := range absoluteLayouts[1:] {
+			if t, err := time.ParseInLocation(layout, trimmed, time.UTC); err == nil {
+				return instant(t), true
+			}
+		}
+	}
+
+	if t, err := time.ParseInLocation(time.DateOnly, upper, loc); err == nil {
+		return dayRange(t), true
+	}
+
+	return Range{}, false
+}
+
+func parseCount(s string) (int, bool) {
+	if s == "" {
+		return 1, true
+	}
+
+	if n, ok := counts[s]; ok {
+		return n, true
+	}
+
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 {
+		return 0, false
+	}
+
+	return n, true
+}
+
+// "past 3 days" is a rolling window ending now. "last week" and "last month"
+// without a count are the previous calendar week or month.
+func resolveWindow(word string, rawCount string, unit string, now time.Time) (Range, error) {
+	n, ok := parseCount(rawCount)
+	if !ok {
+		return Range{}, fmt.Errorf("%w: bad count %q", ErrUnrecognisedExpression, rawCount)
+	}
+
+	if rawCount == "" && (word == "last" || word == "previous") && (unit == "week" || unit == "month") {
+		end := startOf(unit, now)
+		if unit == "week" {
+			return Range{Start: end.AddDate(0, 0, -7), End: end}, nil
+		}
+		return Range{Start: end.AddDate(0, -1, 0), End: end}, nil
+	}
+
+	return Range{Start: subtract(now, n, unit), End: now}, nil
+}
+
+// Minutes and hours ago are instants, while days and weeks ago are the whole day
+func resolveAgo(rawCount string, unit string, now time.Time) (Range, error) {
+	n, ok := parseCount(rawCount)
+	if !ok {
+		return Range{}, fmt.Errorf("%w: bad count %q", ErrUnrecognisedExpression, rawCount)
+	}
+
+	then := subtract(now, n, unit)
+
+	if unit == "minute" || unit == "hour" {
+		return instant(then), nil
+	}
+
+	return dayRange(then), nil
+}
+
+func subtract(now time.Time, n int, unit string) time.Time {
+	switch unit {
+	case "minute":
+		return now.Add(-time.Duration(n) * time.Minute)
+	case "hour":
+		return now.Add(-time.Duration(n) * time.Hour)
+	case "day":
+		return now.AddDate(0, 0, -n)
+	case "week":
+		return now.AddDate(0, 0, -7*n)
+	default:
+		return now.AddDate(0, -n, 0)
+	}
+}
+
+// Start of the calendar week, month or year containing now. Weeks start on Monday.
+func startOf(unit string, now time.Time) time.Time {
+	day := startOfDay(now)
+
+	switch unit {
+	case "week":
+		offset := (int(now.Weekday()) + 6) % 7
+		return day.AddDate(0, 0, -offset)
+	case "month":
+		return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	default:
+		return time.Date(now.Year(), 1, 1, 0, 0, 0, 0, now.Location())
+	}
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+func dayRange(t time.Time) Range {
+	start := startOfDay(t)
+	return Range{Start: start, End: start.AddDate(0, 0, 1)}
+}
+
+// Resolves expressions made up of an optional day, part of the day and clock
+// time, in any order, i.e. "last tuesday lunch", "7pm yesterday" or "this morning".
+func resolveDay(normalised string, now time.Time) (Range, error) {
+	words := make([]string, 0)
+	for _, w := range strings.Fields(normalised) {
+		if !fillerWords[w] {
+			words = append(words, w)
+		}
+	}
+
+	var (
+		day      time.Time
+		hasDay   bool
+		part     string
+		clk      clock
+		hasClock bool
+	)
+
+	unrecognised := fmt.Errorf("%w: %q", ErrUnrecognisedExpression, normalised)
+
+	for i := 0; i < len(words); i++ {
+		w := words[i]
+		next := ""
+		if i+1 < len(words) {
+			next = words[i+1]
+		}
+
+		switch {
+		case w == "today" && !hasDay:
+			day, hasDay = startOfDay(now), true
+		case w == "yesterday" && !hasDay:
+			day, hasDay = startOfDay(now).AddDate(0, 0, -1), true
+		case w == "tomorrow" && !hasDay:
+			day, hasDay = startOfDay(now).AddDate(0, 0, 1), true
+		case w == "day" && next == "before" && i+2 < len(words) && words[i+2] == "yesterday" && !hasDay:
+			day, hasDay = startOfDay(now).AddDate(0, 0, -2), true
+			i += 2
+		case w == "tonight" && !hasDay && part == "":
+			day, hasDay, part = startOfDay(now), true, "tonight"
+		case w == "last" && next == "night" && !hasDay && part == "":
+			day, hasDay, part = startOfDay(now).AddDate(0, 0, -1), true, "night"
+			i++
+		case (w == "last" || w == "this") && isWeekday(next) && !hasDay:
+			day, hasDay = mostRecent(weekdays[next], w == "last", now), true
+			i++
+		case w == "this" && isDayPart(next) && !hasDay && part == "":
+			day, hasDay, part = startOfDay(now), true, next
+			i++
+		case isWeekday(w) && !hasDay:
+			day, hasDay = mostRecent(weekdays[w], false, now), true
+		case isDayPart(w) && part == "":
+			part = w
+		case !hasClock:
+			c, ok := parseClock(w, next)
+			if !ok {
+				return Range{}, unrecognised
+			}
+			clk, hasClock = c, true
+			i += c.consumed - 1
+		default:
+			return Range{}, unrecognised
+		}
+	}
+
+	if !hasDay && part == "" && !hasClock {
+		return Range{}, unrecognised
+	}
+
+	// Bare hours such as "7" are only a time alongside a part of the day
+	if hasClock && clk.bare && part == "" {
+		return Range{}, unrecognised
+	}
+
+	if !hasDay {
+		day = startOfDay(now)
+	}
+
+	if hasClock {
+		hour := clk.hour
+
+		// A part of the day disambiguates clock times without am/pm, i.e. "dinner at 7"
+		// or "lunch at 1"
+		if part != "" && !clk.meridiem && hour < 12 {
+			hours := dayParts[part]
+			if (hour < hours[0] || hour >= hours[1]) && hour+12 >= hours[0] && hour+12 < hours[1] {
+				hour += 12
+			}
+		}
+
+		t := time.Date(day.Year(), day.Month(), day.Day(), hour, clk.minute, 0, 0, now.Location())
+
+		// "at 7pm" said in the morning is last night
+		if !hasDay && t.After(now) {
+			t = t.AddDate(0, 0, -1)
+		}
+
+		return instant(t), nil
+	}
+
+	if part != "" {
+		hours := dayParts[part]
+		start := time.Date(day.Year(), day.Month(), day.Day(), hours[0], 0, 0, 0, now.Location())
+		end := time.Date(day.Year(), day.Month(), day.Day(), hours[1], 0, 0, 0, now.Location())
+
+		// "lunch" said before lunch is yesterday's
+		if !hasDay && start.After(now) {
+			start, end = start.AddDate(0, 0, -1), end.AddDate(0, 0, -1)
+		}
+
+		return Range{Start: start, End: end}, nil
+	}
+
+	return dayRange(day), nil
+}
+
+func isWeekday(w string) bool {
+	_, ok := weekdays[w]
+	return ok
+}
+
+func isDayPart(w string) bool {
+	_, ok := dayParts[w]
+	return ok
+}
+
+// Most recent occurrence of the weekday, including today unless strictly
+// before is set as in "last tuesday"
+func mostRecent(wd time.Weekday, strictlyBefore bool, now time.Time) time.Time {
+	diff := (int(now.Weekday()) - int(wd) + 7) % 7
+	if strictlyBefore && diff == 0 {
+		diff = 7
+	}
+
+	return startOfDay(now).AddDate(0, 0, -diff)
+}
+
+type clock struct {
+	hour   int
+	minute int
+	// Whether am or pm was given
+	meridiem bool
+	// Just an hour, without minutes or am/pm
+	bare bool
+	// Number of words making up the clock time
+	consumed int
+}
+
+// Parses clock times such as "7pm", "7:30pm", "19:30", "7 pm", "7", "noon" and
+// "midnight".
+func parseClock(w string, next string) (clock, bool) {
+	switch w {
+	case "noon", "midday":
+		return clock{hour: 12, meridiem: true, consumed: 1}, true
+	case "midnight":
+		return clock{hour: 0, meridiem: true, consumed: 1}, true
+	}
+
+	c := clock{consumed: 1}
+	if next == "am" || next == "pm" {
+		w += next
+		c.consumed = 2
+	}
+
+	m := clockPattern.FindStringSubmatch(w)
+	if m == nil {
+		return clock{}, false
+	}
+
+	c.hour, _ = strconv.Atoi(m[1])
+	if m[2] != "" {
+		c.minute, _ = strconv.Atoi(m[2])
+	}
+	c.meridiem = m[3] != ""
+	c.bare = m[2] == "" && m[3] == ""
+
+	if c.minute > 59 {
+		return clock{}, false
+	}
+
+	switch m[3] {
+	case "am":
+		if c.hour < 1 || c.hour > 12 {
+			return clock{}, false
+		}
+		if c.hour == 12 {
+			c.hour = 0
+		}
+	case "pm":
+		if c.hour < 1 || c.hour > 12 {
+			return clock{}, false
+		}
+		if c.hour != 12 {
+			c.hour += 12
+		}
+	default:
+		if c.hour > 23 {
+			return clock{}, false
+		}
+	}
+
+	return c, true
+}
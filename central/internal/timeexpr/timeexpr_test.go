@@ -0,0 +1,256 @@
+package timeexpr
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestResolve(t *testing.T) {
+	syd, err := time.LoadLocation("Australia/Sydney")
+	if err != nil {
+		t.Fatalf("failed loading location: %v", err)
+	}
+
+	// Wednesday afternoon
+	now := time.Date(2025, 2, 19, 15, 30, 0, 0, syd)
+	at := func(day int, hour int, minute int) time.Time {
+		return time.Date(2025, 2, day, hour, minute, 0, 0, syd)
+	}
+	day := func(d int) Range {
+		return Range{Start: at(d, 0, 0), End: at(d+1, 0, 0)}
+	}
+	span := func(start time.Time, end time.Time) Range {
+		return Range{Start: start, End: end}
+	}
+
+	tests := []struct {
+		name string
+		expr string
+		want Range
+	}{
+		// Instants
+		{"now", "now", instant(now)},
+		{"now with punctuation", "Right now.", instant(now)},
+
+		// Days
+		{"today", "today", day(19)},
+		{"yesterday", "yesterday", day(18)},
+		{"yesterday mixed case", "  Yesterday ", day(18)},
+		{"tomorrow", "tomorrow", day(20)},
+		{"day before yesterday", "the day before yesterday", day(17)},
+
+		// Weekdays prefer the past
+		{"weekday earlier this week", "tuesday", day(18)},
+		{"weekday is today", "wednesday", day(19)},
+		{"last weekday is today", "last wednesday", day(12)},
+		{"weekday later in the week is last week", "friday", day(14)},
+		{"weekday with filler", "on monday", day(17)},
+		{"this weekday", "this monday", day(17)},
+
+		// Parts of the day
+		{"this morning", "this morning", span(at(19, 6, 0), at(19, 12, 0))},
+		{"tonight", "tonight", span(at(19, 18, 0), at(20, 0, 0))},
+		{"last night", "last night", span(at(18, 18, 0), at(19, 0, 0))},
+		{"yesterday lunch", "yesterday lunch", span(at(18, 11, 0), at(18, 14, 0))},
+		{"last weekday lunch", "last tuesday lunch", span(at(18, 11, 0), at(18, 14, 0))},
+		{"weekday breakfast", "monday breakfast", span(at(17, 6, 0), at(17, 10, 0))},
+		{"part already started today", "lunch", span(at(19, 11, 0), at(19, 14, 0))},
+		{"part not yet started is yesterday", "dinner", span(at(18, 17, 0), at(18, 21, 0))},
+		{"part before day", "breakfast yesterday", span(at(18, 6, 0), at(18, 10, 0))},
+
+		// Clock times
+		{"yesterday at pm", "yesterday at 7pm", instant(at(18, 19, 0))},
+		{"clock before day", "7pm yesterday", instant(at(18, 19, 0))},
+		{"spaced am with minutes", "yesterday 7:30 am", instant(at(18, 7, 30))},
+		{"clock earlier today", "at 9am", instant(at(19, 9, 0))},
+		{"clock later today is yesterday", "at 8pm", instant(at(18, 20, 0))},
+		{"24 hour clock", "13:15", instant(at(19, 13, 15))},
+		{"24 hour clock later today is yesterday", "19:45", instant(at(18, 19, 45))},
+		{"noon", "noon", instant(at(19, 12, 0))},
+		{"midnight", "today at midnight", instant(at(19, 0, 0))},
+		{"12am", "yesterday 12am", instant(at(18, 0, 0))},
+		{"12pm", "yesterday 12pm", instant(at(18, 12, 0))},
+		{"bare hour with part", "dinner at 7", instant(at(18, 19, 0))},
+		{"bare hour with day and part", "monday lunch at 1", instant(at(17, 13, 0))},
+		{"bare morning hour", "yesterday morning at 8", instant(at(18, 8, 0))},
+		{"minutes with part", "yesterday dinner at 7:30", instant(at(18, 19, 30))},
+		{"24 hour clock with part", "yesterday dinner at 19:30", instant(at(18, 19, 30))},
+		{"weekday with clock", "last tuesday at 6:15pm", instant(at(18, 18, 15))},
+
+		// Rolling windows
+		{"past days", "past 3 days", span(at(16, 15, 30), now)},
+		{"last hours", "last 2 hours", span(at(19, 13, 30), now)},
+		{"worded count", "past two days", span(at(17, 15, 30), now)},
+		{"past week", "past week", span(at(12, 15, 30), now)},
+		{"last day", "last day", span(at(18, 15, 30), now)},
+		{"within the last hour", "within the last hour", span(at(19, 14, 30), now)},
+		{"in the last minutes", "in the last 30 minutes", span(at(19, 15, 0), now)},
+		{"past few weeks", "past few weeks", span(time.Date(2025, 1, 29, 15, 30, 0, 0, syd), now)},
+		{"past month", "past month", span(time.Date(2025, 1, 19, 15, 30, 0, 0, syd), now)},
+
+		// Calendar periods
+		{"last week", "last week", span(at(10, 0, 0), at(17, 0, 0))},
+		{"previous month", "previous month", span(time.Date(2025, 1, 1, 0, 0, 0, 0, syd), at(1, 0, 0))},
+		{"this week", "this week", span(at(17, 0, 0), now)},
+		{"this month", "this month", span(at(1, 0, 0), now)},
+		{"this year", "this year", span(time.Date(2025, 1, 1, 0, 0, 0, 0, syd), now)},
+
+		// Ago
+		{"hours ago", "2 hours ago", instant(at(19, 13, 30))},
+		{"an hour ago", "an hour ago", instant(at(19, 14, 30))},
+		{"minutes ago", "45 minutes ago", instant(at(19, 14, 45))},
+		{"days ago", "3 days ago", day(16)},
+		{"a week ago", "a week ago", day(12)},
+		{"couple days ago", "couple days ago", day(17)},
+
+		// Absolute
+		{"rfc3339 with offset", "2025-02-18T07:30:00+11:00", instant(at(18, 7, 30))},
+		{"rfc3339 in utc", "2025-02-18T07:30:00Z", instant(time.Date(2025, 2, 18, 7, 30, 0, 0, time.UTC))},
+		{"fractional seconds", "2025-02-18T07:30:00.5Z", instant(time.Date(2025, 2, 18, 7, 30, 0, 500000000, time.UTC))},
+		{"no offset is local", "2025-02-18T07:30:00", instant(at(18, 7, 30))},
+		{"no seconds is local", "2025-02-18t07:30", instant(at(18, 7, 30))},
+		{"space separated", "2025-02-18 07:30", instant(at(18, 7, 30))},
+		{"no seconds with z is utc", "2025-02-18T07:30Z", instant(time.Date(2025, 2, 18, 7, 30, 0, 0, time.UTC))},
+		{"space separated with z is utc", "2025-02-18 07:30:00z", instant(time.Date(2025, 2, 18, 7, 30, 0, 0, time.UTC))},
+		{"date only", "2025-02-18", day(18)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Resolve(tt.expr, now, syd)
+			if err != nil {
+				t.Fatalf("Resolve(%q) got err %v", tt.expr, err)
+			}
+
+			if !got.Start.Equal(tt.want.Start) || !got.End.Equal(tt.want.End) {
+				t.Errorf("Resolve(%q) got [%v, %v) but want [%v, %v)", tt.expr, got.Start, got.End, tt.want.Start, tt.want.End)
+			}
+		})
+	}
+}
+
+func TestResolveErrors(t *testing.T) {
+	now := time.Date(2025, 2, 19, 15, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		expr string
+		want error
+	}{
+		{"empty", "", ErrEmptyExpression},
+		{"whitespace", "   ", ErrEmptyExpression},
+		{"gibberish", "whenever", ErrUnrecognisedExpression},
+		{"unknown weekday", "last blursday", ErrUnrecognisedExpression},
+		{"hour out of range", "25:00", ErrUnrecognisedExpression},
+		{"pm out of range", "13pm", ErrUnrecognisedExpression},
+		{"minutes out of range", "7:75pm", ErrUnrecognisedExpression},
+		{"bare hour", "7", ErrUnrecognisedExpression},
+		{"two days", "yesterday tuesday", ErrUnrecognisedExpression},
+		{"two clocks", "7pm 8pm", ErrUnrecognisedExpression},
+		{"two parts", "lunch dinner", ErrUnrecognisedExpression},
+		{"multi word count", "past lots of days", ErrUnrecognisedExpression},
+		{"bad worded count", "past many days", ErrUnrecognisedExpression},
+		{"bad date", "2025-02-30", ErrUnrecognisedExpression},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Resolve(tt.expr, now, time.UTC); !errors.Is(err, tt.want) {
+				t.Errorf("Resolve(%q) got err %v but want %v", tt.expr, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveTimezones(t *testing.T) {
+	// 2025-02-19 01:00 UTC is still the 18th in New York, but the 19th in Sydney
+	now := time.Date(2025, 2, 19, 1, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		location string
+		want     time.Time
+	}{
+		{"UTC", time.Date(2025, 2, 18, 0, 0, 0, 0, time.UTC)},
+		{"America/New_York", time.Date(2025, 2, 18, 5, 0, 0, 0, time.UTC).Add(-24 * time.Hour)},
+		{"Australia/Sydney", time.Date(2025, 2, 17, 13, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.location, func(t *testing.T) {
+			loc, err := LoadLocation(tt.location, time.UTC)
+			if err != nil {
+				t.Fatalf("failed loading location: %v", err)
+			}
+
+			got, err := Resolve("yesterday", now, loc)
+			if err != nil {
+				t.Fatalf("got err %v", err)
+			}
+
+			if !got.Start.Equal(tt.want) {
+				t.Errorf("got start %v but want %v", got.Start.UTC(), tt.want)
+			}
+		})
+	}
+
+	t.Run("days span daylight saving changes", func(t *testing.T) {
+		syd, _ := LoadLocation("Australia/Sydney", nil)
+
+		// Sydney daylight saving ended at 3am on the 6th of April 2025
+		got, err := Resolve("yesterday", time.Date(2025, 4, 7, 12, 0, 0, 0, syd), syd)
+		if err != nil {
+			t.Fatalf("got err %v", err)
+		}
+
+		if length := got.End.Sub(got.Start); length != 25*time.Hour {
+			t.Errorf("got day length %v but want 25h", length)
+		}
+	})
+
+	t.Run("nil location is utc", func(t *testing.T) {
+		got, err := Resolve("2025-02-18T07:30", now, nil)
+		if err != nil {
+			t.Fatalf("got err %v", err)
+		}
+
+		if !got.Start.Equal(time.Date(2025, 2, 18, 7, 30, 0, 0, time.UTC)) {
+			t.Errorf("got %v but want utc", got.Start)
+		}
+	})
+}
+
+func TestLoadLocation(t *testing.T) {
+	fallback := time.FixedZone("fallback", 3600)
+
+	if loc, err := LoadLocation("", fallback); err != nil || loc != fallback {
+		t.Errorf("got %v, %v but want fallback for empty name", loc, err)
+	}
+
+	if _, err := LoadLocation("Not/AZone", fallback); err == nil {
+		t.Error("expected error for unknown zone")
+	}
+}
+
+func TestRangePoint(t *testing.T) {
+	now := time.Date(2025, 2, 19, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		rng  Range
+		want time.Time
+	}{
+		{"instant", instant(now.Add(-time.Hour)), now.Add(-time.Hour)},
+		{"past range is midpoint", Range{Start: now.Add(-4 * time.Hour), End: now.Add(-2 * time.Hour)}, now.Add(-3 * time.Hour)},
+		{"range containing now is clamped", dayRange(now), now},
+		{"future range is midpoint", Range{Start: now.Add(time.Hour), End: now.Add(3 * time.Hour)}, now.Add(2 * time.Hour)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.rng.Point(now); !got.Equal(tt.want) {
+				t.Errorf("got %v but want %v", got, tt.want)
+			}
+		})
+	}
+}
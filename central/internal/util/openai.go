@@ -5,10 +5,16 @@ import (
 	"github.com/openai/openai-go/option"
 )
 
-func CreateNewOpenAIClient(token string) *openai.Client {
-	client := openai.NewClient(
+func CreateNewOpenAIClient(token string, baseURL string, extra ...option.RequestOption) *openai.Client {
+	opts := []option.RequestOption{
 		option.WithAPIKey(token),
-	)
+	}
+
+	if baseURL != "" {
+		opts = append(opts, option.WithBaseURL(baseURL))
+	}
+
+	client := openai.NewClient(append(opts, extra...)...)
 
 	return &client
 }
@@ -0,0 +1,79 @@
+// Rules for every request central accepts. They're checked by the validation
+// interceptor before a request reaches its handler, and again on requests the
+// tools make of the food service in process.
+package validation
+
+import (
+	"github.com/calamity-m/reaphur/central/internal/mapping"
+	"github.com/calamity-m/reaphur/pkg/validate"
+	centralproto "github.com/calamity-m/reaphur/proto/v1/central"
+	"github.com/calamity-m/reaphur/proto/v1/domain"
+)
+
+func New() *validate.Validator {
+	return validate.New(
+		// Shared types, checked wherever they're used
+		validate.For(&domain.FoodRecord{},
+			validate.Field("id", validate.UUID()),
+			validate.Field("user_id", validate.UUID()),
+			validate.Field("description", validate.NotBlank()),
+			validate.Field("kj", validate.Min(0)),
+			validate.Field("ml", validate.Min(0)),
+			validate.Field("grams", validate.Min(0)),
+			validate.Field("calories", validate.Min(0)),
+			validate.Field("fl_oz", validate.Min(0)),
+			validate.Field("oz", validate.Min(0)),
+		),
+		validate.For(&centralproto.ImageAttachment{},
+			validate.Field("mime_type", validate.HasPrefix("image/")),
+			validate.Field("url", validate.URL("http", "https")),
+		),
+
+		// Food service
+		validate.For(&centralproto.CreateFoodRecordRequest{},
+			validate.Field("record", validate.Required()),
+			validate.Field("record.user_id", validate.Required()),
+		),
+		validate.For(&centralproto.GetFoodRecordsRequest{},
+			validate.Field("request_user_id", validate.Required(), validate.UUID()),
+			validate.Field("filter", validate.Required()),
+			validate.Field("filter.id", validate.UUID()),
+			validate.NotBefore("filter.before_time", "filter.after_time"),
+		),
+		validate.For(&centralproto.UpdateFoodRecordRequest{},
+			validate.Field("request_user_id", validate.Required(), validate.UUID()),
+			validate.Field("record", validate.Required()),
+			validate.Field("record.id", validate.Required()),
+			validate.Field("record.user_id", validate.Required()),
+		),
+		validate.For(&centralproto.DeleteFoodRecordRequest{},
+			validate.Field("request_user_id", validate.Required(), validate.UUID()),
+			validate.Field("id", validate.Required(), validate.UUID()),
+		),
+
+		// Central service
+		validate.For(&centralproto.ActionUserInputRequest{},
+			validate.Field("request_user_id", validate.UUID()),
+		),
+		validate.For(&centralproto.CallFnUserInputRequest{},
+			validate.Field("request_user_id", validate.Required(), validate.UUID()),
+			validate.RequireAny("request_user_input", "images"),
+			validate.Field("request_user_timezone", validate.Timezone()),
+			validate.Field("images", validate.MaxItems(mapping.MaxImageAttachments)),
+		),
+		validate.For(&centralproto.ConfirmActionRequest{},
+			validate.Field("request_user_id", validate.Required(), validate.UUID()),
+			validate.Field("action_id", validate.Required(), validate.UUID()),
+		),
+		validate.For(&centralproto.CancelActionRequest{},
+			validate.Field("request_user_id", validate.Required(), validate.UUID()),
+			validate.Field("action_id", validate.Required(), validate.UUID()),
+		),
+
+		// Admin service
+		validate.For(&centralproto.GetUsageRequest{},
+			validate.Field("filter.user_id", validate.UUID()),
+			validate.NotBefore("filter.before_time", "filter.after_time"),
+		),
+	)
+}
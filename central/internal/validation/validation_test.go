@@ -0,0 +1,119 @@
+package validation
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/calamity-m/reaphur/central/internal/mapping"
+	"github.com/calamity-m/reaphur/pkg/errs"
+	centralproto "github.com/calamity-m/reaphur/proto/v1/central"
+	"github.com/calamity-m/reaphur/proto/v1/domain"
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestRules(t *testing.T) {
+	user, id := uuid.NewString(), uuid.NewString()
+	earlier := timestamppb.New(time.Unix(100, 0))
+	later := timestamppb.New(time.Unix(200, 0))
+
+	record := func(change func(r *domain.FoodRecord)) *domain.FoodRecord {
+		r := &domain.FoodRecord{Id: id, UserId: user, Description: "two slices of toast", Kj: 800}
+		if change != nil {
+			change(r)
+		}
+		return r
+	}
+
+	tests := []struct {
+		name string
+		msg  proto.Message
+		// Violated field and description, empty when the message is valid
+		field string
+		want  string
+	}{
+		{"create valid", &centralproto.CreateFoodRecordRequest{Record: record(nil)}, "", ""},
+		{"create without record", &centralproto.CreateFoodRecordRequest{}, "record", "is required"},
+		{"create without user", &centralproto.CreateFoodRecordRequest{Record: record(func(r *domain.FoodRecord) { r.UserId = "" })}, "record.user_id", "is required"},
+		{"create bad user", &centralproto.CreateFoodRecordRequest{Record: record(func(r *domain.FoodRecord) { r.UserId = "bob" })}, "record.user_id", "must be a uuid"},
+		{"create bad id", &centralproto.CreateFoodRecordRequest{Record: record(func(r *domain.FoodRecord) { r.Id = "1" })}, "record.id", "must be a uuid"},
+		{"create blank description", &centralproto.CreateFoodRecordRequest{Record: record(func(r *domain.FoodRecord) { r.Description = " " })}, "record.description", "must not be empty"},
+		{"create negative kj", &centralproto.CreateFoodRecordRequest{Record: record(func(r *domain.FoodRecord) { r.Kj = -1 })}, "record.kj", "must be at least 0"},
+		{"create negative ml", &centralproto.CreateFoodRecordRequest{Record: record(func(r *domain.FoodRecord) { r.Ml = -1 })}, "record.ml", "must be at least 0"},
+		{"create negative grams", &centralproto.CreateFoodRecordRequest{Record: record(func(r *domain.FoodRecord) { r.Grams = -1 })}, "record.grams", "must be at least 0"},
+		{"create negative calories", &centralproto.CreateFoodRecordRequest{Record: record(func(r *domain.FoodRecord) { r.Calories = -1 })}, "record.calories", "must be at least 0"},
+		{"create negative fl oz", &centralproto.CreateFoodRecordRequest{Record: record(func(r *domain.FoodRecord) { r.FlOz = -1 })}, "record.fl_oz", "must be at least 0"},
+		{"create negative oz", &centralproto.CreateFoodRecordRequest{Record: record(func(r *domain.FoodRecord) { r.Oz = -1 })}, "record.oz", "must be at least 0"},
+
+		{"get valid", &centralproto.GetFoodRecordsRequest{RequestUserId: user, Filter: &centralproto.GetFoodFilter{Id: &id, BeforeTime: later, AfterTime: earlier}}, "", ""},
+		{"get without user", &centralproto.GetFoodRecordsRequest{Filter: &centralproto.GetFoodFilter{}}, "request_user_id", "is required"},
+		{"get bad user", &centralproto.GetFoodRecordsRequest{RequestUserId: "bob", Filter: &centralproto.GetFoodFilter{}}, "request_user_id", "must be a uuid"},
+		{"get without filter", &centralproto.GetFoodRecordsRequest{RequestUserId: user}, "filter", "is required"},
+		{"get bad id", &centralproto.GetFoodRecordsRequest{RequestUserId: user, Filter: &centralproto.GetFoodFilter{Id: proto.String("1")}}, "filter.id", "must be a uuid"},
+		{"get backwards range", &centralproto.GetFoodRecordsRequest{RequestUserId: user, Filter: &centralproto.GetFoodFilter{BeforeTime: earlier, AfterTime: later}}, "filter.before_time", "must not be before filter.after_time"},
+
+		{"update valid", &centralproto.UpdateFoodRecordRequest{RequestUserId: user, Record: record(nil)}, "", ""},
+		{"update without user", &centralproto.UpdateFoodRecordRequest{Record: record(nil)}, "request_user_id", "is required"},
+		{"update bad user", &centralproto.UpdateFoodRecordRequest{RequestUserId: "bob", Record: record(nil)}, "request_user_id", "must be a uuid"},
+		{"update without record", &centralproto.UpdateFoodRecordRequest{RequestUserId: user}, "record", "is required"},
+		{"update without record id", &centralproto.UpdateFoodRecordRequest{RequestUserId: user, Record: record(func(r *domain.FoodRecord) { r.Id = "" })}, "record.id", "is required"},
+		{"update without record user", &centralproto.UpdateFoodRecordRequest{RequestUserId: user, Record: record(func(r *domain.FoodRecord) { r.UserId = "" })}, "record.user_id", "is required"},
+
+		{"delete valid", &centralproto.DeleteFoodRecordRequest{RequestUserId: user, Id: id}, "", ""},
+		{"delete without user", &centralproto.DeleteFoodRecordRequest{Id: id}, "request_user_id", "is required"},
+		{"delete bad user", &centralproto.DeleteFoodRecordRequest{RequestUserId: "bob", Id: id}, "request_user_id", "must be a uuid"},
+		{"delete without id", &centralproto.DeleteFoodRecordRequest{RequestUserId: user}, "id", "is required"},
+		{"delete bad id", &centralproto.DeleteFoodRecordRequest{RequestUserId: user, Id: "1"}, "id", "must be a uuid"},
+
+		{"action valid", &centralproto.ActionUserInputRequest{RequestUserId: user, RequestUserInput: "toast"}, "", ""},
+		{"action bad user", &centralproto.ActionUserInputRequest{RequestUserId: "bob"}, "request_user_id", "must be a uuid"},
+
+		{"call valid", &centralproto.CallFnUserInputRequest{RequestUserId: user, RequestUserInput: "toast", RequestUserTimezone: "Australia/Melbourne"}, "", ""},
+		{"call valid with only images", &centralproto.CallFnUserInputRequest{RequestUserId: user, Images: []*centralproto.ImageAttachment{{MimeType: "image/png", Source: &centralproto.ImageAttachment_Url{Url: "https://example.com/toast.png"}}}}, "", ""},
+		{"call without user", &centralproto.CallFnUserInputRequest{RequestUserInput: "toast"}, "request_user_id", "is required"},
+		{"call bad user", &centralproto.CallFnUserInputRequest{RequestUserId: "bob", RequestUserInput: "toast"}, "request_user_id", "must be a uuid"},
+		{"call without input or images", &centralproto.CallFnUserInputRequest{RequestUserId: user}, "request_user_input", "is required without images"},
+		{"call bad timezone", &centralproto.CallFnUserInputRequest{RequestUserId: user, RequestUserInput: "toast", RequestUserTimezone: "Mars/Olympus_Mons"}, "request_user_timezone", "must be an IANA timezone"},
+		{"call too many images", &centralproto.CallFnUserInputRequest{RequestUserId: user, Images: make([]*centralproto.ImageAttachment, mapping.MaxImageAttachments+1)}, "images", "must have at most 4 items"},
+		{"call image bad mime type", &centralproto.CallFnUserInputRequest{RequestUserId: user, Images: []*centralproto.ImageAttachment{{MimeType: "text/plain"}}}, "images[0].mime_type", `must start with "image/"`},
+		{"call image bad url", &centralproto.CallFnUserInputRequest{RequestUserId: user, Images: []*centralproto.ImageAttachment{{MimeType: "image/png", Source: &centralproto.ImageAttachment_Url{Url: "file:///etc/passwd"}}}}, "images[0].url", "must be a http or https url"},
+
+		{"confirm valid", &centralproto.ConfirmActionRequest{RequestUserId: user, ActionId: id}, "", ""},
+		{"confirm without user", &centralproto.ConfirmActionRequest{ActionId: id}, "request_user_id", "is required"},
+		{"confirm bad user", &centralproto.ConfirmActionRequest{RequestUserId: "bob", ActionId: id}, "request_user_id", "must be a uuid"},
+		{"confirm without action", &centralproto.ConfirmActionRequest{RequestUserId: user}, "action_id", "is required"},
+		{"confirm bad action", &centralproto.ConfirmActionRequest{RequestUserId: user, ActionId: "1"}, "action_id", "must be a uuid"},
+
+		{"cancel valid", &centralproto.CancelActionRequest{RequestUserId: user, ActionId: id}, "", ""},
+		{"cancel without user", &centralproto.CancelActionRequest{ActionId: id}, "request_user_id", "is required"},
+		{"cancel bad user", &centralproto.CancelActionRequest{RequestUserId: "bob", ActionId: id}, "request_user_id", "must be a uuid"},
+		{"cancel without action", &centralproto.CancelActionRequest{RequestUserId: user}, "action_id", "is required"},
+		{"cancel bad action", &centralproto.CancelActionRequest{RequestUserId: user, ActionId: "1"}, "action_id", "must be a uuid"},
+
+		{"usage valid", &centralproto.GetUsageRequest{Filter: &centralproto.GetUsageFilter{UserId: &user, BeforeTime: later, AfterTime: earlier}}, "", ""},
+		{"usage without filter", &centralproto.GetUsageRequest{}, "", ""},
+		{"usage bad user", &centralproto.GetUsageRequest{Filter: &centralproto.GetUsageFilter{UserId: proto.String("bob")}}, "filter.user_id", "must be a uuid"},
+		{"usage backwards range", &centralproto.GetUsageRequest{Filter: &centralproto.GetUsageFilter{BeforeTime: earlier, AfterTime: later}}, "filter.before_time", "must not be before filter.after_time"},
+	}
+
+	validator := New()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := validator.Violations(tt.msg)
+
+			if tt.field == "" {
+				if len(got) != 0 {
+					t.Errorf("got violations %v but want none", got)
+				}
+				return
+			}
+
+			want := []errs.FieldViolation{{Field: tt.field, Description: tt.want}}
+			if !slices.Equal(got, want) {
+				t.Errorf("got violations %v but want %v", got, want)
+			}
+		})
+	}
+}
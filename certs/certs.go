@@ -0,0 +1,40 @@
+package certs
+
+import (
+	"fmt"
+
+	"github.com/calamity-m/reaphur/pkg/tlsconf"
+	"github.com/spf13/cobra"
+)
+
+var (
+	devDir   string
+	devHosts []string
+
+	CertsCommand = &cobra.Command{
+		Use:   "certs",
+		Short: "manage tls certificates",
+		Long:  `helpers for the certificates central, the gateway and the bot use to talk to each other`,
+	}
+
+	CertsDevCommand = &cobra.Command{
+		Use:   "dev",
+		Short: "generate development certificates",
+		Long: `generate a local CA along with a server certificate for central and client certificates
+for the gateway and bot, for use with compose and testing. An existing CA in the directory
+is reused. Never use these in production.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := tlsconf.GenerateDev(devDir, devHosts); err != nil {
+				return err
+			}
+
+			fmt.Printf("Wrote development certificates to %s\n", devDir)
+			return nil
+		},
+	}
+)
+
+func init() {
+	CertsDevCommand.Flags().StringVar(&devDir, "dir", "certs/dev", "directory to write the certificates to")
+	CertsDevCommand.Flags().StringSliceVar(&devHosts, "hosts", tlsconf.DefaultDevHosts, "hosts and ips central's certificate is valid for")
+}
@@ -1,8 +1,11 @@
 package cmd
 
 import (
+	"github.com/calamity-m/reaphur/all"
 	"github.com/calamity-m/reaphur/central"
+	"github.com/calamity-m/reaphur/certs"
 	"github.com/calamity-m/reaphur/discord"
+	"github.com/calamity-m/reaphur/fakellm"
 	"github.com/calamity-m/reaphur/gw"
 	"github.com/calamity-m/reaphur/pkg/bindings"
 	"github.com/spf13/cobra"
@@ -19,10 +22,17 @@ func Execute() error {
 
 	// Central has some sub commands
 	central.CentralCommand.AddCommand(central.CentralGenerateSchemaCommand)
+	central.CentralCommand.AddCommand(central.CentralEvalCommand)
+
+	// Certs has some sub commands
+	certs.CertsCommand.AddCommand(certs.CertsDevCommand)
 
 	RootCommand.AddCommand(central.CentralCommand)
 	RootCommand.AddCommand(gw.GRPCGatewayCommand)
 	RootCommand.AddCommand(discord.DiscordBotCommand)
+	RootCommand.AddCommand(fakellm.FakeLLMCommand)
+	RootCommand.AddCommand(certs.CertsCommand)
+	RootCommand.AddCommand(all.AllCommand)
 
 	return RootCommand.Execute()
 }
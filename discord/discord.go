@@ -1,6 +1,7 @@
 package discord
 
 import (
+	"context"
 	"log/slog"
 	"os"
 	"os/signal"
@@ -9,11 +10,15 @@ import (
 	"github.com/calamity-m/reaphur/central"
 	"github.com/calamity-m/reaphur/discord/internal/bot"
 	"github.com/calamity-m/reaphur/discord/internal/conf"
+	"github.com/calamity-m/reaphur/pkg/auth"
 	"github.com/calamity-m/reaphur/pkg/bindings"
+	"github.com/calamity-m/reaphur/pkg/inproc"
 	"github.com/calamity-m/reaphur/pkg/logging"
+	"github.com/calamity-m/reaphur/pkg/middleware"
+	"github.com/calamity-m/reaphur/pkg/telemetry"
+	"github.com/calamity-m/reaphur/pkg/tlsconf"
 	"github.com/spf13/cobra"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
 )
 
 var (
@@ -41,34 +46,94 @@ var (
 
 			logger.Info("initialized logging")
 
-			opts := []grpc.DialOption{
-				// For now just use insecure
-				grpc.WithTransportCredentials(insecure.NewCredentials()),
-			}
-			centralClient, centralConn, err := central.NewCentralServiceClient(cfg.CentralServerAddress, opts)
+			stopTracing, err := telemetry.SetupTracing(context.Background(), logger, telemetry.TracingOptions{
+				Enabled:     cfg.TracingEnabled,
+				ServiceName: "reaphur-discord",
+				Endpoint:    cfg.TracingEndpoint,
+				Insecure:    cfg.TracingInsecure,
+				SampleRatio: cfg.TracingSampleRatio,
+			})
 			if err != nil {
-				logger.Error("failed to create central client", slog.Any("err", err))
+				logger.Error("failed to set up tracing", slog.Any("err", err))
 				return err
 			}
-			defer centralConn.Close()
+			defer stopTracing()
 
-			discordBot, err := bot.NewDiscordBot(logger, cfg, centralClient)
-			if err != nil {
-				logger.Error("failed to create discord bot", slog.Any("err", err))
-				return err
-			}
+			metricsCtx, stopMetrics := context.WithCancel(context.Background())
+			defer stopMetrics()
+			telemetry.ServeMetrics(metricsCtx, logger, cfg.MetricsAddress)
 
 			// Create the channel which will wait for our shutdown signals which we can
 			// utilise for a nice graceful shutdown
 			sig := make(chan os.Signal, 2)
 			signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM)
 
-			if err := discordBot.Run(sig); err != nil {
-				logger.Error("failed to run discord bot", slog.Any("err", err))
-				return err
-			}
-
-			return nil
+			return run(logger, cfg, cfg.CentralServerAddress, nil, sig)
 		},
 	}
 )
+
+// Runs the bot for the all in one mode, until notify is pushed to or closed.
+// Central is reached over the link, or the configured address when central
+// isn't run in process.
+func RunInProcess(logger *slog.Logger, link *inproc.Link, notify <-chan os.Signal) error {
+	cfg, err := conf.NewConfig(bindings.Debug)
+	if err != nil {
+		return err
+	}
+
+	if link != nil {
+		// Nothing leaves the process, so the token is sent without TLS
+		cfg.CentralTLS = false
+		return run(logger, cfg, link.Target(), link.DialOptions(), notify)
+	}
+
+	return run(logger, cfg, cfg.CentralServerAddress, nil, notify)
+}
+
+// Runs the bot until notify is pushed to or closed, talking to central at
+// target. Without upstream options central is dialled with the configured TLS.
+func run(logger *slog.Logger, cfg *conf.Config, target string, upstream []grpc.DialOption, notify <-chan os.Signal) error {
+	if upstream == nil {
+		creds, err := tlsconf.DialCredentials(logger, tlsconf.ClientOptions{
+			Enabled:    cfg.CentralTLS,
+			CAFile:     cfg.CentralTLSCAFile,
+			CertFile:   cfg.CentralTLSCertFile,
+			KeyFile:    cfg.CentralTLSKeyFile,
+			ServerName: cfg.CentralTLSServerName,
+		})
+		if err != nil {
+			logger.Error("failed to create central tls config", slog.Any("err", err))
+			return err
+		}
+		upstream = []grpc.DialOption{grpc.WithTransportCredentials(creds)}
+	}
+
+	opts := append(upstream,
+		grpc.WithStatsHandler(telemetry.GRPCClientHandler()),
+		grpc.WithChainUnaryInterceptor(middleware.RequestIdUnaryClientInterceptor(), middleware.MetricsUnaryClientInterceptor()),
+		grpc.WithChainStreamInterceptor(middleware.RequestIdStreamClientInterceptor(), middleware.MetricsStreamClientInterceptor()),
+	)
+	if cfg.CentralToken != "" {
+		opts = append(opts, grpc.WithPerRPCCredentials(auth.TokenCredentials{Token: cfg.CentralToken, RequireTLS: cfg.CentralTLS}))
+	}
+	centralClient, centralConn, err := central.NewCentralServiceClient(target, opts)
+	if err != nil {
+		logger.Error("failed to create central client", slog.Any("err", err))
+		return err
+	}
+	defer centralConn.Close()
+
+	discordBot, err := bot.NewDiscordBot(logger, cfg, centralClient)
+	if err != nil {
+		logger.Error("failed to create discord bot", slog.Any("err", err))
+		return err
+	}
+
+	if err := discordBot.Run(notify); err != nil {
+		logger.Error("failed to run discord bot", slog.Any("err", err))
+		return err
+	}
+
+	return nil
+}
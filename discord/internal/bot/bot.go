@@ -7,6 +7,7 @@ import (
 	"os"
 
 	"github.com/calamity-m/reaphur/discord/internal/conf"
+	"github.com/calamity-m/reaphur/discord/internal/metrics"
 	centralproto "github.com/calamity-m/reaphur/proto/v1/central"
 	"github.com/disgoorg/disgo"
 	disgobot "github.com/disgoorg/disgo/bot"
@@ -30,8 +31,9 @@ func (bot *DiscordBot) Run(notify <-chan os.Signal) error {
 
 	// Now register any listeners we have.
 	bot.disc.AddEventListeners([]disgobot.EventListener{
-		asyncHandler(bot.logger, handleDMMessageCreate(bot)),
-		asyncHandler(bot.logger, handleMessageCreate(bot)),
+		asyncHandler(bot.logger, metrics.EventDMMessageCreate, handleDMMessageCreate(bot)),
+		asyncHandler(bot.logger, metrics.EventMessageCreate, handleMessageCreate(bot)),
+		asyncHandler(bot.logger, metrics.EventComponentInteractionCreate, handleComponentInteractionCreate(bot)),
 	}...)
 
 	// Connect to the gateway and defer closing for if we exit
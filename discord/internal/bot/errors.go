@@ -0,0 +1,68 @@
+package bot
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/calamity-m/reaphur/pkg/middleware"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Turns an error from central into something worth telling the user. Problems
+// with what the user asked for are explained, anything else just gets the
+// unavailable message.
+func errorMessage(err error) string {
+	// Let the user know when they're being rate limited, rather than going quiet
+	if wait, limited := middleware.RetryAfter(err); limited {
+		return fmt.Sprintf("Easy there, even the reaper needs a breather. Try again in %d seconds.", int(math.Ceil(wait.Seconds())))
+	}
+
+	st := status.Convert(err)
+	switch st.Code() {
+	case codes.InvalidArgument:
+		return fmt.Sprintf("The reaper couldn't make sense of that: %s.", invalidReason(st))
+	case codes.NotFound:
+		return "The reaper couldn't find what you were after."
+	case codes.AlreadyExists:
+		return "The reaper already has that written down."
+	case codes.Unimplemented:
+		return "The reaper doesn't know how to do that yet."
+	default:
+		return unavailableMessage
+	}
+}
+
+// Pending actions that expired or were already answered come back as not found,
+// which gets the given message instead
+func actionErrorMessage(err error, expired string) string {
+	if status.Code(err) == codes.NotFound {
+		return expired
+	}
+
+	return errorMessage(err)
+}
+
+// Describes why a request was invalid, preferring the field violations over the
+// status message
+func invalidReason(st *status.Status) string {
+	reasons := []string{}
+	for _, detail := range st.Details() {
+		badRequest, ok := detail.(*errdetails.BadRequest)
+		if !ok {
+			continue
+		}
+
+		for _, violation := range badRequest.GetFieldViolations() {
+			reasons = append(reasons, fmt.Sprintf("%s %s", strings.ReplaceAll(violation.GetField(), "_", " "), violation.GetDescription()))
+		}
+	}
+
+	if len(reasons) == 0 {
+		return st.Message()
+	}
+
+	return strings.Join(reasons, ", ")
+}
@@ -2,28 +2,53 @@ package bot
 
 import (
 	"context"
+	"errors"
 	"log/slog"
+	"mime"
 	"strings"
+	"time"
 
+	"github.com/calamity-m/reaphur/discord/internal/metrics"
+	"github.com/calamity-m/reaphur/pkg/middleware"
 	centralproto "github.com/calamity-m/reaphur/proto/v1/central"
 	"github.com/disgoorg/disgo/bot"
 	"github.com/disgoorg/disgo/discord"
 	"github.com/disgoorg/disgo/events"
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
-func asyncHandler[E bot.Event](log *slog.Logger, listenerFunc func(e E)) bot.EventListener {
+const (
+	unavailableMessage = "The reaper can't come to the door right now. Try again in a little while."
+)
+
+var tracer = otel.Tracer("github.com/calamity-m/reaphur/discord/internal/bot")
+
+// Runs the listener on its own goroutine, recording each event under the given
+// name. Every event starts its own trace and gets its own request id, which
+// central logs alongside ours.
+func asyncHandler[E bot.Event](log *slog.Logger, event string, listenerFunc func(ctx context.Context, e E)) bot.EventListener {
 	async := func(e E) {
 		log.Debug("Entered async handler")
 		go func() {
+			start := time.Now()
+			ctx, span := tracer.Start(middleware.ContextWithRequestID(context.Background()), event, trace.WithSpanKind(trace.SpanKindConsumer))
 			defer func() {
+				outcome := metrics.OutcomeHandled
 				if r := recover(); r != nil {
-					log.Error("panic recovered", slog.Any("panic", r))
+					log.ErrorContext(ctx, "panic recovered", slog.Any("panic", r))
+					outcome = metrics.OutcomePanicked
+					span.SetStatus(codes.Error, "panicked")
 				}
+				span.End()
+				metrics.Events.WithLabelValues(event, outcome).Inc()
+				metrics.EventDuration.WithLabelValues(event).Observe(time.Since(start).Seconds())
 
 				log.Debug("Finisheded async handler")
 			}()
-			listenerFunc(e)
+			listenerFunc(ctx, e)
 		}()
 	}
 
@@ -31,9 +56,31 @@ func asyncHandler[E bot.Event](log *slog.Logger, listenerFunc func(e E)) bot.Eve
 	return bot.NewListenerFunc(async)
 }
 
-func handleDMMessageCreate(bot *DiscordBot) func(e *events.DMMessageCreate) {
-	return func(e *events.DMMessageCreate) {
-		ctx := context.Background()
+// Forwards image attachments by their discord cdn url for the model to fetch.
+// Anything that isn't an image is ignored.
+func imageAttachments(attachments []discord.Attachment) []*centralproto.ImageAttachment {
+	images := []*centralproto.ImageAttachment{}
+	for _, attachment := range attachments {
+		if attachment.ContentType == nil {
+			continue
+		}
+
+		mimeType, _, err := mime.ParseMediaType(*attachment.ContentType)
+		if err != nil || !strings.HasPrefix(mimeType, "image/") {
+			continue
+		}
+
+		images = append(images, &centralproto.ImageAttachment{
+			Source:   &centralproto.ImageAttachment_Url{Url: attachment.URL},
+			MimeType: mimeType,
+		})
+	}
+
+	return images
+}
+
+func handleDMMessageCreate(bot *DiscordBot) func(ctx context.Context, e *events.DMMessageCreate) {
+	return func(ctx context.Context, e *events.DMMessageCreate) {
 		bot.logger.InfoContext(ctx, "DM_MESSAGE_CREATE Started")
 
 		// Perform some sanity checks
@@ -57,59 +104,114 @@ func handleDMMessageCreate(bot *DiscordBot) func(e *events.DMMessageCreate) {
 		input := &centralproto.CallFnUserInputRequest{
 			RequestUserId:    uuid.NewSHA1(uuid.NameSpaceURL, marshalId).String(),
 			RequestUserInput: e.Message.Content,
+			Images:           imageAttachments(e.Message.Attachments),
 		}
-		output, err := bot.central.CallFnUserInput(ctx, input)
-		if err != nil {
-			bot.logger.ErrorContext(ctx, "error calling central fn", slog.Any("err", err), slog.Any("id", e.Message.Author.ID))
+		if input.RequestUserInput == "" && len(input.Images) == 0 {
+			bot.logger.InfoContext(ctx, "message had nothing to act on", slog.Any("id", e.Message.Author.ID))
 			return
 		}
-		bot.logger.InfoContext(ctx, "got output from central", slog.Any("output", output))
-
-		if len(output.ResponseMessage) > 2000 {
-			bot.logger.ErrorContext(ctx, "RESPONSE MESSAGE LENGTH REACHED", slog.Int("length", len(output.ResponseMessage)))
-
-			// Okay this is kinda stupid but I'll try it for now, see how bad it is
-			msgs := strings.Split(output.ResponseMessage, "\n")
+		output, err := bot.streamCallFnUserInput(ctx, e.Client().Rest(), e.ChannelID, input)
+		if err != nil {
+			bot.logger.ErrorContext(ctx, "error calling central fn", slog.Any("err", err), slog.Any("id", e.Message.Author.ID))
 
-			for _, msg := range msgs {
-				// Create response message to the user's DM channel
-				sent, err := e.Client().Rest().CreateMessage(
-					e.ChannelID,
-					discord.NewMessageCreateBuilder().SetContentf(
-						"%s",
-						msg,
-					).Build(),
-				)
+			// Say something rather than leaving the user hanging
+			if !errors.Is(err, errReplied) {
+				_, err := e.Client().Rest().CreateMessage(e.ChannelID, discord.NewMessageCreateBuilder().SetContent(errorMessage(err)).Build())
 				if err != nil {
-					bot.logger.ErrorContext(ctx, "failed to create message", slog.Any("err", err), slog.Any("event", e))
-					return
+					bot.logger.ErrorContext(ctx, "failed to create error message", slog.Any("err", err), slog.Any("event", e))
 				}
-				bot.logger.DebugContext(ctx, "created message successfully", slog.Any("msg", sent))
 			}
+			return
+		}
+		bot.logger.InfoContext(ctx, "got output from central", slog.Any("output", output))
 
-		} else {
-			// Create response message to the user's DM channel
+		// Anything destructive waits on the user, so give them a way to answer
+		for _, action := range output.PendingActions {
 			sent, err := e.Client().Rest().CreateMessage(
 				e.ChannelID,
-				discord.NewMessageCreateBuilder().SetContentf(
-					"%s",
-					output.ResponseMessage,
-				).Build(),
+				discord.NewMessageCreateBuilder().
+					SetContentf("Awaiting your word: %s", action.Summary).
+					AddActionRow(
+						discord.NewSuccessButton("Confirm", confirmActionPrefix+action.ActionId),
+						discord.NewDangerButton("Cancel", cancelActionPrefix+action.ActionId),
+					).Build(),
 			)
 			if err != nil {
-				bot.logger.ErrorContext(ctx, "failed to create message", slog.Any("err", err), slog.Any("event", e))
+				bot.logger.ErrorContext(ctx, "failed to create pending action message", slog.Any("err", err), slog.Any("action", action))
 				return
 			}
-			bot.logger.DebugContext(ctx, "created message successfully", slog.Any("msg", sent))
+			bot.logger.DebugContext(ctx, "created pending action message successfully", slog.Any("msg", sent))
 		}
 
 		bot.logger.InfoContext(ctx, "DM_MESSAGE_CREATE Finished")
 	}
 }
 
-func handleMessageCreate(d *DiscordBot) func(e *events.MessageCreate) {
-	return func(e *events.MessageCreate) {
-		ctx := context.Background()
+const (
+	confirmActionPrefix = "confirm:"
+	cancelActionPrefix  = "cancel:"
+)
+
+func handleComponentInteractionCreate(bot *DiscordBot) func(ctx context.Context, e *events.ComponentInteractionCreate) {
+	return func(ctx context.Context, e *events.ComponentInteractionCreate) {
+		bot.logger.InfoContext(ctx, "COMPONENT_INTERACTION_CREATE Started")
+
+		customId := e.Data.CustomID()
+		if !strings.HasPrefix(customId, confirmActionPrefix) && !strings.HasPrefix(customId, cancelActionPrefix) {
+			bot.logger.InfoContext(ctx, "ignoring unknown component interaction", slog.String("custom_id", customId))
+			return
+		}
+
+		marshalId, err := e.User().ID.MarshalJSON()
+		if err != nil {
+			bot.logger.ErrorContext(ctx, "error marshaling snowflake id for user", slog.Any("err", err), slog.Any("id", e.User().ID))
+			return
+		}
+		userId := uuid.NewSHA1(uuid.NameSpaceURL, marshalId).String()
+
+		// Central may take a while running the action, so acknowledge the click first
+		if err := e.DeferUpdateMessage(); err != nil {
+			bot.logger.ErrorContext(ctx, "failed to defer interaction", slog.Any("err", err))
+			return
+		}
+
+		var message string
+		if actionId, ok := strings.CutPrefix(customId, confirmActionPrefix); ok {
+			output, err := bot.central.ConfirmAction(ctx, &centralproto.ConfirmActionRequest{RequestUserId: userId, ActionId: actionId})
+			if err != nil {
+				bot.logger.ErrorContext(ctx, "error confirming action", slog.Any("err", err), slog.String("action_id", actionId))
+				message = actionErrorMessage(err, "That request has already passed on. Ask me again if you still want it done.")
+			} else {
+				message = output.ResponseMessage
+			}
+		} else {
+			actionId := strings.TrimPrefix(customId, cancelActionPrefix)
+			output, err := bot.central.CancelAction(ctx, &centralproto.CancelActionRequest{RequestUserId: userId, ActionId: actionId})
+			if err != nil {
+				bot.logger.ErrorContext(ctx, "error cancelling action", slog.Any("err", err), slog.String("action_id", actionId))
+				message = actionErrorMessage(err, "That request has already passed on.")
+			} else {
+				message = output.ResponseMessage
+			}
+		}
+
+		// Replace the buttons with the outcome so the action can't be answered twice
+		_, err = e.Client().Rest().UpdateInteractionResponse(
+			e.ApplicationID(),
+			e.Token(),
+			discord.NewMessageUpdateBuilder().SetContent(message).ClearContainerComponents().Build(),
+		)
+		if err != nil {
+			bot.logger.ErrorContext(ctx, "failed to update interaction message", slog.Any("err", err))
+			return
+		}
+
+		bot.logger.InfoContext(ctx, "COMPONENT_INTERACTION_CREATE Finished")
+	}
+}
+
+func handleMessageCreate(d *DiscordBot) func(ctx context.Context, e *events.MessageCreate) {
+	return func(ctx context.Context, e *events.MessageCreate) {
 		d.logger.InfoContext(ctx, "MESSAGE_CREATE Started")
 
 		d.logger.InfoContext(ctx, "MESSAGE_CREATE Finished")
@@ -0,0 +1,180 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	centralproto "github.com/calamity-m/reaphur/proto/v1/central"
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/rest"
+	"github.com/disgoorg/snowflake/v2"
+)
+
+const (
+	// Discord rate limits message edits, so progress is batched up
+	progressEditInterval = time.Second
+	// Discord refuses messages longer than this
+	maxMessageLength = 2000
+	thinkingMessage  = "The reaper is thinking..."
+)
+
+// Marks errors after the user has already been replied to, so nothing more needs saying
+var errReplied = errors.New("already replied")
+
+// A reply that is progressively edited as central streams its progress
+type progressReply struct {
+	rest      rest.Rest
+	channelID snowflake.ID
+	messageID snowflake.ID
+
+	status   string
+	text     strings.Builder
+	lastEdit time.Time
+	dirty    bool
+}
+
+func (p *progressReply) content() string {
+	content := p.text.String()
+	if content == "" {
+		content = p.status
+	}
+
+	// The final message handles long responses, while in progress just show the
+	// tail. The cut backs up to a rune boundary so no character is split.
+	if len(content) > maxMessageLength {
+		start := len(content) - maxMessageLength
+		for start < len(content) && !utf8.RuneStart(content[start]) {
+			start++
+		}
+		content = content[start:]
+	}
+
+	return content
+}
+
+// Edits the reply if enough time has passed since the last edit, or force is set
+func (p *progressReply) flush(force bool) error {
+	if !p.dirty || (!force && time.Since(p.lastEdit) < progressEditInterval) {
+		return nil
+	}
+
+	_, err := p.rest.UpdateMessage(p.channelID, p.messageID, discord.NewMessageUpdateBuilder().SetContent(p.content()).Build())
+	if err != nil {
+		return err
+	}
+
+	p.lastEdit = time.Now()
+	p.dirty = false
+
+	return nil
+}
+
+// Calls CallFnUserInputStream, editing a single reply in the channel as tool
+// progress and text arrive. Once done the reply holds the full response message,
+// split over extra messages if it's too long for one.
+func (bot *DiscordBot) streamCallFnUserInput(ctx context.Context, client rest.Rest, channelID snowflake.ID, input *centralproto.CallFnUserInputRequest) (*centralproto.CallFnUserInputResponse, error) {
+	stream, err := bot.central.CallFnUserInputStream(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	// Rate limiting and validation errors arrive with the first event, so wait
+	// for it before posting anything
+	event, err := stream.Recv()
+	if err != nil {
+		return nil, err
+	}
+
+	sent, err := client.CreateMessage(channelID, discord.NewMessageCreateBuilder().SetContent(thinkingMessage).Build())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create progress message: %w", err)
+	}
+
+	reply := &progressReply{
+		rest:      client,
+		channelID: channelID,
+		messageID: sent.ID,
+		status:    thinkingMessage,
+		lastEdit:  time.Now(),
+	}
+
+	for {
+		switch e := event.Event.(type) {
+		case *centralproto.CallFnUserInputStreamEvent_ToolStarted_:
+			reply.status = fmt.Sprintf("The reaper is working on it (%s)...", e.ToolStarted.GetToolName())
+			reply.dirty = true
+		case *centralproto.CallFnUserInputStreamEvent_ToolFinished_:
+			bot.logger.DebugContext(ctx, "tool finished", slog.Any("tool", e.ToolFinished))
+		case *centralproto.CallFnUserInputStreamEvent_TextDelta_:
+			reply.text.WriteString(e.TextDelta.GetText())
+			reply.dirty = true
+		case *centralproto.CallFnUserInputStreamEvent_Done:
+			return e.Done, bot.finishReply(reply, e.Done.GetResponseMessage())
+		}
+
+		if err := reply.flush(false); err != nil {
+			bot.logger.ErrorContext(ctx, "failed to update progress message", slog.Any("err", err))
+		}
+
+		event, err = stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("%w: central stream ended without a response", errReplied)
+		}
+		if err != nil {
+			reply.text.Reset()
+			reply.status = "The reaper lost their train of thought, please try again."
+			reply.dirty = true
+			if err := reply.flush(true); err != nil {
+				bot.logger.ErrorContext(ctx, "failed to update progress message", slog.Any("err", err))
+			}
+
+			return nil, fmt.Errorf("%w: %w", errReplied, err)
+		}
+	}
+}
+
+// Replaces the progress reply with the full response message
+func (bot *DiscordBot) finishReply(reply *progressReply, message string) error {
+	if len(message) <= maxMessageLength {
+		reply.text.Reset()
+		reply.text.WriteString(message)
+		reply.dirty = true
+
+		return reply.flush(true)
+	}
+
+	bot.logger.Error("RESPONSE MESSAGE LENGTH REACHED", slog.Int("length", len(message)))
+
+	// Okay this is kinda stupid but I'll try it for now, see how bad it is
+	msgs := strings.Split(message, "\n")
+
+	reply.text.Reset()
+	reply.text.WriteString(msgs[0])
+	reply.dirty = true
+	if err := reply.flush(true); err != nil {
+		return err
+	}
+
+	for _, msg := range msgs[1:] {
+		// Create response message to the user's DM channel
+		sent, err := reply.rest.CreateMessage(
+			reply.channelID,
+			discord.NewMessageCreateBuilder().SetContentf(
+				"%s",
+				msg,
+			).Build(),
+		)
+		if err != nil {
+			return err
+		}
+		bot.logger.Debug("created message successfully", slog.Any("msg", sent))
+	}
+
+	return nil
+}
@@ -19,9 +19,28 @@ type Config struct {
 
 	// Endpoint Configuration
 	CentralServerAddress string `mapstructure:"central_server_address" json:"central_server_address,omitempty"`
+	// Prometheus metrics are served on their own listener. Empty disables them.
+	MetricsAddress string `mapstructure:"metrics_address" json:"metrics_address,omitempty"`
+
+	// Dials central over TLS when enabled. An empty CA uses the system roots, and the
+	// cert and key are presented when central requires mutual TLS.
+	CentralTLS           bool   `mapstructure:"central_tls" json:"central_tls,omitempty"`
+	CentralTLSCAFile     string `mapstructure:"central_tls_ca_file" json:"central_tls_ca_file,omitempty"`
+	CentralTLSCertFile   string `mapstructure:"central_tls_cert_file" json:"central_tls_cert_file,omitempty"`
+	CentralTLSKeyFile    string `mapstructure:"central_tls_key_file" json:"central_tls_key_file,omitempty"`
+	CentralTLSServerName string `mapstructure:"central_tls_server_name" json:"central_tls_server_name,omitempty"`
+
+	// Spans are exported to an OTLP gRPC collector when tracing is enabled. The sample
+	// ratio only applies to traces started here, the rest follow their caller.
+	TracingEnabled     bool    `mapstructure:"tracing_enabled" json:"tracing_enabled,omitempty"`
+	TracingEndpoint    string  `mapstructure:"tracing_endpoint" json:"tracing_endpoint,omitempty"`
+	TracingInsecure    bool    `mapstructure:"tracing_insecure" json:"tracing_insecure,omitempty"`
+	TracingSampleRatio float64 `mapstructure:"tracing_sample_ratio" json:"tracing_sample_ratio,omitempty"`
 
 	// Spicy
 	BotToken string `mapstructure:"bot_token" json:"-"`
+	// Service token the bot authenticates to central with, acting for its users
+	CentralToken string `mapstructure:"central_token" json:"-"`
 }
 
 func NewConfig(debug bool) (*Config, error) {
@@ -41,11 +60,24 @@ func NewConfig(debug bool) (*Config, error) {
 	vip.SetDefault("log_add_source", true)
 	vip.SetDefault("log_request_id", true)
 	vip.SetDefault("central_server_address", bindings.DefaultCentralAddress)
+	vip.SetDefault("metrics_address", bindings.DefaultDiscordMetricsAddress)
+	vip.SetDefault("central_tls", false)
+	vip.SetDefault("central_tls_ca_file", "")
+	vip.SetDefault("central_tls_cert_file", "")
+	vip.SetDefault("central_tls_key_file", "")
+	vip.SetDefault("central_tls_server_name", "")
+	vip.SetDefault("tracing_enabled", false)
+	vip.SetDefault("tracing_endpoint", bindings.DefaultOTLPAddress)
+	vip.SetDefault("tracing_insecure", true)
+	vip.SetDefault("tracing_sample_ratio", 1.0)
 
 	// Spicy bindings
 	if err := vip.BindEnv("bot_token"); err != nil {
 		return &Config{}, err
 	}
+	if err := vip.BindEnv("central_token"); err != nil {
+		return &Config{}, err
+	}
 	// Magic to unamrshal viper into the config sturct. The decode hook is used to map things like the logging level
 	// into the slog logging level type.
 	if err := vip.Unmarshal(&base, viper.DecodeHook(mapstructure.TextUnmarshallerHookFunc())); err != nil {
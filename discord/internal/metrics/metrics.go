@@ -0,0 +1,39 @@
+// Prometheus metrics recorded by the discord bot. Everything is registered with
+// the default registry.
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Event names, matching the gateway events the bot listens to
+const (
+	EventDMMessageCreate            = "dm_message_create"
+	EventMessageCreate              = "message_create"
+	EventComponentInteractionCreate = "component_interaction_create"
+)
+
+const (
+	// The listener ran to completion
+	OutcomeHandled = "handled"
+	// The listener panicked and was recovered
+	OutcomePanicked = "panicked"
+)
+
+var (
+	Events = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "reaphur",
+		Subsystem: "discord",
+		Name:      "events_total",
+		Help:      "Discord events handled, by event and outcome, either handled or panicked.",
+	}, []string{"event", "outcome"})
+
+	EventDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "reaphur",
+		Subsystem: "discord",
+		Name:      "event_duration_seconds",
+		Help:      "Time taken handling discord events, by event.",
+		Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60},
+	}, []string{"event"})
+)
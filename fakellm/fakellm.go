@@ -0,0 +1,67 @@
+package fakellm
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+
+	"github.com/calamity-m/reaphur/fakellm/internal/conf"
+	"github.com/calamity-m/reaphur/pkg/bindings"
+	"github.com/calamity-m/reaphur/pkg/fakellm"
+	"github.com/calamity-m/reaphur/pkg/logging"
+	"github.com/spf13/cobra"
+)
+
+var (
+	FakeLLMCommand = &cobra.Command{
+		Use:   "fake-llm",
+		Short: "run a fake openai compatible llm",
+		Long:  `run an openai compatible chat completions server that replies from a script, or records/replays real exchanges`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := conf.NewConfig(bindings.Debug)
+			if err != nil {
+				fmt.Printf("Failed to create config: %v\n", err)
+				return err
+			}
+
+			// Create logger
+			logger := slog.New(logging.NewCustomizedHandler(os.Stderr, &logging.CustomHandlerCfg{
+				Structed:        cfg.LogStructured,
+				RecordRequestId: cfg.LogRequestId,
+				Level:           cfg.LogLevel,
+				AddSource:       cfg.LogAddSource,
+				StaticAttributes: []slog.Attr{
+					slog.String("system", "reap"),
+					slog.String("environment", cfg.Environment),
+				},
+			}))
+
+			handler, err := newHandler(logger, cfg)
+			if err != nil {
+				logger.Error("failed to create fake llm", slog.Any("err", err))
+				return err
+			}
+
+			logger.Info(fmt.Sprintf("Fake LLM running in %s mode on %s", cfg.Mode, cfg.Address))
+			return http.ListenAndServe(cfg.Address, handler)
+		},
+	}
+)
+
+func newHandler(logger *slog.Logger, cfg *conf.Config) (http.Handler, error) {
+	switch cfg.Mode {
+	case conf.ModeScript:
+		script, err := fakellm.LoadScript(cfg.ScriptFile)
+		if err != nil {
+			return nil, err
+		}
+		return fakellm.NewScriptedServer(logger, script), nil
+	case conf.ModeRecord:
+		return fakellm.NewRecorder(logger, cfg.UpstreamURL, cfg.UpstreamToken, cfg.FixturesDir)
+	case conf.ModeReplay:
+		return fakellm.NewReplayer(logger, cfg.FixturesDir)
+	default:
+		return nil, fmt.Errorf("unknown fake llm mode %q", cfg.Mode)
+	}
+}
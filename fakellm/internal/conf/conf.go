@@ -0,0 +1,79 @@
+package conf
+
+import (
+	"log/slog"
+	"strings"
+
+	"github.com/calamity-m/reaphur/pkg/bindings"
+	"github.com/mitchellh/mapstructure"
+	"github.com/spf13/viper"
+)
+
+const (
+	ModeScript = "script"
+	ModeRecord = "record"
+	ModeReplay = "replay"
+)
+
+type Config struct {
+	// Logging Configuration
+	Environment   string     `mapstructure:"environment" json:"environment,omitempty"`
+	LogLevel      slog.Level `mapstructure:"log_level" json:"log_level,omitempty"`
+	LogStructured bool       `mapstructure:"log_structured" json:"log_structured,omitempty"`
+	LogAddSource  bool       `mapstructure:"log_add_source" json:"log_add_source,omitempty"`
+	LogRequestId  bool       `mapstructure:"log_request_id" json:"log_request_id,omitempty"`
+
+	// Listener configuration
+	Address string `mapstructure:"address" json:"address,omitempty"`
+
+	// Fake configuration. Mode is one of script, record or replay.
+	Mode        string `mapstructure:"mode" json:"mode,omitempty"`
+	ScriptFile  string `mapstructure:"script_file" json:"script_file,omitempty"`
+	FixturesDir string `mapstructure:"fixtures_dir" json:"fixtures_dir,omitempty"`
+	UpstreamURL string `mapstructure:"upstream_url" json:"upstream_url,omitempty"`
+
+	// Spicy
+	UpstreamToken string `mapstructure:"upstream_token" json:"-"`
+}
+
+func NewConfig(debug bool) (*Config, error) {
+	// Setup
+	base := &Config{}
+	vip := viper.New()
+
+	// Enable ENV var reading
+	vip.AutomaticEnv()
+	vip.SetEnvPrefix("FAKELLM")
+	vip.SetEnvKeyReplacer(strings.NewReplacer("-", "_"))
+
+	// Establish sane defaults before overriding
+	vip.SetDefault("environment", "dev")
+	vip.SetDefault("log_level", slog.LevelDebug)
+	vip.SetDefault("log_structured", false)
+	vip.SetDefault("log_add_source", true)
+	vip.SetDefault("log_request_id", false)
+	vip.SetDefault("address", bindings.DefaultFakeLLMAddress)
+	vip.SetDefault("mode", ModeScript)
+	vip.SetDefault("script_file", "test/fakellm/script.json")
+	vip.SetDefault("fixtures_dir", "test/fakellm/fixtures")
+	vip.SetDefault("upstream_url", "https://api.openai.com/v1")
+
+	// Spicy bindings
+	if err := vip.BindEnv("upstream_token"); err != nil {
+		return &Config{}, err
+	}
+	// Magic to unamrshal viper into the config sturct. The decode hook is used to map things like the logging level
+	// into the slog logging level type.
+	if err := vip.Unmarshal(&base, viper.DecodeHook(mapstructure.TextUnmarshallerHookFunc())); err != nil {
+		return &Config{}, err
+	}
+
+	// Forecefully override if we're on debug mode
+	// Do this without viper/cobra buy-in and just do the simple
+	// brute force
+	if debug {
+		base.LogLevel = slog.LevelDebug
+	}
+
+	return base, nil
+}
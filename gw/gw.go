@@ -2,21 +2,35 @@ package gw
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
 	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/calamity-m/reaphur/gw/internal/conf"
 	"github.com/calamity-m/reaphur/pkg/bindings"
+	"github.com/calamity-m/reaphur/pkg/inproc"
 	"github.com/calamity-m/reaphur/pkg/logging"
+	"github.com/calamity-m/reaphur/pkg/middleware"
+	"github.com/calamity-m/reaphur/pkg/telemetry"
+	"github.com/calamity-m/reaphur/pkg/tlsconf"
 	centralproto "github.com/calamity-m/reaphur/proto/v1/central"
 	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
 	"github.com/spf13/cobra"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 )
 
+// Gateway route for CallFnUserInputStream, streaming newline delimited JSON
+const callFnUserInputStreamPath = "/centralproto.v1.CentralService/CallFnUserInputStream"
+
 var (
 	GRPCGatewayCommand = &cobra.Command{
 		Use:   "gw",
@@ -41,12 +55,77 @@ var (
 				},
 			}))
 
-			return run(logger, cfg)
+			stopTracing, err := telemetry.SetupTracing(context.Background(), logger, telemetry.TracingOptions{
+				Enabled:     cfg.TracingEnabled,
+				ServiceName: "reaphur-gw",
+				Endpoint:    cfg.TracingEndpoint,
+				Insecure:    cfg.TracingInsecure,
+				SampleRatio: cfg.TracingSampleRatio,
+			})
+			if err != nil {
+				return err
+			}
+			defer stopTracing()
+
+			metricsCtx, stopMetrics := context.WithCancel(context.Background())
+			defer stopMetrics()
+			telemetry.ServeMetrics(metricsCtx, logger, cfg.MetricsAddress)
+
+			upstream, err := centralDialOptions(logger, cfg)
+			if err != nil {
+				return err
+			}
+
+			// Serve until terminated, then drain in flight requests
+			sig := make(chan os.Signal, 2)
+			signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM)
+
+			return run(logger, cfg, cfg.CentralServerAddress, upstream, sig)
 		},
 	}
 )
 
-func run(logger *slog.Logger, cfg *conf.Config) error {
+// Runs the gateway in front of central for the all in one mode, until notify is
+// pushed to or closed. Central is reached over the link, or the configured
+// address when central isn't run in process.
+func RunInProcess(logger *slog.Logger, link *inproc.Link, notify <-chan os.Signal) error {
+	cfg, err := conf.NewConfig(bindings.Debug)
+	if err != nil {
+		return err
+	}
+
+	if link != nil {
+		return run(logger, cfg, link.Target(), link.DialOptions(), notify)
+	}
+
+	upstream, err := centralDialOptions(logger, cfg)
+	if err != nil {
+		return err
+	}
+
+	return run(logger, cfg, cfg.CentralServerAddress, upstream, notify)
+}
+
+// Connects to central over the network, with TLS when configured
+func centralDialOptions(logger *slog.Logger, cfg *conf.Config) ([]grpc.DialOption, error) {
+	creds, err := tlsconf.DialCredentials(logger, tlsconf.ClientOptions{
+		Enabled:    cfg.CentralTLS,
+		CAFile:     cfg.CentralTLSCAFile,
+		CertFile:   cfg.CentralTLSCertFile,
+		KeyFile:    cfg.CentralTLSKeyFile,
+		ServerName: cfg.CentralTLSServerName,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return []grpc.DialOption{grpc.WithTransportCredentials(creds)}, nil
+}
+
+// Serves the gateway until notify is pushed to or closed, proxying to central
+// at target. The upstream options decide how central is connected to, such as
+// its transport credentials.
+func run(logger *slog.Logger, cfg *conf.Config, target string, upstream []grpc.DialOption, notify <-chan os.Signal) error {
 	ctx := context.Background()
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
@@ -54,17 +133,40 @@ func run(logger *slog.Logger, cfg *conf.Config) error {
 	ssmux := http.NewServeMux()
 
 	// Register gRPC server endpoint
-	// Note: Make sure the gRPC server is running properly and accessible
+	// Note: Make sure the gRPC server is running properly and accessible.
+	// The Authorization header is forwarded to central as authorization metadata.
 	mux := runtime.NewServeMux()
-	opts := []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}
-	err := centralproto.RegisterCentralServiceHandlerFromEndpoint(ctx, mux, bindings.DefaultCentralAddress, opts)
+	opts := append(slices.Clone(upstream),
+		grpc.WithStatsHandler(telemetry.GRPCClientHandler()),
+		grpc.WithChainUnaryInterceptor(middleware.RequestIdUnaryClientInterceptor(), middleware.MetricsUnaryClientInterceptor()),
+		grpc.WithChainStreamInterceptor(middleware.RequestIdStreamClientInterceptor(), middleware.MetricsStreamClientInterceptor()),
+	)
+	err := centralproto.RegisterCentralServiceHandlerFromEndpoint(ctx, mux, target, opts)
 	if err != nil {
 		return err
 	}
-	err = centralproto.RegisterCentralFoodServiceHandlerFromEndpoint(ctx, mux, bindings.DefaultCentralAddress, opts)
+	err = centralproto.RegisterCentralFoodServiceHandlerFromEndpoint(ctx, mux, target, opts)
 	if err != nil {
 		return err
 	}
+	if cfg.AdminEnabled {
+		err = centralproto.RegisterCentralAdminServiceHandlerFromEndpoint(ctx, mux, target, opts)
+		if err != nil {
+			return err
+		}
+	}
+
+	// grpc-gateway can't produce server sent events, so the stream gets its own handler
+	conn, err := grpc.NewClient(target, opts...)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	ssmux.HandleFunc(callFnUserInputSSEPath, callFnUserInputSSEHandler(logger, centralproto.NewCentralServiceClient(conn)))
+
+	// Probes for orchestrators, reflecting central's health
+	ssmux.HandleFunc(healthzPath, healthzHandler(logger, healthpb.NewHealthClient(conn)))
+	ssmux.HandleFunc(readyzPath, readyzHandler(logger, healthpb.NewHealthClient(conn)))
 
 	// mount a path to expose the generated OpenAPI specification on disk
 	ssmux.HandleFunc("/swagger-ui/swagger.json", func(w http.ResponseWriter, r *http.Request) {
@@ -75,12 +177,91 @@ func run(logger *slog.Logger, cfg *conf.Config) error {
 		http.ServeFile(w, r, "./proto/v1/central/central_food.swagger.json")
 	})
 
+	ssmux.HandleFunc("/swagger-ui/swagger-admin.json", func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, "./proto/v1/central/central_admin.swagger.json")
+	})
+
 	// mount the Swagger UI that uses the OpenAPI specification path above
 	ssmux.Handle("/swagger-ui/", http.StripPrefix("/swagger-ui/", http.FileServer(http.Dir("./gw/swagger"))))
 
 	ssmux.Handle("/", mux)
 
+	// Streams are exempted from the write timeout first, as nothing inside the
+	// http span can reach the server's connection. Request ids are handed on to
+	// central and sent back in the X-Request-ID header, then logged with the
+	// trace. CORS answers preflights before any body is read.
+	handler := middleware.Wrap(
+		unlimitedWrites(callFnUserInputSSEPath, callFnUserInputStreamPath),
+		otelhttp.NewMiddleware("gw", otelhttp.WithSpanNameFormatter(httpSpanName)),
+		middleware.RequestIDMiddleware(logger, true),
+		middleware.LoggingMiddleware(logger),
+		middleware.CORSMiddleware(middleware.CORSOptions{
+			AllowedOrigins:   splitList(cfg.CORSAllowedOrigins),
+			AllowedMethods:   splitList(cfg.CORSAllowedMethods),
+			AllowedHeaders:   splitList(cfg.CORSAllowedHeaders),
+			ExposedHeaders:   splitList(cfg.CORSExposedHeaders),
+			AllowCredentials: cfg.CORSAllowCredentials,
+			MaxAge:           cfg.CORSMaxAge,
+		}),
+		middleware.MaxBytesMiddleware(cfg.MaxBodyBytes),
+	)(ssmux)
+
+	server := &http.Server{
+		Addr:              cfg.Address,
+		Handler:           handler,
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		ReadTimeout:       cfg.ReadTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+		MaxHeaderBytes:    cfg.MaxHeaderBytes,
+	}
+
+	stopped := make(chan error, 1)
+	go func() {
+		defer close(stopped)
+
+		// Block until we're told to stop, then let in flight requests finish
+		<-notify
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+		defer cancel()
+		stopped <- server.Shutdown(shutdownCtx)
+	}()
+
 	// Start HTTP server (and proxy calls to gRPC server endpoint)
-	logger.Info(fmt.Sprintf("Listening on %s", cfg.Address))
-	return http.ListenAndServe(cfg.Address, ssmux)
+	logger.Info(fmt.Sprintf("Listening on %s, proxying to %s", cfg.Address, target))
+	if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	return <-stopped
+}
+
+// Names http spans by method only, as paths carry record ids
+func httpSpanName(operation string, r *http.Request) string {
+	return operation + " " + r.Method
+}
+
+// Lifts the server's write timeout for the streaming paths, which stay open
+// for as long as central takes to respond
+func unlimitedWrites(paths ...string) func(http.Handler) http.Handler {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if slices.Contains(paths, r.URL.Path) {
+				_ = http.NewResponseController(w).SetWriteDeadline(time.Time{})
+			}
+			h.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Splits a comma separated config list, dropping empty entries
+func splitList(list string) []string {
+	var items []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
 }
@@ -0,0 +1,86 @@
+package gw
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/calamity-m/reaphur/pkg/bindings"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+const (
+	healthzPath = "/healthz"
+	readyzPath  = "/readyz"
+
+	// How long central gets to answer a health check
+	healthCheckTimeout = 2 * time.Second
+)
+
+// Services reported on by readyz alongside central's overall status
+var readyServices = []string{bindings.HealthServiceRedis, bindings.HealthServiceFoodIndex, bindings.HealthServiceLLM}
+
+type healthResponse struct {
+	Status   string            `json:"status"`
+	Services map[string]string `json:"services,omitempty"`
+	Error    string            `json:"error,omitempty"`
+}
+
+func writeHealth(w http.ResponseWriter, code int, body healthResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(body)
+}
+
+// Liveness. Fine as long as central answers, even if it isn't ready to serve,
+// so a struggling dependency doesn't get the gateway restarted.
+func healthzHandler(logger *slog.Logger, client healthpb.HealthClient) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), healthCheckTimeout)
+		defer cancel()
+
+		if _, err := client.Check(ctx, &healthpb.HealthCheckRequest{}); err != nil {
+			logger.WarnContext(r.Context(), "central failed liveness check", slog.Any("err", err))
+			writeHealth(w, http.StatusServiceUnavailable, healthResponse{Status: "unreachable", Error: err.Error()})
+			return
+		}
+
+		writeHealth(w, http.StatusOK, healthResponse{Status: "ok"})
+	}
+}
+
+// Readiness. Only ready while central's overall status is serving, with each
+// dependency's status included to show why not.
+func readyzHandler(logger *slog.Logger, client healthpb.HealthClient) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), healthCheckTimeout)
+		defer cancel()
+
+		overall, err := client.Check(ctx, &healthpb.HealthCheckRequest{})
+		if err != nil {
+			logger.WarnContext(r.Context(), "central failed readiness check", slog.Any("err", err))
+			writeHealth(w, http.StatusServiceUnavailable, healthResponse{Status: "unreachable", Error: err.Error()})
+			return
+		}
+
+		body := healthResponse{Status: overall.GetStatus().String(), Services: make(map[string]string, len(readyServices))}
+		for _, service := range readyServices {
+			resp, err := client.Check(ctx, &healthpb.HealthCheckRequest{Service: service})
+			if err != nil {
+				body.Services[service] = healthpb.HealthCheckResponse_UNKNOWN.String()
+				continue
+			}
+			body.Services[service] = resp.GetStatus().String()
+		}
+
+		code := http.StatusOK
+		if overall.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+			code = http.StatusServiceUnavailable
+		}
+
+		writeHealth(w, code, body)
+	}
+}
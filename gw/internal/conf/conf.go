@@ -3,6 +3,7 @@ package conf
 import (
 	"log/slog"
 	"strings"
+	"time"
 
 	"github.com/calamity-m/reaphur/pkg/bindings"
 	"github.com/mitchellh/mapstructure"
@@ -20,6 +21,51 @@ type Config struct {
 	// Listener configuration
 	Address              string `mapstructure:"address" json:"address,omitempty"`
 	CentralServerAddress string `mapstructure:"central_server_address" json:"central_server_address,omitempty"`
+	// Prometheus metrics are served on their own listener, away from the public
+	// address. Empty disables them.
+	MetricsAddress string `mapstructure:"metrics_address" json:"metrics_address,omitempty"`
+
+	// Exposes central's admin service, such as usage reports for every user.
+	// Central only keeps it to services when its auth is enabled, so this is off
+	// unless asked for.
+	AdminEnabled bool `mapstructure:"admin_enabled" json:"admin_enabled,omitempty"`
+
+	// HTTP server limits. Streaming routes are exempt from the write timeout, as
+	// they're open for as long as central takes to respond.
+	ReadHeaderTimeout time.Duration `mapstructure:"read_header_timeout" json:"read_header_timeout,omitempty"`
+	ReadTimeout       time.Duration `mapstructure:"read_timeout" json:"read_timeout,omitempty"`
+	WriteTimeout      time.Duration `mapstructure:"write_timeout" json:"write_timeout,omitempty"`
+	IdleTimeout       time.Duration `mapstructure:"idle_timeout" json:"idle_timeout,omitempty"`
+	// How long in flight requests get to finish on shutdown
+	ShutdownTimeout time.Duration `mapstructure:"shutdown_timeout" json:"shutdown_timeout,omitempty"`
+	MaxHeaderBytes  int           `mapstructure:"max_header_bytes" json:"max_header_bytes,omitempty"`
+	// Largest request body accepted, big enough for a request with every image
+	// attachment inlined
+	MaxBodyBytes int64 `mapstructure:"max_body_bytes" json:"max_body_bytes,omitempty"`
+
+	// Cross origin requests from browsers. Lists are comma separated, and without
+	// any allowed origins CORS is disabled.
+	CORSAllowedOrigins   string        `mapstructure:"cors_allowed_origins" json:"cors_allowed_origins,omitempty"`
+	CORSAllowedMethods   string        `mapstructure:"cors_allowed_methods" json:"cors_allowed_methods,omitempty"`
+	CORSAllowedHeaders   string        `mapstructure:"cors_allowed_headers" json:"cors_allowed_headers,omitempty"`
+	CORSExposedHeaders   string        `mapstructure:"cors_exposed_headers" json:"cors_exposed_headers,omitempty"`
+	CORSAllowCredentials bool          `mapstructure:"cors_allow_credentials" json:"cors_allow_credentials,omitempty"`
+	CORSMaxAge           time.Duration `mapstructure:"cors_max_age" json:"cors_max_age,omitempty"`
+
+	// Dials central over TLS when enabled. An empty CA uses the system roots, and the
+	// cert and key are presented when central requires mutual TLS.
+	CentralTLS           bool   `mapstructure:"central_tls" json:"central_tls,omitempty"`
+	CentralTLSCAFile     string `mapstructure:"central_tls_ca_file" json:"central_tls_ca_file,omitempty"`
+	CentralTLSCertFile   string `mapstructure:"central_tls_cert_file" json:"central_tls_cert_file,omitempty"`
+	CentralTLSKeyFile    string `mapstructure:"central_tls_key_file" json:"central_tls_key_file,omitempty"`
+	CentralTLSServerName string `mapstructure:"central_tls_server_name" json:"central_tls_server_name,omitempty"`
+
+	// Spans are exported to an OTLP gRPC collector when tracing is enabled. The sample
+	// ratio only applies to traces started here, the rest follow their caller.
+	TracingEnabled     bool    `mapstructure:"tracing_enabled" json:"tracing_enabled,omitempty"`
+	TracingEndpoint    string  `mapstructure:"tracing_endpoint" json:"tracing_endpoint,omitempty"`
+	TracingInsecure    bool    `mapstructure:"tracing_insecure" json:"tracing_insecure,omitempty"`
+	TracingSampleRatio float64 `mapstructure:"tracing_sample_ratio" json:"tracing_sample_ratio,omitempty"`
 }
 
 func NewConfig(debug bool) (*Config, error) {
@@ -41,10 +87,37 @@ func NewConfig(debug bool) (*Config, error) {
 	vip.SetDefault("address", bindings.DefaultGWAddress)
 	vip.SetDefault("reflect", true)
 	vip.SetDefault("central_server_address", bindings.DefaultCentralAddress)
+	vip.SetDefault("metrics_address", bindings.DefaultGWMetricsAddress)
+	vip.SetDefault("read_header_timeout", 5*time.Second)
+	vip.SetDefault("read_timeout", 30*time.Second)
+	vip.SetDefault("write_timeout", 60*time.Second)
+	vip.SetDefault("idle_timeout", 120*time.Second)
+	vip.SetDefault("shutdown_timeout", 10*time.Second)
+	vip.SetDefault("max_header_bytes", 1<<20)
+	vip.SetDefault("max_body_bytes", 32<<20)
+	vip.SetDefault("cors_allowed_origins", "")
+	vip.SetDefault("cors_allowed_methods", "GET,POST,PUT,PATCH,DELETE")
+	vip.SetDefault("cors_allowed_headers", "Authorization,Content-Type,X-Request-ID")
+	vip.SetDefault("cors_exposed_headers", "X-Request-ID")
+	vip.SetDefault("cors_allow_credentials", false)
+	vip.SetDefault("cors_max_age", 10*time.Minute)
+	vip.SetDefault("admin_enabled", false)
+	vip.SetDefault("central_tls", false)
+	vip.SetDefault("central_tls_ca_file", "")
+	vip.SetDefault("central_tls_cert_file", "")
+	vip.SetDefault("central_tls_key_file", "")
+	vip.SetDefault("central_tls_server_name", "")
+	vip.SetDefault("tracing_enabled", false)
+	vip.SetDefault("tracing_endpoint", bindings.DefaultOTLPAddress)
+	vip.SetDefault("tracing_insecure", true)
+	vip.SetDefault("tracing_sample_ratio", 1.0)
 
 	// Magic to unamrshal viper into the config sturct. The decode hook is used to map things like the logging level
 	// into the slog logging level type.
-	if err := vip.Unmarshal(&base, viper.DecodeHook(mapstructure.TextUnmarshallerHookFunc())); err != nil {
+	if err := vip.Unmarshal(&base, viper.DecodeHook(mapstructure.ComposeDecodeHookFunc(
+		mapstructure.TextUnmarshallerHookFunc(),
+		mapstructure.StringToTimeDurationHookFunc(),
+	))); err != nil {
 		return &Config{}, err
 	}
 
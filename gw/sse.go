@@ -0,0 +1,145 @@
+package gw
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/calamity-m/reaphur/pkg/auth"
+	centralproto "github.com/calamity-m/reaphur/proto/v1/central"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// Path the CallFnUserInputStream server sent events are served on. The regular
+// gateway route streams newline delimited JSON, which browsers can't consume
+// with EventSource.
+const callFnUserInputSSEPath = "/sse/centralproto.v1.CentralService/CallFnUserInputStream"
+
+// Relays CallFnUserInputStream as server sent events. The request body is a JSON
+// encoded CallFnUserInputRequest. Each event is named after the event type, i.e.
+// tool_started, tool_finished, text_delta and done. Failures before the first
+// event are returned as a status, while later ones are sent as an error event, as
+// the status code has already been written.
+func callFnUserInputSSEHandler(logger *slog.Logger, client centralproto.CentralServiceClient) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if maxBytesErr := new(http.MaxBytesError); errors.As(err, &maxBytesErr) {
+			http.Error(w, fmt.Sprintf("body larger than %d bytes", maxBytesErr.Limit), http.StatusRequestEntityTooLarge)
+			return
+		}
+		if err != nil {
+			http.Error(w, "failed to read body", http.StatusBadRequest)
+			return
+		}
+
+		req := &centralproto.CallFnUserInputRequest{}
+		if err := protojson.Unmarshal(body, req); err != nil {
+			http.Error(w, fmt.Sprintf("invalid request: %v", err), http.StatusBadRequest)
+			return
+		}
+
+		// The gateway forwards the Authorization header for its own routes, so do the same
+		ctx := r.Context()
+		if authorization := r.Header.Get("Authorization"); authorization != "" {
+			ctx = metadata.AppendToOutgoingContext(ctx, auth.AuthorizationMetadataKey, authorization)
+		}
+
+		stream, err := client.CallFnUserInputStream(ctx, req)
+		if err != nil {
+			logger.ErrorContext(r.Context(), "failed to open central stream", slog.Any("err", err))
+			writeStatus(w, status.Convert(err))
+			return
+		}
+
+		// Central fails calls it won't serve, such as unauthenticated or rate
+		// limited ones, on the first receive. Those are answered with their status
+		// before the stream's headers are committed.
+		event, err := stream.Recv()
+		if err != nil && !errors.Is(err, io.EOF) {
+			logger.ErrorContext(r.Context(), "central stream failed", slog.Any("err", err))
+			writeStatus(w, status.Convert(err))
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.WriteHeader(http.StatusOK)
+		flusher.Flush()
+
+		for ; ; event, err = stream.Recv() {
+			if errors.Is(err, io.EOF) {
+				return
+			}
+			if err != nil {
+				logger.ErrorContext(r.Context(), "central stream failed", slog.Any("err", err))
+				data, _ := protojson.Marshal(status.Convert(err).Proto())
+				writeSSE(w, "error", data)
+				flusher.Flush()
+				return
+			}
+
+			data, err := protojson.Marshal(event)
+			if err != nil {
+				logger.ErrorContext(r.Context(), "failed to marshal stream event", slog.Any("err", err))
+				return
+			}
+
+			if err := writeSSE(w, sseEventName(event), data); err != nil {
+				// Client went away, cancelling the request context stops central too
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+func sseEventName(event *centralproto.CallFnUserInputStreamEvent) string {
+	switch event.Event.(type) {
+	case *centralproto.CallFnUserInputStreamEvent_ToolStarted_:
+		return "tool_started"
+	case *centralproto.CallFnUserInputStreamEvent_ToolFinished_:
+		return "tool_finished"
+	case *centralproto.CallFnUserInputStreamEvent_TextDelta_:
+		return "text_delta"
+	case *centralproto.CallFnUserInputStreamEvent_Done:
+		return "done"
+	default:
+		return "message"
+	}
+}
+
+// Writes a status the same way the gateway does, so callers can handle errors
+// from either route alike
+func writeStatus(w http.ResponseWriter, st *status.Status) {
+	data, err := protojson.Marshal(st.Proto())
+	if err != nil {
+		http.Error(w, st.Message(), runtime.HTTPStatusFromCode(st.Code()))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(runtime.HTTPStatusFromCode(st.Code()))
+	w.Write(data)
+}
+
+func writeSSE(w io.Writer, event string, data []byte) error {
+	_, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data)
+	return err
+}
@@ -0,0 +1,100 @@
+package gw
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	centralproto "github.com/calamity-m/reaphur/proto/v1/central"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Replays events and then err, or io.EOF when err is nil
+type stubStream struct {
+	grpc.ClientStream
+	events []*centralproto.CallFnUserInputStreamEvent
+	err    error
+}
+
+func (s *stubStream) Recv() (*centralproto.CallFnUserInputStreamEvent, error) {
+	if len(s.events) > 0 {
+		event := s.events[0]
+		s.events = s.events[1:]
+		return event, nil
+	}
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	return nil, io.EOF
+}
+
+type stubCentralClient struct {
+	centralproto.CentralServiceClient
+	stream *stubStream
+}
+
+func (c *stubCentralClient) CallFnUserInputStream(ctx context.Context, in *centralproto.CallFnUserInputRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[centralproto.CallFnUserInputStreamEvent], error) {
+	return c.stream, nil
+}
+
+func serveSSE(t *testing.T, stream *stubStream) *httptest.ResponseRecorder {
+	t.Helper()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	handler := callFnUserInputSSEHandler(logger, &stubCentralClient{stream: stream})
+
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodPost, callFnUserInputSSEPath, strings.NewReader(`{"requestUserId":"user","requestUserInput":"hi"}`)))
+
+	return rec
+}
+
+func TestSSEFirstReceiveErrorIsStatus(t *testing.T) {
+	failures := map[codes.Code]int{
+		codes.Unauthenticated:   http.StatusUnauthorized,
+		codes.ResourceExhausted: http.StatusTooManyRequests,
+		codes.InvalidArgument:   http.StatusBadRequest,
+	}
+	for code, want := range failures {
+		rec := serveSSE(t, &stubStream{err: status.Error(code, "nope")})
+
+		if rec.Code != want {
+			t.Errorf("%s: got status %d but want %d", code, rec.Code, want)
+		}
+		if got := rec.Header().Get("Content-Type"); got != "application/json" {
+			t.Errorf("%s: got content type %q but want a json status", code, got)
+		}
+		if strings.Contains(rec.Body.String(), "event:") {
+			t.Errorf("%s: got events %q but want none", code, rec.Body.String())
+		}
+	}
+}
+
+func TestSSELaterErrorIsEvent(t *testing.T) {
+	delta := &centralproto.CallFnUserInputStreamEvent{Event: &centralproto.CallFnUserInputStreamEvent_TextDelta_{
+		TextDelta: &centralproto.CallFnUserInputStreamEvent_TextDelta{Text: "hello"},
+	}}
+	rec := serveSSE(t, &stubStream{events: []*centralproto.CallFnUserInputStreamEvent{delta}, err: status.Error(codes.Unavailable, "gone")})
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("got status %d but want the stream started", rec.Code)
+	}
+	if got := rec.Header().Get("Content-Type"); got != "text/event-stream" {
+		t.Errorf("got content type %q", got)
+	}
+
+	body := rec.Body.String()
+	if !strings.Contains(body, "event: text_delta\n") || !strings.Contains(body, "event: error\n") {
+		t.Errorf("got body %q but want the delta then an error event", body)
+	}
+	if strings.Index(body, "event: text_delta") > strings.Index(body, "event: error") {
+		t.Errorf("got body %q but want the delta first", body)
+	}
+}
@@ -0,0 +1,53 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/calamity-m/reaphur/pkg/errs"
+)
+
+// Who a request was made by. Services, such as the discord bot, act on behalf
+// of their users and may name any user. Users may only ever act as themselves,
+// so their subject is bound to the request's user id.
+type Principal struct {
+	Subject string
+	Service bool
+}
+
+type principalKey struct{}
+
+func ContextWithPrincipal(ctx context.Context, principal Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+func PrincipalFromContext(ctx context.Context) (Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(Principal)
+	return principal, ok
+}
+
+// Returned by an Authenticator when a token isn't one it understands, so the
+// next one can be tried
+var ErrUnrecognised = errors.New("unrecognised token")
+
+type Authenticator interface {
+	// Verifies a bearer token, returning who it belongs to
+	Authenticate(ctx context.Context, token string) (Principal, error)
+}
+
+// Tries each authenticator in order, until one recognises the token
+type Chain []Authenticator
+
+func (c Chain) Authenticate(ctx context.Context, token string) (Principal, error) {
+	for _, authenticator := range c {
+		principal, err := authenticator.Authenticate(ctx, token)
+		if errors.Is(err, ErrUnrecognised) {
+			continue
+		}
+
+		return principal, err
+	}
+
+	return Principal{}, fmt.Errorf("token not recognised - %w", errs.ErrUnauthenticated)
+}
@@ -0,0 +1,230 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/calamity-m/reaphur/pkg/errs"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestServiceTokens(t *testing.T) {
+	tokens, err := ParseServiceTokens("discord=bot-secret, other=other-secret")
+	if err != nil {
+		t.Fatalf("got err %v", err)
+	}
+
+	principal, err := tokens.Authenticate(context.Background(), "bot-secret")
+	if err != nil {
+		t.Fatalf("got err %v", err)
+	}
+	if principal.Subject != "discord" || !principal.Service {
+		t.Errorf("got principal %v", principal)
+	}
+
+	if _, err := tokens.Authenticate(context.Background(), "nope"); !errors.Is(err, ErrUnrecognised) {
+		t.Errorf("got err %v but want the token unrecognised", err)
+	}
+
+	if _, err := ParseServiceTokens("discord"); err == nil {
+		t.Errorf("got no err for a token missing its name")
+	}
+}
+
+func claims(subject string, expires time.Time) jwt.RegisteredClaims {
+	return jwt.RegisteredClaims{
+		Subject:   subject,
+		Issuer:    "reaphur",
+		Audience:  jwt.ClaimStrings{"central"},
+		ExpiresAt: jwt.NewNumericDate(expires),
+	}
+}
+
+func TestHMACAuthenticator(t *testing.T) {
+	secret := []byte("0123456789abcdef0123456789abcdef")
+	authenticator, err := NewHMACAuthenticator(secret, "reaphur", "central")
+	if err != nil {
+		t.Fatalf("got err %v", err)
+	}
+
+	sign := func(c jwt.RegisteredClaims, key []byte) string {
+		signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, c).SignedString(key)
+		if err != nil {
+			t.Fatalf("failed signing: %v", err)
+		}
+		return signed
+	}
+
+	valid := sign(claims("user-1", time.Now().Add(time.Hour)), secret)
+	principal, err := authenticator.Authenticate(context.Background(), valid)
+	if err != nil {
+		t.Fatalf("got err %v", err)
+	}
+	if principal.Subject != "user-1" || principal.Service {
+		t.Errorf("got principal %v", principal)
+	}
+
+	rejected := map[string]string{
+		"expired":      sign(claims("user-1", time.Now().Add(-time.Hour)), secret),
+		"wrong secret": sign(claims("user-1", time.Now().Add(time.Hour)), []byte("fedcba9876543210fedcba9876543210")),
+		"no subject":   sign(claims("", time.Now().Add(time.Hour)), secret),
+	}
+	for name, token := range rejected {
+		if _, err := authenticator.Authenticate(context.Background(), token); !errors.Is(err, errs.ErrUnauthenticated) {
+			t.Errorf("%s: got err %v but want unauthenticated", name, err)
+		}
+	}
+
+	if _, err := authenticator.Authenticate(context.Background(), "bot-secret"); !errors.Is(err, ErrUnrecognised) {
+		t.Errorf("got err %v but want non jwts left for other authenticators", err)
+	}
+
+	if _, err := NewHMACAuthenticator([]byte("short"), "", ""); err == nil {
+		t.Errorf("got no err for a short secret")
+	}
+}
+
+func TestJWKSAuthenticator(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	edPublic, edPrivate, _ := ed25519.GenerateKey(rand.Reader)
+
+	b64 := base64.RawURLEncoding.EncodeToString
+	jwks, _ := json.Marshal(map[string]any{"keys": []map[string]string{
+		{"kty": "RSA", "kid": "rsa", "n": b64(rsaKey.N.Bytes()), "e": b64([]byte{1, 0, 1})},
+		{"kty": "EC", "kid": "ec", "crv": "P-256", "x": b64(ecKey.X.Bytes()), "y": b64(ecKey.Y.Bytes())},
+		{"kty": "OKP", "kid": "ed", "crv": "Ed25519", "x": b64(edPublic)},
+	}})
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, jwks, 0o600); err != nil {
+		t.Fatalf("failed writing jwks: %v", err)
+	}
+
+	authenticator, err := NewJWKSAuthenticator(path, "reaphur", "central")
+	if err != nil {
+		t.Fatalf("got err %v", err)
+	}
+
+	keys := []struct {
+		kid    string
+		method jwt.SigningMethod
+		key    any
+	}{
+		{"rsa", jwt.SigningMethodRS256, rsaKey},
+		{"ec", jwt.SigningMethodES256, ecKey},
+		{"ed", jwt.SigningMethodEdDSA, edPrivate},
+	}
+	for _, k := range keys {
+		token := jwt.NewWithClaims(k.method, claims("user-"+k.kid, time.Now().Add(time.Hour)))
+		token.Header["kid"] = k.kid
+		signed, err := token.SignedString(k.key)
+		if err != nil {
+			t.Fatalf("failed signing: %v", err)
+		}
+
+		principal, err := authenticator.Authenticate(context.Background(), signed)
+		if err != nil {
+			t.Errorf("%s: got err %v", k.kid, err)
+		}
+		if principal.Subject != "user-"+k.kid {
+			t.Errorf("%s: got principal %v", k.kid, principal)
+		}
+	}
+
+	// Signed by the rsa key but claiming to be the ec key
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims("user", time.Now().Add(time.Hour)))
+	token.Header["kid"] = "ec"
+	signed, _ := token.SignedString(rsaKey)
+	if _, err := authenticator.Authenticate(context.Background(), signed); !errors.Is(err, errs.ErrUnauthenticated) {
+		t.Errorf("got err %v but want unauthenticated", err)
+	}
+}
+
+func TestChainHMACAndJWKS(t *testing.T) {
+	secret := []byte("0123456789abcdef0123456789abcdef")
+	hmac, err := NewHMACAuthenticator(secret, "reaphur", "central")
+	if err != nil {
+		t.Fatalf("got err %v", err)
+	}
+
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	otherKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	b64 := base64.RawURLEncoding.EncodeToString
+	jwks, _ := json.Marshal(map[string]any{"keys": []map[string]string{
+		{"kty": "EC", "kid": "ec", "crv": "P-256", "x": b64(ecKey.X.Bytes()), "y": b64(ecKey.Y.Bytes())},
+		{"kty": "EC", "kid": "other", "crv": "P-256", "x": b64(otherKey.X.Bytes()), "y": b64(otherKey.Y.Bytes())},
+	}})
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, jwks, 0o600); err != nil {
+		t.Fatalf("failed writing jwks: %v", err)
+	}
+	keyed, err := NewJWKSAuthenticator(path, "reaphur", "central")
+	if err != nil {
+		t.Fatalf("got err %v", err)
+	}
+
+	tokens, err := ParseServiceTokens("discord=bot-secret")
+	if err != nil {
+		t.Fatalf("got err %v", err)
+	}
+	chain := Chain{tokens, hmac, keyed}
+
+	sign := func(method jwt.SigningMethod, kid string, subject string, key any) string {
+		token := jwt.NewWithClaims(method, claims(subject, time.Now().Add(time.Hour)))
+		if kid != "" {
+			token.Header["kid"] = kid
+		}
+		signed, err := token.SignedString(key)
+		if err != nil {
+			t.Fatalf("failed signing: %v", err)
+		}
+		return signed
+	}
+
+	accepted := map[string]string{
+		"bot-secret": "discord",
+		sign(jwt.SigningMethodHS256, "", "user-hmac", secret):            "user-hmac",
+		sign(jwt.SigningMethodES256, "ec", "user-jwks", ecKey):           "user-jwks",
+		sign(jwt.SigningMethodHS256, "ec", "user-hmac-with-kid", secret): "user-hmac-with-kid",
+	}
+	for token, want := range accepted {
+		principal, err := chain.Authenticate(context.Background(), token)
+		if err != nil {
+			t.Errorf("%s: got err %v", want, err)
+			continue
+		}
+		if principal.Subject != want {
+			t.Errorf("got principal %v but want %s", principal, want)
+		}
+	}
+
+	rejected := map[string]string{
+		"unknown kid":       sign(jwt.SigningMethodES256, "missing", "user", ecKey),
+		"signed by another": sign(jwt.SigningMethodES256, "ec", "user", otherKey),
+		"wrong hmac secret": sign(jwt.SigningMethodHS256, "", "user", []byte("fedcba9876543210fedcba9876543210")),
+		"unknown token":     "nope",
+	}
+	for name, token := range rejected {
+		if _, err := chain.Authenticate(context.Background(), token); !errors.Is(err, errs.ErrUnauthenticated) {
+			t.Errorf("%s: got err %v but want unauthenticated", name, err)
+		}
+	}
+
+	if _, err := keyed.Authenticate(context.Background(), sign(jwt.SigningMethodHS256, "", "user", secret)); !errors.Is(err, ErrUnrecognised) {
+		t.Errorf("got err %v but want hmac tokens left for other authenticators", err)
+	}
+	if _, err := keyed.Authenticate(context.Background(), sign(jwt.SigningMethodES256, "missing", "user", ecKey)); !errors.Is(err, ErrUnrecognised) {
+		t.Errorf("got err %v but want unknown kids left for other authenticators", err)
+	}
+}
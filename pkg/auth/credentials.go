@@ -0,0 +1,52 @@
+package auth
+
+import (
+	"context"
+	"strings"
+
+	"google.golang.org/grpc/metadata"
+)
+
+// Metadata key bearer tokens are sent under, matching what grpc-gateway
+// forwards the Authorization header as
+const AuthorizationMetadataKey = "authorization"
+
+// Per rpc credentials sending a static bearer token, such as a service token
+type TokenCredentials struct {
+	Token string
+	// Only send the token over TLS. Left off while connections are insecure.
+	RequireTLS bool
+}
+
+func (c TokenCredentials) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return map[string]string{AuthorizationMetadataKey: "Bearer " + c.Token}, nil
+}
+
+func (c TokenCredentials) RequireTransportSecurity() bool {
+	return c.RequireTLS
+}
+
+// Pulls the bearer token out of incoming metadata
+func TokenFromContext(ctx context.Context) (string, bool) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return "", false
+	}
+
+	for _, value := range md.Get(AuthorizationMetadataKey) {
+		if token, ok := cutBearer(value); ok {
+			return token, true
+		}
+	}
+
+	return "", false
+}
+
+func cutBearer(value string) (string, bool) {
+	scheme, token, ok := strings.Cut(strings.TrimSpace(value), " ")
+	if !ok || !strings.EqualFold(scheme, "bearer") || strings.TrimSpace(token) == "" {
+		return "", false
+	}
+
+	return strings.TrimSpace(token), true
+}
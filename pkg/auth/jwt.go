@@ -0,0 +1,245 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/calamity-m/reaphur/pkg/errs"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Verifies signed JWTs for end users. The token's subject becomes the
+// principal, and the issuer and audience are checked when configured.
+type JWTAuthenticator struct {
+	keyFunc jwt.Keyfunc
+	parser  *jwt.Parser
+	methods []string
+	// Reports whether a kid names one of this authenticator's keys
+	knowsKey func(kid string) bool
+}
+
+func (j *JWTAuthenticator) Authenticate(ctx context.Context, token string) (Principal, error) {
+	// Anything that isn't shaped like a JWT is left for other authenticators
+	if strings.Count(token, ".") != 2 {
+		return Principal{}, ErrUnrecognised
+	}
+
+	// Tokens signed with another algorithm or key are left for other
+	// authenticators, so HMAC and JWKS tokens can be accepted side by side
+	if !j.recognises(token) {
+		return Principal{}, ErrUnrecognised
+	}
+
+	claims := &jwt.RegisteredClaims{}
+	if _, err := j.parser.ParseWithClaims(token, claims, j.keyFunc); err != nil {
+		return Principal{}, fmt.Errorf("invalid jwt: %v - %w", err, errs.ErrUnauthenticated)
+	}
+
+	if claims.Subject == "" {
+		return Principal{}, fmt.Errorf("jwt has no subject - %w", errs.ErrUnauthenticated)
+	}
+
+	return Principal{Subject: claims.Subject}, nil
+}
+
+// Checks the token's unverified header against the methods and keys this
+// authenticator verifies with. A malformed header is recognised, so parsing
+// rejects it properly.
+func (j *JWTAuthenticator) recognises(token string) bool {
+	unverified, _, err := jwt.NewParser().ParseUnverified(token, &jwt.RegisteredClaims{})
+	if err != nil {
+		return true
+	}
+
+	alg, _ := unverified.Header["alg"].(string)
+	if !slices.Contains(j.methods, alg) {
+		return false
+	}
+
+	kid, _ := unverified.Header["kid"].(string)
+	return j.knowsKey(kid)
+}
+
+func newJWTAuthenticator(keyFunc jwt.Keyfunc, knowsKey func(kid string) bool, methods []string, issuer string, audience string) *JWTAuthenticator {
+	opts := []jwt.ParserOption{jwt.WithValidMethods(methods), jwt.WithExpirationRequired()}
+	if issuer != "" {
+		opts = append(opts, jwt.WithIssuer(issuer))
+	}
+	if audience != "" {
+		opts = append(opts, jwt.WithAudience(audience))
+	}
+
+	return &JWTAuthenticator{keyFunc: keyFunc, parser: jwt.NewParser(opts...), methods: methods, knowsKey: knowsKey}
+}
+
+// Verifies JWTs signed with a shared HMAC secret
+func NewHMACAuthenticator(secret []byte, issuer string, audience string) (*JWTAuthenticator, error) {
+	if len(secret) < 32 {
+		return nil, errors.New("hmac secret must be at least 32 bytes")
+	}
+
+	keyFunc := func(t *jwt.Token) (any, error) {
+		return secret, nil
+	}
+
+	// The secret is the only key, whatever kid the token names
+	knowsKey := func(kid string) bool {
+		return true
+	}
+
+	return newJWTAuthenticator(keyFunc, knowsKey, []string{"HS256", "HS384", "HS512"}, issuer, audience), nil
+}
+
+// Verifies JWTs signed by any of the public keys in a local JWKS file. Tokens
+// are matched to keys on their kid header, which may be left out when the file
+// only holds a single key.
+func NewJWKSAuthenticator(path string, issuer string, audience string) (*JWTAuthenticator, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed reading jwks file: %w", err)
+	}
+
+	keys, err := ParseJWKS(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	lookup := func(kid string) (crypto.PublicKey, bool) {
+		if key, ok := keys[kid]; ok {
+			return key, true
+		}
+		if kid == "" && len(keys) == 1 {
+			for _, key := range keys {
+				return key, true
+			}
+		}
+
+		return nil, false
+	}
+
+	keyFunc := func(t *jwt.Token) (any, error) {
+		kid, _ := t.Header["kid"].(string)
+		if key, ok := lookup(kid); ok {
+			return key, nil
+		}
+
+		return nil, fmt.Errorf("no key found for kid %q", kid)
+	}
+
+	knowsKey := func(kid string) bool {
+		_, ok := lookup(kid)
+		return ok
+	}
+
+	methods := []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}
+	return newJWTAuthenticator(keyFunc, knowsKey, methods, issuer, audience), nil
+}
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// Parses the public RSA, EC and Ed25519 keys of a JWKS document, keyed on kid
+func ParseJWKS(raw []byte) (map[string]crypto.PublicKey, error) {
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.Unmarshal(raw, &set); err != nil {
+		return nil, fmt.Errorf("failed decoding jwks: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, jwk := range set.Keys {
+		key, err := jwk.publicKey()
+		if err != nil {
+			return nil, fmt.Errorf("jwk %q: %w", jwk.Kid, err)
+		}
+
+		keys[jwk.Kid] = key
+	}
+
+	if len(keys) == 0 {
+		return nil, errors.New("jwks has no keys")
+	}
+
+	return keys, nil
+}
+
+func (k jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("bad ed25519 public key")
+		}
+
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+func decodeBigInt(raw string) (*big.Int, error) {
+	decoded, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil || len(decoded) == 0 {
+		return nil, errors.New("bad key parameter")
+	}
+
+	return new(big.Int).SetBytes(decoded), nil
+}
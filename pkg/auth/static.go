@@ -0,0 +1,48 @@
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"strings"
+)
+
+// Long lived tokens for services, keyed on the service name
+type ServiceTokens map[string]string
+
+// Parses tokens in the form "name=token,othername=othertoken"
+func ParseServiceTokens(spec string) (ServiceTokens, error) {
+	tokens := make(ServiceTokens)
+
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		name, token, ok := strings.Cut(entry, "=")
+		if !ok || strings.TrimSpace(name) == "" || strings.TrimSpace(token) == "" {
+			return nil, fmt.Errorf("service token for %q must be in the form name=token", name)
+		}
+
+		tokens[strings.TrimSpace(name)] = strings.TrimSpace(token)
+	}
+
+	return tokens, nil
+}
+
+func (t ServiceTokens) Authenticate(ctx context.Context, token string) (Principal, error) {
+	// Every token is compared so the time taken doesn't give away which matched
+	matched := ""
+	for name, want := range t {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(want)) == 1 {
+			matched = name
+		}
+	}
+
+	if matched == "" {
+		return Principal{}, ErrUnrecognised
+	}
+
+	return Principal{Subject: matched, Service: true}, nil
+}
@@ -13,4 +13,20 @@ const (
 	DefaultCentralAddress = "127.0.0.1:9001"
 	DefaultGWAddress      = "127.0.0.1:9002"
 	DefaultRedisAddress   = "127.0.0.1:6379"
+	DefaultFakeLLMAddress = "127.0.0.1:9003"
+
+	// Prometheus metrics listeners, kept off the public ports
+	DefaultCentralMetricsAddress = "127.0.0.1:9101"
+	DefaultGWMetricsAddress      = "127.0.0.1:9102"
+	DefaultDiscordMetricsAddress = "127.0.0.1:9103"
+
+	// OTLP gRPC collector traces are sent to when tracing is enabled
+	DefaultOTLPAddress = "127.0.0.1:4317"
+)
+
+// Health checked services central reports on, alongside the overall "" service
+const (
+	HealthServiceRedis     = "reaphur.redis"
+	HealthServiceFoodIndex = "reaphur.food_index"
+	HealthServiceLLM       = "reaphur.llm"
 )
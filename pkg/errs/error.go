@@ -0,0 +1,140 @@
+package errs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Describes a single field of a request that was at fault
+type FieldViolation struct {
+	Field       string
+	Description string
+}
+
+// Structured error that is safe to hand back to callers. Message is shown to
+// users as is, so it must never leak internals, while Err keeps the original
+// cause for logging and errors.Is.
+type Error struct {
+	Code    codes.Code
+	Message string
+	Fields  []FieldViolation
+	Err     error
+}
+
+func (e *Error) Error() string {
+	if e.Err == nil || e.Err.Error() == e.Message {
+		return e.Message
+	}
+
+	return fmt.Sprintf("%s: %v", e.Message, e.Err)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Adds a field violation, returning the error for chaining
+func (e *Error) WithField(field string, description string) *Error {
+	e.Fields = append(e.Fields, FieldViolation{Field: field, Description: description})
+	return e
+}
+
+// Converts the error to a grpc status. Field violations are attached as
+// errdetails.BadRequest, which the gateway renders in the response details.
+func (e *Error) GRPCStatus() *status.Status {
+	st := status.New(e.Code, e.Message)
+	if len(e.Fields) == 0 {
+		return st
+	}
+
+	violations := make([]*errdetails.BadRequest_FieldViolation, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		violations = append(violations, &errdetails.BadRequest_FieldViolation{Field: f.Field, Description: f.Description})
+	}
+
+	detailed, err := st.WithDetails(&errdetails.BadRequest{FieldViolations: violations})
+	if err != nil {
+		return st
+	}
+
+	return detailed
+}
+
+func New(code codes.Code, message string) *Error {
+	return &Error{Code: code, Message: message}
+}
+
+// Wraps err with a code and a message that is safe to show users
+func Wrap(err error, code codes.Code, message string) *Error {
+	return &Error{Code: code, Message: message, Err: err}
+}
+
+// Wraps err, which should be or wrap one of the sentinel errors, with a
+// violation for the given field
+func Field(err error, field string, description string) *Error {
+	return From(err).WithField(field, description)
+}
+
+// Sentinel errors and the grpc codes they map to, checked in order
+var sentinelCodes = []struct {
+	err  error
+	code codes.Code
+}{
+	{ErrBadRequest, codes.InvalidArgument},
+	{ErrBadId, codes.InvalidArgument},
+	{ErrBadUserId, codes.InvalidArgument},
+	{ErrInvalidInputField, codes.InvalidArgument},
+	{ErrNilNotAllowed, codes.InvalidArgument},
+	{ErrNotFound, codes.NotFound},
+	{ErrAlreadyExists, codes.AlreadyExists},
+	{ErrUnauthenticated, codes.Unauthenticated},
+	{ErrPermissionDenied, codes.PermissionDenied},
+	{ErrNotImplementedYet, codes.Unimplemented},
+	{ErrUnavailable, codes.Unavailable},
+	{ErrTimeout, codes.DeadlineExceeded},
+	{context.DeadlineExceeded, codes.DeadlineExceeded},
+	{context.Canceled, codes.Canceled},
+	{ErrInternal, codes.Internal},
+}
+
+// Returns the grpc code an error maps to, Internal for anything unrecognised
+func CodeOf(err error) codes.Code {
+	if err == nil {
+		return codes.OK
+	}
+
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Code
+	}
+
+	for _, s := range sentinelCodes {
+		if errors.Is(err, s.err) {
+			return s.code
+		}
+	}
+
+	return codes.Internal
+}
+
+// Converts any error to an Error. Errors wrapping a sentinel keep their message,
+// as those are written by us, while anything else could be leaking from a
+// dependency and is reported as an internal error.
+func From(err error) *Error {
+	var e *Error
+	if errors.As(err, &e) {
+		return e
+	}
+
+	code := CodeOf(err)
+	if code == codes.Internal {
+		return Wrap(err, code, ErrInternal.Error())
+	}
+
+	return Wrap(err, code, err.Error())
+}
@@ -0,0 +1,51 @@
+package errs
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+)
+
+func TestCodeOf(t *testing.T) {
+	tests := []struct {
+		err  error
+		want codes.Code
+	}{
+		{nil, codes.OK},
+		{ErrBadRequest, codes.InvalidArgument},
+		{fmt.Errorf("wrapped - %w", ErrBadId), codes.InvalidArgument},
+		{ErrNotFound, codes.NotFound},
+		{ErrAlreadyExists, codes.AlreadyExists},
+		{ErrNotImplementedYet, codes.Unimplemented},
+		{ErrTimeout, codes.DeadlineExceeded},
+		{New(codes.PermissionDenied, "no"), codes.PermissionDenied},
+		{errors.New("something else"), codes.Internal},
+	}
+
+	for _, tt := range tests {
+		if got := CodeOf(tt.err); got != tt.want {
+			t.Errorf("got %v for %v but want %v", got, tt.err, tt.want)
+		}
+	}
+}
+
+func TestField(t *testing.T) {
+	err := Field(ErrBadUserId, "request_user_id", "must be a uuid").WithField("id", "must be a uuid")
+
+	if !errors.Is(err, ErrBadUserId) {
+		t.Errorf("got %v but want it to still wrap the sentinel", err)
+	}
+	if err.Error() != "bad user id" {
+		t.Errorf("got %q", err.Error())
+	}
+	if len(err.Fields) != 2 {
+		t.Errorf("got fields %v", err.Fields)
+	}
+
+	hidden := From(fmt.Errorf("redis: %w", errors.New("connection refused")))
+	if hidden.Message != ErrInternal.Error() {
+		t.Errorf("got message %q but want internals hidden", hidden.Message)
+	}
+}
@@ -8,7 +8,11 @@ var (
 	ErrNotImplementedYet = errors.New("not implemented yet")
 	ErrNilNotAllowed     = errors.New("nil values not allowed")
 	ErrTimeout           = errors.New("timeout")
+	ErrUnavailable       = errors.New("unavailable")
 	ErrNotFound          = errors.New("not found")
+	ErrAlreadyExists     = errors.New("already exists")
+	ErrUnauthenticated   = errors.New("unauthenticated")
+	ErrPermissionDenied  = errors.New("permission denied")
 	ErrBadRequest        = errors.New("bad request")
 	ErrBadId             = errors.New("bad id")
 	ErrBadUserId         = errors.New("bad user id")
@@ -0,0 +1,165 @@
+package fakellm
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// Loose representation of an incoming OpenAI chat completion request. Only the
+// bits the fake server cares about are decoded.
+type chatRequest struct {
+	Model    string        `json:"model"`
+	Stream   bool          `json:"stream,omitempty"`
+	Messages []chatMessage `json:"messages"`
+}
+
+type chatMessage struct {
+	Role       string          `json:"role"`
+	Content    json.RawMessage `json:"content,omitempty"`
+	ToolCallID string          `json:"tool_call_id,omitempty"`
+	ToolCalls  []chatToolCall  `json:"tool_calls,omitempty"`
+}
+
+type chatToolCall struct {
+	ID       string `json:"id"`
+	Type     string `json:"type"`
+	Function struct {
+		Name      string `json:"name"`
+		Arguments string `json:"arguments"`
+	} `json:"function"`
+}
+
+type contentPart struct {
+	Type     string `json:"type"`
+	Text     string `json:"text,omitempty"`
+	ImageURL struct {
+		URL string `json:"url"`
+	} `json:"image_url,omitempty"`
+}
+
+// Urls of every image part in the message, either links or base64 data urls
+func (m chatMessage) images() []string {
+	var parts []contentPart
+	if err := json.Unmarshal(m.Content, &parts); err != nil {
+		return nil
+	}
+
+	urls := make([]string, 0)
+	for _, part := range parts {
+		if part.Type == "image_url" {
+			urls = append(urls, part.ImageURL.URL)
+		}
+	}
+
+	return urls
+}
+
+// Flattens the message content into plain text. Content may either be a plain
+// string or an array of typed parts.
+func (m chatMessage) text() string {
+	if len(m.Content) == 0 {
+		return ""
+	}
+
+	var s string
+	if err := json.Unmarshal(m.Content, &s); err == nil {
+		return s
+	}
+
+	var parts []contentPart
+	if err := json.Unmarshal(m.Content, &parts); err != nil {
+		return ""
+	}
+
+	var b strings.Builder
+	for _, part := range parts {
+		if part.Type == "text" {
+			b.WriteString(part.Text)
+		}
+	}
+
+	return b.String()
+}
+
+func (r chatRequest) lastRole() string {
+	if len(r.Messages) == 0 {
+		return ""
+	}
+
+	return r.Messages[len(r.Messages)-1].Role
+}
+
+func (r chatRequest) lastUserText() string {
+	for i := len(r.Messages) - 1; i >= 0; i-- {
+		if r.Messages[i].Role == TurnUser {
+			return r.Messages[i].text()
+		}
+	}
+
+	return ""
+}
+
+func (r chatRequest) lastUserImages() []string {
+	for i := len(r.Messages) - 1; i >= 0; i-- {
+		if r.Messages[i].Role == TurnUser {
+			return r.Messages[i].images()
+		}
+	}
+
+	return nil
+}
+
+// Resolves the tool name of the final tool message by looking back through the
+// assistant tool calls for the matching id.
+func (r chatRequest) lastToolName() string {
+	if r.lastRole() != TurnTool {
+		return ""
+	}
+
+	id := r.Messages[len(r.Messages)-1].ToolCallID
+	for i := len(r.Messages) - 1; i >= 0; i-- {
+		for _, call := range r.Messages[i].ToolCalls {
+			if call.ID == id {
+				return call.Function.Name
+			}
+		}
+	}
+
+	return ""
+}
+
+type chatCompletion struct {
+	ID      string       `json:"id"`
+	Object  string       `json:"object"`
+	Created int64        `json:"created"`
+	Model   string       `json:"model"`
+	Choices []chatChoice `json:"choices"`
+	Usage   chatUsage    `json:"usage"`
+}
+
+type chatChoice struct {
+	Index        int             `json:"index"`
+	Message      responseMessage `json:"message"`
+	FinishReason string          `json:"finish_reason"`
+}
+
+type responseMessage struct {
+	Role      string         `json:"role"`
+	Content   string         `json:"content"`
+	Refusal   string         `json:"refusal"`
+	ToolCalls []chatToolCall `json:"tool_calls,omitempty"`
+}
+
+type chatUsage struct {
+	PromptTokens     int64 `json:"prompt_tokens"`
+	CompletionTokens int64 `json:"completion_tokens"`
+	TotalTokens      int64 `json:"total_tokens"`
+}
+
+type errorBody struct {
+	Error struct {
+		Message string `json:"message"`
+		Type    string `json:"type"`
+		Code    string `json:"code"`
+	} `json:"error"`
+}
@@ -0,0 +1,244 @@
+package fakellm
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/calamity-m/reaphur/pkg/serr"
+)
+
+// Exchange is a single captured request/response pair, stored as one fixture file
+type Exchange struct {
+	Hash        string          `json:"hash"`
+	Request     json.RawMessage `json:"request"`
+	Status      int             `json:"status"`
+	ContentType string          `json:"content_type"`
+	Body        string          `json:"body"`
+}
+
+// Recorder proxies chat completion requests to a real upstream provider and
+// writes every exchange into the fixture directory.
+type Recorder struct {
+	logger   *slog.Logger
+	upstream string
+	token    string
+	dir      string
+	client   *http.Client
+
+	mux     sync.Mutex
+	counter int
+}
+
+func (rec *Recorder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "failed to read body")
+		return
+	}
+
+	// Forward everything after the version prefix onto the upstream
+	path := r.URL.Path
+	if idx := strings.Index(path, "/chat/completions"); idx >= 0 {
+		path = path[idx:]
+	}
+
+	upstreamReq, err := http.NewRequestWithContext(r.Context(), r.Method, strings.TrimSuffix(rec.upstream, "/")+path, bytes.NewReader(body))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	upstreamReq.Header.Set("Content-Type", "application/json")
+	if rec.token != "" {
+		upstreamReq.Header.Set("Authorization", "Bearer "+rec.token)
+	} else {
+		upstreamReq.Header.Set("Authorization", r.Header.Get("Authorization"))
+	}
+
+	resp, err := rec.client.Do(upstreamReq)
+	if err != nil {
+		rec.logger.ErrorContext(r.Context(), "failed calling upstream", slog.Any("err", err))
+		writeError(w, http.StatusBadGateway, err.Error())
+		return
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err.Error())
+		return
+	}
+
+	exchange := Exchange{
+		Request:     json.RawMessage(body),
+		Status:      resp.StatusCode,
+		ContentType: resp.Header.Get("Content-Type"),
+		Body:        string(respBody),
+	}
+
+	if err := rec.write(exchange); err != nil {
+		rec.logger.ErrorContext(r.Context(), "failed writing fixture", slog.Any("err", err))
+	}
+
+	w.Header().Set("Content-Type", exchange.ContentType)
+	w.WriteHeader(resp.StatusCode)
+	w.Write(respBody)
+}
+
+func (rec *Recorder) write(exchange Exchange) error {
+	hash, err := requestHash(exchange.Request)
+	if err != nil {
+		return err
+	}
+	exchange.Hash = hash
+
+	rec.mux.Lock()
+	rec.counter++
+	name := fmt.Sprintf("%04d-%s.json", rec.counter, hash[:12])
+	rec.mux.Unlock()
+
+	b, err := json.MarshalIndent(exchange, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	rec.logger.Info(fmt.Sprintf("recording exchange to %s", name))
+
+	return os.WriteFile(filepath.Join(rec.dir, name), b, 0644)
+}
+
+// Replayer serves previously recorded exchanges. Requests are matched on the
+// hash of their canonical body first, and otherwise fall back to the next
+// unplayed exchange in recording order.
+type Replayer struct {
+	logger    *slog.Logger
+	mux       sync.Mutex
+	exchanges []Exchange
+	played    []bool
+}
+
+func (rep *Replayer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "failed to read body")
+		return
+	}
+
+	hash, err := requestHash(body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	exchange, ok := rep.next(hash)
+	if !ok {
+		rep.logger.ErrorContext(r.Context(), "no recorded exchange left to replay", slog.String("hash", hash))
+		writeError(w, http.StatusInternalServerError, "no recorded exchange left to replay")
+		return
+	}
+
+	w.Header().Set("Content-Type", exchange.ContentType)
+	w.WriteHeader(exchange.Status)
+	io.WriteString(w, exchange.Body)
+}
+
+func (rep *Replayer) next(hash string) (Exchange, bool) {
+	rep.mux.Lock()
+	defer rep.mux.Unlock()
+
+	for i, exchange := range rep.exchanges {
+		if !rep.played[i] && exchange.Hash == hash {
+			rep.played[i] = true
+			return exchange, true
+		}
+	}
+
+	for i, exchange := range rep.exchanges {
+		if !rep.played[i] {
+			rep.played[i] = true
+			return exchange, true
+		}
+	}
+
+	return Exchange{}, false
+}
+
+// Canonicalises the request body by round tripping it through a generic value,
+// which sorts object keys, before hashing.
+func requestHash(body []byte) (string, error) {
+	val, err := serr.DecodeJSON[any](body)
+	if err != nil {
+		return "", err
+	}
+
+	canonical, err := json.Marshal(val)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(canonical)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func NewRecorder(logger *slog.Logger, upstream string, token string, dir string) (*Recorder, error) {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create fixture dir: %w", err)
+	}
+
+	return &Recorder{
+		logger:   logger,
+		upstream: upstream,
+		token:    token,
+		dir:      dir,
+		client:   &http.Client{},
+	}, nil
+}
+
+func NewReplayer(logger *slog.Logger, dir string) (*Replayer, error) {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+
+	exchanges := make([]Exchange, 0, len(paths))
+	for _, path := range paths {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		exchange, err := serr.DecodeJSON[Exchange](b)
+		if err != nil {
+			return nil, fmt.Errorf("failed decoding fixture %s: %w", path, err)
+		}
+
+		if exchange.Hash == "" {
+			if exchange.Hash, err = requestHash(exchange.Request); err != nil {
+				return nil, err
+			}
+		}
+
+		exchanges = append(exchanges, exchange)
+	}
+
+	return &Replayer{logger: logger, exchanges: exchanges, played: make([]bool, len(exchanges))}, nil
+}
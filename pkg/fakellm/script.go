@@ -0,0 +1,122 @@
+package fakellm
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/calamity-m/reaphur/pkg/serr"
+)
+
+const (
+	// Matches when the last message in the conversation came from the user
+	TurnUser = "user"
+	// Matches when the last message in the conversation is a tool result
+	TurnTool = "tool"
+)
+
+// Script drives the fake chat completions server. Rules are evaluated in order and
+// the first matching rule provides the reply. If nothing matches, Default is used.
+type Script struct {
+	Rules   []Rule `json:"rules"`
+	Default *Reply `json:"default,omitempty"`
+}
+
+// Rule pairs some match conditions with the reply that should be sent back
+type Rule struct {
+	Match Match `json:"match"`
+	Reply Reply `json:"reply"`
+}
+
+// Match conditions are and'd together. Empty conditions always match.
+type Match struct {
+	// Either "user" or "tool", matches against the role of the final message
+	Turn string `json:"turn,omitempty"`
+	// Case insensitive substring that must be present in the latest user message
+	UserContains string `json:"user_contains,omitempty"`
+	// Name of the tool that produced the latest tool result message
+	ToolName string `json:"tool_name,omitempty"`
+	// Model that must have been requested
+	Model string `json:"model,omitempty"`
+	// Requires the latest user message to carry at least one image
+	UserHasImage bool `json:"user_has_image,omitempty"`
+}
+
+// Reply is what the fake server responds with. Setting Status to a non 2xx code
+// sends back an OpenAI styled error instead of a completion.
+type Reply struct {
+	Content   string     `json:"content,omitempty"`
+	ToolCalls []ToolCall `json:"tool_calls,omitempty"`
+	Usage     Usage      `json:"usage,omitempty"`
+
+	Status     int    `json:"status,omitempty"`
+	Error      string `json:"error,omitempty"`
+	RetryAfter string `json:"retry_after,omitempty"`
+}
+
+type ToolCall struct {
+	Name      string          `json:"name"`
+	Arguments json.RawMessage `json:"arguments"`
+}
+
+type Usage struct {
+	PromptTokens     int64 `json:"prompt_tokens"`
+	CompletionTokens int64 `json:"completion_tokens"`
+}
+
+// Finds the reply for the given chat completion request
+func (s *Script) reply(req chatRequest) (Reply, error) {
+	for _, rule := range s.Rules {
+		if rule.Match.matches(req) {
+			return rule.Reply, nil
+		}
+	}
+
+	if s.Default != nil {
+		return *s.Default, nil
+	}
+
+	return Reply{}, fmt.Errorf("no scripted reply matched request")
+}
+
+func (m Match) matches(req chatRequest) bool {
+	if m.Model != "" && m.Model != req.Model {
+		return false
+	}
+
+	if m.Turn != "" && m.Turn != req.lastRole() {
+		return false
+	}
+
+	if m.UserContains != "" {
+		if !strings.Contains(strings.ToLower(req.lastUserText()), strings.ToLower(m.UserContains)) {
+			return false
+		}
+	}
+
+	if m.ToolName != "" && m.ToolName != req.lastToolName() {
+		return false
+	}
+
+	if m.UserHasImage && len(req.lastUserImages()) == 0 {
+		return false
+	}
+
+	return true
+}
+
+// Loads a JSON encoded script from disk
+func LoadScript(path string) (*Script, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read script: %w", err)
+	}
+
+	script, err := serr.DecodeJSON[Script](b)
+	if err != nil {
+		return nil, err
+	}
+
+	return &script, nil
+}
@@ -0,0 +1,188 @@
+package fakellm
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/calamity-m/reaphur/pkg/serr"
+)
+
+// Fixed creation time so responses are byte for byte reproducible
+const fakeCreated = 1700000000
+
+// Server is an OpenAI compatible chat completions server that answers with
+// scripted replies. It is deterministic, ids are generated from a counter
+// rather than randomness, so it can be relied on from tests.
+type Server struct {
+	logger *slog.Logger
+	script *Script
+
+	mux      sync.Mutex
+	counter  int
+	requests []json.RawMessage
+}
+
+// Requests returns the raw body of every chat completion request received so far
+func (s *Server) Requests() []json.RawMessage {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	reqs := make([]json.RawMessage, len(s.requests))
+	copy(reqs, s.requests)
+
+	return reqs
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// Listing models is only used to check the llm is reachable
+	if r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/models") {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"object":"list","data":[{"id":"fake-llm","object":"model","created":%d,"owned_by":"reaphur"}]}`, fakeCreated)
+		return
+	}
+
+	if r.Method != http.MethodPost || !strings.HasSuffix(r.URL.Path, "/chat/completions") {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("unknown route %s %s", r.Method, r.URL.Path))
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "failed to read body")
+		return
+	}
+
+	req, err := serr.DecodeJSON[chatRequest](body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	s.mux.Lock()
+	s.counter++
+	n := s.counter
+	s.requests = append(s.requests, json.RawMessage(body))
+	s.mux.Unlock()
+
+	reply, err := s.script.reply(req)
+	if err != nil {
+		s.logger.ErrorContext(r.Context(), "fake llm could not find a reply", slog.Any("err", err), slog.String("last_user", req.lastUserText()))
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if reply.Status >= 300 {
+		if reply.RetryAfter != "" {
+			w.Header().Set("Retry-After", reply.RetryAfter)
+		}
+		writeError(w, reply.Status, reply.Error)
+		return
+	}
+
+	completion, err := buildCompletion(n, req.Model, reply)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	s.logger.DebugContext(r.Context(), "fake llm replying", slog.Int("request", n), slog.Any("completion", completion))
+
+	if req.Stream {
+		if err := writeStream(w, completion); err != nil {
+			s.logger.ErrorContext(r.Context(), "failed writing fake completion stream", slog.Any("err", err))
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(completion); err != nil {
+		s.logger.ErrorContext(r.Context(), "failed writing fake completion", slog.Any("err", err))
+	}
+}
+
+func buildCompletion(n int, model string, reply Reply) (chatCompletion, error) {
+	msg := responseMessage{
+		Role:    "assistant",
+		Content: reply.Content,
+	}
+
+	for i, call := range reply.ToolCalls {
+		args, err := argumentString(call.Arguments)
+		if err != nil {
+			return chatCompletion{}, err
+		}
+
+		tc := chatToolCall{
+			ID:   fmt.Sprintf("call_%d_%d", n, i),
+			Type: "function",
+		}
+		tc.Function.Name = call.Name
+		tc.Function.Arguments = args
+
+		msg.ToolCalls = append(msg.ToolCalls, tc)
+	}
+
+	finish := "stop"
+	if len(msg.ToolCalls) > 0 {
+		finish = "tool_calls"
+	}
+
+	return chatCompletion{
+		ID:      fmt.Sprintf("chatcmpl-fake-%d", n),
+		Object:  "chat.completion",
+		Created: fakeCreated,
+		Model:   model,
+		Choices: []chatChoice{{Index: 0, Message: msg, FinishReason: finish}},
+		Usage: chatUsage{
+			PromptTokens:     reply.Usage.PromptTokens,
+			CompletionTokens: reply.Usage.CompletionTokens,
+			TotalTokens:      reply.Usage.PromptTokens + reply.Usage.CompletionTokens,
+		},
+	}, nil
+}
+
+// Tool call arguments are sent by OpenAI as a JSON encoded string. Scripts may
+// provide either that string, or the raw JSON object for readability.
+func argumentString(raw json.RawMessage) (string, error) {
+	if len(raw) == 0 {
+		return "{}", nil
+	}
+
+	if raw[0] == '"' {
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return "", fmt.Errorf("failed decoding tool arguments: %w", err)
+		}
+		return s, nil
+	}
+
+	return string(raw), nil
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	body := errorBody{}
+	body.Error.Message = message
+	body.Error.Type = "fake_llm_error"
+	body.Error.Code = http.StatusText(status)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+func NewScriptedServer(logger *slog.Logger, script *Script) *Server {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	if script == nil {
+		script = &Script{}
+	}
+
+	return &Server{logger: logger, script: script}
+}
@@ -0,0 +1,214 @@
+package fakellm
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func testScript() *Script {
+	return &Script{
+		Rules: []Rule{
+			{
+				Match: Match{Turn: TurnUser, UserHasImage: true},
+				Reply: Reply{Content: "nice photo"},
+			},
+			{
+				Match: Match{Turn: TurnUser, UserContains: "banana"},
+				Reply: Reply{
+					ToolCalls: []ToolCall{{Name: "log_food", Arguments: json.RawMessage(`{"name":"banana"}`)}},
+					Usage:     Usage{PromptTokens: 10, CompletionTokens: 5},
+				},
+			},
+			{
+				Match: Match{Turn: TurnTool, ToolName: "log_food"},
+				Reply: Reply{Content: "logged"},
+			},
+			{
+				Match: Match{UserContains: "overloaded"},
+				Reply: Reply{Status: http.StatusTooManyRequests, Error: "slow down", RetryAfter: "2"},
+			},
+		},
+		Default: &Reply{Content: "default"},
+	}
+}
+
+func post(t *testing.T, h http.Handler, body string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	rs := httptest.NewRecorder()
+	rq := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(body))
+	h.ServeHTTP(rs, rq)
+
+	return rs
+}
+
+func TestScriptedServer(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	t.Run("user turn produces tool call", func(t *testing.T) {
+		srv := NewScriptedServer(logger, testScript())
+		rs := post(t, srv, `{"model":"m","messages":[{"role":"developer","content":"x"},{"role":"user","content":"I ate a BANANA"}]}`)
+
+		if rs.Code != http.StatusOK {
+			t.Fatalf("got status %d but want 200", rs.Code)
+		}
+
+		var got chatCompletion
+		if err := json.NewDecoder(rs.Body).Decode(&got); err != nil {
+			t.Fatalf("failed decoding completion: %v", err)
+		}
+
+		calls := got.Choices[0].Message.ToolCalls
+		if len(calls) != 1 || calls[0].Function.Name != "log_food" {
+			t.Fatalf("got tool calls %+v but want single log_food", calls)
+		}
+		if calls[0].Function.Arguments != `{"name":"banana"}` {
+			t.Errorf("got arguments %q", calls[0].Function.Arguments)
+		}
+		if got.Choices[0].FinishReason != "tool_calls" {
+			t.Errorf("got finish reason %q but want tool_calls", got.Choices[0].FinishReason)
+		}
+		if got.Usage.TotalTokens != 15 {
+			t.Errorf("got total tokens %d but want 15", got.Usage.TotalTokens)
+		}
+		if len(srv.Requests()) != 1 {
+			t.Errorf("got %d recorded requests but want 1", len(srv.Requests()))
+		}
+	})
+
+	t.Run("tool turn resolves tool name", func(t *testing.T) {
+		srv := NewScriptedServer(logger, testScript())
+		rs := post(t, srv, `{"model":"m","messages":[
+			{"role":"user","content":[{"type":"text","text":"banana"}]},
+			{"role":"assistant","tool_calls":[{"id":"call_1","type":"function","function":{"name":"log_food","arguments":"{}"}}]},
+			{"role":"tool","tool_call_id":"call_1","content":"{}"}]}`)
+
+		var got chatCompletion
+		if err := json.NewDecoder(rs.Body).Decode(&got); err != nil {
+			t.Fatalf("failed decoding completion: %v", err)
+		}
+
+		if got.Choices[0].Message.Content != "logged" {
+			t.Errorf("got content %q but want logged", got.Choices[0].Message.Content)
+		}
+	})
+
+	t.Run("user image matches", func(t *testing.T) {
+		srv := NewScriptedServer(logger, testScript())
+		rs := post(t, srv, `{"model":"m","messages":[{"role":"user","content":[
+			{"type":"text","text":"banana"},
+			{"type":"image_url","image_url":{"url":"data:image/png;base64,AAAA"}}]}]}`)
+
+		if !bytes.Contains(rs.Body.Bytes(), []byte(`"content":"nice photo"`)) {
+			t.Errorf("got body %s but want image reply", rs.Body.String())
+		}
+	})
+
+	t.Run("scripted error", func(t *testing.T) {
+		srv := NewScriptedServer(logger, testScript())
+		rs := post(t, srv, `{"model":"m","messages":[{"role":"user","content":"overloaded"}]}`)
+
+		if rs.Code != http.StatusTooManyRequests {
+			t.Errorf("got status %d but want 429", rs.Code)
+		}
+		if rs.Header().Get("Retry-After") != "2" {
+			t.Errorf("got retry after %q but want 2", rs.Header().Get("Retry-After"))
+		}
+	})
+
+	t.Run("falls back to default", func(t *testing.T) {
+		srv := NewScriptedServer(logger, testScript())
+		rs := post(t, srv, `{"model":"m","messages":[{"role":"user","content":"hello"}]}`)
+
+		if !bytes.Contains(rs.Body.Bytes(), []byte(`"content":"default"`)) {
+			t.Errorf("got body %s but want default content", rs.Body.String())
+		}
+	})
+}
+
+func TestRecordReplay(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	upstream := httptest.NewServer(NewScriptedServer(logger, testScript()))
+	defer upstream.Close()
+
+	dir := t.TempDir()
+	recorder, err := NewRecorder(logger, upstream.URL+"/v1", "token", dir)
+	if err != nil {
+		t.Fatalf("failed creating recorder: %v", err)
+	}
+
+	first := `{"model":"m","messages":[{"role":"user","content":"banana"}]}`
+	second := `{"model":"m","messages":[{"role":"user","content":"hello"}]}`
+	recordedFirst := post(t, recorder, first).Body.String()
+	recordedSecond := post(t, recorder, second).Body.String()
+
+	replayer, err := NewReplayer(logger, dir)
+	if err != nil {
+		t.Fatalf("failed creating replayer: %v", err)
+	}
+
+	// Replay out of order to make sure matching is on the request, not the order
+	if got := post(t, replayer, second).Body.String(); got != recordedSecond {
+		t.Errorf("got %s but want %s", got, recordedSecond)
+	}
+	if got := post(t, replayer, first).Body.String(); got != recordedFirst {
+		t.Errorf("got %s but want %s", got, recordedFirst)
+	}
+	if rs := post(t, replayer, first); rs.Code != http.StatusInternalServerError {
+		t.Errorf("got status %d but want 500 once fixtures are exhausted", rs.Code)
+	}
+}
+
+func TestScriptedServerStream(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	srv := NewScriptedServer(logger, &Script{Default: &Reply{Content: "rest in peace", Usage: Usage{PromptTokens: 3, CompletionTokens: 2}}})
+
+	rs := post(t, srv, `{"model":"m","stream":true,"messages":[{"role":"user","content":"hi"}]}`)
+	if rs.Code != http.StatusOK {
+		t.Fatalf("got status %d but want 200", rs.Code)
+	}
+	if ct := rs.Header().Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("got content type %q", ct)
+	}
+
+	var content strings.Builder
+	var usage *chatUsage
+	done := false
+	for _, line := range strings.Split(rs.Body.String(), "\n") {
+		data, ok := strings.CutPrefix(line, "data: ")
+		if !ok {
+			continue
+		}
+		if data == "[DONE]" {
+			done = true
+			continue
+		}
+
+		var chunk chatCompletionChunk
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			t.Fatalf("failed decoding chunk %q: %v", data, err)
+		}
+		for _, choice := range chunk.Choices {
+			content.WriteString(choice.Delta.Content)
+		}
+		if chunk.Usage != nil {
+			usage = chunk.Usage
+		}
+	}
+
+	if !done {
+		t.Error("stream was not terminated with [DONE]")
+	}
+	if content.String() != "rest in peace" {
+		t.Errorf("got content %q", content.String())
+	}
+	if usage == nil || usage.TotalTokens != 5 {
+		t.Errorf("got usage %+v but want 5 total tokens", usage)
+	}
+}
@@ -0,0 +1,108 @@
+package fakellm
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+type chatCompletionChunk struct {
+	ID      string        `json:"id"`
+	Object  string        `json:"object"`
+	Created int64         `json:"created"`
+	Model   string        `json:"model"`
+	Choices []chunkChoice `json:"choices"`
+	Usage   *chatUsage    `json:"usage,omitempty"`
+}
+
+type chunkChoice struct {
+	Index        int        `json:"index"`
+	Delta        chunkDelta `json:"delta"`
+	FinishReason *string    `json:"finish_reason"`
+}
+
+type chunkDelta struct {
+	Role      string          `json:"role,omitempty"`
+	Content   string          `json:"content,omitempty"`
+	ToolCalls []chunkToolCall `json:"tool_calls,omitempty"`
+}
+
+type chunkToolCall struct {
+	Index int `json:"index"`
+	chatToolCall
+}
+
+// Splits a completion into the chunks OpenAI would stream for it. Content is
+// split into words so that clients see more than a single delta. Usage is sent
+// as a final chunk without choices.
+func streamChunks(completion chatCompletion) []chatCompletionChunk {
+	chunk := func(delta chunkDelta, finish *string) chatCompletionChunk {
+		return chatCompletionChunk{
+			ID:      completion.ID,
+			Object:  "chat.completion.chunk",
+			Created: completion.Created,
+			Model:   completion.Model,
+			Choices: []chunkChoice{{Index: 0, Delta: delta, FinishReason: finish}},
+		}
+	}
+
+	msg := completion.Choices[0].Message
+	chunks := []chatCompletionChunk{chunk(chunkDelta{Role: "assistant"}, nil)}
+
+	if msg.Content != "" {
+		for i, word := range strings.Split(msg.Content, " ") {
+			if i > 0 {
+				word = " " + word
+			}
+
+			chunks = append(chunks, chunk(chunkDelta{Content: word}, nil))
+		}
+	}
+
+	for i, call := range msg.ToolCalls {
+		chunks = append(chunks, chunk(chunkDelta{ToolCalls: []chunkToolCall{{Index: i, chatToolCall: call}}}, nil))
+	}
+
+	finish := completion.Choices[0].FinishReason
+	chunks = append(chunks, chunk(chunkDelta{}, &finish))
+
+	usage := completion.Usage
+	chunks = append(chunks, chatCompletionChunk{
+		ID:      completion.ID,
+		Object:  "chat.completion.chunk",
+		Created: completion.Created,
+		Model:   completion.Model,
+		Choices: []chunkChoice{},
+		Usage:   &usage,
+	})
+
+	return chunks
+}
+
+// Writes the completion as a server sent event stream
+func writeStream(w http.ResponseWriter, completion chatCompletion) error {
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+
+	flusher, _ := w.(http.Flusher)
+
+	for _, chunk := range streamChunks(completion) {
+		data, err := json.Marshal(chunk)
+		if err != nil {
+			return err
+		}
+
+		if _, err := fmt.Fprintf(w, "data: %s\n\n", data); err != nil {
+			return err
+		}
+
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+
+	_, err := io.WriteString(w, "data: [DONE]\n\n")
+	return err
+}
@@ -0,0 +1,33 @@
+package inproc
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestLink(t *testing.T) {
+	link := NewLink()
+
+	server := grpc.NewServer()
+	healthpb.RegisterHealthServer(server, health.NewServer())
+	go server.Serve(link.Listener())
+	defer server.Stop()
+
+	conn, err := grpc.NewClient(link.Target(), link.DialOptions()...)
+	if err != nil {
+		t.Fatalf("failed creating client: %v", err)
+	}
+	defer conn.Close()
+
+	resp, err := healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{})
+	if err != nil {
+		t.Fatalf("got err %v", err)
+	}
+	if resp.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+		t.Errorf("got status %v but want serving", resp.GetStatus())
+	}
+}
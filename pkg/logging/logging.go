@@ -6,6 +6,7 @@ import (
 	"log/slog"
 
 	"github.com/calamity-m/reaphur/pkg/bindings"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type CustomHandler struct {
@@ -26,7 +27,8 @@ type CustomHandlerCfg struct {
 
 	// Whether to grab the request id from the request's context
 	// variable and append it to log lines created by this
-	// handler
+	// handler. The trace id is appended alongside it when the
+	// context carries a span.
 	RecordRequestId bool
 
 	// Static attributes that will be appended to every
@@ -43,6 +45,10 @@ func (c *CustomHandler) Handle(ctx context.Context, r slog.Record) error {
 		} else {
 			r.AddAttrs(slog.String("request-id", "unknown"))
 		}
+
+		if span := trace.SpanContextFromContext(ctx); span.HasTraceID() {
+			r.AddAttrs(slog.String("trace-id", span.TraceID().String()))
+		}
 	}
 
 	// Stop intercepting and continue on
@@ -11,6 +11,7 @@ import (
 	"testing"
 
 	"github.com/calamity-m/reaphur/pkg/bindings"
+	"go.opentelemetry.io/otel/trace"
 )
 
 func assertStructuredLog(t testing.TB, buffer *bytes.Buffer, want map[string]interface{}) {
@@ -152,4 +153,30 @@ func TestCustomziedHandlerRequestId(t *testing.T) {
 
 		assertStructuredLog(t, &buffer, want)
 	})
+
+	t.Run("handler logs the trace id next to the request id", func(t *testing.T) {
+		want := map[string]any{
+			"msg":        "echo",
+			"level":      "INFO",
+			"request-id": "req-id",
+			"trace-id":   "0af7651916cd43dd8448eb211c80319c",
+		}
+		buffer := bytes.Buffer{}
+
+		handler := NewCustomizedHandler(&buffer, &CustomHandlerCfg{
+			Structed:        true,
+			RecordRequestId: true,
+		})
+		logger := slog.New(handler)
+
+		traceId, _ := trace.TraceIDFromHex("0af7651916cd43dd8448eb211c80319c")
+		spanId, _ := trace.SpanIDFromHex("b7ad6b7169203331")
+		ctx := trace.ContextWithSpanContext(
+			context.WithValue(context.Background(), bindings.RequestIDKey{}, "req-id"),
+			trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceId, SpanID: spanId}),
+		)
+		logger.InfoContext(ctx, "echo")
+
+		assertStructuredLog(t, &buffer, want)
+	})
 }
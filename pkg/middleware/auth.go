@@ -0,0 +1,164 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/calamity-m/reaphur/pkg/auth"
+	"github.com/calamity-m/reaphur/pkg/errs"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Which methods need what. Methods are matched on prefix of the full method
+// name, so "/centralproto.v1.CentralAdminService/" covers the whole service.
+type AuthPolicy struct {
+	// Callable without any credentials, such as health checks
+	Public []string
+	// Only callable by services, never by users
+	ServiceOnly []string
+}
+
+func matchesAny(prefixes []string, fullMethod string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(fullMethod, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Authenticates the bearer token of every non public rpc and attaches the
+// principal to the context. Users are bound to the user ids of their requests,
+// empty ids are filled in with the user's subject while any other user's id is
+// rejected with PermissionDenied.
+func AuthUnaryInterceptor(logger *slog.Logger, authenticator auth.Authenticator, policy AuthPolicy) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		if matchesAny(policy.Public, info.FullMethod) {
+			return handler(ctx, req)
+		}
+
+		principal, err := authenticate(ctx, logger, authenticator, policy, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := bindPrincipal(principal, req); err != nil {
+			logger.WarnContext(ctx, "user acted on behalf of another", slog.String("method", info.FullMethod), slog.String("subject", principal.Subject))
+			return nil, err
+		}
+
+		return handler(auth.ContextWithPrincipal(ctx, principal), req)
+	}
+}
+
+// Stream equivalent of AuthUnaryInterceptor. Each received message is bound to
+// the principal.
+func AuthStreamInterceptor(logger *slog.Logger, authenticator auth.Authenticator, policy AuthPolicy) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if matchesAny(policy.Public, info.FullMethod) {
+			return handler(srv, ss)
+		}
+
+		principal, err := authenticate(ss.Context(), logger, authenticator, policy, info.FullMethod)
+		if err != nil {
+			return err
+		}
+
+		return handler(srv, &boundStream{
+			ServerStream: ss,
+			ctx:          auth.ContextWithPrincipal(ss.Context(), principal),
+			principal:    principal,
+		})
+	}
+}
+
+func authenticate(ctx context.Context, logger *slog.Logger, authenticator auth.Authenticator, policy AuthPolicy, fullMethod string) (auth.Principal, error) {
+	token, ok := auth.TokenFromContext(ctx)
+	if !ok {
+		return auth.Principal{}, errs.Wrap(errs.ErrUnauthenticated, codes.Unauthenticated, "missing bearer token")
+	}
+
+	principal, err := authenticator.Authenticate(ctx, token)
+	if err != nil {
+		logger.WarnContext(ctx, "failed to authenticate", slog.String("method", fullMethod), slog.Any("err", err))
+		return auth.Principal{}, errs.Wrap(err, codes.Unauthenticated, "invalid bearer token")
+	}
+
+	if !principal.Service && matchesAny(policy.ServiceOnly, fullMethod) {
+		return auth.Principal{}, errs.From(fmt.Errorf("method is only available to services - %w", errs.ErrPermissionDenied))
+	}
+
+	return principal, nil
+}
+
+// Fields holding the user a request acts on
+var userIdFields = map[protoreflect.Name]bool{
+	"request_user_id": true,
+	"user_id":         true,
+}
+
+// Binds every user id field in the request, including those of nested messages
+// such as the record being created, to the principal's subject. Services may
+// act on behalf of any user and are left alone.
+func bindPrincipal(principal auth.Principal, req any) error {
+	if principal.Service {
+		return nil
+	}
+
+	msg, ok := req.(proto.Message)
+	if !ok {
+		return nil
+	}
+
+	return bindMessage(principal.Subject, msg.ProtoReflect())
+}
+
+func bindMessage(subject string, msg protoreflect.Message) error {
+	fields := msg.Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		field := fields.Get(i)
+		if field.IsList() || field.IsMap() {
+			continue
+		}
+
+		switch {
+		case field.Kind() == protoreflect.StringKind && userIdFields[field.Name()]:
+			current := msg.Get(field).String()
+			if current != "" && current != subject {
+				return errs.Field(fmt.Errorf("cannot act on behalf of another user - %w", errs.ErrPermissionDenied), string(field.Name()), "must be your own user id")
+			}
+			msg.Set(field, protoreflect.ValueOfString(subject))
+		case field.Kind() == protoreflect.MessageKind && msg.Has(field):
+			if err := bindMessage(subject, msg.Mutable(field).Message()); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// Server stream carrying the principal, binding each received message to it
+type boundStream struct {
+	grpc.ServerStream
+	ctx       context.Context
+	principal auth.Principal
+}
+
+func (s *boundStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *boundStream) RecvMsg(m any) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+
+	return bindPrincipal(s.principal, m)
+}
@@ -0,0 +1,125 @@
+package middleware
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/calamity-m/reaphur/pkg/auth"
+	centralproto "github.com/calamity-m/reaphur/proto/v1/central"
+	"github.com/calamity-m/reaphur/proto/v1/domain"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// Authenticates tokens named after their subject, "service" being a service
+type fakeAuthenticator struct{}
+
+func (fakeAuthenticator) Authenticate(ctx context.Context, token string) (auth.Principal, error) {
+	if token == "bad" {
+		return auth.Principal{}, auth.ErrUnrecognised
+	}
+
+	return auth.Principal{Subject: token, Service: token == "service"}, nil
+}
+
+func TestAuthUnaryInterceptor(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	interceptor := AuthUnaryInterceptor(logger, auth.Chain{fakeAuthenticator{}}, AuthPolicy{
+		Public:      []string{"/grpc.health.v1.Health/"},
+		ServiceOnly: []string{"/centralproto.v1.CentralAdminService/"},
+	})
+
+	withToken := func(token string) context.Context {
+		return metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+token))
+	}
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		method   string
+		req      proto.Message
+		wantCode codes.Code
+		// The request as the handler should see it
+		wantReq proto.Message
+	}{
+		{
+			name:     "public methods need no token",
+			ctx:      context.Background(),
+			method:   "/grpc.health.v1.Health/Check",
+			wantCode: codes.OK,
+		},
+		{
+			name:     "missing token",
+			ctx:      context.Background(),
+			method:   "/centralproto.v1.CentralService/CallFnUserInput",
+			req:      &centralproto.CallFnUserInputRequest{RequestUserId: "user-1"},
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name:     "unrecognised token",
+			ctx:      withToken("bad"),
+			method:   "/centralproto.v1.CentralService/CallFnUserInput",
+			req:      &centralproto.CallFnUserInputRequest{RequestUserId: "user-1"},
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name:     "users fill in their own id",
+			ctx:      withToken("user-1"),
+			method:   "/centralproto.v1.CentralService/CallFnUserInput",
+			req:      &centralproto.CallFnUserInputRequest{RequestUserInput: "ate a banana"},
+			wantCode: codes.OK,
+			wantReq:  &centralproto.CallFnUserInputRequest{RequestUserId: "user-1", RequestUserInput: "ate a banana"},
+		},
+		{
+			name:     "users can't act for others",
+			ctx:      withToken("user-1"),
+			method:   "/centralproto.v1.CentralService/CallFnUserInput",
+			req:      &centralproto.CallFnUserInputRequest{RequestUserId: "user-2"},
+			wantCode: codes.PermissionDenied,
+		},
+		{
+			name:     "nested user ids are bound too",
+			ctx:      withToken("user-1"),
+			method:   "/centralproto.v1.CentralFoodService/CreateFoodRecord",
+			req:      &centralproto.CreateFoodRecordRequest{Record: &domain.FoodRecord{UserId: "user-2"}},
+			wantCode: codes.PermissionDenied,
+		},
+		{
+			name:     "services act for any user",
+			ctx:      withToken("service"),
+			method:   "/centralproto.v1.CentralService/CallFnUserInput",
+			req:      &centralproto.CallFnUserInputRequest{RequestUserId: "user-2"},
+			wantCode: codes.OK,
+			wantReq:  &centralproto.CallFnUserInputRequest{RequestUserId: "user-2"},
+		},
+		{
+			name:     "users can't call service only methods",
+			ctx:      withToken("user-1"),
+			method:   "/centralproto.v1.CentralAdminService/GetUsage",
+			req:      &centralproto.GetUsageRequest{},
+			wantCode: codes.PermissionDenied,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var seen any
+			_, err := interceptor(tt.ctx, tt.req, &grpc.UnaryServerInfo{FullMethod: tt.method}, func(ctx context.Context, req any) (any, error) {
+				seen = req
+				return nil, nil
+			})
+
+			if code := status.Code(err); code != tt.wantCode {
+				t.Fatalf("got code %v (%v) but want %v", code, err, tt.wantCode)
+			}
+			if tt.wantReq != nil && !proto.Equal(seen.(proto.Message), tt.wantReq) {
+				t.Errorf("got request %v but want %v", seen, tt.wantReq)
+			}
+		})
+	}
+}
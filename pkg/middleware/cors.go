@@ -0,0 +1,93 @@
+package middleware
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Which cross origin requests browsers are allowed to make
+type CORSOptions struct {
+	// Origins allowed to call, such as "https://reaphur.example". "*" allows any
+	// origin. Without any, no CORS headers are sent and browsers refuse cross
+	// origin requests.
+	AllowedOrigins []string
+	AllowedMethods []string
+	AllowedHeaders []string
+	// Response headers scripts are allowed to read
+	ExposedHeaders []string
+	// Allows cookies and the Authorization header to be sent along. Origins are
+	// always echoed back rather than "*" when set, as browsers require.
+	AllowCredentials bool
+	// How long browsers may cache a preflight response
+	MaxAge time.Duration
+}
+
+func (o CORSOptions) allowed(origin string) bool {
+	return slices.Contains(o.AllowedOrigins, "*") || slices.Contains(o.AllowedOrigins, origin)
+}
+
+// Answers preflight requests and adds CORS headers to requests from allowed
+// origins. Preflights from any other origin are refused with a 403, while
+// their regular requests pass through without headers for the browser to block.
+func CORSMiddleware(opts CORSOptions) func(http.Handler) http.Handler {
+	allowOrigin := func(origin string) string {
+		if slices.Contains(opts.AllowedOrigins, "*") && !opts.AllowCredentials {
+			return "*"
+		}
+		return origin
+	}
+
+	return func(h http.Handler) http.Handler {
+		if len(opts.AllowedOrigins) == 0 {
+			return h
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Responses differ by origin, so caches must too
+			w.Header().Add("Vary", "Origin")
+
+			origin := r.Header.Get("Origin")
+			if origin == "" {
+				h.ServeHTTP(w, r)
+				return
+			}
+
+			preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+			if !opts.allowed(origin) {
+				if preflight {
+					w.WriteHeader(http.StatusForbidden)
+					return
+				}
+				h.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Set("Access-Control-Allow-Origin", allowOrigin(origin))
+			if opts.AllowCredentials {
+				w.Header().Set("Access-Control-Allow-Credentials", "true")
+			}
+
+			if !preflight {
+				if len(opts.ExposedHeaders) > 0 {
+					w.Header().Set("Access-Control-Expose-Headers", strings.Join(opts.ExposedHeaders, ", "))
+				}
+				h.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Add("Vary", "Access-Control-Request-Method")
+			w.Header().Add("Vary", "Access-Control-Request-Headers")
+			w.Header().Set("Access-Control-Allow-Methods", strings.Join(opts.AllowedMethods, ", "))
+			if len(opts.AllowedHeaders) > 0 {
+				w.Header().Set("Access-Control-Allow-Headers", strings.Join(opts.AllowedHeaders, ", "))
+			}
+			if opts.MaxAge > 0 {
+				w.Header().Set("Access-Control-Max-Age", strconv.Itoa(int(opts.MaxAge.Seconds())))
+			}
+			w.WriteHeader(http.StatusNoContent)
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCORSMiddleware(t *testing.T) {
+	opts := CORSOptions{
+		AllowedOrigins: []string{"https://reaphur.example"},
+		AllowedMethods: []string{"GET", "POST"},
+		AllowedHeaders: []string{"Authorization", "Content-Type"},
+		ExposedHeaders: []string{"X-Request-ID"},
+		MaxAge:         10 * time.Minute,
+	}
+
+	tests := []struct {
+		name   string
+		opts   CORSOptions
+		method string
+		// Origin and Access-Control-Request-Method request headers
+		origin        string
+		requestMethod string
+		// Whether the request reaches the handler, and the expected response
+		reached bool
+		status  int
+		headers map[string]string
+	}{
+		{
+			name: "no origin", opts: opts, method: http.MethodGet,
+			reached: true, status: http.StatusOK,
+			headers: map[string]string{"Access-Control-Allow-Origin": "", "Vary": "Origin"},
+		},
+		{
+			name: "allowed origin", opts: opts, method: http.MethodGet, origin: "https://reaphur.example",
+			reached: true, status: http.StatusOK,
+			headers: map[string]string{
+				"Access-Control-Allow-Origin":      "https://reaphur.example",
+				"Access-Control-Expose-Headers":    "X-Request-ID",
+				"Access-Control-Allow-Credentials": "",
+				"Access-Control-Allow-Methods":     "",
+			},
+		},
+		{
+			name: "disallowed origin", opts: opts, method: http.MethodGet, origin: "https://evil.example",
+			reached: true, status: http.StatusOK,
+			headers: map[string]string{"Access-Control-Allow-Origin": ""},
+		},
+		{
+			name: "allowed preflight", opts: opts, method: http.MethodOptions, origin: "https://reaphur.example", requestMethod: http.MethodPost,
+			reached: false, status: http.StatusNoContent,
+			headers: map[string]string{
+				"Access-Control-Allow-Origin":  "https://reaphur.example",
+				"Access-Control-Allow-Methods": "GET, POST",
+				"Access-Control-Allow-Headers": "Authorization, Content-Type",
+				"Access-Control-Max-Age":       "600",
+			},
+		},
+		{
+			name: "disallowed preflight", opts: opts, method: http.MethodOptions, origin: "https://evil.example", requestMethod: http.MethodPost,
+			reached: false, status: http.StatusForbidden,
+			headers: map[string]string{"Access-Control-Allow-Origin": ""},
+		},
+		{
+			name: "options without preflight", opts: opts, method: http.MethodOptions, origin: "https://reaphur.example",
+			reached: true, status: http.StatusOK,
+			headers: map[string]string{"Access-Control-Allow-Origin": "https://reaphur.example"},
+		},
+		{
+			name: "wildcard", opts: CORSOptions{AllowedOrigins: []string{"*"}}, method: http.MethodGet, origin: "https://anywhere.example",
+			reached: true, status: http.StatusOK,
+			headers: map[string]string{"Access-Control-Allow-Origin": "*"},
+		},
+		{
+			name: "wildcard with credentials", opts: CORSOptions{AllowedOrigins: []string{"*"}, AllowCredentials: true}, method: http.MethodGet, origin: "https://anywhere.example",
+			reached: true, status: http.StatusOK,
+			headers: map[string]string{
+				"Access-Control-Allow-Origin":      "https://anywhere.example",
+				"Access-Control-Allow-Credentials": "true",
+			},
+		},
+		{
+			name: "disabled", opts: CORSOptions{}, method: http.MethodGet, origin: "https://reaphur.example",
+			reached: true, status: http.StatusOK,
+			headers: map[string]string{"Access-Control-Allow-Origin": "", "Vary": ""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reached := false
+			handler := CORSMiddleware(tt.opts)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				reached = true
+			}))
+
+			rq := httptest.NewRequest(tt.method, "/", nil)
+			if tt.origin != "" {
+				rq.Header.Set("Origin", tt.origin)
+			}
+			if tt.requestMethod != "" {
+				rq.Header.Set("Access-Control-Request-Method", tt.requestMethod)
+			}
+			rs := httptest.NewRecorder()
+
+			handler.ServeHTTP(rs, rq)
+
+			if reached != tt.reached {
+				t.Errorf("got handler reached %v but want %v", reached, tt.reached)
+			}
+			if rs.Code != tt.status {
+				t.Errorf("got status %d but want %d", rs.Code, tt.status)
+			}
+			for header, want := range tt.headers {
+				if got := rs.Header().Get(header); got != want {
+					t.Errorf("got %s %q but want %q", header, got, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/calamity-m/reaphur/pkg/errs"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Maps errors returned by handlers to grpc statuses. Structured errs.Error values
+// and sentinel errors get their matching code, such as InvalidArgument or
+// NotFound, while anything unrecognised is logged and replaced with a generic
+// Internal error so nothing leaks to the caller.
+func ErrorUnaryInterceptor(logger *slog.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		resp, err = handler(ctx, req)
+
+		return resp, toStatusError(logger, ctx, info.FullMethod, err)
+	}
+}
+
+// Stream equivalent of ErrorUnaryInterceptor
+func ErrorStreamInterceptor(logger *slog.Logger) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return toStatusError(logger, ss.Context(), info.FullMethod, handler(srv, ss))
+	}
+}
+
+func toStatusError(logger *slog.Logger, ctx context.Context, method string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	// Statuses built by hand, such as by the rate limiter, are already fine
+	var structured *errs.Error
+	if _, ok := status.FromError(err); ok && !errors.As(err, &structured) {
+		return err
+	}
+
+	e := errs.From(err)
+	if e.Code == codes.Internal {
+		logger.ErrorContext(ctx, "rpc failed with an internal error", slog.String("method", method), slog.Any("err", err))
+	}
+
+	return e.GRPCStatus().Err()
+}
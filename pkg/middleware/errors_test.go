@@ -0,0 +1,106 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/calamity-m/reaphur/pkg/errs"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestErrorUnaryInterceptor(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	interceptor := ErrorUnaryInterceptor(logger)
+
+	tests := []struct {
+		name        string
+		err         error
+		wantCode    codes.Code
+		wantMessage string
+		wantFields  []string
+	}{
+		{
+			name:     "no error",
+			err:      nil,
+			wantCode: codes.OK,
+		},
+		{
+			name:        "wrapped bad request",
+			err:         fmt.Errorf("description must not be empty - %w", errs.ErrBadRequest),
+			wantCode:    codes.InvalidArgument,
+			wantMessage: "description must not be empty - bad request",
+		},
+		{
+			name:        "not found",
+			err:         errs.ErrNotFound,
+			wantCode:    codes.NotFound,
+			wantMessage: "not found",
+		},
+		{
+			name:        "already exists",
+			err:         fmt.Errorf("record already exists for id - %w", errs.ErrAlreadyExists),
+			wantCode:    codes.AlreadyExists,
+			wantMessage: "record already exists for id - already exists",
+		},
+		{
+			name:        "unavailable",
+			err:         fmt.Errorf("%w: %w", errs.ErrUnavailable, errors.New("circuit open")),
+			wantCode:    codes.Unavailable,
+			wantMessage: "unavailable: circuit open",
+		},
+		{
+			name:        "structured error with fields",
+			err:         errs.Field(errs.ErrBadUserId, "record.user_id", "must be a uuid"),
+			wantCode:    codes.InvalidArgument,
+			wantMessage: "bad user id",
+			wantFields:  []string{"record.user_id"},
+		},
+		{
+			name:        "unrecognised errors are hidden",
+			err:         errors.New("dial tcp 10.0.0.1:6379: connection refused"),
+			wantCode:    codes.Internal,
+			wantMessage: "internal server error",
+		},
+		{
+			name:        "existing statuses are left alone",
+			err:         status.Error(codes.ResourceExhausted, "rate limited, retry after 3s"),
+			wantCode:    codes.ResourceExhausted,
+			wantMessage: "rate limited, retry after 3s",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/test"}, func(ctx context.Context, req any) (any, error) {
+				return nil, tt.err
+			})
+
+			st := status.Convert(err)
+			if st.Code() != tt.wantCode {
+				t.Errorf("got code %v but want %v", st.Code(), tt.wantCode)
+			}
+			if st.Message() != tt.wantMessage {
+				t.Errorf("got message %q but want %q", st.Message(), tt.wantMessage)
+			}
+
+			fields := []string{}
+			for _, detail := range st.Details() {
+				if badRequest, ok := detail.(*errdetails.BadRequest); ok {
+					for _, violation := range badRequest.GetFieldViolations() {
+						fields = append(fields, violation.GetField())
+					}
+				}
+			}
+			if fmt.Sprint(fields) != fmt.Sprint(append([]string{}, tt.wantFields...)) {
+				t.Errorf("got field violations %v but want %v", fields, tt.wantFields)
+			}
+		})
+	}
+}
@@ -2,32 +2,186 @@ package middleware
 
 import (
 	"context"
+	"fmt"
+	"log/slog"
+	"time"
 
+	"github.com/calamity-m/reaphur/pkg/bindings"
+	"github.com/google/uuid"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
 )
 
-// TODO all dis stuff, hello me in probably 3 weeks
+// Metadata key that will be queried and written to, the grpc equivalent of the
+// X-Request-ID header
+var (
+	RequestIDMetadataKey = "x-request-id"
+)
+
+// Creates a new UUID7 request id
+func NewRequestID() (string, error) {
+	id, err := uuid.NewV7()
+	if err != nil {
+		return "", err
+	}
+
+	return id.String(), nil
+}
+
+// Returns the context with a request id, generating one if it doesn't already
+// have one. Used where requests start, such as on discord events.
+func ContextWithRequestID(ctx context.Context) context.Context {
+	if id, ok := ctx.Value(bindings.RequestIDKey{}).(string); ok && id != "" {
+		return ctx
+	}
+
+	id, err := NewRequestID()
+	if err != nil {
+		return ctx
+	}
+
+	return context.WithValue(ctx, bindings.RequestIDKey{}, id)
+}
+
+// Pulls the request id from the incoming metadata, generating a new one when
+// the caller didn't provide one
+func incomingRequestID(logger *slog.Logger, ctx context.Context) string {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if ids := md.Get(RequestIDMetadataKey); len(ids) > 0 && ids[0] != "" {
+			return ids[0]
+		}
+	}
 
-func RequestIdUnaryInterceptor() grpc.UnaryServerInterceptor {
+	id, err := NewRequestID()
+	if err != nil {
+		logger.Error(fmt.Sprintf("failed to create a V7 request id due to: %v", err))
+	}
+
+	return id
+}
+
+// Attaches the request's request id to the context, and sends it back to the
+// caller as a header. Works off of the x-request-id metadata, creating a new
+// UUID7 when the request has not provided one.
+func RequestIdUnaryInterceptor(logger *slog.Logger) grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
-		return nil, nil
+		id := incomingRequestID(logger, ctx)
+
+		if err := grpc.SetHeader(ctx, metadata.Pairs(RequestIDMetadataKey, id)); err != nil {
+			logger.Debug("failed to set request id header", slog.Any("err", err))
+		}
+
+		return handler(context.WithValue(ctx, bindings.RequestIDKey{}, id), req)
 	}
 }
 
-func LoggingUnaryInterceptor() grpc.UnaryServerInterceptor {
+// Logs the end of each rpc with tracing information, including the status code
+// and the duration the rpc took.
+func LoggingUnaryInterceptor(logger *slog.Logger) grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
-		return nil, nil
+		start := time.Now()
+
+		resp, err = handler(ctx, req)
+
+		logRPC(logger, ctx, info.FullMethod, err, time.Since(start))
+
+		return resp, err
 	}
 }
 
-func RequestIdStreamInterceptor() grpc.StreamServerInterceptor {
+// Stream equivalent of RequestIdUnaryInterceptor
+func RequestIdStreamInterceptor(logger *slog.Logger) grpc.StreamServerInterceptor {
 	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
-		return nil
+		id := incomingRequestID(logger, ss.Context())
+
+		if err := ss.SetHeader(metadata.Pairs(RequestIDMetadataKey, id)); err != nil {
+			logger.Debug("failed to set request id header", slog.Any("err", err))
+		}
+
+		return handler(srv, &contextStream{
+			ServerStream: ss,
+			ctx:          context.WithValue(ss.Context(), bindings.RequestIDKey{}, id),
+		})
 	}
 }
 
-func LoggingStreamInterceptor() grpc.StreamServerInterceptor {
+// Stream equivalent of LoggingUnaryInterceptor, logged once the stream ends
+func LoggingStreamInterceptor(logger *slog.Logger) grpc.StreamServerInterceptor {
 	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
-		return nil
+		start := time.Now()
+
+		err := handler(srv, ss)
+
+		logRPC(logger, ss.Context(), info.FullMethod, err, time.Since(start))
+
+		return err
+	}
+}
+
+// Sends the context's request id along with outgoing rpcs, so a request can be
+// followed across services
+func RequestIdUnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return invoker(outgoingRequestID(ctx), method, req, reply, cc, opts...)
+	}
+}
+
+// Stream equivalent of RequestIdUnaryClientInterceptor
+func RequestIdStreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return streamer(outgoingRequestID(ctx), desc, cc, method, opts...)
+	}
+}
+
+func outgoingRequestID(ctx context.Context) context.Context {
+	id, ok := ctx.Value(bindings.RequestIDKey{}).(string)
+	if !ok || id == "" {
+		return ctx
+	}
+
+	// Don't clobber an id the caller already set themselves
+	if md, ok := metadata.FromOutgoingContext(ctx); ok && len(md.Get(RequestIDMetadataKey)) > 0 {
+		return ctx
 	}
+
+	return metadata.AppendToOutgoingContext(ctx, RequestIDMetadataKey, id)
+}
+
+func logRPC(logger *slog.Logger, ctx context.Context, method string, err error, duration time.Duration) {
+	code := status.Code(err)
+
+	addr := "unknown"
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		addr = p.Addr.String()
+	}
+
+	// Only failures on our side are worth shouting about
+	level := slog.LevelInfo
+	switch code {
+	case codes.Internal, codes.Unknown, codes.DataLoss:
+		level = slog.LevelError
+	}
+
+	logger.LogAttrs(
+		ctx,
+		level,
+		"processed rpc",
+		slog.String("code", code.String()),
+		slog.String("method", method),
+		slog.String("peer", addr),
+		slog.String("duration", duration.String()),
+	)
+}
+
+// Server stream carrying a replacement context
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
 }
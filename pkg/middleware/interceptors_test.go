@@ -0,0 +1,162 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/calamity-m/reaphur/pkg/bindings"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// Thread safe buffer, as the server logs from its own goroutines
+type syncBuffer struct {
+	mux sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	return b.buf.String()
+}
+
+func TestRequestIdAndLoggingInterceptors(t *testing.T) {
+	logs := &syncBuffer{}
+	logger := slog.New(slog.NewJSONHandler(logs, nil))
+
+	// Spies on the request id the handlers see
+	var (
+		seenMux sync.Mutex
+		seen    []string
+	)
+	spy := func(ctx context.Context) {
+		seenMux.Lock()
+		defer seenMux.Unlock()
+		id, _ := ctx.Value(bindings.RequestIDKey{}).(string)
+		seen = append(seen, id)
+	}
+
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(
+			RequestIdUnaryInterceptor(logger),
+			LoggingUnaryInterceptor(logger),
+			func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+				spy(ctx)
+				return handler(ctx, req)
+			},
+		),
+		grpc.ChainStreamInterceptor(
+			RequestIdStreamInterceptor(logger),
+			LoggingStreamInterceptor(logger),
+			func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+				spy(ss.Context())
+				return handler(srv, ss)
+			},
+		),
+	)
+	healthpb.RegisterHealthServer(server, health.NewServer())
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient(
+		"passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithChainUnaryInterceptor(RequestIdUnaryClientInterceptor()),
+		grpc.WithChainStreamInterceptor(RequestIdStreamClientInterceptor()),
+	)
+	if err != nil {
+		t.Fatalf("failed creating client: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	client := healthpb.NewHealthClient(conn)
+
+	t.Run("propagates the caller's request id", func(t *testing.T) {
+		ctx := context.WithValue(context.Background(), bindings.RequestIDKey{}, "faked-request-id")
+
+		var header metadata.MD
+		if _, err := client.Check(ctx, &healthpb.HealthCheckRequest{}, grpc.Header(&header)); err != nil {
+			t.Fatalf("got err %v", err)
+		}
+
+		if got := seen[len(seen)-1]; got != "faked-request-id" {
+			t.Errorf("got %q but want the caller's request id in the handler context", got)
+		}
+		if got := header.Get(RequestIDMetadataKey); len(got) != 1 || got[0] != "faked-request-id" {
+			t.Errorf("got %v but want the request id sent back as a header", got)
+		}
+	})
+
+	t.Run("generates a request id when missing", func(t *testing.T) {
+		var header metadata.MD
+		if _, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{}, grpc.Header(&header)); err != nil {
+			t.Fatalf("got err %v", err)
+		}
+
+		got := seen[len(seen)-1]
+		if got == "" || got == "faked-request-id" {
+			t.Errorf("got %q but want a generated request id", got)
+		}
+		if ids := header.Get(RequestIDMetadataKey); len(ids) != 1 || ids[0] != got {
+			t.Errorf("got %v but want the generated request id %q sent back", ids, got)
+		}
+	})
+
+	t.Run("streams get a request id too", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.WithValue(context.Background(), bindings.RequestIDKey{}, "streamed-request-id"))
+		stream, err := client.Watch(ctx, &healthpb.HealthCheckRequest{})
+		if err != nil {
+			t.Fatalf("got err %v", err)
+		}
+		if _, err := stream.Recv(); err != nil {
+			t.Fatalf("got err %v", err)
+		}
+		cancel()
+
+		seenMux.Lock()
+		got := seen[len(seen)-1]
+		seenMux.Unlock()
+		if got != "streamed-request-id" {
+			t.Errorf("got %q but want the caller's request id in the stream context", got)
+		}
+	})
+
+	t.Run("logs the method and status", func(t *testing.T) {
+		found := false
+		for _, line := range strings.Split(strings.TrimSpace(logs.String()), "\n") {
+			var entry map[string]any
+			if err := json.Unmarshal([]byte(line), &entry); err != nil {
+				t.Fatalf("failed decoding log line %q: %v", line, err)
+			}
+
+			if entry["msg"] == "processed rpc" && entry["method"] == "/grpc.health.v1.Health/Check" {
+				found = true
+				if entry["code"] != "OK" || entry["peer"] == "" || entry["duration"] == "" {
+					t.Errorf("got log entry %v", entry)
+				}
+			}
+		}
+
+		if !found {
+			t.Errorf("got logs %s but want the rpc logged", logs.String())
+		}
+	})
+}
@@ -0,0 +1,20 @@
+package middleware
+
+import (
+	"net/http"
+)
+
+// Limits request bodies to limit bytes. Reading past it fails with a
+// *http.MaxBytesError, which handlers should report as a 413.
+func MaxBytesMiddleware(limit int64) func(http.Handler) http.Handler {
+	return func(h http.Handler) http.Handler {
+		if limit <= 0 {
+			return h
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r.Body = http.MaxBytesReader(w, r.Body, limit)
+			h.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMaxBytesMiddleware(t *testing.T) {
+	tests := []struct {
+		name   string
+		limit  int64
+		body   string
+		tooBig bool
+	}{
+		{"under limit", 10, "short", false},
+		{"at limit", 5, "short", false},
+		{"over limit", 4, "short", true},
+		{"no limit", 0, "short", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var readErr error
+			handler := MaxBytesMiddleware(tt.limit)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, readErr = io.ReadAll(r.Body)
+			}))
+
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body)))
+
+			var maxBytesErr *http.MaxBytesError
+			if got := errors.As(readErr, &maxBytesErr); got != tt.tooBig {
+				t.Errorf("got too big %v but want %v, err %v", got, tt.tooBig, readErr)
+			}
+		})
+	}
+}
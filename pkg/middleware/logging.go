@@ -18,6 +18,19 @@ func (l *loggingWriter) WriteHeader(statusCode int) {
 	l.statusCode = statusCode
 }
 
+// Passes flushes through, so streamed responses such as server sent events
+// still reach the client as they're written
+func (l *loggingWriter) Flush() {
+	if flusher, ok := l.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Lets http.ResponseController reach the inner writer
+func (l *loggingWriter) Unwrap() http.ResponseWriter {
+	return l.ResponseWriter
+}
+
 // Logs the end of request action with tracing information, including
 // the duration the request took.
 func LoggingMiddleware(logger *slog.Logger) func(http.Handler) http.Handler {
@@ -26,7 +39,8 @@ func LoggingMiddleware(logger *slog.Logger) func(http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			start := time.Now()
 
-			wrappedWriter := loggingWriter{ResponseWriter: w}
+			// Handlers that never write a header are sent back as a 200
+			wrappedWriter := loggingWriter{ResponseWriter: w, statusCode: http.StatusOK}
 
 			h.ServeHTTP(&wrappedWriter, r)
 
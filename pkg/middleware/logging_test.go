@@ -53,3 +53,22 @@ func TestLoggingMiddleware(t *testing.T) {
 		t.Errorf("failed to record duration")
 	}
 }
+
+func TestLoggingMiddlewareFlushes(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(&bytes.Buffer{}, nil))
+	rq := httptest.NewRequest(http.MethodGet, "/", nil)
+	rs := httptest.NewRecorder()
+
+	LoggingMiddleware(logger)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			t.Fatalf("wrapped writer isn't a flusher")
+		}
+		fmt.Fprint(w, "event")
+		flusher.Flush()
+	})).ServeHTTP(rs, rq)
+
+	if !rs.Flushed {
+		t.Errorf("flush didn't reach the underlying writer")
+	}
+}
@@ -0,0 +1,139 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// RED metrics for rpcs, labelled by the full method name and status code only.
+// Both are bounded, as grpc never runs interceptors for unknown methods.
+var (
+	serverHandled = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "reaphur",
+		Subsystem: "grpc_server",
+		Name:      "handled_total",
+		Help:      "Rpcs completed by the server, by method and status code.",
+	}, []string{"method", "code"})
+
+	serverHandling = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "reaphur",
+		Subsystem: "grpc_server",
+		Name:      "handling_seconds",
+		Help:      "Time taken by the server to handle rpcs, by method and status code.",
+		Buckets:   rpcBuckets,
+	}, []string{"method", "code"})
+
+	clientHandled = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "reaphur",
+		Subsystem: "grpc_client",
+		Name:      "handled_total",
+		Help:      "Rpcs completed by clients, by method and status code.",
+	}, []string{"method", "code"})
+
+	clientHandling = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "reaphur",
+		Subsystem: "grpc_client",
+		Name:      "handling_seconds",
+		Help:      "Time taken for rpcs to complete as seen by clients, by method and status code.",
+		Buckets:   rpcBuckets,
+	}, []string{"method", "code"})
+)
+
+// Rpcs can sit on the llm for a good while, so the buckets run well past the
+// prometheus defaults
+var rpcBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+
+func observeRPC(handled *prometheus.CounterVec, handling *prometheus.HistogramVec, method string, err error, duration time.Duration) {
+	code := status.Code(err).String()
+
+	handled.WithLabelValues(method, code).Inc()
+	handling.WithLabelValues(method, code).Observe(duration.Seconds())
+}
+
+// Records the rate, errors and duration of each rpc. Should sit outside of the
+// error interceptor so the mapped status codes are what get recorded.
+func MetricsUnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		start := time.Now()
+
+		resp, err = handler(ctx, req)
+
+		observeRPC(serverHandled, serverHandling, info.FullMethod, err, time.Since(start))
+
+		return resp, err
+	}
+}
+
+// Stream equivalent of MetricsUnaryInterceptor, recorded once the stream ends
+func MetricsStreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+
+		err := handler(srv, ss)
+
+		observeRPC(serverHandled, serverHandling, info.FullMethod, err, time.Since(start))
+
+		return err
+	}
+}
+
+// Client equivalent of MetricsUnaryInterceptor
+func MetricsUnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		start := time.Now()
+
+		err := invoker(ctx, method, req, reply, cc, opts...)
+
+		observeRPC(clientHandled, clientHandling, method, err, time.Since(start))
+
+		return err
+	}
+}
+
+// Client equivalent of MetricsStreamInterceptor. The stream is recorded when
+// receiving ends, or straight away if it couldn't be opened.
+func MetricsStreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		start := time.Now()
+
+		stream, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			observeRPC(clientHandled, clientHandling, method, err, time.Since(start))
+			return nil, err
+		}
+
+		return &observedClientStream{ClientStream: stream, method: method, start: start}, nil
+	}
+}
+
+// Client stream that records itself the first time receiving fails, which is
+// how grpc reports the end of a stream
+type observedClientStream struct {
+	grpc.ClientStream
+	method string
+	start  time.Time
+	done   bool
+}
+
+func (s *observedClientStream) RecvMsg(m any) error {
+	err := s.ClientStream.RecvMsg(m)
+	if err == nil || s.done {
+		return err
+	}
+
+	s.done = true
+	if errors.Is(err, io.EOF) {
+		observeRPC(clientHandled, clientHandling, s.method, nil, time.Since(s.start))
+	} else {
+		observeRPC(clientHandled, clientHandling, s.method, err, time.Since(s.start))
+	}
+
+	return err
+}
@@ -0,0 +1,98 @@
+package middleware
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestMetricsInterceptors(t *testing.T) {
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(MetricsUnaryInterceptor()),
+		grpc.ChainStreamInterceptor(MetricsStreamInterceptor()),
+	)
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus("known", healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(server, healthServer)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient(
+		"passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithChainUnaryInterceptor(MetricsUnaryClientInterceptor()),
+		grpc.WithChainStreamInterceptor(MetricsStreamClientInterceptor()),
+	)
+	if err != nil {
+		t.Fatalf("failed creating client: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	client := healthpb.NewHealthClient(conn)
+
+	const (
+		check = "/grpc.health.v1.Health/Check"
+		watch = "/grpc.health.v1.Health/Watch"
+	)
+	count := func(t *testing.T, method string, code codes.Code) (float64, float64) {
+		t.Helper()
+		return testutil.ToFloat64(serverHandled.WithLabelValues(method, code.String())),
+			testutil.ToFloat64(clientHandled.WithLabelValues(method, code.String()))
+	}
+
+	t.Run("unary", func(t *testing.T) {
+		okServer, okClient := count(t, check, codes.OK)
+		notFoundServer, notFoundClient := count(t, check, codes.NotFound)
+
+		if _, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "known"}); err != nil {
+			t.Fatalf("got err %v", err)
+		}
+		if _, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "unknown"}); err == nil {
+			t.Fatalf("got no err but want not found")
+		}
+
+		server, client := count(t, check, codes.OK)
+		if server-okServer != 1 || client-okClient != 1 {
+			t.Errorf("got %v server and %v client ok rpcs but want 1 each", server-okServer, client-okClient)
+		}
+		server, client = count(t, check, codes.NotFound)
+		if server-notFoundServer != 1 || client-notFoundClient != 1 {
+			t.Errorf("got %v server and %v client not found rpcs but want 1 each", server-notFoundServer, client-notFoundClient)
+		}
+	})
+
+	t.Run("client stream recorded once it ends", func(t *testing.T) {
+		_, before := count(t, watch, codes.Canceled)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		stream, err := client.Watch(ctx, &healthpb.HealthCheckRequest{Service: "known"})
+		if err != nil {
+			t.Fatalf("got err %v", err)
+		}
+		if _, err := stream.Recv(); err != nil {
+			t.Fatalf("got err %v", err)
+		}
+		if _, after := count(t, watch, codes.Canceled); after != before {
+			t.Errorf("got the stream recorded before it ended")
+		}
+
+		cancel()
+		if _, err := stream.Recv(); err == nil {
+			t.Fatalf("got no err but want the stream cancelled")
+		}
+		stream.Recv()
+
+		if _, after := count(t, watch, codes.Canceled); after-before != 1 {
+			t.Errorf("got %v cancelled streams but want 1", after-before)
+		}
+	})
+}
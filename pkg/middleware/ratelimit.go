@@ -0,0 +1,273 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+const (
+	// Header sent back alongside ResourceExhausted errors, holding the whole
+	// number of seconds the caller should wait before retrying.
+	RetryAfterHeader = "retry-after"
+
+	// How often the memory limiter drops buckets that have refilled
+	memorySweepInterval = time.Minute
+)
+
+// Token bucket limit. Rate is the number of tokens refilled per second and Burst
+// the maximum number of tokens the bucket can hold.
+type RateLimit struct {
+	Rate  float64
+	Burst int
+}
+
+type RateLimiter interface {
+	// Takes a single token from the bucket identified by key. When no token is
+	// available, the duration until one will be is returned.
+	Allow(ctx context.Context, key string, limit RateLimit) (bool, time.Duration, error)
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+	// When the bucket will have refilled, after which it is no different from
+	// a new one
+	full time.Time
+}
+
+// In memory token buckets, only suitable for a single instance deployment.
+// Buckets that have refilled are dropped as it goes, so only recent callers
+// are held onto.
+type MemoryRateLimiter struct {
+	mux       sync.Mutex
+	buckets   map[string]*bucket
+	now       func() time.Time
+	lastSweep time.Time
+}
+
+func (m *MemoryRateLimiter) Allow(ctx context.Context, key string, limit RateLimit) (bool, time.Duration, error) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	now := m.now()
+	if now.Sub(m.lastSweep) >= memorySweepInterval {
+		m.sweep(now)
+	}
+
+	b, ok := m.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Burst), last: now}
+		m.buckets[key] = b
+	}
+
+	// Refill based on the time elapsed since we last looked at this bucket
+	b.tokens = math.Min(float64(limit.Burst), b.tokens+now.Sub(b.last).Seconds()*limit.Rate)
+	b.last = now
+
+	allowed := b.tokens >= 1
+	if allowed {
+		b.tokens--
+	}
+	b.full = now.Add(time.Duration((float64(limit.Burst) - b.tokens) / limit.Rate * float64(time.Second)))
+
+	if allowed {
+		return true, 0, nil
+	}
+
+	wait := time.Duration((1 - b.tokens) / limit.Rate * float64(time.Second))
+	return false, wait, nil
+}
+
+// Drops every bucket that has refilled by now
+func (m *MemoryRateLimiter) sweep(now time.Time) {
+	for key, b := range m.buckets {
+		if !now.Before(b.full) {
+			delete(m.buckets, key)
+		}
+	}
+	m.lastSweep = now
+}
+
+func NewMemoryRateLimiter() *MemoryRateLimiter {
+	return &MemoryRateLimiter{buckets: make(map[string]*bucket), now: time.Now}
+}
+
+// Per RPC limits. Methods are keyed on either the full method name, i.e.
+// "/centralproto.v1.CentralService/CallFnUserInput", or just the method, i.e.
+// "CallFnUserInput". Methods without a limit are not limited.
+type RateLimits map[string]RateLimit
+
+func (l RateLimits) lookup(fullMethod string) (RateLimit, bool) {
+	if limit, ok := l[fullMethod]; ok {
+		return limit, true
+	}
+
+	limit, ok := l[fullMethod[strings.LastIndex(fullMethod, "/")+1:]]
+	return limit, ok
+}
+
+// Parses limits in the form "Method=rate:burst,OtherMethod=rate:burst"
+func ParseRateLimits(spec string) (RateLimits, error) {
+	limits := make(RateLimits)
+
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		method, limit, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("rate limit %q missing '='", entry)
+		}
+
+		rawRate, rawBurst, ok := strings.Cut(limit, ":")
+		if !ok {
+			return nil, fmt.Errorf("rate limit %q missing ':'", entry)
+		}
+
+		rate, err := strconv.ParseFloat(rawRate, 64)
+		if err != nil || rate <= 0 {
+			return nil, fmt.Errorf("rate limit %q has bad rate", entry)
+		}
+
+		burst, err := strconv.Atoi(rawBurst)
+		if err != nil || burst < 1 {
+			return nil, fmt.Errorf("rate limit %q has bad burst", entry)
+		}
+
+		limits[strings.TrimSpace(method)] = RateLimit{Rate: rate, Burst: burst}
+	}
+
+	return limits, nil
+}
+
+type requestUserIdentifier interface {
+	GetRequestUserId() string
+}
+
+// Rate limits incoming requests on the request_user_id of the message. Requests that
+// do not carry a user id, or calls to methods without a configured limit, pass straight
+// through. Limited requests receive ResourceExhausted with a RetryInfo detail and a
+// retry-after header.
+//
+// Limiter failures are logged and fail open, we would rather serve than lock everyone out.
+func RateLimitUnaryInterceptor(logger *slog.Logger, limiter RateLimiter, limits RateLimits) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		limit, ok := limits.lookup(info.FullMethod)
+		if !ok {
+			return handler(ctx, req)
+		}
+
+		if err := allow(ctx, logger, limiter, limit, info.FullMethod, req); err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// Stream equivalent of RateLimitUnaryInterceptor. The user id is only known once
+// the first request message has been received, so that is when the limit applies.
+func RateLimitStreamInterceptor(logger *slog.Logger, limiter RateLimiter, limits RateLimits) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		limit, ok := limits.lookup(info.FullMethod)
+		if !ok {
+			return handler(srv, ss)
+		}
+
+		return handler(srv, &rateLimitedStream{
+			ServerStream: ss,
+			check: func(ctx context.Context, req any) error {
+				return allow(ctx, logger, limiter, limit, info.FullMethod, req)
+			},
+		})
+	}
+}
+
+type rateLimitedStream struct {
+	grpc.ServerStream
+	checked bool
+	check   func(ctx context.Context, req any) error
+}
+
+func (s *rateLimitedStream) RecvMsg(m any) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+
+	if s.checked {
+		return nil
+	}
+	s.checked = true
+
+	return s.check(s.Context(), m)
+}
+
+// Takes a token for the user of the request, returning the rate limited error
+// when none are left
+func allow(ctx context.Context, logger *slog.Logger, limiter RateLimiter, limit RateLimit, method string, req any) error {
+	identified, ok := req.(requestUserIdentifier)
+	if !ok || identified.GetRequestUserId() == "" {
+		return nil
+	}
+
+	key := fmt.Sprintf("%s:%s", method, identified.GetRequestUserId())
+
+	allowed, wait, err := limiter.Allow(ctx, key, limit)
+	if err != nil {
+		logger.ErrorContext(ctx, "rate limiter failed, allowing request", slog.Any("err", err), slog.String("method", method))
+		return nil
+	}
+
+	if !allowed {
+		logger.WarnContext(ctx, "rate limited request", slog.String("method", method), slog.String("user", identified.GetRequestUserId()), slog.Duration("retry_after", wait))
+		return rateLimitedError(ctx, wait)
+	}
+
+	return nil
+}
+
+func rateLimitedError(ctx context.Context, wait time.Duration) error {
+	seconds := int(math.Ceil(wait.Seconds()))
+
+	// Best effort, the RetryInfo detail carries the same information
+	grpc.SetHeader(ctx, metadata.Pairs(RetryAfterHeader, strconv.Itoa(seconds)))
+
+	st := status.New(codes.ResourceExhausted, fmt.Sprintf("rate limited, retry after %ds", seconds))
+	detailed, err := st.WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(wait)})
+	if err != nil {
+		return st.Err()
+	}
+
+	return detailed.Err()
+}
+
+// Extracts the retry delay from a rate limited error, if there is one
+func RetryAfter(err error) (time.Duration, bool) {
+	st, ok := status.FromError(err)
+	if !ok || st.Code() != codes.ResourceExhausted {
+		return 0, false
+	}
+
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.RetryInfo); ok {
+			return info.GetRetryDelay().AsDuration(), true
+		}
+	}
+
+	return 0, false
+}
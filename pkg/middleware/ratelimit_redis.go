@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Token bucket refill and take, performed atomically inside redis. Buckets are stored
+// as hashes and expire once they would have fully refilled.
+//
+// Returns {allowed, wait in milliseconds}
+var tokenBucketScript = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+
+local state = redis.call("HMGET", KEYS[1], "tokens", "ts")
+local tokens = tonumber(state[1]) or burst
+local ts = tonumber(state[2]) or now
+
+tokens = math.min(burst, tokens + ((now - ts) / 1000) * rate)
+
+local allowed = 0
+local wait = 0
+if tokens >= 1 then
+  tokens = tokens - 1
+  allowed = 1
+else
+  wait = math.ceil(((1 - tokens) / rate) * 1000)
+end
+
+redis.call("HSET", KEYS[1], "tokens", tostring(tokens), "ts", tostring(now))
+redis.call("PEXPIRE", KEYS[1], math.ceil((burst / rate) * 1000) + 1000)
+
+return {allowed, wait}
+`)
+
+// Redis backed token buckets, shared between every instance pointed at the same redis
+type RedisRateLimiter struct {
+	rdb    redis.Scripter
+	prefix string
+	now    func() time.Time
+}
+
+func (r *RedisRateLimiter) Allow(ctx context.Context, key string, limit RateLimit) (bool, time.Duration, error) {
+	res, err := tokenBucketScript.Run(
+		ctx,
+		r.rdb,
+		[]string{r.prefix + key},
+		limit.Rate,
+		limit.Burst,
+		r.now().UnixMilli(),
+	).Int64Slice()
+	if err != nil {
+		return false, 0, fmt.Errorf("failed running token bucket script: %w", err)
+	}
+
+	if len(res) != 2 {
+		return false, 0, fmt.Errorf("unexpected token bucket script result %v", res)
+	}
+
+	return res[0] == 1, time.Duration(res[1]) * time.Millisecond, nil
+}
+
+func NewRedisRateLimiter(rdb redis.Scripter) *RedisRateLimiter {
+	return &RedisRateLimiter{rdb: rdb, prefix: "ratelimit:", now: time.Now}
+}
@@ -0,0 +1,212 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type fakeUserRequest struct {
+	user string
+}
+
+func (f *fakeUserRequest) GetRequestUserId() string {
+	return f.user
+}
+
+func TestMemoryRateLimiter(t *testing.T) {
+	now := time.Unix(0, 0)
+	limiter := NewMemoryRateLimiter()
+	limiter.now = func() time.Time { return now }
+	limit := RateLimit{Rate: 1, Burst: 2}
+
+	// Burst is available straight away
+	for i := range 2 {
+		if ok, _, _ := limiter.Allow(context.Background(), "a", limit); !ok {
+			t.Fatalf("request %d should have been allowed", i)
+		}
+	}
+
+	ok, wait, _ := limiter.Allow(context.Background(), "a", limit)
+	if ok {
+		t.Fatal("request over burst should have been limited")
+	}
+	if wait != time.Second {
+		t.Errorf("got wait %v but want 1s", wait)
+	}
+
+	// Other keys have their own bucket
+	if ok, _, _ := limiter.Allow(context.Background(), "b", limit); !ok {
+		t.Error("separate key should have been allowed")
+	}
+
+	// Refills over time
+	now = now.Add(time.Second)
+	if ok, _, _ := limiter.Allow(context.Background(), "a", limit); !ok {
+		t.Error("request should have been allowed after refill")
+	}
+}
+
+func TestMemoryRateLimiterDropsRefilledBuckets(t *testing.T) {
+	now := time.Unix(0, 0)
+	limiter := NewMemoryRateLimiter()
+	limiter.now = func() time.Time { return now }
+	limit := RateLimit{Rate: 0.01, Burst: 2}
+
+	for _, key := range []string{"a", "b", "b"} {
+		limiter.Allow(context.Background(), key, limit)
+	}
+
+	// "a" is a token short, so refills after 100s, while "b" takes 200s
+	now = now.Add(150 * time.Second)
+	limiter.Allow(context.Background(), "c", limit)
+
+	if _, ok := limiter.buckets["a"]; ok {
+		t.Error("refilled bucket should have been dropped")
+	}
+	if _, ok := limiter.buckets["b"]; !ok {
+		t.Error("bucket still refilling should have been kept")
+	}
+
+	// "b" kept what it spent, so has one and a half tokens rather than a full bucket
+	ok, _, _ := limiter.Allow(context.Background(), "b", limit)
+	if !ok {
+		t.Error("request should have been allowed with a token refilled")
+	}
+	if ok, _, _ := limiter.Allow(context.Background(), "b", limit); ok {
+		t.Error("request should have been limited as the bucket wasn't reset")
+	}
+}
+
+func TestRedisRateLimiter(t *testing.T) {
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer rdb.Close()
+
+	now := time.UnixMilli(1_000_000)
+	limiter := NewRedisRateLimiter(rdb)
+	limiter.now = func() time.Time { return now }
+	limit := RateLimit{Rate: 0.5, Burst: 1}
+
+	if ok, _, err := limiter.Allow(context.Background(), "a", limit); !ok || err != nil {
+		t.Fatalf("first request should have been allowed, got err %v", err)
+	}
+
+	ok, wait, err := limiter.Allow(context.Background(), "a", limit)
+	if err != nil {
+		t.Fatalf("got err %v", err)
+	}
+	if ok {
+		t.Fatal("second request should have been limited")
+	}
+	if wait != 2*time.Second {
+		t.Errorf("got wait %v but want 2s", wait)
+	}
+
+	now = now.Add(2 * time.Second)
+	if ok, _, _ := limiter.Allow(context.Background(), "a", limit); !ok {
+		t.Error("request should have been allowed after refill")
+	}
+}
+
+func TestRateLimitUnaryInterceptor(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(&bytes.Buffer{}, nil))
+	limits := RateLimits{"CallFnUserInput": {Rate: 0.1, Burst: 1}}
+	interceptor := RateLimitUnaryInterceptor(logger, NewMemoryRateLimiter(), limits)
+
+	handler := func(ctx context.Context, req any) (any, error) {
+		return "ok", nil
+	}
+	limited := &grpc.UnaryServerInfo{FullMethod: "/centralproto.v1.CentralService/CallFnUserInput"}
+	unlimited := &grpc.UnaryServerInfo{FullMethod: "/centralproto.v1.CentralService/ActionUserInput"}
+
+	if _, err := interceptor(context.Background(), &fakeUserRequest{user: "one"}, limited, handler); err != nil {
+		t.Fatalf("first call should pass, got %v", err)
+	}
+
+	_, err := interceptor(context.Background(), &fakeUserRequest{user: "one"}, limited, handler)
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("got code %v but want ResourceExhausted", status.Code(err))
+	}
+
+	wait, ok := RetryAfter(err)
+	if !ok || wait < 9*time.Second || wait > 10*time.Second {
+		t.Errorf("got retry after %v (%t) but want ~10s", wait, ok)
+	}
+
+	if _, err := interceptor(context.Background(), &fakeUserRequest{user: "two"}, limited, handler); err != nil {
+		t.Errorf("other users should not be limited, got %v", err)
+	}
+
+	for range 3 {
+		if _, err := interceptor(context.Background(), &fakeUserRequest{user: "one"}, unlimited, handler); err != nil {
+			t.Errorf("unconfigured methods should not be limited, got %v", err)
+		}
+	}
+}
+
+func TestParseRateLimits(t *testing.T) {
+	got, err := ParseRateLimits("CallFnUserInput=0.2:5, /centralproto.v1.CentralService/ActionUserInput=1:1")
+	if err != nil {
+		t.Fatalf("got err %v", err)
+	}
+
+	if got["CallFnUserInput"] != (RateLimit{Rate: 0.2, Burst: 5}) {
+		t.Errorf("got %+v for CallFnUserInput", got["CallFnUserInput"])
+	}
+	if got["/centralproto.v1.CentralService/ActionUserInput"] != (RateLimit{Rate: 1, Burst: 1}) {
+		t.Errorf("got %+v for ActionUserInput", got["/centralproto.v1.CentralService/ActionUserInput"])
+	}
+
+	for _, bad := range []string{"nope", "A=1", "A=x:1", "A=1:0", "A=-1:1"} {
+		if _, err := ParseRateLimits(bad); err == nil {
+			t.Errorf("expected error parsing %q", bad)
+		}
+	}
+}
+
+type fakeServerStream struct {
+	grpc.ServerStream
+	user string
+}
+
+func (f *fakeServerStream) Context() context.Context {
+	return context.Background()
+}
+
+func (f *fakeServerStream) RecvMsg(m any) error {
+	m.(*fakeUserRequest).user = f.user
+	return nil
+}
+
+func TestRateLimitStreamInterceptor(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(&bytes.Buffer{}, nil))
+	limits := RateLimits{"CallFnUserInputStream": {Rate: 0.1, Burst: 1}}
+	interceptor := RateLimitStreamInterceptor(logger, NewMemoryRateLimiter(), limits)
+
+	handler := func(srv any, stream grpc.ServerStream) error {
+		return stream.RecvMsg(&fakeUserRequest{})
+	}
+	info := &grpc.StreamServerInfo{FullMethod: "/centralproto.v1.CentralService/CallFnUserInputStream"}
+
+	if err := interceptor(nil, &fakeServerStream{user: "one"}, info, handler); err != nil {
+		t.Fatalf("first stream should pass, got %v", err)
+	}
+
+	err := interceptor(nil, &fakeServerStream{user: "one"}, info, handler)
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("got code %v but want ResourceExhausted", status.Code(err))
+	}
+
+	if err := interceptor(nil, &fakeServerStream{user: "two"}, info, handler); err != nil {
+		t.Errorf("other users should not be limited, got %v", err)
+	}
+}
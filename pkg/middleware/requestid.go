@@ -7,7 +7,6 @@ import (
 	"net/http"
 
 	"github.com/calamity-m/reaphur/pkg/bindings"
-	"github.com/google/uuid"
 )
 
 // Header that will be queried and written to
@@ -22,22 +21,13 @@ var (
 // Works off of the X-Request-ID header.
 func RequestIDMiddleware(logger *slog.Logger, writeHeader bool) func(http.Handler) http.Handler {
 
-	gen := func() (string, error) {
-		id, err := uuid.NewV7()
-		if err != nil {
-			return "", err
-		}
-
-		return id.String(), err
-	}
-
 	return func(h http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			id := r.Header.Get(RequestIDHeader)
 
 			// Generate a UUID7 if id not populated
 			if id == "" {
-				newid, err := gen()
+				newid, err := NewRequestID()
 				if err != nil {
 					logger.Error(fmt.Sprintf("failed to create a V7 request id due to: %v", err))
 				}
@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+// Checks a request message, returning an error describing each bad field
+type Validator interface {
+	Validate(msg proto.Message) error
+}
+
+// Rejects requests that fail validation before they reach the handler. Messages
+// that aren't protos, which grpc never hands us, are passed through.
+func ValidationUnaryInterceptor(validator Validator) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		if msg, ok := req.(proto.Message); ok {
+			if err := validator.Validate(msg); err != nil {
+				return nil, err
+			}
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// Stream equivalent of ValidationUnaryInterceptor. Each received message is
+// validated, failing the receive when it's bad.
+func ValidationStreamInterceptor(validator Validator) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &validatedStream{ServerStream: ss, validator: validator})
+	}
+}
+
+type validatedStream struct {
+	grpc.ServerStream
+	validator Validator
+}
+
+func (s *validatedStream) RecvMsg(m any) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+
+	if msg, ok := m.(proto.Message); ok {
+		return s.validator.Validate(msg)
+	}
+
+	return nil
+}
@@ -0,0 +1,80 @@
+package middleware
+
+import (
+	"context"
+	"testing"
+
+	"github.com/calamity-m/reaphur/pkg/validate"
+	centralproto "github.com/calamity-m/reaphur/proto/v1/central"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+var testValidator = validate.New(validate.For(&centralproto.DeleteFoodRecordRequest{},
+	validate.Field("id", validate.Required(), validate.UUID()),
+))
+
+func TestValidationUnaryInterceptor(t *testing.T) {
+	interceptor := ValidationUnaryInterceptor(testValidator)
+
+	called := false
+	handler := func(ctx context.Context, req any) (any, error) {
+		called = true
+		return "ok", nil
+	}
+	info := &grpc.UnaryServerInfo{FullMethod: "/centralproto.v1.CentralFoodService/DeleteFoodRecord"}
+
+	_, err := interceptor(context.Background(), &centralproto.DeleteFoodRecordRequest{Id: "nope"}, info, handler)
+	if called {
+		t.Error("invalid request reached the handler")
+	}
+
+	st := status.Convert(err)
+	if st.Code() != codes.InvalidArgument {
+		t.Fatalf("got code %v but want InvalidArgument", st.Code())
+	}
+
+	details := st.Details()
+	if len(details) != 1 {
+		t.Fatalf("got %d details but want 1", len(details))
+	}
+	violations := details[0].(*errdetails.BadRequest).GetFieldViolations()
+	if len(violations) != 1 || violations[0].GetField() != "id" || violations[0].GetDescription() != "must be a uuid" {
+		t.Errorf("got violations %v", violations)
+	}
+
+	if _, err := interceptor(context.Background(), &centralproto.DeleteFoodRecordRequest{Id: "0195a7d4-32c4-7b5e-9d1c-6f0e2a3b4c5d"}, info, handler); err != nil || !called {
+		t.Errorf("valid request should reach the handler, got %v", err)
+	}
+}
+
+type fakeProtoStream struct {
+	grpc.ServerStream
+	msg proto.Message
+}
+
+func (f *fakeProtoStream) RecvMsg(m any) error {
+	proto.Merge(m.(proto.Message), f.msg)
+	return nil
+}
+
+func TestValidationStreamInterceptor(t *testing.T) {
+	interceptor := ValidationStreamInterceptor(testValidator)
+
+	handler := func(srv any, stream grpc.ServerStream) error {
+		return stream.RecvMsg(&centralproto.DeleteFoodRecordRequest{})
+	}
+	info := &grpc.StreamServerInfo{FullMethod: "/centralproto.v1.CentralFoodService/DeleteFoodRecord"}
+
+	err := interceptor(nil, &fakeProtoStream{msg: &centralproto.DeleteFoodRecordRequest{}}, info, handler)
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("got code %v but want InvalidArgument", status.Code(err))
+	}
+
+	if err := interceptor(nil, &fakeProtoStream{msg: &centralproto.DeleteFoodRecordRequest{Id: "0195a7d4-32c4-7b5e-9d1c-6f0e2a3b4c5d"}}, info, handler); err != nil {
+		t.Errorf("valid request should be received, got %v", err)
+	}
+}
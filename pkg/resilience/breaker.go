@@ -0,0 +1,115 @@
+package resilience
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+type State int
+
+const (
+	StateClosed State = iota
+	StateOpen
+	// Cooldown has passed and a single probe call is allowed through
+	StateHalfOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+// Stops calling a dependency after it fails repeatedly. Once the cooldown has
+// passed a single probe is let through, closing the breaker again if it works.
+type Breaker struct {
+	mux       sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	failures  int
+	state     State
+	opened    time.Time
+	probing   bool
+	now       func() time.Time
+}
+
+// Creates a breaker that opens after threshold consecutive failures. A zero
+// threshold never opens.
+func NewBreaker(threshold int, cooldown time.Duration) *Breaker {
+	return &Breaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		now:       time.Now,
+	}
+}
+
+// Returns ErrCircuitOpen if calls shouldn't be made right now. Every allowed
+// call must be followed by Success or Failure.
+func (b *Breaker) Allow() error {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+
+	if b.state == StateOpen && b.now().Sub(b.opened) >= b.cooldown {
+		b.state = StateHalfOpen
+	}
+
+	switch b.state {
+	case StateOpen:
+		return ErrCircuitOpen
+	case StateHalfOpen:
+		if b.probing {
+			return ErrCircuitOpen
+		}
+		b.probing = true
+	}
+
+	return nil
+}
+
+func (b *Breaker) Success() {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+
+	b.failures = 0
+	b.probing = false
+	b.state = StateClosed
+}
+
+func (b *Breaker) Failure() {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+
+	b.failures++
+	b.probing = false
+	if b.state == StateHalfOpen || (b.threshold > 0 && b.failures >= b.threshold) {
+		b.state = StateOpen
+		b.opened = b.now()
+	}
+}
+
+// Ends an allowed call without an outcome, such as when the caller gave up
+func (b *Breaker) Release() {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+
+	b.probing = false
+}
+
+func (b *Breaker) State() State {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+
+	if b.state == StateOpen && b.now().Sub(b.opened) >= b.cooldown {
+		return StateHalfOpen
+	}
+
+	return b.state
+}
@@ -0,0 +1,157 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// Retries and circuit breaks http calls. Its Do method has the shape of an
+// openai-go middleware, so it can be handed to option.WithMiddleware.
+type HTTPMiddleware struct {
+	logger  *slog.Logger
+	policy  RetryPolicy
+	breaker *Breaker
+	// Limit on a single attempt, zero leaves it to the request's context
+	attemptTimeout time.Duration
+}
+
+func NewHTTPMiddleware(logger *slog.Logger, policy RetryPolicy, breaker *Breaker, attemptTimeout time.Duration) *HTTPMiddleware {
+	return &HTTPMiddleware{
+		logger:         logger,
+		policy:         policy,
+		breaker:        breaker,
+		attemptTimeout: attemptTimeout,
+	}
+}
+
+// Sends the request, retrying connection errors, timeouts and retryable
+// statuses. The last response is returned once retries run out so the caller
+// still sees the real status.
+func (m *HTTPMiddleware) Do(req *http.Request, next func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+	if m.breaker != nil {
+		if err := m.breaker.Allow(); err != nil {
+			return nil, err
+		}
+	}
+
+	ctx := req.Context()
+	for retry := 0; ; retry++ {
+		res, err := m.attempt(req, next)
+
+		// The caller giving up says nothing about the dependency
+		if err != nil && ctx.Err() != nil {
+			m.release()
+			return nil, err
+		}
+
+		if err == nil && !Retryable(res.StatusCode) {
+			m.record(true)
+			return res, nil
+		}
+
+		wait, worthwhile := m.policy.Delay(retry, res)
+		if retry >= m.policy.MaxRetries || !worthwhile || req.Body != nil && req.GetBody == nil {
+			m.record(false)
+			return res, err
+		}
+
+		m.logger.WarnContext(ctx, "retrying failed http call", slog.String("url", req.URL.String()), slog.Int("retry", retry+1), slog.Duration("wait", wait), slog.Any("err", err), slog.Int("status", status(res)))
+
+		// Let the connection be reused before waiting
+		if res != nil {
+			_, _ = io.Copy(io.Discard, res.Body)
+			res.Body.Close()
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			m.release()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				m.release()
+				return nil, err
+			}
+			req = req.Clone(ctx)
+			req.Body = body
+		}
+	}
+}
+
+func (m *HTTPMiddleware) attempt(req *http.Request, next func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+	if m.attemptTimeout <= 0 {
+		return next(req)
+	}
+
+	ctx, cancel := context.WithTimeout(req.Context(), m.attemptTimeout)
+	res, err := next(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+
+		// Our own timeout is a failed attempt rather than the caller giving up
+		if errors.Is(err, context.DeadlineExceeded) && req.Context().Err() == nil {
+			return nil, &attemptTimeoutError{err: err}
+		}
+
+		return nil, err
+	}
+
+	// The body is still being read, so only cancel once it's closed
+	res.Body = &cancelOnClose{ReadCloser: res.Body, cancel: cancel}
+
+	return res, nil
+}
+
+func (m *HTTPMiddleware) record(success bool) {
+	if m.breaker == nil {
+		return
+	}
+
+	if success {
+		m.breaker.Success()
+	} else {
+		m.breaker.Failure()
+	}
+}
+
+func (m *HTTPMiddleware) release() {
+	if m.breaker != nil {
+		m.breaker.Release()
+	}
+}
+
+func status(res *http.Response) int {
+	if res == nil {
+		return 0
+	}
+
+	return res.StatusCode
+}
+
+type attemptTimeoutError struct {
+	err error
+}
+
+func (e *attemptTimeoutError) Error() string { return "attempt timed out: " + e.err.Error() }
+func (e *attemptTimeoutError) Unwrap() error { return e.err }
+func (e *attemptTimeoutError) Timeout() bool { return true }
+
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c *cancelOnClose) Close() error {
+	defer c.cancel()
+	return c.ReadCloser.Close()
+}
@@ -0,0 +1,247 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyDelay(t *testing.T) {
+	policy := RetryPolicy{MaxRetries: 5, BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+
+	t.Run("jittered exponential backoff", func(t *testing.T) {
+		for retry, ceiling := range []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond, time.Second, time.Second} {
+			for range 50 {
+				wait, ok := policy.Delay(retry, nil)
+				if !ok || wait <= 0 || wait > ceiling {
+					t.Fatalf("retry %d got wait %v but want within (0, %v]", retry, wait, ceiling)
+				}
+			}
+		}
+	})
+
+	t.Run("honours retry after", func(t *testing.T) {
+		res := &http.Response{Header: http.Header{"Retry-After": []string{"3"}}}
+		if wait, ok := policy.Delay(0, res); !ok || wait != 3*time.Second {
+			t.Errorf("got %v, %t but want 3s", wait, ok)
+		}
+	})
+
+	t.Run("long retry after isn't worth waiting", func(t *testing.T) {
+		res := &http.Response{Header: http.Header{"Retry-After": []string{"3600"}}}
+		if _, ok := policy.Delay(0, res); ok {
+			t.Error("expected an hour long retry after to not be worthwhile")
+		}
+	})
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 2, 19, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		header string
+		want   time.Duration
+		ok     bool
+	}{
+		{"", 0, false},
+		{"2", 2 * time.Second, true},
+		{"0.5", 500 * time.Millisecond, true},
+		{"Wed, 19 Feb 2025 12:00:10 GMT", 10 * time.Second, true},
+		{"Wed, 19 Feb 2025 11:00:00 GMT", 0, true},
+		{"-1", 0, false},
+		{"soon", 0, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := ParseRetryAfter(tt.header, now)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("ParseRetryAfter(%q) got %v, %t but want %v, %t", tt.header, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestBreaker(t *testing.T) {
+	now := time.Unix(0, 0)
+	breaker := NewBreaker(2, time.Minute)
+	breaker.now = func() time.Time { return now }
+
+	fail := func() {
+		if err := breaker.Allow(); err != nil {
+			t.Fatalf("got err %v but want the call allowed", err)
+		}
+		breaker.Failure()
+	}
+
+	fail()
+	if breaker.State() != StateClosed {
+		t.Fatalf("got state %v after one failure but want closed", breaker.State())
+	}
+
+	fail()
+	if err := breaker.Allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("got err %v but want open after two failures", err)
+	}
+
+	// A single probe is let through once cooled down
+	now = now.Add(time.Minute)
+	if breaker.State() != StateHalfOpen {
+		t.Fatalf("got state %v but want half-open", breaker.State())
+	}
+	if err := breaker.Allow(); err != nil {
+		t.Fatalf("got err %v but want the probe allowed", err)
+	}
+	if err := breaker.Allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("got err %v but want a single probe", err)
+	}
+
+	// A failed probe opens it straight back up
+	breaker.Failure()
+	if err := breaker.Allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("got err %v but want open after a failed probe", err)
+	}
+
+	now = now.Add(time.Minute)
+	if err := breaker.Allow(); err != nil {
+		t.Fatalf("got err %v but want the probe allowed", err)
+	}
+	breaker.Success()
+	if breaker.State() != StateClosed {
+		t.Errorf("got state %v but want closed after a successful probe", breaker.State())
+	}
+}
+
+func TestHTTPMiddleware(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	policy := RetryPolicy{MaxRetries: 2, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}
+
+	// Replies with each status in turn, repeating the last
+	server := func(statuses ...int) (*httptest.Server, *atomic.Int32) {
+		calls := &atomic.Int32{}
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			if string(body) != "hello" {
+				t.Errorf("got body %q on call %d", body, calls.Load())
+			}
+
+			i := min(int(calls.Add(1))-1, len(statuses)-1)
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(statuses[i])
+		}))
+		t.Cleanup(srv.Close)
+
+		return srv, calls
+	}
+
+	do := func(m *HTTPMiddleware, url string) (*http.Response, error) {
+		req, err := http.NewRequest(http.MethodPost, url, strings.NewReader("hello"))
+		if err != nil {
+			t.Fatalf("failed creating request: %v", err)
+		}
+
+		return m.Do(req, http.DefaultClient.Do)
+	}
+
+	t.Run("retries until success", func(t *testing.T) {
+		srv, calls := server(http.StatusServiceUnavailable, http.StatusTooManyRequests, http.StatusOK)
+
+		res, err := do(NewHTTPMiddleware(logger, policy, nil, 0), srv.URL)
+		if err != nil || res.StatusCode != http.StatusOK {
+			t.Fatalf("got %v, %v but want ok", res, err)
+		}
+		if calls.Load() != 3 {
+			t.Errorf("got %d calls but want 3", calls.Load())
+		}
+	})
+
+	t.Run("returns the last response once out of retries", func(t *testing.T) {
+		srv, calls := server(http.StatusBadGateway)
+
+		res, err := do(NewHTTPMiddleware(logger, policy, nil, 0), srv.URL)
+		if err != nil || res.StatusCode != http.StatusBadGateway {
+			t.Fatalf("got %v, %v but want bad gateway", res, err)
+		}
+		if calls.Load() != 3 {
+			t.Errorf("got %d calls but want 3", calls.Load())
+		}
+	})
+
+	t.Run("doesn't retry client errors", func(t *testing.T) {
+		srv, calls := server(http.StatusBadRequest)
+
+		res, err := do(NewHTTPMiddleware(logger, policy, nil, 0), srv.URL)
+		if err != nil || res.StatusCode != http.StatusBadRequest {
+			t.Fatalf("got %v, %v but want bad request", res, err)
+		}
+		if calls.Load() != 1 {
+			t.Errorf("got %d calls but want 1", calls.Load())
+		}
+	})
+
+	t.Run("retries attempts that time out", func(t *testing.T) {
+		calls := &atomic.Int32{}
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if calls.Add(1) == 1 {
+				time.Sleep(200 * time.Millisecond)
+				return
+			}
+			w.Write([]byte("done"))
+		}))
+		t.Cleanup(srv.Close)
+
+		res, err := do(NewHTTPMiddleware(logger, policy, nil, 50*time.Millisecond), srv.URL)
+		if err != nil {
+			t.Fatalf("got err %v", err)
+		}
+
+		// The body is readable after the attempt returned
+		body, err := io.ReadAll(res.Body)
+		res.Body.Close()
+		if err != nil || string(body) != "done" {
+			t.Errorf("got body %q, %v", body, err)
+		}
+		if calls.Load() != 2 {
+			t.Errorf("got %d calls but want 2", calls.Load())
+		}
+	})
+
+	t.Run("breaker opens after failed calls", func(t *testing.T) {
+		srv, calls := server(http.StatusServiceUnavailable)
+		m := NewHTTPMiddleware(logger, RetryPolicy{}, NewBreaker(2, time.Hour), 0)
+
+		for range 2 {
+			if _, err := do(m, srv.URL); err != nil {
+				t.Fatalf("got err %v", err)
+			}
+		}
+
+		if _, err := do(m, srv.URL); !errors.Is(err, ErrCircuitOpen) {
+			t.Errorf("got err %v but want the circuit open", err)
+		}
+		if calls.Load() != 2 {
+			t.Errorf("got %d calls but want 2", calls.Load())
+		}
+	})
+
+	t.Run("caller giving up stops retrying", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		t.Cleanup(srv.Close)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		m := NewHTTPMiddleware(logger, RetryPolicy{MaxRetries: 5, BaseDelay: time.Hour, MaxDelay: time.Hour}, nil, 0)
+		req, _ := http.NewRequestWithContext(ctx, http.MethodPost, srv.URL, strings.NewReader("hello"))
+		if _, err := m.Do(req, http.DefaultClient.Do); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("got err %v but want deadline exceeded", err)
+		}
+	})
+}
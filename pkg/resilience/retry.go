@@ -0,0 +1,63 @@
+package resilience
+
+import (
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Longest Retry-After that is honoured, anything longer isn't worth waiting on
+const maxRetryAfter = time.Minute
+
+// How failed calls are retried. Delays grow exponentially from BaseDelay up to
+// MaxDelay with full jitter, unless the server asks for a specific wait.
+type RetryPolicy struct {
+	MaxRetries int
+	BaseDelay  time.Duration
+	MaxDelay   time.Duration
+}
+
+// Delay before the given retry, starting at zero. The second value is false when
+// the server asked us to wait longer than is worthwhile.
+func (p RetryPolicy) Delay(retry int, res *http.Response) (time.Duration, bool) {
+	if res != nil {
+		if wait, ok := ParseRetryAfter(res.Header.Get("Retry-After"), time.Now()); ok {
+			return wait, wait <= maxRetryAfter
+		}
+	}
+
+	ceiling := p.MaxDelay
+	if retry < 32 {
+		ceiling = min(p.BaseDelay<<retry, p.MaxDelay)
+	}
+	if ceiling <= 0 {
+		return 0, true
+	}
+
+	return rand.N(ceiling) + 1, true
+}
+
+// Whether a response status is worth retrying
+func Retryable(status int) bool {
+	return status == http.StatusRequestTimeout ||
+		status == http.StatusTooManyRequests ||
+		status >= http.StatusInternalServerError
+}
+
+// Parses a Retry-After header, given either in seconds or as an http date
+func ParseRetryAfter(header string, now time.Time) (time.Duration, bool) {
+	if header == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.ParseFloat(header, 64); err == nil && seconds >= 0 {
+		return time.Duration(seconds * float64(time.Second)), true
+	}
+
+	if at, err := http.ParseTime(header); err == nil {
+		return max(at.Sub(now), 0), true
+	}
+
+	return 0, false
+}
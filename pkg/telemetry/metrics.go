@@ -0,0 +1,53 @@
+// Observability shared by every component
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const (
+	MetricsPath = "/metrics"
+
+	metricsShutdownTimeout = 5 * time.Second
+)
+
+// Serves the default registry on MetricsPath at addr until the context is done.
+// An empty address disables it. Failing to serve is only logged, as losing
+// metrics isn't worth taking the component down over.
+func ServeMetrics(ctx context.Context, logger *slog.Logger, addr string) {
+	if addr == "" {
+		logger.Info("Metrics disabled")
+		return
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(MetricsPath, promhttp.Handler())
+
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	go func() {
+		logger.Info(fmt.Sprintf("Serving metrics on %s%s", addr, MetricsPath))
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("failed to serve metrics", slog.Any("err", err))
+		}
+	}()
+
+	go func() {
+		<-ctx.Done()
+
+		shutdown, cancel := context.WithTimeout(context.Background(), metricsShutdownTimeout)
+		defer cancel()
+		server.Shutdown(shutdown)
+	}()
+}
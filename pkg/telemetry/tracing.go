@@ -0,0 +1,94 @@
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc/filters"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"google.golang.org/grpc/stats"
+)
+
+const tracingShutdownTimeout = 5 * time.Second
+
+type TracingOptions struct {
+	Enabled bool
+	// Name the component's spans are reported under, such as reaphur-central
+	ServiceName string
+	// Address of an OTLP gRPC collector
+	Endpoint string
+	// Sends to the collector without TLS
+	Insecure bool
+	// Fraction of new traces sampled, between 0 and 1. Traces started elsewhere
+	// follow the caller's decision.
+	SampleRatio float64
+}
+
+// Installs the global tracer provider and W3C trace context propagation. Spans
+// are batched off to the collector, and the returned func flushes whatever is
+// left on shutdown. When tracing is disabled nothing is recorded, though
+// incoming trace context is still passed along to anything downstream.
+func SetupTracing(ctx context.Context, logger *slog.Logger, opts TracingOptions) (func(), error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	if !opts.Enabled {
+		logger.Info("Tracing disabled")
+		return func() {}, nil
+	}
+
+	exporterOpts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(opts.Endpoint)}
+	if opts.Insecure {
+		exporterOpts = append(exporterOpts, otlptracegrpc.WithInsecure())
+	}
+	exporter, err := otlptracegrpc.New(ctx, exporterOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed creating otlp exporter: %w", err)
+	}
+
+	res, err := resource.New(ctx,
+		resource.WithTelemetrySDK(),
+		resource.WithFromEnv(),
+		resource.WithAttributes(semconv.ServiceName(opts.ServiceName)),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed creating tracing resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(opts.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+
+	logger.Info(fmt.Sprintf("Tracing to %s as %s, sampling %.2f", opts.Endpoint, opts.ServiceName, opts.SampleRatio))
+
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), tracingShutdownTimeout)
+		defer cancel()
+
+		if err := provider.Shutdown(ctx); err != nil {
+			logger.Error("failed to flush traces", slog.Any("err", err))
+		}
+	}, nil
+}
+
+// Server side grpc tracing, picking up the caller's trace context. Health
+// checks are polled constantly and left out.
+func GRPCServerHandler() stats.Handler {
+	return otelgrpc.NewServerHandler(otelgrpc.WithFilter(filters.Not(filters.HealthCheck())))
+}
+
+// Client side equivalent of GRPCServerHandler, sending the trace context along
+// with each rpc
+func GRPCClientHandler() stats.Handler {
+	return otelgrpc.NewClientHandler(otelgrpc.WithFilter(filters.Not(filters.HealthCheck())))
+}
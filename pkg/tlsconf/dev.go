@@ -0,0 +1,168 @@
+package tlsconf
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// How long generated development certificates last
+const devCertValidity = 365 * 24 * time.Hour
+
+// Hosts the development server certificate is valid for, covering compose and
+// running locally
+var DefaultDevHosts = []string{"central", "localhost", "127.0.0.1", "::1"}
+
+// Development certificates that GenerateDev writes into its directory, as
+// name.pem and name-key.pem
+const (
+	DevCAName      = "ca"
+	DevCentralName = "central"
+	DevGWName      = "gw"
+	DevDiscordName = "discord"
+)
+
+// Generates a local CA and leaf certificates for central, the gateway and the
+// bot, for compose and testing only. An existing CA in the directory is reused,
+// so regenerated leaves are trusted by anything already holding the CA.
+func GenerateDev(dir string, hosts []string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	ca, caKey, err := loadDevCA(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		ca, caKey, err = createDevCA(dir)
+	}
+	if err != nil {
+		return err
+	}
+
+	leaves := []struct {
+		name   string
+		usages []x509.ExtKeyUsage
+		hosts  []string
+	}{
+		{DevCentralName, []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}, hosts},
+		{DevGWName, []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}, nil},
+		{DevDiscordName, []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}, nil},
+	}
+
+	for _, leaf := range leaves {
+		template, err := newTemplate(leaf.name)
+		if err != nil {
+			return err
+		}
+		template.KeyUsage = x509.KeyUsageDigitalSignature
+		template.ExtKeyUsage = leaf.usages
+
+		for _, host := range leaf.hosts {
+			if ip := net.ParseIP(host); ip != nil {
+				template.IPAddresses = append(template.IPAddresses, ip)
+			} else {
+				template.DNSNames = append(template.DNSNames, host)
+			}
+		}
+
+		if _, _, err := writeCert(dir, leaf.name, template, ca, caKey); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func newTemplate(name string) (*x509.Certificate, error) {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, err
+	}
+
+	return &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: name, Organization: []string{"reaphur development"}},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(devCertValidity),
+	}, nil
+}
+
+func createDevCA(dir string) (*x509.Certificate, crypto.Signer, error) {
+	template, err := newTemplate("reaphur development ca")
+	if err != nil {
+		return nil, nil, err
+	}
+	template.IsCA = true
+	template.BasicConstraintsValid = true
+	template.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageCRLSign
+
+	// Self signed, so the template is its own parent until the key exists
+	return writeCert(dir, DevCAName, template, nil, nil)
+}
+
+func loadDevCA(dir string) (*x509.Certificate, crypto.Signer, error) {
+	pair, err := tls.LoadX509KeyPair(filepath.Join(dir, DevCAName+".pem"), filepath.Join(dir, DevCAName+"-key.pem"))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ca, err := x509.ParseCertificate(pair.Certificate[0])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	signer, ok := pair.PrivateKey.(crypto.Signer)
+	if !ok {
+		return nil, nil, fmt.Errorf("ca key can't sign")
+	}
+
+	return ca, signer, nil
+}
+
+// Creates a key for the template, signs it with the parent, or itself when
+// there is no parent, and writes both out as PEM
+func writeCert(dir string, name string, template *x509.Certificate, parent *x509.Certificate, parentKey crypto.Signer) (*x509.Certificate, crypto.Signer, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, key.Public(), parentKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed creating %s certificate: %w", name, err)
+	}
+
+	keyDer, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, name+".pem"), pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o644); err != nil {
+		return nil, nil, err
+	}
+	if err := os.WriteFile(filepath.Join(dir, name+"-key.pem"), pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDer}), 0o600); err != nil {
+		return nil, nil, err
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return cert, key, nil
+}
@@ -0,0 +1,164 @@
+package tlsconf
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// How often the files are checked for changes, at most. Checks happen lazily
+// on handshakes, so there's no goroutine to manage.
+const defaultReloadInterval = 5 * time.Second
+
+// Tracks the modification times of a set of files
+type fileWatch struct {
+	paths    []string
+	modTimes []time.Time
+}
+
+// Reports whether any of the files changed since last asked
+func (w *fileWatch) changed() bool {
+	changed := false
+	for i, path := range w.paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+
+		if !info.ModTime().Equal(w.modTimes[i]) {
+			w.modTimes[i] = info.ModTime()
+			changed = true
+		}
+	}
+
+	return changed
+}
+
+func newFileWatch(paths ...string) *fileWatch {
+	w := &fileWatch{paths: paths, modTimes: make([]time.Time, len(paths))}
+	w.changed()
+
+	return w
+}
+
+// Keeps a value loaded from files, reloading it when the files change. A failed
+// reload is logged and the previous value kept, so a half written renewal
+// doesn't take the service down.
+type reloading[T any] struct {
+	logger   *slog.Logger
+	load     func() (T, error)
+	watch    *fileWatch
+	interval time.Duration
+
+	mux     sync.Mutex
+	value   T
+	checked time.Time
+}
+
+func newReloading[T any](logger *slog.Logger, load func() (T, error), paths ...string) (*reloading[T], error) {
+	value, err := load()
+	if err != nil {
+		return nil, err
+	}
+
+	return &reloading[T]{
+		logger:   logger,
+		load:     load,
+		watch:    newFileWatch(paths...),
+		interval: defaultReloadInterval,
+		value:    value,
+		checked:  time.Now(),
+	}, nil
+}
+
+func (r *reloading[T]) get() T {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	if time.Since(r.checked) < r.interval {
+		return r.value
+	}
+	r.checked = time.Now()
+
+	if !r.watch.changed() {
+		return r.value
+	}
+
+	value, err := r.load()
+	if err != nil {
+		r.logger.Error("failed to reload tls files, keeping the previous ones", slog.Any("paths", r.watch.paths), slog.Any("err", err))
+		return r.value
+	}
+
+	r.logger.Info("reloaded tls files", slog.Any("paths", r.watch.paths))
+	r.value = value
+
+	return value
+}
+
+// Certificate and key pair that is reloaded when either file changes
+type CertReloader struct {
+	*reloading[*tls.Certificate]
+}
+
+func NewCertReloader(logger *slog.Logger, certFile string, keyFile string) (*CertReloader, error) {
+	r, err := newReloading(logger, func() (*tls.Certificate, error) {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed loading key pair %q and %q: %w", certFile, keyFile, err)
+		}
+
+		return &cert, nil
+	}, certFile, keyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CertReloader{r}, nil
+}
+
+func (c *CertReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return c.get(), nil
+}
+
+func (c *CertReloader) GetClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	return c.get(), nil
+}
+
+// CA bundle that is reloaded when the file changes
+type PoolReloader struct {
+	*reloading[*x509.CertPool]
+}
+
+func NewPoolReloader(logger *slog.Logger, caFile string) (*PoolReloader, error) {
+	r, err := newReloading(logger, func() (*x509.CertPool, error) {
+		return LoadCertPool(caFile)
+	}, caFile)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PoolReloader{r}, nil
+}
+
+func (p *PoolReloader) Pool() *x509.CertPool {
+	return p.get()
+}
+
+func LoadCertPool(caFile string) (*x509.CertPool, error) {
+	raw, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed reading ca file: %w", err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(raw) {
+		return nil, fmt.Errorf("no certificates found in ca file %q", caFile)
+	}
+
+	return pool, nil
+}
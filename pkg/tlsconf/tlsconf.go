@@ -0,0 +1,112 @@
+package tlsconf
+
+import (
+	"crypto/tls"
+	"errors"
+	"log/slog"
+
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// Server side TLS. Setting a client CA turns on mutual TLS, requiring every
+// client to present a certificate signed by it.
+type ServerOptions struct {
+	CertFile     string
+	KeyFile      string
+	ClientCAFile string
+}
+
+func (o ServerOptions) Enabled() bool {
+	return o.CertFile != "" || o.KeyFile != ""
+}
+
+// Creates the server's TLS config. The key pair and client CA are reloaded when
+// their files change, so renewed certificates are picked up without a restart.
+func NewServerConfig(logger *slog.Logger, opts ServerOptions) (*tls.Config, error) {
+	if opts.CertFile == "" || opts.KeyFile == "" {
+		return nil, errors.New("tls needs both a cert file and a key file")
+	}
+
+	cert, err := NewCertReloader(logger, opts.CertFile, opts.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	base := &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: cert.GetCertificate,
+	}
+
+	if opts.ClientCAFile == "" {
+		return base, nil
+	}
+
+	clientCAs, err := NewPoolReloader(logger, opts.ClientCAFile)
+	if err != nil {
+		return nil, err
+	}
+
+	// The client CA can only be swapped per connection through its own config
+	base.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		conf := base.Clone()
+		conf.GetConfigForClient = nil
+		conf.ClientAuth = tls.RequireAndVerifyClientCert
+		conf.ClientCAs = clientCAs.Pool()
+
+		return conf, nil
+	}
+
+	return base, nil
+}
+
+// Client side TLS. An empty CA uses the system roots, and a cert and key pair
+// is presented to servers wanting mutual TLS.
+type ClientOptions struct {
+	Enabled    bool
+	CAFile     string
+	CertFile   string
+	KeyFile    string
+	ServerName string
+}
+
+// Creates the client's TLS config. The client certificate is reloaded when its
+// files change, while the CA is only read once.
+func NewClientConfig(logger *slog.Logger, opts ClientOptions) (*tls.Config, error) {
+	conf := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: opts.ServerName,
+	}
+
+	if opts.CAFile != "" {
+		pool, err := LoadCertPool(opts.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		conf.RootCAs = pool
+	}
+
+	if opts.CertFile != "" || opts.KeyFile != "" {
+		cert, err := NewCertReloader(logger, opts.CertFile, opts.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		conf.GetClientCertificate = cert.GetClientCertificate
+	}
+
+	return conf, nil
+}
+
+// Transport credentials for dialing central, insecure unless TLS is enabled
+func DialCredentials(logger *slog.Logger, opts ClientOptions) (credentials.TransportCredentials, error) {
+	if !opts.Enabled {
+		return insecure.NewCredentials(), nil
+	}
+
+	conf, err := NewClientConfig(logger, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return credentials.NewTLS(conf), nil
+}
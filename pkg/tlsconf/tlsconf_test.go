@@ -0,0 +1,130 @@
+package tlsconf
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func devPath(dir string, name string) (string, string) {
+	return filepath.Join(dir, name+".pem"), filepath.Join(dir, name+"-key.pem")
+}
+
+func TestMutualTLS(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	dir := t.TempDir()
+	if err := GenerateDev(dir, DefaultDevHosts); err != nil {
+		t.Fatalf("failed generating certs: %v", err)
+	}
+
+	caFile, _ := devPath(dir, DevCAName)
+	centralCert, centralKey := devPath(dir, DevCentralName)
+	serverConf, err := NewServerConfig(logger, ServerOptions{CertFile: centralCert, KeyFile: centralKey, ClientCAFile: caFile})
+	if err != nil {
+		t.Fatalf("got err %v", err)
+	}
+
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer(grpc.Creds(credentials.NewTLS(serverConf)))
+	healthpb.RegisterHealthServer(server, health.NewServer())
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	check := func(opts ClientOptions) error {
+		creds, err := DialCredentials(logger, opts)
+		if err != nil {
+			t.Fatalf("got err %v", err)
+		}
+
+		conn, err := grpc.NewClient(
+			"passthrough:///bufnet",
+			grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+			grpc.WithTransportCredentials(creds),
+		)
+		if err != nil {
+			t.Fatalf("failed creating client: %v", err)
+		}
+		defer conn.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_, err = healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+		return err
+	}
+
+	gwCert, gwKey := devPath(dir, DevGWName)
+	if err := check(ClientOptions{Enabled: true, CAFile: caFile, CertFile: gwCert, KeyFile: gwKey, ServerName: "central"}); err != nil {
+		t.Errorf("got err %v but want clients with a certificate let in", err)
+	}
+
+	if err := check(ClientOptions{Enabled: true, CAFile: caFile, ServerName: "central"}); err == nil {
+		t.Errorf("got no err but want clients without a certificate turned away")
+	}
+
+	if err := check(ClientOptions{Enabled: true, CertFile: gwCert, KeyFile: gwKey, ServerName: "central"}); err == nil {
+		t.Errorf("got no err but want the dev ca untrusted by the system roots")
+	}
+}
+
+func TestCertReloader(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	dir := t.TempDir()
+	if err := GenerateDev(dir, DefaultDevHosts); err != nil {
+		t.Fatalf("failed generating certs: %v", err)
+	}
+
+	certFile, keyFile := devPath(dir, DevCentralName)
+	reloader, err := NewCertReloader(logger, certFile, keyFile)
+	if err != nil {
+		t.Fatalf("got err %v", err)
+	}
+	reloader.interval = 0
+
+	before, _ := reloader.GetCertificate(nil)
+
+	// A broken renewal keeps the previous certificate
+	if err := os.WriteFile(certFile, []byte("garbage"), 0o644); err != nil {
+		t.Fatalf("failed writing cert: %v", err)
+	}
+	bump(t, certFile, time.Minute)
+	if got, _ := reloader.GetCertificate(nil); got != before {
+		t.Errorf("got a new certificate from a broken file")
+	}
+
+	// Regenerating reuses the ca, so only the leaves change
+	caBefore, _ := os.ReadFile(filepath.Join(dir, DevCAName+".pem"))
+	if err := GenerateDev(dir, DefaultDevHosts); err != nil {
+		t.Fatalf("failed regenerating certs: %v", err)
+	}
+	caAfter, _ := os.ReadFile(filepath.Join(dir, DevCAName+".pem"))
+	if string(caBefore) != string(caAfter) {
+		t.Errorf("got a new ca but want the existing one reused")
+	}
+	bump(t, certFile, 2*time.Minute)
+	bump(t, keyFile, 2*time.Minute)
+
+	after, _ := reloader.GetCertificate(nil)
+	if after == before || string(after.Certificate[0]) == string(before.Certificate[0]) {
+		t.Errorf("got the same certificate but want the renewed one")
+	}
+}
+
+// Moves a file's modification time forward, as rewrites can land within the
+// file system's timestamp resolution
+func bump(t *testing.T, path string, by time.Duration) {
+	future := time.Now().Add(by)
+	if err := os.Chtimes(path, future, future); err != nil {
+		t.Fatalf("failed touching %s: %v", path, err)
+	}
+}
@@ -0,0 +1,211 @@
+package validate
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// The field must be populated. Repeated fields need at least one element.
+func Required() Rule {
+	return func(v Value) string {
+		if !v.Set {
+			return "is required"
+		}
+		return ""
+	}
+}
+
+// The repeated field must have at most max elements
+func MaxItems(max int) Rule {
+	return func(v Value) string {
+		if !v.Set {
+			return ""
+		}
+		if v.Value.List().Len() > max {
+			return fmt.Sprintf("must have at most %d items", max)
+		}
+		return ""
+	}
+}
+
+// The string must have something other than whitespace in it, whether or not
+// the field is populated
+func NotBlank() Rule {
+	return func(v Value) string {
+		if strings.TrimSpace(v.Value.String()) == "" {
+			return "must not be empty"
+		}
+		return ""
+	}
+}
+
+// The string must be a uuid
+func UUID() Rule {
+	return func(v Value) string {
+		if !v.Set {
+			return ""
+		}
+		if _, err := uuid.Parse(v.Value.String()); err != nil {
+			return "must be a uuid"
+		}
+		return ""
+	}
+}
+
+// The number must be at least min. NaN is never at least anything.
+func Min(min float64) Rule {
+	return func(v Value) string {
+		if !v.Set {
+			return ""
+		}
+
+		var n float64
+		switch v.Field.Kind() {
+		case protoreflect.FloatKind, protoreflect.DoubleKind:
+			n = v.Value.Float()
+		case protoreflect.Int32Kind, protoreflect.Int64Kind, protoreflect.Sint32Kind, protoreflect.Sint64Kind, protoreflect.Sfixed32Kind, protoreflect.Sfixed64Kind:
+			n = float64(v.Value.Int())
+		case protoreflect.Uint32Kind, protoreflect.Uint64Kind, protoreflect.Fixed32Kind, protoreflect.Fixed64Kind:
+			n = float64(v.Value.Uint())
+		default:
+			return "must be a number"
+		}
+
+		if !(n >= min) {
+			return fmt.Sprintf("must be at least %g", min)
+		}
+		return ""
+	}
+}
+
+// The string must be an IANA timezone, such as Australia/Melbourne
+func Timezone() Rule {
+	return func(v Value) string {
+		if !v.Set {
+			return ""
+		}
+		if _, err := time.LoadLocation(v.Value.String()); err != nil {
+			return "must be an IANA timezone"
+		}
+		return ""
+	}
+}
+
+// The string must be an absolute url using one of the schemes
+func URL(schemes ...string) Rule {
+	return func(v Value) string {
+		if !v.Set {
+			return ""
+		}
+		u, err := url.Parse(v.Value.String())
+		if err != nil || u.Host == "" || !slices.Contains(schemes, u.Scheme) {
+			return fmt.Sprintf("must be a %s url", strings.Join(schemes, " or "))
+		}
+		return ""
+	}
+}
+
+// The string must start with prefix
+func HasPrefix(prefix string) Rule {
+	return func(v Value) string {
+		if !v.Set {
+			return ""
+		}
+		if !strings.HasPrefix(v.Value.String(), prefix) {
+			return fmt.Sprintf("must start with %q", prefix)
+		}
+		return ""
+	}
+}
+
+type notBefore struct {
+	field, other         string
+	fieldPath, otherPath []protoreflect.FieldDescriptor
+}
+
+// The timestamp at field must not be before the one at other, when both are
+// set. Used for ranges, such as a before_time that is earlier than the
+// after_time, which could never match anything.
+func NotBefore(field string, other string) Constraint {
+	return &notBefore{field: field, other: other}
+}
+
+func (c *notBefore) resolve(md protoreflect.MessageDescriptor) (err error) {
+	if c.fieldPath, err = resolvePath(md, c.field); err != nil {
+		return err
+	}
+	if c.otherPath, err = resolvePath(md, c.other); err != nil {
+		return err
+	}
+
+	for _, path := range [][]protoreflect.FieldDescriptor{c.fieldPath, c.otherPath} {
+		last := path[len(path)-1]
+		if last.Message() == nil || last.Message().FullName() != (&timestamppb.Timestamp{}).ProtoReflect().Descriptor().FullName() {
+			return fmt.Errorf("%q isn't a timestamp", last.FullName())
+		}
+	}
+
+	return nil
+}
+
+func (c *notBefore) apply(m protoreflect.Message, report func(string, string)) {
+	field, fieldOk := lookup(m, c.fieldPath)
+	other, otherOk := lookup(m, c.otherPath)
+	if !fieldOk || !otherOk || !field.Set || !other.Set {
+		return
+	}
+
+	if asTime(field.Value.Message()).Before(asTime(other.Value.Message())) {
+		report(c.field, "must not be before "+c.other)
+	}
+}
+
+func asTime(m protoreflect.Message) time.Time {
+	fields := m.Descriptor().Fields()
+	return time.Unix(m.Get(fields.ByName("seconds")).Int(), m.Get(fields.ByName("nanos")).Int())
+}
+
+type requireAny struct {
+	paths  []string
+	fields [][]protoreflect.FieldDescriptor
+}
+
+// At least one of the fields must be populated. The violation is reported
+// against the first.
+func RequireAny(paths ...string) Constraint {
+	return &requireAny{paths: paths}
+}
+
+func (c *requireAny) resolve(md protoreflect.MessageDescriptor) error {
+	if len(c.paths) < 2 {
+		return fmt.Errorf("require any needs at least two fields")
+	}
+
+	c.fields = make([][]protoreflect.FieldDescriptor, 0, len(c.paths))
+	for _, path := range c.paths {
+		fields, err := resolvePath(md, path)
+		if err != nil {
+			return err
+		}
+		c.fields = append(c.fields, fields)
+	}
+
+	return nil
+}
+
+func (c *requireAny) apply(m protoreflect.Message, report func(string, string)) {
+	for _, fields := range c.fields {
+		if value, ok := lookup(m, fields); ok && value.Set {
+			return
+		}
+	}
+
+	report(c.paths[0], "is required without "+strings.Join(c.paths[1:], " or "))
+}
@@ -0,0 +1,193 @@
+// Declarative validation of proto messages. Rules are declared per message type
+// against field paths, and checked recursively through every populated message
+// field, so rules declared on a nested type apply wherever it's used.
+package validate
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/calamity-m/reaphur/pkg/errs"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// A field's value as seen by a rule
+type Value struct {
+	Field protoreflect.FieldDescriptor
+	Value protoreflect.Value
+	// Whether the field is populated. Rules other than Required and NotBlank pass
+	// unpopulated fields, leaving it to Required to say whether they're needed.
+	Set bool
+}
+
+// Checks a single field, returning a description of what's wrong with it or ""
+// when it's fine
+type Rule func(v Value) string
+
+// A constraint on a message, reporting violations by field path
+type Constraint interface {
+	resolve(md protoreflect.MessageDescriptor) error
+	apply(m protoreflect.Message, report func(field string, description string))
+}
+
+// Rules declared for a single message type
+type MessageRules struct {
+	name        protoreflect.FullName
+	constraints []Constraint
+}
+
+// Declares the constraints for msg's type. Field paths are checked against the
+// message's descriptor, panicking on any that don't exist as the rules are
+// fixed at compile time.
+func For(msg proto.Message, constraints ...Constraint) MessageRules {
+	md := msg.ProtoReflect().Descriptor()
+	for _, c := range constraints {
+		if err := c.resolve(md); err != nil {
+			panic(fmt.Sprintf("validate: bad rule for %s: %v", md.FullName(), err))
+		}
+	}
+
+	return MessageRules{name: md.FullName(), constraints: constraints}
+}
+
+type Validator struct {
+	rules map[protoreflect.FullName][]Constraint
+}
+
+func New(rules ...MessageRules) *Validator {
+	v := &Validator{rules: make(map[protoreflect.FullName][]Constraint, len(rules))}
+	for _, r := range rules {
+		v.rules[r.name] = append(v.rules[r.name], r.constraints...)
+	}
+
+	return v
+}
+
+// Checks msg against every rule, returning an InvalidArgument errs.Error with a
+// violation for each bad field, or nil if there are none
+func (v *Validator) Validate(msg proto.Message) error {
+	if msg == nil {
+		return nil
+	}
+
+	violations := v.Violations(msg)
+	if len(violations) == 0 {
+		return nil
+	}
+
+	described := make([]string, 0, len(violations))
+	for _, f := range violations {
+		described = append(described, f.Field+" "+f.Description)
+	}
+
+	e := errs.Wrap(errs.ErrBadRequest, codes.InvalidArgument, strings.Join(described, ", "))
+	e.Fields = violations
+
+	return e
+}
+
+// Returns every violation in msg, with fields named by their path from msg
+func (v *Validator) Violations(msg proto.Message) []errs.FieldViolation {
+	violations := []errs.FieldViolation{}
+	v.check(msg.ProtoReflect(), "", &violations)
+
+	return violations
+}
+
+func (v *Validator) check(m protoreflect.Message, prefix string, violations *[]errs.FieldViolation) {
+	for _, c := range v.rules[m.Descriptor().FullName()] {
+		c.apply(m, func(field string, description string) {
+			*violations = append(*violations, errs.FieldViolation{Field: prefix + field, Description: description})
+		})
+	}
+
+	m.Range(func(fd protoreflect.FieldDescriptor, value protoreflect.Value) bool {
+		if fd.Message() == nil || fd.IsMap() {
+			return true
+		}
+
+		if fd.IsList() {
+			list := value.List()
+			for i := range list.Len() {
+				v.check(list.Get(i).Message(), fmt.Sprintf("%s%s[%d].", prefix, fd.Name(), i), violations)
+			}
+			return true
+		}
+
+		v.check(value.Message(), prefix+string(fd.Name())+".", violations)
+		return true
+	})
+}
+
+// Resolves a dotted path through singular message fields
+func resolvePath(md protoreflect.MessageDescriptor, path string) ([]protoreflect.FieldDescriptor, error) {
+	names := strings.Split(path, ".")
+	fields := make([]protoreflect.FieldDescriptor, 0, len(names))
+
+	for i, name := range names {
+		if md == nil {
+			return nil, fmt.Errorf("%q isn't a message", strings.Join(names[:i], "."))
+		}
+
+		fd := md.Fields().ByName(protoreflect.Name(name))
+		if fd == nil {
+			return nil, fmt.Errorf("no field %q in %s", name, md.FullName())
+		}
+		if i < len(names)-1 && fd.IsList() {
+			return nil, fmt.Errorf("%q is repeated, declare rules on its message type instead", name)
+		}
+
+		fields = append(fields, fd)
+		md = fd.Message()
+	}
+
+	return fields, nil
+}
+
+// Looks up the value at the end of a resolved path, returning false when a
+// message along the way is unset and there's no field to look at
+func lookup(m protoreflect.Message, fields []protoreflect.FieldDescriptor) (Value, bool) {
+	for _, fd := range fields[:len(fields)-1] {
+		if !m.Has(fd) {
+			return Value{}, false
+		}
+		m = m.Get(fd).Message()
+	}
+
+	last := fields[len(fields)-1]
+	return Value{Field: last, Value: m.Get(last), Set: m.Has(last)}, true
+}
+
+type fieldConstraint struct {
+	path   string
+	fields []protoreflect.FieldDescriptor
+	rules  []Rule
+}
+
+// Checks the field at path against each rule in turn. Only the first broken
+// rule is reported, so a missing field isn't also called a bad uuid. Fields
+// under an unset message aren't checked, leave that to a rule on the message.
+func Field(path string, rules ...Rule) Constraint {
+	return &fieldConstraint{path: path, rules: rules}
+}
+
+func (c *fieldConstraint) resolve(md protoreflect.MessageDescriptor) (err error) {
+	c.fields, err = resolvePath(md, c.path)
+	return err
+}
+
+func (c *fieldConstraint) apply(m protoreflect.Message, report func(string, string)) {
+	value, ok := lookup(m, c.fields)
+	if !ok {
+		return
+	}
+
+	for _, rule := range c.rules {
+		if description := rule(value); description != "" {
+			report(c.path, description)
+			return
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package validate
+
+import (
+	"errors"
+	"math"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/calamity-m/reaphur/pkg/errs"
+	centralproto "github.com/calamity-m/reaphur/proto/v1/central"
+	"github.com/calamity-m/reaphur/proto/v1/domain"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+const testUUID = "0195a7d4-32c4-7b5e-9d1c-6f0e2a3b4c5d"
+
+func TestRules(t *testing.T) {
+	earlier := timestamppb.New(time.Unix(100, 0))
+	later := timestamppb.New(time.Unix(200, 0))
+
+	tests := []struct {
+		name  string
+		rules MessageRules
+		msg   proto.Message
+		// Violated field and description, empty when the message is valid
+		field string
+		want  string
+	}{
+		{"required missing", For(&centralproto.DeleteFoodRecordRequest{}, Field("id", Required())), &centralproto.DeleteFoodRecordRequest{}, "id", "is required"},
+		{"required set", For(&centralproto.DeleteFoodRecordRequest{}, Field("id", Required())), &centralproto.DeleteFoodRecordRequest{Id: "x"}, "", ""},
+		{"required message missing", For(&centralproto.CreateFoodRecordRequest{}, Field("record", Required())), &centralproto.CreateFoodRecordRequest{}, "record", "is required"},
+		{"required message set", For(&centralproto.CreateFoodRecordRequest{}, Field("record", Required())), &centralproto.CreateFoodRecordRequest{Record: &domain.FoodRecord{}}, "", ""},
+		{"required list empty", For(&centralproto.CallFnUserInputRequest{}, Field("images", Required())), &centralproto.CallFnUserInputRequest{}, "images", "is required"},
+		{"required list set", For(&centralproto.CallFnUserInputRequest{}, Field("images", Required())), &centralproto.CallFnUserInputRequest{Images: []*centralproto.ImageAttachment{{}}}, "", ""},
+		{"required skipped under unset parent", For(&centralproto.CreateFoodRecordRequest{}, Field("record.user_id", Required())), &centralproto.CreateFoodRecordRequest{}, "", ""},
+
+		{"not blank unset", For(&domain.FoodRecord{}, Field("description", NotBlank())), &domain.FoodRecord{}, "description", "must not be empty"},
+		{"not blank whitespace", For(&domain.FoodRecord{}, Field("description", NotBlank())), &domain.FoodRecord{Description: " \t"}, "description", "must not be empty"},
+		{"not blank set", For(&domain.FoodRecord{}, Field("description", NotBlank())), &domain.FoodRecord{Description: "toast"}, "", ""},
+
+		{"uuid bad", For(&domain.FoodRecord{}, Field("user_id", UUID())), &domain.FoodRecord{UserId: "bob"}, "user_id", "must be a uuid"},
+		{"uuid good", For(&domain.FoodRecord{}, Field("user_id", UUID())), &domain.FoodRecord{UserId: testUUID}, "", ""},
+		{"uuid unset", For(&domain.FoodRecord{}, Field("user_id", UUID())), &domain.FoodRecord{}, "", ""},
+
+		{"min float below", For(&domain.FoodRecord{}, Field("kj", Min(0))), &domain.FoodRecord{Kj: -1}, "kj", "must be at least 0"},
+		{"min float nan", For(&domain.FoodRecord{}, Field("kj", Min(0))), &domain.FoodRecord{Kj: float32(math.NaN())}, "kj", "must be at least 0"},
+		{"min float at", For(&domain.FoodRecord{}, Field("kj", Min(0))), &domain.FoodRecord{Kj: 0}, "", ""},
+		{"min float above", For(&domain.FoodRecord{}, Field("kj", Min(0))), &domain.FoodRecord{Kj: 420.5}, "", ""},
+		{"min int below", For(&timestamppb.Timestamp{}, Field("seconds", Min(1))), &timestamppb.Timestamp{Seconds: -5}, "seconds", "must be at least 1"},
+		{"min int above", For(&timestamppb.Timestamp{}, Field("seconds", Min(1))), &timestamppb.Timestamp{Seconds: 5}, "", ""},
+		{"min uint below", For(&wrapperspb.UInt32Value{}, Field("value", Min(2))), &wrapperspb.UInt32Value{Value: 1}, "value", "must be at least 2"},
+		{"min uint above", For(&wrapperspb.UInt32Value{}, Field("value", Min(2))), &wrapperspb.UInt32Value{Value: 3}, "", ""},
+		{"min not a number", For(&domain.FoodRecord{}, Field("name", Min(0))), &domain.FoodRecord{Name: "toast"}, "name", "must be a number"},
+
+		{"timezone bad", For(&centralproto.CallFnUserInputRequest{}, Field("request_user_timezone", Timezone())), &centralproto.CallFnUserInputRequest{RequestUserTimezone: "Mars/Olympus_Mons"}, "request_user_timezone", "must be an IANA timezone"},
+		{"timezone good", For(&centralproto.CallFnUserInputRequest{}, Field("request_user_timezone", Timezone())), &centralproto.CallFnUserInputRequest{RequestUserTimezone: "Australia/Melbourne"}, "", ""},
+
+		{"url bad scheme", For(&centralproto.ImageAttachment{}, Field("url", URL("http", "https"))), &centralproto.ImageAttachment{Source: &centralproto.ImageAttachment_Url{Url: "ftp://example.com/a.png"}}, "url", "must be a http or https url"},
+		{"url relative", For(&centralproto.ImageAttachment{}, Field("url", URL("http", "https"))), &centralproto.ImageAttachment{Source: &centralproto.ImageAttachment_Url{Url: "/a.png"}}, "url", "must be a http or https url"},
+		{"url good", For(&centralproto.ImageAttachment{}, Field("url", URL("http", "https"))), &centralproto.ImageAttachment{Source: &centralproto.ImageAttachment_Url{Url: "https://example.com/a.png"}}, "", ""},
+		{"url other oneof", For(&centralproto.ImageAttachment{}, Field("url", URL("http", "https"))), &centralproto.ImageAttachment{Source: &centralproto.ImageAttachment_Data{Data: []byte{1}}}, "", ""},
+
+		{"prefix bad", For(&centralproto.ImageAttachment{}, Field("mime_type", HasPrefix("image/"))), &centralproto.ImageAttachment{MimeType: "text/plain"}, "mime_type", `must start with "image/"`},
+		{"prefix good", For(&centralproto.ImageAttachment{}, Field("mime_type", HasPrefix("image/"))), &centralproto.ImageAttachment{MimeType: "image/png"}, "", ""},
+
+		{"max items over", For(&centralproto.CallFnUserInputRequest{}, Field("images", MaxItems(1))), &centralproto.CallFnUserInputRequest{Images: []*centralproto.ImageAttachment{{}, {}}}, "images", "must have at most 1 items"},
+		{"max items at", For(&centralproto.CallFnUserInputRequest{}, Field("images", MaxItems(1))), &centralproto.CallFnUserInputRequest{Images: []*centralproto.ImageAttachment{{}}}, "", ""},
+
+		{"not before earlier", For(&centralproto.GetFoodRecordsRequest{}, NotBefore("filter.before_time", "filter.after_time")), &centralproto.GetFoodRecordsRequest{Filter: &centralproto.GetFoodFilter{BeforeTime: earlier, AfterTime: later}}, "filter.before_time", "must not be before filter.after_time"},
+		{"not before later", For(&centralproto.GetFoodRecordsRequest{}, NotBefore("filter.before_time", "filter.after_time")), &centralproto.GetFoodRecordsRequest{Filter: &centralproto.GetFoodFilter{BeforeTime: later, AfterTime: earlier}}, "", ""},
+		{"not before equal", For(&centralproto.GetFoodRecordsRequest{}, NotBefore("filter.before_time", "filter.after_time")), &centralproto.GetFoodRecordsRequest{Filter: &centralproto.GetFoodFilter{BeforeTime: earlier, AfterTime: earlier}}, "", ""},
+		{"not before one unset", For(&centralproto.GetFoodRecordsRequest{}, NotBefore("filter.before_time", "filter.after_time")), &centralproto.GetFoodRecordsRequest{Filter: &centralproto.GetFoodFilter{BeforeTime: earlier}}, "", ""},
+
+		{"require any none", For(&centralproto.CallFnUserInputRequest{}, RequireAny("request_user_input", "images")), &centralproto.CallFnUserInputRequest{}, "request_user_input", "is required without images"},
+		{"require any first", For(&centralproto.CallFnUserInputRequest{}, RequireAny("request_user_input", "images")), &centralproto.CallFnUserInputRequest{RequestUserInput: "toast"}, "", ""},
+		{"require any second", For(&centralproto.CallFnUserInputRequest{}, RequireAny("request_user_input", "images")), &centralproto.CallFnUserInputRequest{Images: []*centralproto.ImageAttachment{{}}}, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := New(tt.rules).Violations(tt.msg)
+
+			if tt.field == "" {
+				if len(got) != 0 {
+					t.Errorf("got violations %v but want none", got)
+				}
+				return
+			}
+
+			want := []errs.FieldViolation{{Field: tt.field, Description: tt.want}}
+			if !slices.Equal(got, want) {
+				t.Errorf("got violations %v but want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestValidatorReportsFirstBrokenRule(t *testing.T) {
+	v := New(For(&centralproto.DeleteFoodRecordRequest{}, Field("id", Required(), UUID())))
+
+	got := v.Violations(&centralproto.DeleteFoodRecordRequest{})
+	want := []errs.FieldViolation{{Field: "id", Description: "is required"}}
+	if !slices.Equal(got, want) {
+		t.Errorf("got violations %v but want %v", got, want)
+	}
+}
+
+func TestValidatorChecksNestedMessages(t *testing.T) {
+	v := New(
+		For(&domain.FoodRecord{}, Field("kj", Min(0))),
+		For(&centralproto.ImageAttachment{}, Field("mime_type", HasPrefix("image/"))),
+	)
+
+	got := v.Violations(&centralproto.UpdateFoodRecordRequest{Record: &domain.FoodRecord{Kj: -1}})
+	want := []errs.FieldViolation{{Field: "record.kj", Description: "must be at least 0"}}
+	if !slices.Equal(got, want) {
+		t.Errorf("got violations %v but want %v", got, want)
+	}
+
+	got = v.Violations(&centralproto.CallFnUserInputRequest{Images: []*centralproto.ImageAttachment{
+		{MimeType: "image/png"},
+		{MimeType: "text/plain"},
+	}})
+	want = []errs.FieldViolation{{Field: "images[1].mime_type", Description: `must start with "image/"`}}
+	if !slices.Equal(got, want) {
+		t.Errorf("got violations %v but want %v", got, want)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	v := New(For(&centralproto.DeleteFoodRecordRequest{},
+		Field("request_user_id", Required(), UUID()),
+		Field("id", Required(), UUID()),
+	))
+
+	if err := v.Validate(&centralproto.DeleteFoodRecordRequest{RequestUserId: testUUID, Id: testUUID}); err != nil {
+		t.Errorf("got err %v for a valid request", err)
+	}
+
+	err := v.Validate(&centralproto.DeleteFoodRecordRequest{RequestUserId: "bob"})
+	if !errors.Is(err, errs.ErrBadRequest) {
+		t.Fatalf("got err %v but want bad request", err)
+	}
+
+	st, _ := status.FromError(err)
+	if st.Code() != codes.InvalidArgument {
+		t.Errorf("got code %v but want %v", st.Code(), codes.InvalidArgument)
+	}
+	if st.Message() != "request_user_id must be a uuid, id is required" {
+		t.Errorf("got message %q", st.Message())
+	}
+	if len(st.Details()) != 1 {
+		t.Errorf("got %d details but want the field violations", len(st.Details()))
+	}
+}
+
+func TestForPanicsOnBadPaths(t *testing.T) {
+	tests := []struct {
+		name       string
+		constraint Constraint
+	}{
+		{"unknown field", Field("nope", Required())},
+		{"unknown nested field", Field("record.nope", Required())},
+		{"through a scalar", Field("request_user_id.nope", Required())},
+		{"not a timestamp", NotBefore("request_user_id", "record.time")},
+		{"require any of one", RequireAny("record")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("expected a panic")
+				}
+			}()
+
+			For(&centralproto.UpdateFoodRecordRequest{}, tt.constraint)
+		})
+	}
+}
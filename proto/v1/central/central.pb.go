@@ -186,17 +186,120 @@ func (x *ActionUserInputResponse) GetData() []*GenericData {
 	return nil
 }
 
+// Image sent along with user input, such as a photo of a meal
+type ImageAttachment struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Source:
+	//
+	//	*ImageAttachment_Data
+	//	*ImageAttachment_Url
+	Source isImageAttachment_Source `protobuf_oneof:"source"`
+	// MIME type of the image, i.e. "image/jpeg"
+	MimeType      string `protobuf:"bytes,3,opt,name=mime_type,json=mimeType,proto3" json:"mime_type,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ImageAttachment) Reset() {
+	*x = ImageAttachment{}
+	mi := &file_proto_v1_central_central_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImageAttachment) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImageAttachment) ProtoMessage() {}
+
+func (x *ImageAttachment) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_central_central_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImageAttachment.ProtoReflect.Descriptor instead.
+func (*ImageAttachment) Descriptor() ([]byte, []int) {
+	return file_proto_v1_central_central_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ImageAttachment) GetSource() isImageAttachment_Source {
+	if x != nil {
+		return x.Source
+	}
+	return nil
+}
+
+func (x *ImageAttachment) GetData() []byte {
+	if x != nil {
+		if x, ok := x.Source.(*ImageAttachment_Data); ok {
+			return x.Data
+		}
+	}
+	return nil
+}
+
+func (x *ImageAttachment) GetUrl() string {
+	if x != nil {
+		if x, ok := x.Source.(*ImageAttachment_Url); ok {
+			return x.Url
+		}
+	}
+	return ""
+}
+
+func (x *ImageAttachment) GetMimeType() string {
+	if x != nil {
+		return x.MimeType
+	}
+	return ""
+}
+
+type isImageAttachment_Source interface {
+	isImageAttachment_Source()
+}
+
+type ImageAttachment_Data struct {
+	// Raw image bytes
+	Data []byte `protobuf:"bytes,1,opt,name=data,proto3,oneof"`
+}
+
+type ImageAttachment_Url struct {
+	// Publicly reachable http(s) url of the image
+	Url string `protobuf:"bytes,2,opt,name=url,proto3,oneof"`
+}
+
+func (*ImageAttachment_Data) isImageAttachment_Source() {}
+
+func (*ImageAttachment_Url) isImageAttachment_Source() {}
+
 type CallFnUserInputRequest struct {
 	state            protoimpl.MessageState `protogen:"open.v1"`
 	RequestUserId    string                 `protobuf:"bytes,1,opt,name=request_user_id,json=requestUserId,proto3" json:"request_user_id,omitempty"`
 	RequestUserInput string                 `protobuf:"bytes,2,opt,name=request_user_input,json=requestUserInput,proto3" json:"request_user_input,omitempty"`
-	unknownFields    protoimpl.UnknownFields
-	sizeCache        protoimpl.SizeCache
+	// IANA timezone of the user, i.e. "Australia/Sydney", used to resolve times
+	// such as "yesterday". Defaults to the timezone central is configured with.
+	RequestUserTimezone string `protobuf:"bytes,3,opt,name=request_user_timezone,json=requestUserTimezone,proto3" json:"request_user_timezone,omitempty"`
+	// Images the user sent with their input. Requests with images are handled
+	// by the configured vision model.
+	Images []*ImageAttachment `protobuf:"bytes,4,rep,name=images,proto3" json:"images,omitempty"`
+	// What the user has shared about themselves, such as their goals or diet.
+	// Rendered into the prompt as untrusted data.
+	RequestUserProfile string `protobuf:"bytes,5,opt,name=request_user_profile,json=requestUserProfile,proto3" json:"request_user_profile,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
 }
 
 func (x *CallFnUserInputRequest) Reset() {
 	*x = CallFnUserInputRequest{}
-	mi := &file_proto_v1_central_central_proto_msgTypes[3]
+	mi := &file_proto_v1_central_central_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -208,7 +311,7 @@ func (x *CallFnUserInputRequest) String() string {
 func (*CallFnUserInputRequest) ProtoMessage() {}
 
 func (x *CallFnUserInputRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_v1_central_central_proto_msgTypes[3]
+	mi := &file_proto_v1_central_central_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -221,7 +324,7 @@ func (x *CallFnUserInputRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CallFnUserInputRequest.ProtoReflect.Descriptor instead.
 func (*CallFnUserInputRequest) Descriptor() ([]byte, []int) {
-	return file_proto_v1_central_central_proto_rawDescGZIP(), []int{3}
+	return file_proto_v1_central_central_proto_rawDescGZIP(), []int{4}
 }
 
 func (x *CallFnUserInputRequest) GetRequestUserId() string {
@@ -238,20 +341,102 @@ func (x *CallFnUserInputRequest) GetRequestUserInput() string {
 	return ""
 }
 
+func (x *CallFnUserInputRequest) GetRequestUserTimezone() string {
+	if x != nil {
+		return x.RequestUserTimezone
+	}
+	return ""
+}
+
+func (x *CallFnUserInputRequest) GetImages() []*ImageAttachment {
+	if x != nil {
+		return x.Images
+	}
+	return nil
+}
+
+func (x *CallFnUserInputRequest) GetRequestUserProfile() string {
+	if x != nil {
+		return x.RequestUserProfile
+	}
+	return ""
+}
+
+// An action the model wants to take that must first be confirmed by the user,
+// such as changing or deleting existing records.
+type PendingAction struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Unique identifier used to confirm or cancel the action
+	ActionId string `protobuf:"bytes,1,opt,name=action_id,json=actionId,proto3" json:"action_id,omitempty"`
+	// Human readable summary of what the action will do
+	Summary       string `protobuf:"bytes,2,opt,name=summary,proto3" json:"summary,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PendingAction) Reset() {
+	*x = PendingAction{}
+	mi := &file_proto_v1_central_central_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PendingAction) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PendingAction) ProtoMessage() {}
+
+func (x *PendingAction) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_central_central_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PendingAction.ProtoReflect.Descriptor instead.
+func (*PendingAction) Descriptor() ([]byte, []int) {
+	return file_proto_v1_central_central_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *PendingAction) GetActionId() string {
+	if x != nil {
+		return x.ActionId
+	}
+	return ""
+}
+
+func (x *PendingAction) GetSummary() string {
+	if x != nil {
+		return x.Summary
+	}
+	return ""
+}
+
 type CallFnUserInputResponse struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
 	// Message that can be displayed back to the user
 	// with no further processing over formatting
 	ResponseMessage string `protobuf:"bytes,1,opt,name=response_message,json=responseMessage,proto3" json:"response_message,omitempty"`
 	// Potential response data
-	Data          []*GenericData `protobuf:"bytes,2,rep,name=data,proto3" json:"data,omitempty"`
+	Data []*GenericData `protobuf:"bytes,2,rep,name=data,proto3" json:"data,omitempty"`
+	// Actions awaiting confirmation. Nothing has been done for these yet.
+	PendingActions []*PendingAction `protobuf:"bytes,3,rep,name=pending_actions,json=pendingActions,proto3" json:"pending_actions,omitempty"`
+	// Version of the prompt template that served the request, i.e. "central.v1".
+	// Empty when the request never reached the model.
+	PromptVersion string `protobuf:"bytes,4,opt,name=prompt_version,json=promptVersion,proto3" json:"prompt_version,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *CallFnUserInputResponse) Reset() {
 	*x = CallFnUserInputResponse{}
-	mi := &file_proto_v1_central_central_proto_msgTypes[4]
+	mi := &file_proto_v1_central_central_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -263,7 +448,7 @@ func (x *CallFnUserInputResponse) String() string {
 func (*CallFnUserInputResponse) ProtoMessage() {}
 
 func (x *CallFnUserInputResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_v1_central_central_proto_msgTypes[4]
+	mi := &file_proto_v1_central_central_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -276,7 +461,7 @@ func (x *CallFnUserInputResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CallFnUserInputResponse.ProtoReflect.Descriptor instead.
 func (*CallFnUserInputResponse) Descriptor() ([]byte, []int) {
-	return file_proto_v1_central_central_proto_rawDescGZIP(), []int{4}
+	return file_proto_v1_central_central_proto_rawDescGZIP(), []int{6}
 }
 
 func (x *CallFnUserInputResponse) GetResponseMessage() string {
@@ -293,6 +478,341 @@ func (x *CallFnUserInputResponse) GetData() []*GenericData {
 	return nil
 }
 
+func (x *CallFnUserInputResponse) GetPendingActions() []*PendingAction {
+	if x != nil {
+		return x.PendingActions
+	}
+	return nil
+}
+
+func (x *CallFnUserInputResponse) GetPromptVersion() string {
+	if x != nil {
+		return x.PromptVersion
+	}
+	return ""
+}
+
+// Progress of a CallFnUserInputStream request
+type CallFnUserInputStreamEvent struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Event:
+	//
+	//	*CallFnUserInputStreamEvent_ToolStarted_
+	//	*CallFnUserInputStreamEvent_ToolFinished_
+	//	*CallFnUserInputStreamEvent_TextDelta_
+	//	*CallFnUserInputStreamEvent_Done
+	Event         isCallFnUserInputStreamEvent_Event `protobuf_oneof:"event"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CallFnUserInputStreamEvent) Reset() {
+	*x = CallFnUserInputStreamEvent{}
+	mi := &file_proto_v1_central_central_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CallFnUserInputStreamEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CallFnUserInputStreamEvent) ProtoMessage() {}
+
+func (x *CallFnUserInputStreamEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_central_central_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CallFnUserInputStreamEvent.ProtoReflect.Descriptor instead.
+func (*CallFnUserInputStreamEvent) Descriptor() ([]byte, []int) {
+	return file_proto_v1_central_central_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *CallFnUserInputStreamEvent) GetEvent() isCallFnUserInputStreamEvent_Event {
+	if x != nil {
+		return x.Event
+	}
+	return nil
+}
+
+func (x *CallFnUserInputStreamEvent) GetToolStarted() *CallFnUserInputStreamEvent_ToolStarted {
+	if x != nil {
+		if x, ok := x.Event.(*CallFnUserInputStreamEvent_ToolStarted_); ok {
+			return x.ToolStarted
+		}
+	}
+	return nil
+}
+
+func (x *CallFnUserInputStreamEvent) GetToolFinished() *CallFnUserInputStreamEvent_ToolFinished {
+	if x != nil {
+		if x, ok := x.Event.(*CallFnUserInputStreamEvent_ToolFinished_); ok {
+			return x.ToolFinished
+		}
+	}
+	return nil
+}
+
+func (x *CallFnUserInputStreamEvent) GetTextDelta() *CallFnUserInputStreamEvent_TextDelta {
+	if x != nil {
+		if x, ok := x.Event.(*CallFnUserInputStreamEvent_TextDelta_); ok {
+			return x.TextDelta
+		}
+	}
+	return nil
+}
+
+func (x *CallFnUserInputStreamEvent) GetDone() *CallFnUserInputResponse {
+	if x != nil {
+		if x, ok := x.Event.(*CallFnUserInputStreamEvent_Done); ok {
+			return x.Done
+		}
+	}
+	return nil
+}
+
+type isCallFnUserInputStreamEvent_Event interface {
+	isCallFnUserInputStreamEvent_Event()
+}
+
+type CallFnUserInputStreamEvent_ToolStarted_ struct {
+	ToolStarted *CallFnUserInputStreamEvent_ToolStarted `protobuf:"bytes,1,opt,name=tool_started,json=toolStarted,proto3,oneof"`
+}
+
+type CallFnUserInputStreamEvent_ToolFinished_ struct {
+	ToolFinished *CallFnUserInputStreamEvent_ToolFinished `protobuf:"bytes,2,opt,name=tool_finished,json=toolFinished,proto3,oneof"`
+}
+
+type CallFnUserInputStreamEvent_TextDelta_ struct {
+	TextDelta *CallFnUserInputStreamEvent_TextDelta `protobuf:"bytes,3,opt,name=text_delta,json=textDelta,proto3,oneof"`
+}
+
+type CallFnUserInputStreamEvent_Done struct {
+	// Final response, always the last event of the stream
+	Done *CallFnUserInputResponse `protobuf:"bytes,4,opt,name=done,proto3,oneof"`
+}
+
+func (*CallFnUserInputStreamEvent_ToolStarted_) isCallFnUserInputStreamEvent_Event() {}
+
+func (*CallFnUserInputStreamEvent_ToolFinished_) isCallFnUserInputStreamEvent_Event() {}
+
+func (*CallFnUserInputStreamEvent_TextDelta_) isCallFnUserInputStreamEvent_Event() {}
+
+func (*CallFnUserInputStreamEvent_Done) isCallFnUserInputStreamEvent_Event() {}
+
+type ConfirmActionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RequestUserId string                 `protobuf:"bytes,1,opt,name=request_user_id,json=requestUserId,proto3" json:"request_user_id,omitempty"`
+	ActionId      string                 `protobuf:"bytes,2,opt,name=action_id,json=actionId,proto3" json:"action_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConfirmActionRequest) Reset() {
+	*x = ConfirmActionRequest{}
+	mi := &file_proto_v1_central_central_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConfirmActionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConfirmActionRequest) ProtoMessage() {}
+
+func (x *ConfirmActionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_central_central_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConfirmActionRequest.ProtoReflect.Descriptor instead.
+func (*ConfirmActionRequest) Descriptor() ([]byte, []int) {
+	return file_proto_v1_central_central_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ConfirmActionRequest) GetRequestUserId() string {
+	if x != nil {
+		return x.RequestUserId
+	}
+	return ""
+}
+
+func (x *ConfirmActionRequest) GetActionId() string {
+	if x != nil {
+		return x.ActionId
+	}
+	return ""
+}
+
+type ConfirmActionResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Message that can be displayed back to the user
+	// with no further processing over formatting
+	ResponseMessage string `protobuf:"bytes,1,opt,name=response_message,json=responseMessage,proto3" json:"response_message,omitempty"`
+	// Potential response data
+	Data          []*GenericData `protobuf:"bytes,2,rep,name=data,proto3" json:"data,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConfirmActionResponse) Reset() {
+	*x = ConfirmActionResponse{}
+	mi := &file_proto_v1_central_central_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConfirmActionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConfirmActionResponse) ProtoMessage() {}
+
+func (x *ConfirmActionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_central_central_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConfirmActionResponse.ProtoReflect.Descriptor instead.
+func (*ConfirmActionResponse) Descriptor() ([]byte, []int) {
+	return file_proto_v1_central_central_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ConfirmActionResponse) GetResponseMessage() string {
+	if x != nil {
+		return x.ResponseMessage
+	}
+	return ""
+}
+
+func (x *ConfirmActionResponse) GetData() []*GenericData {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+type CancelActionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RequestUserId string                 `protobuf:"bytes,1,opt,name=request_user_id,json=requestUserId,proto3" json:"request_user_id,omitempty"`
+	ActionId      string                 `protobuf:"bytes,2,opt,name=action_id,json=actionId,proto3" json:"action_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CancelActionRequest) Reset() {
+	*x = CancelActionRequest{}
+	mi := &file_proto_v1_central_central_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelActionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelActionRequest) ProtoMessage() {}
+
+func (x *CancelActionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_central_central_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelActionRequest.ProtoReflect.Descriptor instead.
+func (*CancelActionRequest) Descriptor() ([]byte, []int) {
+	return file_proto_v1_central_central_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *CancelActionRequest) GetRequestUserId() string {
+	if x != nil {
+		return x.RequestUserId
+	}
+	return ""
+}
+
+func (x *CancelActionRequest) GetActionId() string {
+	if x != nil {
+		return x.ActionId
+	}
+	return ""
+}
+
+type CancelActionResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Message that can be displayed back to the user
+	// with no further processing over formatting
+	ResponseMessage string `protobuf:"bytes,1,opt,name=response_message,json=responseMessage,proto3" json:"response_message,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *CancelActionResponse) Reset() {
+	*x = CancelActionResponse{}
+	mi := &file_proto_v1_central_central_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelActionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelActionResponse) ProtoMessage() {}
+
+func (x *CancelActionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_central_central_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelActionResponse.ProtoReflect.Descriptor instead.
+func (*CancelActionResponse) Descriptor() ([]byte, []int) {
+	return file_proto_v1_central_central_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *CancelActionResponse) GetResponseMessage() string {
+	if x != nil {
+		return x.ResponseMessage
+	}
+	return ""
+}
+
 // Map of string key/value pairs. Receivers should handle as expected
 // depending on the key value
 type GenericDataValue struct {
@@ -305,7 +825,7 @@ type GenericDataValue struct {
 
 func (x *GenericDataValue) Reset() {
 	*x = GenericDataValue{}
-	mi := &file_proto_v1_central_central_proto_msgTypes[5]
+	mi := &file_proto_v1_central_central_proto_msgTypes[12]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -317,7 +837,7 @@ func (x *GenericDataValue) String() string {
 func (*GenericDataValue) ProtoMessage() {}
 
 func (x *GenericDataValue) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_v1_central_central_proto_msgTypes[5]
+	mi := &file_proto_v1_central_central_proto_msgTypes[12]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -347,6 +867,193 @@ func (x *GenericDataValue) GetValue() string {
 	return ""
 }
 
+// A tool call is about to run
+type CallFnUserInputStreamEvent_ToolStarted struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CallId        string                 `protobuf:"bytes,1,opt,name=call_id,json=callId,proto3" json:"call_id,omitempty"`
+	ToolName      string                 `protobuf:"bytes,2,opt,name=tool_name,json=toolName,proto3" json:"tool_name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CallFnUserInputStreamEvent_ToolStarted) Reset() {
+	*x = CallFnUserInputStreamEvent_ToolStarted{}
+	mi := &file_proto_v1_central_central_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CallFnUserInputStreamEvent_ToolStarted) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CallFnUserInputStreamEvent_ToolStarted) ProtoMessage() {}
+
+func (x *CallFnUserInputStreamEvent_ToolStarted) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_central_central_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CallFnUserInputStreamEvent_ToolStarted.ProtoReflect.Descriptor instead.
+func (*CallFnUserInputStreamEvent_ToolStarted) Descriptor() ([]byte, []int) {
+	return file_proto_v1_central_central_proto_rawDescGZIP(), []int{7, 0}
+}
+
+func (x *CallFnUserInputStreamEvent_ToolStarted) GetCallId() string {
+	if x != nil {
+		return x.CallId
+	}
+	return ""
+}
+
+func (x *CallFnUserInputStreamEvent_ToolStarted) GetToolName() string {
+	if x != nil {
+		return x.ToolName
+	}
+	return ""
+}
+
+// A tool call finished running
+type CallFnUserInputStreamEvent_ToolFinished struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	CallId   string                 `protobuf:"bytes,1,opt,name=call_id,json=callId,proto3" json:"call_id,omitempty"`
+	ToolName string                 `protobuf:"bytes,2,opt,name=tool_name,json=toolName,proto3" json:"tool_name,omitempty"`
+	Success  bool                   `protobuf:"varint,3,opt,name=success,proto3" json:"success,omitempty"`
+	// Result message of the tool, intended for the model rather than the user
+	Message string `protobuf:"bytes,4,opt,name=message,proto3" json:"message,omitempty"`
+	// Set when the tool did not run and is awaiting confirmation instead
+	PendingAction *PendingAction `protobuf:"bytes,5,opt,name=pending_action,json=pendingAction,proto3,oneof" json:"pending_action,omitempty"`
+	// Records the tool created or found
+	Data          []*GenericData `protobuf:"bytes,6,rep,name=data,proto3" json:"data,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CallFnUserInputStreamEvent_ToolFinished) Reset() {
+	*x = CallFnUserInputStreamEvent_ToolFinished{}
+	mi := &file_proto_v1_central_central_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CallFnUserInputStreamEvent_ToolFinished) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CallFnUserInputStreamEvent_ToolFinished) ProtoMessage() {}
+
+func (x *CallFnUserInputStreamEvent_ToolFinished) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_central_central_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CallFnUserInputStreamEvent_ToolFinished.ProtoReflect.Descriptor instead.
+func (*CallFnUserInputStreamEvent_ToolFinished) Descriptor() ([]byte, []int) {
+	return file_proto_v1_central_central_proto_rawDescGZIP(), []int{7, 1}
+}
+
+func (x *CallFnUserInputStreamEvent_ToolFinished) GetCallId() string {
+	if x != nil {
+		return x.CallId
+	}
+	return ""
+}
+
+func (x *CallFnUserInputStreamEvent_ToolFinished) GetToolName() string {
+	if x != nil {
+		return x.ToolName
+	}
+	return ""
+}
+
+func (x *CallFnUserInputStreamEvent_ToolFinished) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *CallFnUserInputStreamEvent_ToolFinished) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *CallFnUserInputStreamEvent_ToolFinished) GetPendingAction() *PendingAction {
+	if x != nil {
+		return x.PendingAction
+	}
+	return nil
+}
+
+func (x *CallFnUserInputStreamEvent_ToolFinished) GetData() []*GenericData {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+// Piece of the response message, concatenating every delta in order gives
+// the full response message
+type CallFnUserInputStreamEvent_TextDelta struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Text          string                 `protobuf:"bytes,1,opt,name=text,proto3" json:"text,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CallFnUserInputStreamEvent_TextDelta) Reset() {
+	*x = CallFnUserInputStreamEvent_TextDelta{}
+	mi := &file_proto_v1_central_central_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CallFnUserInputStreamEvent_TextDelta) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CallFnUserInputStreamEvent_TextDelta) ProtoMessage() {}
+
+func (x *CallFnUserInputStreamEvent_TextDelta) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_central_central_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CallFnUserInputStreamEvent_TextDelta.ProtoReflect.Descriptor instead.
+func (*CallFnUserInputStreamEvent_TextDelta) Descriptor() ([]byte, []int) {
+	return file_proto_v1_central_central_proto_rawDescGZIP(), []int{7, 2}
+}
+
+func (x *CallFnUserInputStreamEvent_TextDelta) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
 var File_proto_v1_central_central_proto protoreflect.FileDescriptor
 
 var file_proto_v1_central_central_proto_rawDesc = string([]byte{
@@ -380,40 +1087,157 @@ var file_proto_v1_central_central_proto_rawDesc = string([]byte{
 	0x73, 0x61, 0x67, 0x65, 0x12, 0x30, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x02, 0x20, 0x03,
 	0x28, 0x0b, 0x32, 0x1c, 0x2e, 0x63, 0x65, 0x6e, 0x74, 0x72, 0x61, 0x6c, 0x70, 0x72, 0x6f, 0x74,
 	0x6f, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x6e, 0x65, 0x72, 0x69, 0x63, 0x44, 0x61, 0x74, 0x61,
-	0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x22, 0x6e, 0x0a, 0x16, 0x43, 0x61, 0x6c, 0x6c, 0x46, 0x6e,
-	0x55, 0x73, 0x65, 0x72, 0x49, 0x6e, 0x70, 0x75, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
-	0x12, 0x26, 0x0a, 0x0f, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x5f, 0x75, 0x73, 0x65, 0x72,
-	0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x72, 0x65, 0x71, 0x75, 0x65,
-	0x73, 0x74, 0x55, 0x73, 0x65, 0x72, 0x49, 0x64, 0x12, 0x2c, 0x0a, 0x12, 0x72, 0x65, 0x71, 0x75,
-	0x65, 0x73, 0x74, 0x5f, 0x75, 0x73, 0x65, 0x72, 0x5f, 0x69, 0x6e, 0x70, 0x75, 0x74, 0x18, 0x02,
-	0x20, 0x01, 0x28, 0x09, 0x52, 0x10, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x55, 0x73, 0x65,
-	0x72, 0x49, 0x6e, 0x70, 0x75, 0x74, 0x22, 0x76, 0x0a, 0x17, 0x43, 0x61, 0x6c, 0x6c, 0x46, 0x6e,
-	0x55, 0x73, 0x65, 0x72, 0x49, 0x6e, 0x70, 0x75, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
-	0x65, 0x12, 0x29, 0x0a, 0x10, 0x72, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x5f, 0x6d, 0x65,
-	0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0f, 0x72, 0x65, 0x73,
-	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x30, 0x0a, 0x04,
-	0x64, 0x61, 0x74, 0x61, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1c, 0x2e, 0x63, 0x65, 0x6e,
-	0x74, 0x72, 0x61, 0x6c, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x6e,
-	0x65, 0x72, 0x69, 0x63, 0x44, 0x61, 0x74, 0x61, 0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x32, 0xe0,
-	0x01, 0x0a, 0x0e, 0x43, 0x65, 0x6e, 0x74, 0x72, 0x61, 0x6c, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63,
-	0x65, 0x12, 0x66, 0x0a, 0x0f, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x55, 0x73, 0x65, 0x72, 0x49,
-	0x6e, 0x70, 0x75, 0x74, 0x12, 0x27, 0x2e, 0x63, 0x65, 0x6e, 0x74, 0x72, 0x61, 0x6c, 0x70, 0x72,
-	0x6f, 0x74, 0x6f, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x55, 0x73, 0x65,
-	0x72, 0x49, 0x6e, 0x70, 0x75, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x28, 0x2e,
-	0x63, 0x65, 0x6e, 0x74, 0x72, 0x61, 0x6c, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x76, 0x31, 0x2e,
-	0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x55, 0x73, 0x65, 0x72, 0x49, 0x6e, 0x70, 0x75, 0x74, 0x52,
-	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x66, 0x0a, 0x0f, 0x43, 0x61, 0x6c,
-	0x6c, 0x46, 0x6e, 0x55, 0x73, 0x65, 0x72, 0x49, 0x6e, 0x70, 0x75, 0x74, 0x12, 0x27, 0x2e, 0x63,
-	0x65, 0x6e, 0x74, 0x72, 0x61, 0x6c, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x76, 0x31, 0x2e, 0x43,
+	0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x22, 0x62, 0x0a, 0x0f, 0x49, 0x6d, 0x61, 0x67, 0x65, 0x41,
+	0x74, 0x74, 0x61, 0x63, 0x68, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x14, 0x0a, 0x04, 0x64, 0x61, 0x74,
+	0x61, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x48, 0x00, 0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x12,
+	0x12, 0x0a, 0x03, 0x75, 0x72, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x03,
+	0x75, 0x72, 0x6c, 0x12, 0x1b, 0x0a, 0x09, 0x6d, 0x69, 0x6d, 0x65, 0x5f, 0x74, 0x79, 0x70, 0x65,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x6d, 0x69, 0x6d, 0x65, 0x54, 0x79, 0x70, 0x65,
+	0x42, 0x08, 0x0a, 0x06, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x22, 0x8e, 0x02, 0x0a, 0x16, 0x43,
 	0x61, 0x6c, 0x6c, 0x46, 0x6e, 0x55, 0x73, 0x65, 0x72, 0x49, 0x6e, 0x70, 0x75, 0x74, 0x52, 0x65,
-	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x28, 0x2e, 0x63, 0x65, 0x6e, 0x74, 0x72, 0x61, 0x6c, 0x70,
-	0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x61, 0x6c, 0x6c, 0x46, 0x6e, 0x55, 0x73,
-	0x65, 0x72, 0x49, 0x6e, 0x70, 0x75, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22,
-	0x00, 0x42, 0x3d, 0x5a, 0x3b, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f,
-	0x63, 0x61, 0x6c, 0x61, 0x6d, 0x69, 0x74, 0x79, 0x2d, 0x6d, 0x2f, 0x72, 0x65, 0x61, 0x70, 0x68,
-	0x75, 0x72, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x76, 0x31, 0x2f, 0x63, 0x65, 0x6e, 0x74,
-	0x72, 0x61, 0x6c, 0x2f, 0x63, 0x65, 0x6e, 0x74, 0x72, 0x61, 0x6c, 0x70, 0x72, 0x6f, 0x74, 0x6f,
-	0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x26, 0x0a, 0x0f, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x5f, 0x75, 0x73, 0x65, 0x72, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d,
+	0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x55, 0x73, 0x65, 0x72, 0x49, 0x64, 0x12, 0x2c, 0x0a,
+	0x12, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x5f, 0x75, 0x73, 0x65, 0x72, 0x5f, 0x69, 0x6e,
+	0x70, 0x75, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x10, 0x72, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x55, 0x73, 0x65, 0x72, 0x49, 0x6e, 0x70, 0x75, 0x74, 0x12, 0x32, 0x0a, 0x15, 0x72,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x5f, 0x75, 0x73, 0x65, 0x72, 0x5f, 0x74, 0x69, 0x6d, 0x65,
+	0x7a, 0x6f, 0x6e, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x13, 0x72, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x55, 0x73, 0x65, 0x72, 0x54, 0x69, 0x6d, 0x65, 0x7a, 0x6f, 0x6e, 0x65, 0x12,
+	0x38, 0x0a, 0x06, 0x69, 0x6d, 0x61, 0x67, 0x65, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x20, 0x2e, 0x63, 0x65, 0x6e, 0x74, 0x72, 0x61, 0x6c, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x76,
+	0x31, 0x2e, 0x49, 0x6d, 0x61, 0x67, 0x65, 0x41, 0x74, 0x74, 0x61, 0x63, 0x68, 0x6d, 0x65, 0x6e,
+	0x74, 0x52, 0x06, 0x69, 0x6d, 0x61, 0x67, 0x65, 0x73, 0x12, 0x30, 0x0a, 0x14, 0x72, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x5f, 0x75, 0x73, 0x65, 0x72, 0x5f, 0x70, 0x72, 0x6f, 0x66, 0x69, 0x6c,
+	0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x12, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x55, 0x73, 0x65, 0x72, 0x50, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x22, 0x46, 0x0a, 0x0d, 0x50,
+	0x65, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x1b, 0x0a, 0x09,
+	0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x08, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x49, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x73, 0x75, 0x6d,
+	0x6d, 0x61, 0x72, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x73, 0x75, 0x6d, 0x6d,
+	0x61, 0x72, 0x79, 0x22, 0xe6, 0x01, 0x0a, 0x17, 0x43, 0x61, 0x6c, 0x6c, 0x46, 0x6e, 0x55, 0x73,
+	0x65, 0x72, 0x49, 0x6e, 0x70, 0x75, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12,
+	0x29, 0x0a, 0x10, 0x72, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x5f, 0x6d, 0x65, 0x73, 0x73,
+	0x61, 0x67, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0f, 0x72, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x30, 0x0a, 0x04, 0x64, 0x61,
+	0x74, 0x61, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1c, 0x2e, 0x63, 0x65, 0x6e, 0x74, 0x72,
+	0x61, 0x6c, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x6e, 0x65, 0x72,
+	0x69, 0x63, 0x44, 0x61, 0x74, 0x61, 0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x12, 0x47, 0x0a, 0x0f,
+	0x70, 0x65, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x5f, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18,
+	0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x63, 0x65, 0x6e, 0x74, 0x72, 0x61, 0x6c, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x76, 0x31, 0x2e, 0x50, 0x65, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x41,
+	0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0e, 0x70, 0x65, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x41, 0x63,
+	0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x25, 0x0a, 0x0e, 0x70, 0x72, 0x6f, 0x6d, 0x70, 0x74, 0x5f,
+	0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x70,
+	0x72, 0x6f, 0x6d, 0x70, 0x74, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x22, 0xee, 0x05, 0x0a,
+	0x1a, 0x43, 0x61, 0x6c, 0x6c, 0x46, 0x6e, 0x55, 0x73, 0x65, 0x72, 0x49, 0x6e, 0x70, 0x75, 0x74,
+	0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x12, 0x5c, 0x0a, 0x0c, 0x74,
+	0x6f, 0x6f, 0x6c, 0x5f, 0x73, 0x74, 0x61, 0x72, 0x74, 0x65, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x37, 0x2e, 0x63, 0x65, 0x6e, 0x74, 0x72, 0x61, 0x6c, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x2e, 0x76, 0x31, 0x2e, 0x43, 0x61, 0x6c, 0x6c, 0x46, 0x6e, 0x55, 0x73, 0x65, 0x72, 0x49, 0x6e,
+	0x70, 0x75, 0x74, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x2e, 0x54,
+	0x6f, 0x6f, 0x6c, 0x53, 0x74, 0x61, 0x72, 0x74, 0x65, 0x64, 0x48, 0x00, 0x52, 0x0b, 0x74, 0x6f,
+	0x6f, 0x6c, 0x53, 0x74, 0x61, 0x72, 0x74, 0x65, 0x64, 0x12, 0x5f, 0x0a, 0x0d, 0x74, 0x6f, 0x6f,
+	0x6c, 0x5f, 0x66, 0x69, 0x6e, 0x69, 0x73, 0x68, 0x65, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x38, 0x2e, 0x63, 0x65, 0x6e, 0x74, 0x72, 0x61, 0x6c, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e,
+	0x76, 0x31, 0x2e, 0x43, 0x61, 0x6c, 0x6c, 0x46, 0x6e, 0x55, 0x73, 0x65, 0x72, 0x49, 0x6e, 0x70,
+	0x75, 0x74, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x2e, 0x54, 0x6f,
+	0x6f, 0x6c, 0x46, 0x69, 0x6e, 0x69, 0x73, 0x68, 0x65, 0x64, 0x48, 0x00, 0x52, 0x0c, 0x74, 0x6f,
+	0x6f, 0x6c, 0x46, 0x69, 0x6e, 0x69, 0x73, 0x68, 0x65, 0x64, 0x12, 0x56, 0x0a, 0x0a, 0x74, 0x65,
+	0x78, 0x74, 0x5f, 0x64, 0x65, 0x6c, 0x74, 0x61, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x35,
+	0x2e, 0x63, 0x65, 0x6e, 0x74, 0x72, 0x61, 0x6c, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x76, 0x31,
+	0x2e, 0x43, 0x61, 0x6c, 0x6c, 0x46, 0x6e, 0x55, 0x73, 0x65, 0x72, 0x49, 0x6e, 0x70, 0x75, 0x74,
+	0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x2e, 0x54, 0x65, 0x78, 0x74,
+	0x44, 0x65, 0x6c, 0x74, 0x61, 0x48, 0x00, 0x52, 0x09, 0x74, 0x65, 0x78, 0x74, 0x44, 0x65, 0x6c,
+	0x74, 0x61, 0x12, 0x3e, 0x0a, 0x04, 0x64, 0x6f, 0x6e, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x28, 0x2e, 0x63, 0x65, 0x6e, 0x74, 0x72, 0x61, 0x6c, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e,
+	0x76, 0x31, 0x2e, 0x43, 0x61, 0x6c, 0x6c, 0x46, 0x6e, 0x55, 0x73, 0x65, 0x72, 0x49, 0x6e, 0x70,
+	0x75, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x48, 0x00, 0x52, 0x04, 0x64, 0x6f,
+	0x6e, 0x65, 0x1a, 0x43, 0x0a, 0x0b, 0x54, 0x6f, 0x6f, 0x6c, 0x53, 0x74, 0x61, 0x72, 0x74, 0x65,
+	0x64, 0x12, 0x17, 0x0a, 0x07, 0x63, 0x61, 0x6c, 0x6c, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x06, 0x63, 0x61, 0x6c, 0x6c, 0x49, 0x64, 0x12, 0x1b, 0x0a, 0x09, 0x74, 0x6f,
+	0x6f, 0x6c, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x74,
+	0x6f, 0x6f, 0x6c, 0x4e, 0x61, 0x6d, 0x65, 0x1a, 0x89, 0x02, 0x0a, 0x0c, 0x54, 0x6f, 0x6f, 0x6c,
+	0x46, 0x69, 0x6e, 0x69, 0x73, 0x68, 0x65, 0x64, 0x12, 0x17, 0x0a, 0x07, 0x63, 0x61, 0x6c, 0x6c,
+	0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x63, 0x61, 0x6c, 0x6c, 0x49,
+	0x64, 0x12, 0x1b, 0x0a, 0x09, 0x74, 0x6f, 0x6f, 0x6c, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x74, 0x6f, 0x6f, 0x6c, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x18,
+	0x0a, 0x07, 0x73, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52,
+	0x07, 0x73, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73,
+	0x61, 0x67, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61,
+	0x67, 0x65, 0x12, 0x4a, 0x0a, 0x0e, 0x70, 0x65, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x5f, 0x61, 0x63,
+	0x74, 0x69, 0x6f, 0x6e, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x63, 0x65, 0x6e,
+	0x74, 0x72, 0x61, 0x6c, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x76, 0x31, 0x2e, 0x50, 0x65, 0x6e,
+	0x64, 0x69, 0x6e, 0x67, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x48, 0x00, 0x52, 0x0d, 0x70, 0x65,
+	0x6e, 0x64, 0x69, 0x6e, 0x67, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x88, 0x01, 0x01, 0x12, 0x30,
+	0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x06, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1c, 0x2e, 0x63,
+	0x65, 0x6e, 0x74, 0x72, 0x61, 0x6c, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x76, 0x31, 0x2e, 0x47,
+	0x65, 0x6e, 0x65, 0x72, 0x69, 0x63, 0x44, 0x61, 0x74, 0x61, 0x52, 0x04, 0x64, 0x61, 0x74, 0x61,
+	0x42, 0x11, 0x0a, 0x0f, 0x5f, 0x70, 0x65, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x5f, 0x61, 0x63, 0x74,
+	0x69, 0x6f, 0x6e, 0x1a, 0x1f, 0x0a, 0x09, 0x54, 0x65, 0x78, 0x74, 0x44, 0x65, 0x6c, 0x74, 0x61,
+	0x12, 0x12, 0x0a, 0x04, 0x74, 0x65, 0x78, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04,
+	0x74, 0x65, 0x78, 0x74, 0x42, 0x07, 0x0a, 0x05, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x22, 0x5b, 0x0a,
+	0x14, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x72, 0x6d, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x26, 0x0a, 0x0f, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x5f, 0x75, 0x73, 0x65, 0x72, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d,
+	0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x55, 0x73, 0x65, 0x72, 0x49, 0x64, 0x12, 0x1b, 0x0a,
+	0x09, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x08, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x49, 0x64, 0x22, 0x74, 0x0a, 0x15, 0x43, 0x6f,
+	0x6e, 0x66, 0x69, 0x72, 0x6d, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x12, 0x29, 0x0a, 0x10, 0x72, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x5f,
+	0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0f, 0x72,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x30,
+	0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1c, 0x2e, 0x63,
+	0x65, 0x6e, 0x74, 0x72, 0x61, 0x6c, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x76, 0x31, 0x2e, 0x47,
+	0x65, 0x6e, 0x65, 0x72, 0x69, 0x63, 0x44, 0x61, 0x74, 0x61, 0x52, 0x04, 0x64, 0x61, 0x74, 0x61,
+	0x22, 0x5a, 0x0a, 0x13, 0x43, 0x61, 0x6e, 0x63, 0x65, 0x6c, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x26, 0x0a, 0x0f, 0x72, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x5f, 0x75, 0x73, 0x65, 0x72, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x0d, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x55, 0x73, 0x65, 0x72, 0x49, 0x64, 0x12,
+	0x1b, 0x0a, 0x09, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x08, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x49, 0x64, 0x22, 0x41, 0x0a, 0x14,
+	0x43, 0x61, 0x6e, 0x63, 0x65, 0x6c, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x29, 0x0a, 0x10, 0x72, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x5f, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0f,
+	0x72, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x32,
+	0x94, 0x04, 0x0a, 0x0e, 0x43, 0x65, 0x6e, 0x74, 0x72, 0x61, 0x6c, 0x53, 0x65, 0x72, 0x76, 0x69,
+	0x63, 0x65, 0x12, 0x66, 0x0a, 0x0f, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x55, 0x73, 0x65, 0x72,
+	0x49, 0x6e, 0x70, 0x75, 0x74, 0x12, 0x27, 0x2e, 0x63, 0x65, 0x6e, 0x74, 0x72, 0x61, 0x6c, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x55, 0x73,
+	0x65, 0x72, 0x49, 0x6e, 0x70, 0x75, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x28,
+	0x2e, 0x63, 0x65, 0x6e, 0x74, 0x72, 0x61, 0x6c, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x76, 0x31,
+	0x2e, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x55, 0x73, 0x65, 0x72, 0x49, 0x6e, 0x70, 0x75, 0x74,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x66, 0x0a, 0x0f, 0x43, 0x61,
+	0x6c, 0x6c, 0x46, 0x6e, 0x55, 0x73, 0x65, 0x72, 0x49, 0x6e, 0x70, 0x75, 0x74, 0x12, 0x27, 0x2e,
+	0x63, 0x65, 0x6e, 0x74, 0x72, 0x61, 0x6c, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x76, 0x31, 0x2e,
+	0x43, 0x61, 0x6c, 0x6c, 0x46, 0x6e, 0x55, 0x73, 0x65, 0x72, 0x49, 0x6e, 0x70, 0x75, 0x74, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x28, 0x2e, 0x63, 0x65, 0x6e, 0x74, 0x72, 0x61, 0x6c,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x61, 0x6c, 0x6c, 0x46, 0x6e, 0x55,
+	0x73, 0x65, 0x72, 0x49, 0x6e, 0x70, 0x75, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x22, 0x00, 0x12, 0x71, 0x0a, 0x15, 0x43, 0x61, 0x6c, 0x6c, 0x46, 0x6e, 0x55, 0x73, 0x65, 0x72,
+	0x49, 0x6e, 0x70, 0x75, 0x74, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x12, 0x27, 0x2e, 0x63, 0x65,
+	0x6e, 0x74, 0x72, 0x61, 0x6c, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x61,
+	0x6c, 0x6c, 0x46, 0x6e, 0x55, 0x73, 0x65, 0x72, 0x49, 0x6e, 0x70, 0x75, 0x74, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x2b, 0x2e, 0x63, 0x65, 0x6e, 0x74, 0x72, 0x61, 0x6c, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x61, 0x6c, 0x6c, 0x46, 0x6e, 0x55, 0x73, 0x65,
+	0x72, 0x49, 0x6e, 0x70, 0x75, 0x74, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x45, 0x76, 0x65, 0x6e,
+	0x74, 0x22, 0x00, 0x30, 0x01, 0x12, 0x60, 0x0a, 0x0d, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x72, 0x6d,
+	0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x25, 0x2e, 0x63, 0x65, 0x6e, 0x74, 0x72, 0x61, 0x6c,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x72, 0x6d,
+	0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x26, 0x2e,
+	0x63, 0x65, 0x6e, 0x74, 0x72, 0x61, 0x6c, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x76, 0x31, 0x2e,
+	0x43, 0x6f, 0x6e, 0x66, 0x69, 0x72, 0x6d, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x5d, 0x0a, 0x0c, 0x43, 0x61, 0x6e, 0x63, 0x65,
+	0x6c, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x24, 0x2e, 0x63, 0x65, 0x6e, 0x74, 0x72, 0x61,
+	0x6c, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x61, 0x6e, 0x63, 0x65, 0x6c,
+	0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x25, 0x2e,
+	0x63, 0x65, 0x6e, 0x74, 0x72, 0x61, 0x6c, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x76, 0x31, 0x2e,
+	0x43, 0x61, 0x6e, 0x63, 0x65, 0x6c, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x42, 0x3d, 0x5a, 0x3b, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62,
+	0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x63, 0x61, 0x6c, 0x61, 0x6d, 0x69, 0x74, 0x79, 0x2d, 0x6d, 0x2f,
+	0x72, 0x65, 0x61, 0x70, 0x68, 0x75, 0x72, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x76, 0x31,
+	0x2f, 0x63, 0x65, 0x6e, 0x74, 0x72, 0x61, 0x6c, 0x2f, 0x63, 0x65, 0x6e, 0x74, 0x72, 0x61, 0x6c,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 })
 
 var (
@@ -428,28 +1252,53 @@ func file_proto_v1_central_central_proto_rawDescGZIP() []byte {
 	return file_proto_v1_central_central_proto_rawDescData
 }
 
-var file_proto_v1_central_central_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
+var file_proto_v1_central_central_proto_msgTypes = make([]protoimpl.MessageInfo, 16)
 var file_proto_v1_central_central_proto_goTypes = []any{
-	(*GenericData)(nil),             // 0: centralproto.v1.GenericData
-	(*ActionUserInputRequest)(nil),  // 1: centralproto.v1.ActionUserInputRequest
-	(*ActionUserInputResponse)(nil), // 2: centralproto.v1.ActionUserInputResponse
-	(*CallFnUserInputRequest)(nil),  // 3: centralproto.v1.CallFnUserInputRequest
-	(*CallFnUserInputResponse)(nil), // 4: centralproto.v1.CallFnUserInputResponse
-	(*GenericDataValue)(nil),        // 5: centralproto.v1.GenericData.value
+	(*GenericData)(nil),                             // 0: centralproto.v1.GenericData
+	(*ActionUserInputRequest)(nil),                  // 1: centralproto.v1.ActionUserInputRequest
+	(*ActionUserInputResponse)(nil),                 // 2: centralproto.v1.ActionUserInputResponse
+	(*ImageAttachment)(nil),                         // 3: centralproto.v1.ImageAttachment
+	(*CallFnUserInputRequest)(nil),                  // 4: centralproto.v1.CallFnUserInputRequest
+	(*PendingAction)(nil),                           // 5: centralproto.v1.PendingAction
+	(*CallFnUserInputResponse)(nil),                 // 6: centralproto.v1.CallFnUserInputResponse
+	(*CallFnUserInputStreamEvent)(nil),              // 7: centralproto.v1.CallFnUserInputStreamEvent
+	(*ConfirmActionRequest)(nil),                    // 8: centralproto.v1.ConfirmActionRequest
+	(*ConfirmActionResponse)(nil),                   // 9: centralproto.v1.ConfirmActionResponse
+	(*CancelActionRequest)(nil),                     // 10: centralproto.v1.CancelActionRequest
+	(*CancelActionResponse)(nil),                    // 11: centralproto.v1.CancelActionResponse
+	(*GenericDataValue)(nil),                        // 12: centralproto.v1.GenericData.value
+	(*CallFnUserInputStreamEvent_ToolStarted)(nil),  // 13: centralproto.v1.CallFnUserInputStreamEvent.ToolStarted
+	(*CallFnUserInputStreamEvent_ToolFinished)(nil), // 14: centralproto.v1.CallFnUserInputStreamEvent.ToolFinished
+	(*CallFnUserInputStreamEvent_TextDelta)(nil),    // 15: centralproto.v1.CallFnUserInputStreamEvent.TextDelta
 }
 var file_proto_v1_central_central_proto_depIdxs = []int32{
-	5, // 0: centralproto.v1.GenericData.data_values:type_name -> centralproto.v1.GenericData.value
-	0, // 1: centralproto.v1.ActionUserInputResponse.data:type_name -> centralproto.v1.GenericData
-	0, // 2: centralproto.v1.CallFnUserInputResponse.data:type_name -> centralproto.v1.GenericData
-	1, // 3: centralproto.v1.CentralService.ActionUserInput:input_type -> centralproto.v1.ActionUserInputRequest
-	3, // 4: centralproto.v1.CentralService.CallFnUserInput:input_type -> centralproto.v1.CallFnUserInputRequest
-	2, // 5: centralproto.v1.CentralService.ActionUserInput:output_type -> centralproto.v1.ActionUserInputResponse
-	4, // 6: centralproto.v1.CentralService.CallFnUserInput:output_type -> centralproto.v1.CallFnUserInputResponse
-	5, // [5:7] is the sub-list for method output_type
-	3, // [3:5] is the sub-list for method input_type
-	3, // [3:3] is the sub-list for extension type_name
-	3, // [3:3] is the sub-list for extension extendee
-	0, // [0:3] is the sub-list for field type_name
+	12, // 0: centralproto.v1.GenericData.data_values:type_name -> centralproto.v1.GenericData.value
+	0,  // 1: centralproto.v1.ActionUserInputResponse.data:type_name -> centralproto.v1.GenericData
+	3,  // 2: centralproto.v1.CallFnUserInputRequest.images:type_name -> centralproto.v1.ImageAttachment
+	0,  // 3: centralproto.v1.CallFnUserInputResponse.data:type_name -> centralproto.v1.GenericData
+	5,  // 4: centralproto.v1.CallFnUserInputResponse.pending_actions:type_name -> centralproto.v1.PendingAction
+	13, // 5: centralproto.v1.CallFnUserInputStreamEvent.tool_started:type_name -> centralproto.v1.CallFnUserInputStreamEvent.ToolStarted
+	14, // 6: centralproto.v1.CallFnUserInputStreamEvent.tool_finished:type_name -> centralproto.v1.CallFnUserInputStreamEvent.ToolFinished
+	15, // 7: centralproto.v1.CallFnUserInputStreamEvent.text_delta:type_name -> centralproto.v1.CallFnUserInputStreamEvent.TextDelta
+	6,  // 8: centralproto.v1.CallFnUserInputStreamEvent.done:type_name -> centralproto.v1.CallFnUserInputResponse
+	0,  // 9: centralproto.v1.ConfirmActionResponse.data:type_name -> centralproto.v1.GenericData
+	5,  // 10: centralproto.v1.CallFnUserInputStreamEvent.ToolFinished.pending_action:type_name -> centralproto.v1.PendingAction
+	0,  // 11: centralproto.v1.CallFnUserInputStreamEvent.ToolFinished.data:type_name -> centralproto.v1.GenericData
+	1,  // 12: centralproto.v1.CentralService.ActionUserInput:input_type -> centralproto.v1.ActionUserInputRequest
+	4,  // 13: centralproto.v1.CentralService.CallFnUserInput:input_type -> centralproto.v1.CallFnUserInputRequest
+	4,  // 14: centralproto.v1.CentralService.CallFnUserInputStream:input_type -> centralproto.v1.CallFnUserInputRequest
+	8,  // 15: centralproto.v1.CentralService.ConfirmAction:input_type -> centralproto.v1.ConfirmActionRequest
+	10, // 16: centralproto.v1.CentralService.CancelAction:input_type -> centralproto.v1.CancelActionRequest
+	2,  // 17: centralproto.v1.CentralService.ActionUserInput:output_type -> centralproto.v1.ActionUserInputResponse
+	6,  // 18: centralproto.v1.CentralService.CallFnUserInput:output_type -> centralproto.v1.CallFnUserInputResponse
+	7,  // 19: centralproto.v1.CentralService.CallFnUserInputStream:output_type -> centralproto.v1.CallFnUserInputStreamEvent
+	9,  // 20: centralproto.v1.CentralService.ConfirmAction:output_type -> centralproto.v1.ConfirmActionResponse
+	11, // 21: centralproto.v1.CentralService.CancelAction:output_type -> centralproto.v1.CancelActionResponse
+	17, // [17:22] is the sub-list for method output_type
+	12, // [12:17] is the sub-list for method input_type
+	12, // [12:12] is the sub-list for extension type_name
+	12, // [12:12] is the sub-list for extension extendee
+	0,  // [0:12] is the sub-list for field type_name
 }
 
 func init() { file_proto_v1_central_central_proto_init() }
@@ -457,13 +1306,24 @@ func file_proto_v1_central_central_proto_init() {
 	if File_proto_v1_central_central_proto != nil {
 		return
 	}
+	file_proto_v1_central_central_proto_msgTypes[3].OneofWrappers = []any{
+		(*ImageAttachment_Data)(nil),
+		(*ImageAttachment_Url)(nil),
+	}
+	file_proto_v1_central_central_proto_msgTypes[7].OneofWrappers = []any{
+		(*CallFnUserInputStreamEvent_ToolStarted_)(nil),
+		(*CallFnUserInputStreamEvent_ToolFinished_)(nil),
+		(*CallFnUserInputStreamEvent_TextDelta_)(nil),
+		(*CallFnUserInputStreamEvent_Done)(nil),
+	}
+	file_proto_v1_central_central_proto_msgTypes[14].OneofWrappers = []any{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_v1_central_central_proto_rawDesc), len(file_proto_v1_central_central_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   6,
+			NumMessages:   16,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -0,0 +1,127 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v5.29.2
+// source: proto/v1/central/central_admin.proto
+
+package centralproto
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	CentralAdminService_GetUsage_FullMethodName = "/centralproto.v1.CentralAdminService/GetUsage"
+)
+
+// CentralAdminServiceClient is the client API for CentralAdminService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type CentralAdminServiceClient interface {
+	// Simple RPC
+	//
+	// Fetch aggregated llm token usage, grouped by user and model
+	GetUsage(ctx context.Context, in *GetUsageRequest, opts ...grpc.CallOption) (*GetUsageResponse, error)
+}
+
+type centralAdminServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewCentralAdminServiceClient(cc grpc.ClientConnInterface) CentralAdminServiceClient {
+	return &centralAdminServiceClient{cc}
+}
+
+func (c *centralAdminServiceClient) GetUsage(ctx context.Context, in *GetUsageRequest, opts ...grpc.CallOption) (*GetUsageResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetUsageResponse)
+	err := c.cc.Invoke(ctx, CentralAdminService_GetUsage_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CentralAdminServiceServer is the server API for CentralAdminService service.
+// All implementations must embed UnimplementedCentralAdminServiceServer
+// for forward compatibility.
+type CentralAdminServiceServer interface {
+	// Simple RPC
+	//
+	// Fetch aggregated llm token usage, grouped by user and model
+	GetUsage(context.Context, *GetUsageRequest) (*GetUsageResponse, error)
+	mustEmbedUnimplementedCentralAdminServiceServer()
+}
+
+// UnimplementedCentralAdminServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedCentralAdminServiceServer struct{}
+
+func (UnimplementedCentralAdminServiceServer) GetUsage(context.Context, *GetUsageRequest) (*GetUsageResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetUsage not implemented")
+}
+func (UnimplementedCentralAdminServiceServer) mustEmbedUnimplementedCentralAdminServiceServer() {}
+func (UnimplementedCentralAdminServiceServer) testEmbeddedByValue()                             {}
+
+// UnsafeCentralAdminServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to CentralAdminServiceServer will
+// result in compilation errors.
+type UnsafeCentralAdminServiceServer interface {
+	mustEmbedUnimplementedCentralAdminServiceServer()
+}
+
+func RegisterCentralAdminServiceServer(s grpc.ServiceRegistrar, srv CentralAdminServiceServer) {
+	// If the following call pancis, it indicates UnimplementedCentralAdminServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&CentralAdminService_ServiceDesc, srv)
+}
+
+func _CentralAdminService_GetUsage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetUsageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CentralAdminServiceServer).GetUsage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CentralAdminService_GetUsage_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CentralAdminServiceServer).GetUsage(ctx, req.(*GetUsageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// CentralAdminService_ServiceDesc is the grpc.ServiceDesc for CentralAdminService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var CentralAdminService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "centralproto.v1.CentralAdminService",
+	HandlerType: (*CentralAdminServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetUsage",
+			Handler:    _CentralAdminService_GetUsage_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "proto/v1/central/central_admin.proto",
+}
@@ -112,12 +112,14 @@ func (x *CreateFoodRecordResponse) GetRecord() *domain.FoodRecord {
 }
 
 type GetFoodFilter struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            *string                `protobuf:"bytes,1,opt,name=id,proto3,oneof" json:"id,omitempty"`
-	Name          *string                `protobuf:"bytes,2,opt,name=name,proto3,oneof" json:"name,omitempty"`
-	Description   *string                `protobuf:"bytes,3,opt,name=description,proto3,oneof" json:"description,omitempty"`
-	BeforeTime    *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=before_time,json=beforeTime,proto3,oneof" json:"before_time,omitempty"`
-	AfterTime     *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=after_time,json=afterTime,proto3,oneof" json:"after_time,omitempty"`
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Id          *string                `protobuf:"bytes,1,opt,name=id,proto3,oneof" json:"id,omitempty"`
+	Name        *string                `protobuf:"bytes,2,opt,name=name,proto3,oneof" json:"name,omitempty"`
+	Description *string                `protobuf:"bytes,3,opt,name=description,proto3,oneof" json:"description,omitempty"`
+	BeforeTime  *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=before_time,json=beforeTime,proto3,oneof" json:"before_time,omitempty"`
+	AfterTime   *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=after_time,json=afterTime,proto3,oneof" json:"after_time,omitempty"`
+	// Only the most recently created matching record
+	Latest        bool `protobuf:"varint,6,opt,name=latest,proto3" json:"latest,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -187,6 +189,13 @@ func (x *GetFoodFilter) GetAfterTime() *timestamppb.Timestamp {
 	return nil
 }
 
+func (x *GetFoodFilter) GetLatest() bool {
+	if x != nil {
+		return x.Latest
+	}
+	return false
+}
+
 type GetFoodRecordsRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	RequestUserId string                 `protobuf:"bytes,1,opt,name=request_user_id,json=requestUserId,proto3" json:"request_user_id,omitempty"`
@@ -283,6 +292,200 @@ func (x *GetFoodRecordsResponse) GetRecords() []*domain.FoodRecord {
 	return nil
 }
 
+type UpdateFoodRecordRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RequestUserId string                 `protobuf:"bytes,1,opt,name=request_user_id,json=requestUserId,proto3" json:"request_user_id,omitempty"`
+	// Replaces the record with the same id, which must belong to the user
+	Record        *domain.FoodRecord `protobuf:"bytes,2,opt,name=record,proto3" json:"record,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateFoodRecordRequest) Reset() {
+	*x = UpdateFoodRecordRequest{}
+	mi := &file_proto_v1_central_central_food_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateFoodRecordRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateFoodRecordRequest) ProtoMessage() {}
+
+func (x *UpdateFoodRecordRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_central_central_food_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateFoodRecordRequest.ProtoReflect.Descriptor instead.
+func (*UpdateFoodRecordRequest) Descriptor() ([]byte, []int) {
+	return file_proto_v1_central_central_food_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *UpdateFoodRecordRequest) GetRequestUserId() string {
+	if x != nil {
+		return x.RequestUserId
+	}
+	return ""
+}
+
+func (x *UpdateFoodRecordRequest) GetRecord() *domain.FoodRecord {
+	if x != nil {
+		return x.Record
+	}
+	return nil
+}
+
+type UpdateFoodRecordResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Record        *domain.FoodRecord     `protobuf:"bytes,1,opt,name=record,proto3" json:"record,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateFoodRecordResponse) Reset() {
+	*x = UpdateFoodRecordResponse{}
+	mi := &file_proto_v1_central_central_food_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateFoodRecordResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateFoodRecordResponse) ProtoMessage() {}
+
+func (x *UpdateFoodRecordResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_central_central_food_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateFoodRecordResponse.ProtoReflect.Descriptor instead.
+func (*UpdateFoodRecordResponse) Descriptor() ([]byte, []int) {
+	return file_proto_v1_central_central_food_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *UpdateFoodRecordResponse) GetRecord() *domain.FoodRecord {
+	if x != nil {
+		return x.Record
+	}
+	return nil
+}
+
+type DeleteFoodRecordRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RequestUserId string                 `protobuf:"bytes,1,opt,name=request_user_id,json=requestUserId,proto3" json:"request_user_id,omitempty"`
+	Id            string                 `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteFoodRecordRequest) Reset() {
+	*x = DeleteFoodRecordRequest{}
+	mi := &file_proto_v1_central_central_food_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteFoodRecordRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteFoodRecordRequest) ProtoMessage() {}
+
+func (x *DeleteFoodRecordRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_central_central_food_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteFoodRecordRequest.ProtoReflect.Descriptor instead.
+func (*DeleteFoodRecordRequest) Descriptor() ([]byte, []int) {
+	return file_proto_v1_central_central_food_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *DeleteFoodRecordRequest) GetRequestUserId() string {
+	if x != nil {
+		return x.RequestUserId
+	}
+	return ""
+}
+
+func (x *DeleteFoodRecordRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type DeleteFoodRecordResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The record as it was before being deleted
+	Record        *domain.FoodRecord `protobuf:"bytes,1,opt,name=record,proto3" json:"record,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteFoodRecordResponse) Reset() {
+	*x = DeleteFoodRecordResponse{}
+	mi := &file_proto_v1_central_central_food_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteFoodRecordResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteFoodRecordResponse) ProtoMessage() {}
+
+func (x *DeleteFoodRecordResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_central_central_food_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteFoodRecordResponse.ProtoReflect.Descriptor instead.
+func (*DeleteFoodRecordResponse) Descriptor() ([]byte, []int) {
+	return file_proto_v1_central_central_food_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *DeleteFoodRecordResponse) GetRecord() *domain.FoodRecord {
+	if x != nil {
+		return x.Record
+	}
+	return nil
+}
+
 var File_proto_v1_central_central_food_proto protoreflect.FileDescriptor
 
 var file_proto_v1_central_central_food_proto_rawDesc = string([]byte{
@@ -302,7 +505,7 @@ var file_proto_v1_central_central_food_proto_rawDesc = string([]byte{
 	0x64, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x2d, 0x0a, 0x06, 0x72, 0x65, 0x63,
 	0x6f, 0x72, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x64, 0x6f, 0x6d, 0x61,
 	0x69, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x46, 0x6f, 0x6f, 0x64, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64,
-	0x52, 0x06, 0x72, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x22, 0xa5, 0x02, 0x0a, 0x0d, 0x47, 0x65, 0x74,
+	0x52, 0x06, 0x72, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x22, 0xbd, 0x02, 0x0a, 0x0d, 0x47, 0x65, 0x74,
 	0x46, 0x6f, 0x6f, 0x64, 0x46, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x12, 0x13, 0x0a, 0x02, 0x69, 0x64,
 	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x02, 0x69, 0x64, 0x88, 0x01, 0x01, 0x12,
 	0x17, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x48, 0x01, 0x52,
@@ -317,41 +520,78 @@ var file_proto_v1_central_central_food_proto_rawDesc = string([]byte{
 	0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70,
 	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d,
 	0x70, 0x48, 0x04, 0x52, 0x09, 0x61, 0x66, 0x74, 0x65, 0x72, 0x54, 0x69, 0x6d, 0x65, 0x88, 0x01,
-	0x01, 0x42, 0x05, 0x0a, 0x03, 0x5f, 0x69, 0x64, 0x42, 0x07, 0x0a, 0x05, 0x5f, 0x6e, 0x61, 0x6d,
-	0x65, 0x42, 0x0e, 0x0a, 0x0c, 0x5f, 0x64, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f,
-	0x6e, 0x42, 0x0e, 0x0a, 0x0c, 0x5f, 0x62, 0x65, 0x66, 0x6f, 0x72, 0x65, 0x5f, 0x74, 0x69, 0x6d,
-	0x65, 0x42, 0x0d, 0x0a, 0x0b, 0x5f, 0x61, 0x66, 0x74, 0x65, 0x72, 0x5f, 0x74, 0x69, 0x6d, 0x65,
-	0x22, 0x77, 0x0a, 0x15, 0x47, 0x65, 0x74, 0x46, 0x6f, 0x6f, 0x64, 0x52, 0x65, 0x63, 0x6f, 0x72,
-	0x64, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x26, 0x0a, 0x0f, 0x72, 0x65, 0x71,
-	0x75, 0x65, 0x73, 0x74, 0x5f, 0x75, 0x73, 0x65, 0x72, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01,
-	0x28, 0x09, 0x52, 0x0d, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x55, 0x73, 0x65, 0x72, 0x49,
-	0x64, 0x12, 0x36, 0x0a, 0x06, 0x66, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28,
-	0x0b, 0x32, 0x1e, 0x2e, 0x63, 0x65, 0x6e, 0x74, 0x72, 0x61, 0x6c, 0x70, 0x72, 0x6f, 0x74, 0x6f,
-	0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x46, 0x6f, 0x6f, 0x64, 0x46, 0x69, 0x6c, 0x74, 0x65,
-	0x72, 0x52, 0x06, 0x66, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x22, 0x49, 0x0a, 0x16, 0x47, 0x65, 0x74,
-	0x46, 0x6f, 0x6f, 0x64, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f,
-	0x6e, 0x73, 0x65, 0x12, 0x2f, 0x0a, 0x07, 0x72, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x73, 0x18, 0x01,
-	0x20, 0x03, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x64, 0x6f, 0x6d, 0x61, 0x69, 0x6e, 0x2e, 0x76, 0x31,
-	0x2e, 0x46, 0x6f, 0x6f, 0x64, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x52, 0x07, 0x72, 0x65, 0x63,
-	0x6f, 0x72, 0x64, 0x73, 0x32, 0xe4, 0x01, 0x0a, 0x12, 0x43, 0x65, 0x6e, 0x74, 0x72, 0x61, 0x6c,
-	0x46, 0x6f, 0x6f, 0x64, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x69, 0x0a, 0x10, 0x43,
-	0x72, 0x65, 0x61, 0x74, 0x65, 0x46, 0x6f, 0x6f, 0x64, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x12,
-	0x28, 0x2e, 0x63, 0x65, 0x6e, 0x74, 0x72, 0x61, 0x6c, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x76,
-	0x31, 0x2e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x46, 0x6f, 0x6f, 0x64, 0x52, 0x65, 0x63, 0x6f,
-	0x72, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x29, 0x2e, 0x63, 0x65, 0x6e, 0x74,
-	0x72, 0x61, 0x6c, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x72, 0x65, 0x61,
-	0x74, 0x65, 0x46, 0x6f, 0x6f, 0x64, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x52, 0x65, 0x73, 0x70,
-	0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x63, 0x0a, 0x0e, 0x47, 0x65, 0x74, 0x46, 0x6f, 0x6f,
-	0x64, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x73, 0x12, 0x26, 0x2e, 0x63, 0x65, 0x6e, 0x74, 0x72,
-	0x61, 0x6c, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x46, 0x6f,
-	0x6f, 0x64, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
-	0x1a, 0x27, 0x2e, 0x63, 0x65, 0x6e, 0x74, 0x72, 0x61, 0x6c, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e,
-	0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x46, 0x6f, 0x6f, 0x64, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64,
-	0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x42, 0x35, 0x5a, 0x33, 0x67,
-	0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x63, 0x61, 0x6c, 0x61, 0x6d, 0x69,
-	0x74, 0x79, 0x2d, 0x6d, 0x2f, 0x72, 0x65, 0x61, 0x70, 0x68, 0x75, 0x72, 0x2f, 0x70, 0x72, 0x6f,
-	0x74, 0x6f, 0x2f, 0x76, 0x31, 0x2f, 0x63, 0x65, 0x6e, 0x74, 0x72, 0x61, 0x6c, 0x70, 0x72, 0x6f,
-	0x74, 0x6f, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x01, 0x12, 0x16, 0x0a, 0x06, 0x6c, 0x61, 0x74, 0x65, 0x73, 0x74, 0x18, 0x06, 0x20, 0x01, 0x28,
+	0x08, 0x52, 0x06, 0x6c, 0x61, 0x74, 0x65, 0x73, 0x74, 0x42, 0x05, 0x0a, 0x03, 0x5f, 0x69, 0x64,
+	0x42, 0x07, 0x0a, 0x05, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x42, 0x0e, 0x0a, 0x0c, 0x5f, 0x64, 0x65,
+	0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x42, 0x0e, 0x0a, 0x0c, 0x5f, 0x62, 0x65,
+	0x66, 0x6f, 0x72, 0x65, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x42, 0x0d, 0x0a, 0x0b, 0x5f, 0x61, 0x66,
+	0x74, 0x65, 0x72, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x22, 0x77, 0x0a, 0x15, 0x47, 0x65, 0x74, 0x46,
+	0x6f, 0x6f, 0x64, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x12, 0x26, 0x0a, 0x0f, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x5f, 0x75, 0x73, 0x65,
+	0x72, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x72, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x55, 0x73, 0x65, 0x72, 0x49, 0x64, 0x12, 0x36, 0x0a, 0x06, 0x66, 0x69, 0x6c,
+	0x74, 0x65, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x63, 0x65, 0x6e, 0x74,
+	0x72, 0x61, 0x6c, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x46,
+	0x6f, 0x6f, 0x64, 0x46, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x52, 0x06, 0x66, 0x69, 0x6c, 0x74, 0x65,
+	0x72, 0x22, 0x49, 0x0a, 0x16, 0x47, 0x65, 0x74, 0x46, 0x6f, 0x6f, 0x64, 0x52, 0x65, 0x63, 0x6f,
+	0x72, 0x64, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x2f, 0x0a, 0x07, 0x72,
+	0x65, 0x63, 0x6f, 0x72, 0x64, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x64,
+	0x6f, 0x6d, 0x61, 0x69, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x46, 0x6f, 0x6f, 0x64, 0x52, 0x65, 0x63,
+	0x6f, 0x72, 0x64, 0x52, 0x07, 0x72, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x73, 0x22, 0x70, 0x0a, 0x17,
+	0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x46, 0x6f, 0x6f, 0x64, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x26, 0x0a, 0x0f, 0x72, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x5f, 0x75, 0x73, 0x65, 0x72, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x0d, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x55, 0x73, 0x65, 0x72, 0x49, 0x64, 0x12,
+	0x2d, 0x0a, 0x06, 0x72, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x15, 0x2e, 0x64, 0x6f, 0x6d, 0x61, 0x69, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x46, 0x6f, 0x6f, 0x64,
+	0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x52, 0x06, 0x72, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x22, 0x49,
+	0x0a, 0x18, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x46, 0x6f, 0x6f, 0x64, 0x52, 0x65, 0x63, 0x6f,
+	0x72, 0x64, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x2d, 0x0a, 0x06, 0x72, 0x65,
+	0x63, 0x6f, 0x72, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x64, 0x6f, 0x6d,
+	0x61, 0x69, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x46, 0x6f, 0x6f, 0x64, 0x52, 0x65, 0x63, 0x6f, 0x72,
+	0x64, 0x52, 0x06, 0x72, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x22, 0x51, 0x0a, 0x17, 0x44, 0x65, 0x6c,
+	0x65, 0x74, 0x65, 0x46, 0x6f, 0x6f, 0x64, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x12, 0x26, 0x0a, 0x0f, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x5f,
+	0x75, 0x73, 0x65, 0x72, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x72,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x55, 0x73, 0x65, 0x72, 0x49, 0x64, 0x12, 0x0e, 0x0a, 0x02,
+	0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x22, 0x49, 0x0a, 0x18,
+	0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x46, 0x6f, 0x6f, 0x64, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x2d, 0x0a, 0x06, 0x72, 0x65, 0x63, 0x6f,
+	0x72, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x64, 0x6f, 0x6d, 0x61, 0x69,
+	0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x46, 0x6f, 0x6f, 0x64, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x52,
+	0x06, 0x72, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x32, 0xba, 0x03, 0x0a, 0x12, 0x43, 0x65, 0x6e, 0x74,
+	0x72, 0x61, 0x6c, 0x46, 0x6f, 0x6f, 0x64, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x69,
+	0x0a, 0x10, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x46, 0x6f, 0x6f, 0x64, 0x52, 0x65, 0x63, 0x6f,
+	0x72, 0x64, 0x12, 0x28, 0x2e, 0x63, 0x65, 0x6e, 0x74, 0x72, 0x61, 0x6c, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x46, 0x6f, 0x6f, 0x64, 0x52,
+	0x65, 0x63, 0x6f, 0x72, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x29, 0x2e, 0x63,
+	0x65, 0x6e, 0x74, 0x72, 0x61, 0x6c, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x76, 0x31, 0x2e, 0x43,
+	0x72, 0x65, 0x61, 0x74, 0x65, 0x46, 0x6f, 0x6f, 0x64, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x63, 0x0a, 0x0e, 0x47, 0x65, 0x74,
+	0x46, 0x6f, 0x6f, 0x64, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x73, 0x12, 0x26, 0x2e, 0x63, 0x65,
+	0x6e, 0x74, 0x72, 0x61, 0x6c, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65,
+	0x74, 0x46, 0x6f, 0x6f, 0x64, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x73, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x1a, 0x27, 0x2e, 0x63, 0x65, 0x6e, 0x74, 0x72, 0x61, 0x6c, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x46, 0x6f, 0x6f, 0x64, 0x52, 0x65, 0x63,
+	0x6f, 0x72, 0x64, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x69,
+	0x0a, 0x10, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x46, 0x6f, 0x6f, 0x64, 0x52, 0x65, 0x63, 0x6f,
+	0x72, 0x64, 0x12, 0x28, 0x2e, 0x63, 0x65, 0x6e, 0x74, 0x72, 0x61, 0x6c, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x2e, 0x76, 0x31, 0x2e, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x46, 0x6f, 0x6f, 0x64, 0x52,
+	0x65, 0x63, 0x6f, 0x72, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x29, 0x2e, 0x63,
+	0x65, 0x6e, 0x74, 0x72, 0x61, 0x6c, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x76, 0x31, 0x2e, 0x55,
+	0x70, 0x64, 0x61, 0x74, 0x65, 0x46, 0x6f, 0x6f, 0x64, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x69, 0x0a, 0x10, 0x44, 0x65, 0x6c,
+	0x65, 0x74, 0x65, 0x46, 0x6f, 0x6f, 0x64, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x12, 0x28, 0x2e,
+	0x63, 0x65, 0x6e, 0x74, 0x72, 0x61, 0x6c, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x76, 0x31, 0x2e,
+	0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x46, 0x6f, 0x6f, 0x64, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x29, 0x2e, 0x63, 0x65, 0x6e, 0x74, 0x72, 0x61,
+	0x6c, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65,
+	0x46, 0x6f, 0x6f, 0x64, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x22, 0x00, 0x42, 0x35, 0x5a, 0x33, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63,
+	0x6f, 0x6d, 0x2f, 0x63, 0x61, 0x6c, 0x61, 0x6d, 0x69, 0x74, 0x79, 0x2d, 0x6d, 0x2f, 0x72, 0x65,
+	0x61, 0x70, 0x68, 0x75, 0x72, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x76, 0x31, 0x2f, 0x63,
+	0x65, 0x6e, 0x74, 0x72, 0x61, 0x6c, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x06, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x33,
 })
 
 var (
@@ -366,32 +606,43 @@ func file_proto_v1_central_central_food_proto_rawDescGZIP() []byte {
 	return file_proto_v1_central_central_food_proto_rawDescData
 }
 
-var file_proto_v1_central_central_food_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
+var file_proto_v1_central_central_food_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
 var file_proto_v1_central_central_food_proto_goTypes = []any{
 	(*CreateFoodRecordRequest)(nil),  // 0: centralproto.v1.CreateFoodRecordRequest
 	(*CreateFoodRecordResponse)(nil), // 1: centralproto.v1.CreateFoodRecordResponse
 	(*GetFoodFilter)(nil),            // 2: centralproto.v1.GetFoodFilter
 	(*GetFoodRecordsRequest)(nil),    // 3: centralproto.v1.GetFoodRecordsRequest
 	(*GetFoodRecordsResponse)(nil),   // 4: centralproto.v1.GetFoodRecordsResponse
-	(*domain.FoodRecord)(nil),        // 5: domain.v1.FoodRecord
-	(*timestamppb.Timestamp)(nil),    // 6: google.protobuf.Timestamp
+	(*UpdateFoodRecordRequest)(nil),  // 5: centralproto.v1.UpdateFoodRecordRequest
+	(*UpdateFoodRecordResponse)(nil), // 6: centralproto.v1.UpdateFoodRecordResponse
+	(*DeleteFoodRecordRequest)(nil),  // 7: centralproto.v1.DeleteFoodRecordRequest
+	(*DeleteFoodRecordResponse)(nil), // 8: centralproto.v1.DeleteFoodRecordResponse
+	(*domain.FoodRecord)(nil),        // 9: domain.v1.FoodRecord
+	(*timestamppb.Timestamp)(nil),    // 10: google.protobuf.Timestamp
 }
 var file_proto_v1_central_central_food_proto_depIdxs = []int32{
-	5, // 0: centralproto.v1.CreateFoodRecordRequest.record:type_name -> domain.v1.FoodRecord
-	5, // 1: centralproto.v1.CreateFoodRecordResponse.record:type_name -> domain.v1.FoodRecord
-	6, // 2: centralproto.v1.GetFoodFilter.before_time:type_name -> google.protobuf.Timestamp
-	6, // 3: centralproto.v1.GetFoodFilter.after_time:type_name -> google.protobuf.Timestamp
-	2, // 4: centralproto.v1.GetFoodRecordsRequest.filter:type_name -> centralproto.v1.GetFoodFilter
-	5, // 5: centralproto.v1.GetFoodRecordsResponse.records:type_name -> domain.v1.FoodRecord
-	0, // 6: centralproto.v1.CentralFoodService.CreateFoodRecord:input_type -> centralproto.v1.CreateFoodRecordRequest
-	3, // 7: centralproto.v1.CentralFoodService.GetFoodRecords:input_type -> centralproto.v1.GetFoodRecordsRequest
-	1, // 8: centralproto.v1.CentralFoodService.CreateFoodRecord:output_type -> centralproto.v1.CreateFoodRecordResponse
-	4, // 9: centralproto.v1.CentralFoodService.GetFoodRecords:output_type -> centralproto.v1.GetFoodRecordsResponse
-	8, // [8:10] is the sub-list for method output_type
-	6, // [6:8] is the sub-list for method input_type
-	6, // [6:6] is the sub-list for extension type_name
-	6, // [6:6] is the sub-list for extension extendee
-	0, // [0:6] is the sub-list for field type_name
+	9,  // 0: centralproto.v1.CreateFoodRecordRequest.record:type_name -> domain.v1.FoodRecord
+	9,  // 1: centralproto.v1.CreateFoodRecordResponse.record:type_name -> domain.v1.FoodRecord
+	10, // 2: centralproto.v1.GetFoodFilter.before_time:type_name -> google.protobuf.Timestamp
+	10, // 3: centralproto.v1.GetFoodFilter.after_time:type_name -> google.protobuf.Timestamp
+	2,  // 4: centralproto.v1.GetFoodRecordsRequest.filter:type_name -> centralproto.v1.GetFoodFilter
+	9,  // 5: centralproto.v1.GetFoodRecordsResponse.records:type_name -> domain.v1.FoodRecord
+	9,  // 6: centralproto.v1.UpdateFoodRecordRequest.record:type_name -> domain.v1.FoodRecord
+	9,  // 7: centralproto.v1.UpdateFoodRecordResponse.record:type_name -> domain.v1.FoodRecord
+	9,  // 8: centralproto.v1.DeleteFoodRecordResponse.record:type_name -> domain.v1.FoodRecord
+	0,  // 9: centralproto.v1.CentralFoodService.CreateFoodRecord:input_type -> centralproto.v1.CreateFoodRecordRequest
+	3,  // 10: centralproto.v1.CentralFoodService.GetFoodRecords:input_type -> centralproto.v1.GetFoodRecordsRequest
+	5,  // 11: centralproto.v1.CentralFoodService.UpdateFoodRecord:input_type -> centralproto.v1.UpdateFoodRecordRequest
+	7,  // 12: centralproto.v1.CentralFoodService.DeleteFoodRecord:input_type -> centralproto.v1.DeleteFoodRecordRequest
+	1,  // 13: centralproto.v1.CentralFoodService.CreateFoodRecord:output_type -> centralproto.v1.CreateFoodRecordResponse
+	4,  // 14: centralproto.v1.CentralFoodService.GetFoodRecords:output_type -> centralproto.v1.GetFoodRecordsResponse
+	6,  // 15: centralproto.v1.CentralFoodService.UpdateFoodRecord:output_type -> centralproto.v1.UpdateFoodRecordResponse
+	8,  // 16: centralproto.v1.CentralFoodService.DeleteFoodRecord:output_type -> centralproto.v1.DeleteFoodRecordResponse
+	13, // [13:17] is the sub-list for method output_type
+	9,  // [9:13] is the sub-list for method input_type
+	9,  // [9:9] is the sub-list for extension type_name
+	9,  // [9:9] is the sub-list for extension extendee
+	0,  // [0:9] is the sub-list for field type_name
 }
 
 func init() { file_proto_v1_central_central_food_proto_init() }
@@ -406,7 +657,7 @@ func file_proto_v1_central_central_food_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_v1_central_central_food_proto_rawDesc), len(file_proto_v1_central_central_food_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   5,
+			NumMessages:   9,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -21,6 +21,8 @@ const _ = grpc.SupportPackageIsVersion9
 const (
 	CentralFoodService_CreateFoodRecord_FullMethodName = "/centralproto.v1.CentralFoodService/CreateFoodRecord"
 	CentralFoodService_GetFoodRecords_FullMethodName   = "/centralproto.v1.CentralFoodService/GetFoodRecords"
+	CentralFoodService_UpdateFoodRecord_FullMethodName = "/centralproto.v1.CentralFoodService/UpdateFoodRecord"
+	CentralFoodService_DeleteFoodRecord_FullMethodName = "/centralproto.v1.CentralFoodService/DeleteFoodRecord"
 )
 
 // CentralFoodServiceClient is the client API for CentralFoodService service.
@@ -35,6 +37,14 @@ type CentralFoodServiceClient interface {
 	//
 	// Fetch some food records from the food diary/journal
 	GetFoodRecords(ctx context.Context, in *GetFoodRecordsRequest, opts ...grpc.CallOption) (*GetFoodRecordsResponse, error)
+	// Simple RPC
+	//
+	// Replace some food record in the food diary/journal
+	UpdateFoodRecord(ctx context.Context, in *UpdateFoodRecordRequest, opts ...grpc.CallOption) (*UpdateFoodRecordResponse, error)
+	// Simple RPC
+	//
+	// Remove some food record from the food diary/journal
+	DeleteFoodRecord(ctx context.Context, in *DeleteFoodRecordRequest, opts ...grpc.CallOption) (*DeleteFoodRecordResponse, error)
 }
 
 type centralFoodServiceClient struct {
@@ -65,6 +75,26 @@ func (c *centralFoodServiceClient) GetFoodRecords(ctx context.Context, in *GetFo
 	return out, nil
 }
 
+func (c *centralFoodServiceClient) UpdateFoodRecord(ctx context.Context, in *UpdateFoodRecordRequest, opts ...grpc.CallOption) (*UpdateFoodRecordResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UpdateFoodRecordResponse)
+	err := c.cc.Invoke(ctx, CentralFoodService_UpdateFoodRecord_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *centralFoodServiceClient) DeleteFoodRecord(ctx context.Context, in *DeleteFoodRecordRequest, opts ...grpc.CallOption) (*DeleteFoodRecordResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteFoodRecordResponse)
+	err := c.cc.Invoke(ctx, CentralFoodService_DeleteFoodRecord_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // CentralFoodServiceServer is the server API for CentralFoodService service.
 // All implementations must embed UnimplementedCentralFoodServiceServer
 // for forward compatibility.
@@ -77,6 +107,14 @@ type CentralFoodServiceServer interface {
 	//
 	// Fetch some food records from the food diary/journal
 	GetFoodRecords(context.Context, *GetFoodRecordsRequest) (*GetFoodRecordsResponse, error)
+	// Simple RPC
+	//
+	// Replace some food record in the food diary/journal
+	UpdateFoodRecord(context.Context, *UpdateFoodRecordRequest) (*UpdateFoodRecordResponse, error)
+	// Simple RPC
+	//
+	// Remove some food record from the food diary/journal
+	DeleteFoodRecord(context.Context, *DeleteFoodRecordRequest) (*DeleteFoodRecordResponse, error)
 	mustEmbedUnimplementedCentralFoodServiceServer()
 }
 
@@ -93,6 +131,12 @@ func (UnimplementedCentralFoodServiceServer) CreateFoodRecord(context.Context, *
 func (UnimplementedCentralFoodServiceServer) GetFoodRecords(context.Context, *GetFoodRecordsRequest) (*GetFoodRecordsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetFoodRecords not implemented")
 }
+func (UnimplementedCentralFoodServiceServer) UpdateFoodRecord(context.Context, *UpdateFoodRecordRequest) (*UpdateFoodRecordResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateFoodRecord not implemented")
+}
+func (UnimplementedCentralFoodServiceServer) DeleteFoodRecord(context.Context, *DeleteFoodRecordRequest) (*DeleteFoodRecordResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteFoodRecord not implemented")
+}
 func (UnimplementedCentralFoodServiceServer) mustEmbedUnimplementedCentralFoodServiceServer() {}
 func (UnimplementedCentralFoodServiceServer) testEmbeddedByValue()                            {}
 
@@ -150,6 +194,42 @@ func _CentralFoodService_GetFoodRecords_Handler(srv interface{}, ctx context.Con
 	return interceptor(ctx, in, info, handler)
 }
 
+func _CentralFoodService_UpdateFoodRecord_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateFoodRecordRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CentralFoodServiceServer).UpdateFoodRecord(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CentralFoodService_UpdateFoodRecord_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CentralFoodServiceServer).UpdateFoodRecord(ctx, req.(*UpdateFoodRecordRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CentralFoodService_DeleteFoodRecord_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteFoodRecordRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CentralFoodServiceServer).DeleteFoodRecord(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CentralFoodService_DeleteFoodRecord_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CentralFoodServiceServer).DeleteFoodRecord(ctx, req.(*DeleteFoodRecordRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // CentralFoodService_ServiceDesc is the grpc.ServiceDesc for CentralFoodService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -165,6 +245,14 @@ var CentralFoodService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetFoodRecords",
 			Handler:    _CentralFoodService_GetFoodRecords_Handler,
 		},
+		{
+			MethodName: "UpdateFoodRecord",
+			Handler:    _CentralFoodService_UpdateFoodRecord_Handler,
+		},
+		{
+			MethodName: "DeleteFoodRecord",
+			Handler:    _CentralFoodService_DeleteFoodRecord_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "proto/v1/central/central_food.proto",
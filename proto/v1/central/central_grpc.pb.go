@@ -19,8 +19,11 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	CentralService_ActionUserInput_FullMethodName = "/centralproto.v1.CentralService/ActionUserInput"
-	CentralService_CallFnUserInput_FullMethodName = "/centralproto.v1.CentralService/CallFnUserInput"
+	CentralService_ActionUserInput_FullMethodName       = "/centralproto.v1.CentralService/ActionUserInput"
+	CentralService_CallFnUserInput_FullMethodName       = "/centralproto.v1.CentralService/CallFnUserInput"
+	CentralService_CallFnUserInputStream_FullMethodName = "/centralproto.v1.CentralService/CallFnUserInputStream"
+	CentralService_ConfirmAction_FullMethodName         = "/centralproto.v1.CentralService/ConfirmAction"
+	CentralService_CancelAction_FullMethodName          = "/centralproto.v1.CentralService/CancelAction"
 )
 
 // CentralServiceClient is the client API for CentralService service.
@@ -41,6 +44,20 @@ type CentralServiceClient interface {
 	// to actually call the functions themselves, rather than them being stitched together
 	// by the implementing rpc service.
 	CallFnUserInput(ctx context.Context, in *CallFnUserInputRequest, opts ...grpc.CallOption) (*CallFnUserInputResponse, error)
+	// Server streaming RPC
+	//
+	// Same as CallFnUserInput, but streams tool progress and the response message
+	// as it is generated. The final event always holds the complete response.
+	CallFnUserInputStream(ctx context.Context, in *CallFnUserInputRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[CallFnUserInputStreamEvent], error)
+	// Simple RPC
+	//
+	// Executes a pending action previously returned from CallFnUserInput. Actions
+	// can only be confirmed by the user they were created for, and expire.
+	ConfirmAction(ctx context.Context, in *ConfirmActionRequest, opts ...grpc.CallOption) (*ConfirmActionResponse, error)
+	// Simple RPC
+	//
+	// Discards a pending action previously returned from CallFnUserInput
+	CancelAction(ctx context.Context, in *CancelActionRequest, opts ...grpc.CallOption) (*CancelActionResponse, error)
 }
 
 type centralServiceClient struct {
@@ -71,6 +88,45 @@ func (c *centralServiceClient) CallFnUserInput(ctx context.Context, in *CallFnUs
 	return out, nil
 }
 
+func (c *centralServiceClient) CallFnUserInputStream(ctx context.Context, in *CallFnUserInputRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[CallFnUserInputStreamEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &CentralService_ServiceDesc.Streams[0], CentralService_CallFnUserInputStream_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[CallFnUserInputRequest, CallFnUserInputStreamEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type CentralService_CallFnUserInputStreamClient = grpc.ServerStreamingClient[CallFnUserInputStreamEvent]
+
+func (c *centralServiceClient) ConfirmAction(ctx context.Context, in *ConfirmActionRequest, opts ...grpc.CallOption) (*ConfirmActionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ConfirmActionResponse)
+	err := c.cc.Invoke(ctx, CentralService_ConfirmAction_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *centralServiceClient) CancelAction(ctx context.Context, in *CancelActionRequest, opts ...grpc.CallOption) (*CancelActionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CancelActionResponse)
+	err := c.cc.Invoke(ctx, CentralService_CancelAction_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // CentralServiceServer is the server API for CentralService service.
 // All implementations must embed UnimplementedCentralServiceServer
 // for forward compatibility.
@@ -89,6 +145,20 @@ type CentralServiceServer interface {
 	// to actually call the functions themselves, rather than them being stitched together
 	// by the implementing rpc service.
 	CallFnUserInput(context.Context, *CallFnUserInputRequest) (*CallFnUserInputResponse, error)
+	// Server streaming RPC
+	//
+	// Same as CallFnUserInput, but streams tool progress and the response message
+	// as it is generated. The final event always holds the complete response.
+	CallFnUserInputStream(*CallFnUserInputRequest, grpc.ServerStreamingServer[CallFnUserInputStreamEvent]) error
+	// Simple RPC
+	//
+	// Executes a pending action previously returned from CallFnUserInput. Actions
+	// can only be confirmed by the user they were created for, and expire.
+	ConfirmAction(context.Context, *ConfirmActionRequest) (*ConfirmActionResponse, error)
+	// Simple RPC
+	//
+	// Discards a pending action previously returned from CallFnUserInput
+	CancelAction(context.Context, *CancelActionRequest) (*CancelActionResponse, error)
 	mustEmbedUnimplementedCentralServiceServer()
 }
 
@@ -105,6 +175,15 @@ func (UnimplementedCentralServiceServer) ActionUserInput(context.Context, *Actio
 func (UnimplementedCentralServiceServer) CallFnUserInput(context.Context, *CallFnUserInputRequest) (*CallFnUserInputResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method CallFnUserInput not implemented")
 }
+func (UnimplementedCentralServiceServer) CallFnUserInputStream(*CallFnUserInputRequest, grpc.ServerStreamingServer[CallFnUserInputStreamEvent]) error {
+	return status.Errorf(codes.Unimplemented, "method CallFnUserInputStream not implemented")
+}
+func (UnimplementedCentralServiceServer) ConfirmAction(context.Context, *ConfirmActionRequest) (*ConfirmActionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ConfirmAction not implemented")
+}
+func (UnimplementedCentralServiceServer) CancelAction(context.Context, *CancelActionRequest) (*CancelActionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CancelAction not implemented")
+}
 func (UnimplementedCentralServiceServer) mustEmbedUnimplementedCentralServiceServer() {}
 func (UnimplementedCentralServiceServer) testEmbeddedByValue()                        {}
 
@@ -162,6 +241,53 @@ func _CentralService_CallFnUserInput_Handler(srv interface{}, ctx context.Contex
 	return interceptor(ctx, in, info, handler)
 }
 
+func _CentralService_CallFnUserInputStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(CallFnUserInputRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(CentralServiceServer).CallFnUserInputStream(m, &grpc.GenericServerStream[CallFnUserInputRequest, CallFnUserInputStreamEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type CentralService_CallFnUserInputStreamServer = grpc.ServerStreamingServer[CallFnUserInputStreamEvent]
+
+func _CentralService_ConfirmAction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ConfirmActionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CentralServiceServer).ConfirmAction(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CentralService_ConfirmAction_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CentralServiceServer).ConfirmAction(ctx, req.(*ConfirmActionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CentralService_CancelAction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CancelActionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CentralServiceServer).CancelAction(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CentralService_CancelAction_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CentralServiceServer).CancelAction(ctx, req.(*CancelActionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // CentralService_ServiceDesc is the grpc.ServiceDesc for CentralService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -177,7 +303,21 @@ var CentralService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "CallFnUserInput",
 			Handler:    _CentralService_CallFnUserInput_Handler,
 		},
+		{
+			MethodName: "ConfirmAction",
+			Handler:    _CentralService_ConfirmAction_Handler,
+		},
+		{
+			MethodName: "CancelAction",
+			Handler:    _CentralService_CancelAction_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "CallFnUserInputStream",
+			Handler:       _CentralService_CallFnUserInputStream_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "proto/v1/central/central.proto",
 }